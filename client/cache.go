@@ -0,0 +1,287 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DefaultCacheMaxBytes is the default memory bound of a CachingClient
+const DefaultCacheMaxBytes = 64 << 20
+
+// pinnedMethods holds the methods whose results are immutable when they are called for a specific block, with the
+// number of arguments of such calls. The block hash is always the last argument, calls with fewer arguments refer to
+// the latest block and are not cached.
+var pinnedMethods = map[string]int{
+	"chain_getBlock":               1,
+	"chain_getHeader":              1,
+	"state_getMetadata":            1,
+	"state_getRuntimeVersion":      1,
+	"state_getStorage":             2,
+	"state_getStorageHash":         2,
+	"state_getStorageSize":         2,
+	"state_getKeys":                2,
+	"state_getKeysPaged":           4,
+	"state_queryStorage":           3,
+	"state_queryStorageAt":         2,
+	"state_getReadProof":           2,
+	"state_getChildKeys":           3,
+	"state_getChildStorage":        3,
+	"state_getChildStorageHash":    3,
+	"state_getChildStorageSize":    3,
+	"childstate_getKeys":           3,
+	"childstate_getKeysPaged":      5,
+	"childstate_getStorage":        3,
+	"childstate_getStorageEntries": 3,
+	"childstate_getStorageHash":    3,
+	"childstate_getStorageSize":    3,
+}
+
+// unknownIfNullMethods holds the pinned methods that return null for blocks the node does not know, because they have
+// not been imported yet or have been pruned. Such results may change and are never cached.
+var unknownIfNullMethods = map[string]bool{
+	"chain_getBlock":  true,
+	"chain_getHeader": true,
+}
+
+// CacheOptions configures a CachingClient
+type CacheOptions struct {
+	// MaxBytes bounds the size of the results held in memory, DefaultCacheMaxBytes if zero. The least recently used
+	// results are evicted first.
+	MaxBytes int
+	// Dir is the directory of the optional on-disk store. If set, results are also written to this directory and read
+	// from it when they are not held in memory, so they survive restarts. The directory is not bounded in size.
+	Dir string
+}
+
+// CacheStats holds the statistics of a CachingClient
+type CacheStats struct {
+	// Hits is the number of calls served from memory
+	Hits uint64
+	// DiskHits is the number of calls served from the on-disk store
+	DiskHits uint64
+	// Misses is the number of cacheable calls sent to the node
+	Misses uint64
+	// Uncached is the number of calls sent to the node that are not cacheable, like calls for the latest block
+	Uncached uint64
+	// Evictions is the number of results evicted from memory
+	Evictions uint64
+	// Entries and Bytes are the number and size of the results held in memory
+	Entries int
+	Bytes   int
+}
+
+// CachingClient is a Client caching the results of calls that are immutable, because they are made for a specific
+// block hash, like GetStorage, GetMetadata, GetBlock or GetHeader. Calls for the latest block, calls of other methods
+// and subscriptions are passed through. Errors are never cached, neither are null blocks and headers, as the node returns
+// them for blocks it does not know yet.
+//
+// Wrap a client to cache all calls made through it:
+//
+//	cl, err := client.Connect(url)
+//	if err != nil {
+//		return err
+//	}
+//	r := rpc.NewRPC(client.NewCachingClient(cl, client.CacheOptions{Dir: "cache"}))
+type CachingClient struct {
+	Client
+	maxBytes int
+	dir      string
+
+	mu      sync.Mutex
+	lru     *list.List // of *cacheEntry, most recently used first
+	entries map[string]*list.Element
+	stats   CacheStats
+}
+
+type cacheEntry struct {
+	key    string
+	result json.RawMessage
+}
+
+// NewCachingClient creates a new CachingClient around the given client
+func NewCachingClient(c Client, opts CacheOptions) *CachingClient {
+	maxBytes := opts.MaxBytes
+	if maxBytes == 0 {
+		maxBytes = DefaultCacheMaxBytes
+	}
+	return &CachingClient{
+		Client:   c,
+		maxBytes: maxBytes,
+		dir:      opts.Dir,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Call performs the call, or serves it from the cache if it is immutable and has been made before
+func (c *CachingClient) Call(result interface{}, method string, args ...interface{}) error {
+	n, ok := pinnedMethods[method]
+	if !ok || len(args) != n || args[n-1] == nil {
+		c.mu.Lock()
+		c.stats.Uncached++
+		c.mu.Unlock()
+		return c.Client.Call(result, method, args...)
+	}
+
+	key, err := cacheKey(method, args)
+	if err != nil {
+		return err
+	}
+
+	if raw, ok := c.get(key); ok {
+		return json.Unmarshal(raw, result)
+	}
+
+	var raw json.RawMessage
+	err = c.Client.Call(&raw, method, args...)
+	if err != nil {
+		return err
+	}
+	if raw == nil {
+		// null results are not unmarshalled into raw
+		raw = json.RawMessage("null")
+		if unknownIfNullMethods[method] {
+			return json.Unmarshal(raw, result)
+		}
+	}
+	c.put(key, raw, true)
+	return json.Unmarshal(raw, result)
+}
+
+// Stats returns the statistics of the cache
+func (c *CachingClient) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	return stats
+}
+
+// Purge removes all results held in memory, the on-disk store is left untouched
+func (c *CachingClient) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Init()
+	c.entries = make(map[string]*list.Element)
+	c.stats.Bytes = 0
+}
+
+// get returns the cached result of the call with the given key, from memory or from the on-disk store
+func (c *CachingClient) get(key string) (json.RawMessage, bool) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(e)
+		c.stats.Hits++
+		c.mu.Unlock()
+		return e.Value.(*cacheEntry).result, true
+	}
+	c.mu.Unlock()
+
+	if c.dir != "" {
+		raw, err := ioutil.ReadFile(c.path(key))
+		if err == nil {
+			c.put(key, raw, false)
+			c.mu.Lock()
+			c.stats.DiskHits++
+			c.mu.Unlock()
+			return raw, true
+		}
+	}
+
+	c.mu.Lock()
+	c.stats.Misses++
+	c.mu.Unlock()
+	return nil, false
+}
+
+// put adds the result of the call with the given key to memory, evicting the least recently used results beyond the
+// memory bound, and to the on-disk store if store is set. Results larger than the memory bound are not held in memory.
+func (c *CachingClient) put(key string, raw json.RawMessage, store bool) {
+	if store && c.dir != "" {
+		// the cache is best effort, failing writes only mean the result is fetched again
+		_ = c.write(key, raw)
+	}
+
+	size := len(key) + len(raw)
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, result: raw})
+	c.stats.Bytes += size
+
+	for c.stats.Bytes > c.maxBytes {
+		e := c.lru.Back()
+		entry := e.Value.(*cacheEntry)
+		c.lru.Remove(e)
+		delete(c.entries, entry.key)
+		c.stats.Bytes -= len(entry.key) + len(entry.result)
+		c.stats.Evictions++
+	}
+}
+
+// write writes the result to the on-disk store, through a temporary file so readers never see partial results
+func (c *CachingClient) write(key string, raw json.RawMessage) error {
+	err := os.MkdirAll(c.dir, 0755)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(c.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(raw)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	err = f.Close()
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), c.path(key))
+}
+
+// path returns the path of the file holding the result of the call with the given key
+func (c *CachingClient) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// cacheKey returns the key of the call, the method and its arguments as sent to the node
+func cacheKey(method string, args []interface{}) (string, error) {
+	bz, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	return method + string(bz), nil
+}
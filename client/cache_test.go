@@ -0,0 +1,191 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/zenghq3/go-substrate-rpc-client/client"
+	"github.com/zenghq3/go-substrate-rpc-client/rpcmocksrv"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// countingMockSrv counts the calls of state_getStorage and fails for the key 0xff
+type countingMockSrv struct {
+	calls int
+}
+
+func (s *countingMockSrv) GetStorage(key string, hash *string) (*string, error) {
+	s.calls++
+	if key == "0xff" {
+		return nil, fmt.Errorf("unavailable")
+	}
+	if key == "0x00" {
+		return nil, nil
+	}
+	value := key + "00"
+	return &value, nil
+}
+
+func newCountingMock(t *testing.T) (Client, *countingMockSrv) {
+	srv := &countingMockSrv{}
+	s := rpcmocksrv.New()
+	assert.NoError(t, s.RegisterName("state", srv))
+	cl, err := Connect(s.URL)
+	assert.NoError(t, err)
+	return cl, srv
+}
+
+func TestCachingClient_Call(t *testing.T) {
+	cl, srv := newCountingMock(t)
+	c := NewCachingClient(cl, CacheOptions{})
+	hash := types.NewHash([]byte{0x01}).Hex()
+
+	for i := 0; i < 3; i++ {
+		var res string
+		assert.NoError(t, c.Call(&res, "state_getStorage", "0x01", hash))
+		assert.Equal(t, "0x0100", res)
+	}
+	assert.Equal(t, 1, srv.calls)
+
+	// absent values are immutable as well
+	for i := 0; i < 2; i++ {
+		var res *string
+		assert.NoError(t, c.Call(&res, "state_getStorage", "0x00", hash))
+		assert.Nil(t, res)
+	}
+	assert.Equal(t, 2, srv.calls)
+
+	// calls for the latest block are not cached
+	for i := 0; i < 2; i++ {
+		var res string
+		assert.NoError(t, c.Call(&res, "state_getStorage", "0x01"))
+	}
+	assert.Equal(t, 4, srv.calls)
+
+	// errors are not cached
+	for i := 0; i < 2; i++ {
+		var res string
+		assert.EqualError(t, c.Call(&res, "state_getStorage", "0xff", hash), "unavailable")
+	}
+	assert.Equal(t, 6, srv.calls)
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(3), stats.Hits)
+	assert.Equal(t, uint64(4), stats.Misses)
+	assert.Equal(t, uint64(2), stats.Uncached)
+	assert.Equal(t, 2, stats.Entries)
+}
+
+// headerMockSrv serves chain_getHeader and returns null until the header is imported
+type headerMockSrv struct {
+	calls    int
+	imported bool
+}
+
+func (s *headerMockSrv) GetHeader(hash *string) (*types.Header, error) {
+	s.calls++
+	if !s.imported {
+		return nil, nil
+	}
+	return &types.Header{Number: 5}, nil
+}
+
+func TestCachingClient_UnknownBlock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	srv := &headerMockSrv{}
+	s := rpcmocksrv.New()
+	assert.NoError(t, s.RegisterName("chain", srv))
+	cl, err := Connect(s.URL)
+	assert.NoError(t, err)
+	c := NewCachingClient(cl, CacheOptions{Dir: dir})
+	hash := types.NewHash([]byte{0x01}).Hex()
+
+	// headers of blocks not imported yet are neither held in memory nor written to disk
+	var header *types.Header
+	assert.NoError(t, c.Call(&header, "chain_getHeader", hash))
+	assert.Nil(t, header)
+	assert.Equal(t, 0, c.Stats().Entries)
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+
+	srv.imported = true
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, c.Call(&header, "chain_getHeader", hash))
+		assert.Equal(t, types.BlockNumber(5), header.Number)
+	}
+	assert.Equal(t, 2, srv.calls)
+}
+
+func TestCachingClient_Eviction(t *testing.T) {
+	cl, srv := newCountingMock(t)
+	hash := types.NewHash([]byte{0x01}).Hex()
+	var res string
+	assert.NoError(t, cl.Call(&res, "state_getStorage", "0x01", hash))
+	// room for two entries, each the key and the result
+	entrySize := len(`state_getStorage["0x01","`+hash+`"]`) + len(`"0x0100"`)
+	c := NewCachingClient(cl, CacheOptions{MaxBytes: 2 * entrySize})
+	srv.calls = 0
+
+	for _, key := range []string{"0x01", "0x02", "0x01", "0x03", "0x02"} {
+		assert.NoError(t, c.Call(&res, "state_getStorage", key, hash))
+		assert.Equal(t, key+"00", res)
+	}
+	// 0x03 evicted 0x02, the least recently used
+	assert.Equal(t, 4, srv.calls)
+	stats := c.Stats()
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(2), stats.Evictions)
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, 2*entrySize, stats.Bytes)
+
+	c.Purge()
+	assert.Equal(t, 0, c.Stats().Entries)
+	assert.Equal(t, 0, c.Stats().Bytes)
+}
+
+func TestCachingClient_Disk(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cl, srv := newCountingMock(t)
+	hash := types.NewHash([]byte{0x01}).Hex()
+	var res string
+	c := NewCachingClient(cl, CacheOptions{Dir: dir})
+	assert.NoError(t, c.Call(&res, "state_getStorage", "0x01", hash))
+
+	// a new client, like after a restart, reads the result from disk
+	c = NewCachingClient(cl, CacheOptions{Dir: dir})
+	assert.NoError(t, c.Call(&res, "state_getStorage", "0x01", hash))
+	assert.Equal(t, "0x0100", res)
+	assert.NoError(t, c.Call(&res, "state_getStorage", "0x01", hash))
+	assert.Equal(t, 1, srv.calls)
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(1), stats.DiskHits)
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(0), stats.Misses)
+}
@@ -33,18 +33,12 @@ type Client interface {
 		*gethrpc.ClientSubscription, error)
 
 	URL() string
-	//MetaData(cache bool) (*MetadataVersioned, error)
 }
 
 type client struct {
 	gethrpc.Client
 
 	url string
-
-	// metadataVersioned is the metadata cache to prevent unnecessary requests
-	//metadataVersioned *MetadataVersioned
-
-	//metadataLock sync.RWMutex
 }
 
 // URL returns the URL the client connects to
@@ -52,26 +46,6 @@ func (c client) URL() string {
 	return c.url
 }
 
-// TODO move to State struct
-//func (c *client) MetaData(cache bool) (m *MetadataVersioned, err error) {
-//	if cache && c.metadataVersioned != nil {
-//		c.metadataLock.RLock()
-//		defer c.metadataLock.RUnlock()
-//		m = c.metadataVersioned
-//	} else {
-//		s := NewStateRPC(c)
-//		m, err = s.MetaData(nil)
-//		if err != nil {
-//			return nil, err
-//		}
-//		// set cache
-//		c.metadataLock.Lock()
-//		defer c.metadataLock.Unlock()
-//		c.metadataVersioned = m
-//	}
-//	return
-//}
-
 // Connect connects to the provided url
 func Connect(url string) (Client, error) {
 	log.Printf("Connecting to %v...", url)
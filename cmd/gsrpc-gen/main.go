@@ -0,0 +1,93 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command gsrpc-gen generates a Go package with typed calls, events, storage accessors and constants from metadata.
+//
+// The metadata is read from a file containing either the SCALE encoded metadata or its hex encoding, as returned by
+// state_getMetadata. It is meant to be used with go generate, e.g.
+//
+//	//go:generate go run github.com/zenghq3/go-substrate-rpc-client/cmd/gsrpc-gen -metadata metadata.hex -out runtime.go
+//
+// Chain specific types for metadata before v14 can be given with a polkadot-js style types bundle, together with the
+// spec name and version of the runtime to select the applicable definitions.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/zenghq3/go-substrate-rpc-client/cmd/internal/metafile"
+	"github.com/zenghq3/go-substrate-rpc-client/codegen"
+	"github.com/zenghq3/go-substrate-rpc-client/registry"
+)
+
+func main() {
+	metadataPath := flag.String("metadata", "", "path of the SCALE or hex encoded metadata")
+	pkg := flag.String("pkg", "runtime", "name of the generated package")
+	out := flag.String("out", "", "path of the generated file, defaults to stdout")
+	bundlePath := flag.String("types", "", "path of a JSON types bundle with chain specific types")
+	specName := flag.String("spec", "", "spec name of the runtime, to select the types of the bundle")
+	specVersion := flag.Uint("spec-version", 0, "spec version of the runtime, to select the types of the bundle")
+	flag.Parse()
+
+	err := run(*metadataPath, *pkg, *out, *bundlePath, *specName, uint32(*specVersion))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gsrpc-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(metadataPath, pkg, out, bundlePath, specName string, specVersion uint32) error {
+	if metadataPath == "" {
+		return fmt.Errorf("missing -metadata")
+	}
+	meta, err := metafile.Read(metadataPath)
+	if err != nil {
+		return err
+	}
+
+	reg := registry.NewRegistry()
+	if bundlePath != "" {
+		bz, err := ioutil.ReadFile(bundlePath)
+		if err != nil {
+			return err
+		}
+		bundle, err := registry.ParseTypesBundle(bz)
+		if err != nil {
+			return err
+		}
+		reg, err = bundle.Registry(reg, specName, specVersion)
+		if err != nil {
+			return err
+		}
+	}
+
+	output, err := codegen.Generate(meta, codegen.Options{Package: pkg, Registry: reg})
+	if err != nil {
+		return err
+	}
+	for _, s := range output.Skipped {
+		fmt.Fprintf(os.Stderr, "gsrpc-gen: skipped %v\n", s)
+	}
+
+	if out == "" {
+		_, err = os.Stdout.Write(output.Source)
+		return err
+	}
+	return ioutil.WriteFile(out, output.Source, 0644)
+}
@@ -0,0 +1,83 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command gsrpc-metadiff compares two versions of metadata and reports the added, removed and changed modules, calls,
+// events, storage entries, constants and errors, for example
+//
+//	gsrpc-metadiff -fail-on-breaking old.hex new.hex
+//
+// The metadata is read from files containing either the SCALE encoded metadata or its hex encoding, as returned by
+// state_getMetadata. With -json the report is written as JSON instead of text. With -fail-on-breaking the command
+// exits with status 2 if there are breaking changes.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/zenghq3/go-substrate-rpc-client/cmd/internal/metafile"
+	"github.com/zenghq3/go-substrate-rpc-client/metadiff"
+)
+
+func main() {
+	asJSON := flag.Bool("json", false, "write the report as JSON")
+	failOnBreaking := flag.Bool("fail-on-breaking", false, "exit with status 2 if there are breaking changes")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: gsrpc-metadiff [flags] <old metadata> <new metadata>\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	report, err := run(flag.Arg(0), flag.Arg(1), *asJSON)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gsrpc-metadiff: %v\n", err)
+		os.Exit(1)
+	}
+	if *failOnBreaking && report.HasBreakingChanges() {
+		os.Exit(2)
+	}
+}
+
+func run(oldPath, newPath string, asJSON bool) (*metadiff.Report, error) {
+	oldMeta, err := metafile.Read(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newMeta, err := metafile.Read(newPath)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := metadiff.Compare(oldMeta, newMeta)
+	if err != nil {
+		return nil, err
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return report, enc.Encode(report)
+	}
+	_, err = fmt.Print(report)
+	return report, err
+}
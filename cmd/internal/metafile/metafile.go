@@ -0,0 +1,50 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metafile reads metadata files for the commands of this repository.
+package metafile
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// Read reads SCALE or hex encoded metadata from a file. The hex encoding may be given as JSON string, as in the result
+// of state_getMetadata.
+func Read(path string) (*types.Metadata, error) {
+	bz, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := bytes.Trim(bytes.TrimSpace(bz), `"`)
+	if bytes.HasPrefix(trimmed, []byte("0x")) {
+		bz, err = types.HexDecodeString(string(trimmed))
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode hex metadata of %v: %v", path, err)
+		}
+	}
+
+	var meta types.Metadata
+	err = types.DecodeFromBytes(bz, &meta)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode metadata of %v: %v", path, err)
+	}
+	return &meta, nil
+}
@@ -0,0 +1,57 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metafile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+func TestRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metafile")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bz, err := types.HexDecodeString(types.ExamplaryMetadataV4String)
+	assert.NoError(t, err)
+
+	files := map[string][]byte{
+		"scale":   bz,
+		"hex":     []byte(types.ExamplaryMetadataV4String + "\n"),
+		"json":    []byte(`"` + types.ExamplaryMetadataV4String + `"`),
+		"invalid": []byte("0xzz"),
+	}
+	for name, content := range files {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), content, 0644))
+	}
+
+	for _, name := range []string{"scale", "hex", "json"} {
+		meta, err := Read(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		assert.Equal(t, types.ExamplaryMetadataV4, meta)
+	}
+
+	_, err = Read(filepath.Join(dir, "invalid"))
+	assert.Error(t, err)
+	_, err = Read(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,566 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package codegen generates a Go package with typed calls, events, storage accessors and constants from metadata. The
+generated package depends on the types package only, for example
+
+	// NewBalancesTransferCall creates a call to Balances.transfer
+	func NewBalancesTransferCall(meta *types.Metadata, dest types.Address, value types.UCompact) (types.Call, error)
+
+	// EventBalancesTransfer is emitted by Balances.Transfer
+	type EventBalancesTransfer struct { ... }
+
+	// EventRecords holds the events of all modules, to be used with types.EventRecordsRaw.DecodeEventRecords
+	type EventRecords struct { ... }
+
+	// GetBalancesFreeBalance returns the value of the storage Balances.FreeBalance at the given block
+	func GetBalancesFreeBalance(state State, meta *types.Metadata, accountID types.AccountID, blockHash types.Hash)
+		(types.U128, bool, error)
+
+	// BalancesExistentialDeposit returns the value of the constant Balances.ExistentialDeposit
+	func BalancesExistentialDeposit(meta *types.Metadata) (types.U128, error)
+
+Type strings of metadata before v14 are resolved with a registry.Registry, which can be extended with chain specific
+types from a types bundle. Types without a suitable type in the types package, such as structs and enums, are declared
+in the generated package. Items with types that are unknown to the registry are skipped and listed in Output.Skipped.
+Code generation requires metadata v7 or later.
+*/
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+
+	"github.com/zenghq3/go-substrate-rpc-client/registry"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// Options configure the code generation
+type Options struct {
+	// Package is the name of the generated package
+	Package string
+	// Registry resolves the type strings of metadata before v14, defaults to registry.NewRegistry()
+	Registry *registry.Registry
+}
+
+// Output is the result of the code generation
+type Output struct {
+	// Source is the formatted source of the generated package
+	Source []byte
+	// Skipped lists the items that were not generated together with the reason
+	Skipped []string
+}
+
+// Generate generates the source of a Go package for the metadata
+func Generate(meta *types.Metadata, opts Options) (*Output, error) {
+	if opts.Package == "" {
+		return nil, fmt.Errorf("missing package name")
+	}
+	if opts.Registry == nil {
+		opts.Registry = registry.NewRegistry()
+	}
+
+	g, err := newGenerator(meta, opts.Registry)
+	if err != nil {
+		return nil, err
+	}
+	modules, err := g.modules()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range modules {
+		g.reserveModule(m)
+	}
+	for _, m := range modules {
+		g.generateModule(m)
+	}
+
+	var buf bytes.Buffer
+	g.writeFile(&buf, opts.Package)
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("unable to format generated code: %v", err)
+	}
+	return &Output{Source: src, Skipped: g.skipped}, nil
+}
+
+// module is the part of the module metadata used for code generation, with the same shape for all versions
+type module struct {
+	name      string
+	calls     []function
+	events    []function
+	storage   []string
+	prefix    string
+	constants []constant
+}
+
+type function struct {
+	name string
+	args []arg
+}
+
+type arg struct {
+	name string
+	def  *registry.TypeDef
+}
+
+type constant struct {
+	name string
+	def  *registry.TypeDef
+}
+
+type generator struct {
+	meta     *types.Metadata
+	reg      *registry.Registry
+	defaults *registry.Registry
+	portable map[int64]types.Si1Type
+
+	// names holds the identifiers declared in the generated package
+	names map[string]bool
+	// typeNames maps registry type names to the names of the declared Go types
+	typeNames map[string]string
+	// inProgress holds the names of the types that are being declared, to detect recursive types
+	inProgress map[string]bool
+
+	calls      bytes.Buffer
+	events     bytes.Buffer
+	records    bytes.Buffer
+	storage    bytes.Buffer
+	constants  bytes.Buffer
+	decls      bytes.Buffer
+	hasStorage bool
+	usesScale  bool
+	usesFmt    bool
+	skipped    []string
+}
+
+func newGenerator(meta *types.Metadata, reg *registry.Registry) (*generator, error) {
+	g := &generator{
+		meta:       meta,
+		reg:        reg,
+		defaults:   registry.NewRegistry(),
+		names:      make(map[string]bool),
+		typeNames:  make(map[string]string),
+		inProgress: make(map[string]bool),
+	}
+
+	if meta.IsMetadataV14 {
+		var err error
+		g.reg, err = reg.WithPortableTypes(meta.AsMetadataV14.Lookup)
+		if err != nil {
+			return nil, err
+		}
+		g.portable = make(map[int64]types.Si1Type, len(meta.AsMetadataV14.Lookup))
+		for _, t := range meta.AsMetadataV14.Lookup {
+			g.portable[t.ID.Int64()] = t.Type
+		}
+	}
+	return g, nil
+}
+
+// modules converts the modules of the metadata
+func (g *generator) modules() ([]module, error) {
+	switch {
+	case g.meta.IsMetadataV7:
+		var modules []module
+		for _, m := range g.meta.AsMetadataV7.Modules {
+			mod := g.legacyModule(string(m.Name), m.HasStorage, storageNamesV5(m.Storage), m.HasCalls, m.Calls,
+				m.HasEvents, m.Events, m.Constants)
+			mod.prefix = string(m.Storage.Prefix)
+			modules = append(modules, mod)
+		}
+		return modules, nil
+	case g.meta.IsMetadataV8:
+		return g.modulesV8(g.meta.AsMetadataV8.Modules), nil
+	case g.meta.IsMetadataV9:
+		return g.modulesV8(g.meta.AsMetadataV9.Modules), nil
+	case g.meta.IsMetadataV10:
+		var modules []module
+		for _, m := range g.meta.AsMetadataV10.Modules {
+			names := make([]string, len(m.Storage.Items))
+			for i, s := range m.Storage.Items {
+				names[i] = string(s.Name)
+			}
+			mod := g.legacyModule(string(m.Name), m.HasStorage, names, m.HasCalls, m.Calls, m.HasEvents, m.Events,
+				m.Constants)
+			mod.prefix = string(m.Storage.Prefix)
+			modules = append(modules, mod)
+		}
+		return modules, nil
+	case g.meta.IsMetadataV11:
+		var modules []module
+		for _, m := range g.meta.AsMetadataV11.Modules {
+			names := make([]string, len(m.Storage.Items))
+			for i, s := range m.Storage.Items {
+				names[i] = string(s.Name)
+			}
+			mod := g.legacyModule(string(m.Name), m.HasStorage, names, m.HasCalls, m.Calls, m.HasEvents, m.Events,
+				m.Constants)
+			mod.prefix = string(m.Storage.Prefix)
+			modules = append(modules, mod)
+		}
+		return modules, nil
+	case g.meta.IsMetadataV14:
+		return g.modulesV14()
+	default:
+		return nil, fmt.Errorf("code generation requires metadata v7 or later, but got v%v", g.meta.Version)
+	}
+}
+
+func (g *generator) modulesV8(mods []types.ModuleMetadataV8) []module {
+	modules := make([]module, len(mods))
+	for i, m := range mods {
+		modules[i] = g.legacyModule(string(m.Name), m.HasStorage, storageNamesV5(m.Storage), m.HasCalls, m.Calls,
+			m.HasEvents, m.Events, m.Constants)
+		modules[i].prefix = string(m.Storage.Prefix)
+	}
+	return modules
+}
+
+func storageNamesV5(s types.StorageMetadata) []string {
+	names := make([]string, len(s.Items))
+	for i, item := range s.Items {
+		names[i] = string(item.Name)
+	}
+	return names
+}
+
+// legacyModule converts a module of metadata before v14, where types are described by type strings. Items with type
+// strings that cannot be parsed are skipped.
+func (g *generator) legacyModule(name string, hasStorage bool, storage []string, hasCalls bool,
+	calls []types.FunctionMetadataV4, hasEvents bool, events []types.EventMetadataV4,
+	constants []types.ModuleConstantMetadataV6) module {
+	m := module{name: name, prefix: name}
+
+	if hasStorage {
+		m.storage = storage
+	}
+
+	if hasCalls {
+	calls:
+		for _, c := range calls {
+			f := function{name: string(c.Name)}
+			for _, a := range c.Args {
+				def, err := g.reg.ParseModuleTypeString(name, string(a.Type))
+				if err != nil {
+					g.skip("call %v.%v: %v", name, c.Name, err)
+					continue calls
+				}
+				f.args = append(f.args, arg{name: string(a.Name), def: def})
+			}
+			m.calls = append(m.calls, f)
+		}
+	}
+
+	if hasEvents {
+	events:
+		for _, e := range events {
+			f := function{name: string(e.Name)}
+			for _, a := range e.Args {
+				def, err := g.reg.ParseModuleTypeString(name, string(a))
+				if err != nil {
+					g.skip("event %v.%v: %v", name, e.Name, err)
+					continue events
+				}
+				f.args = append(f.args, arg{def: def})
+			}
+			m.events = append(m.events, f)
+		}
+	}
+
+	for _, c := range constants {
+		def, err := g.reg.ParseModuleTypeString(name, string(c.Type))
+		if err != nil {
+			g.skip("constant %v.%v: %v", name, c.Name, err)
+			continue
+		}
+		m.constants = append(m.constants, constant{name: string(c.Name), def: def})
+	}
+	return m
+}
+
+func (g *generator) modulesV14() ([]module, error) {
+	meta := g.meta.AsMetadataV14
+	modules := make([]module, len(meta.Pallets))
+	for i, p := range meta.Pallets {
+		m := module{name: string(p.Name), prefix: string(p.Storage.Prefix)}
+
+		if p.HasStorage {
+			for _, s := range p.Storage.Items {
+				m.storage = append(m.storage, string(s.Name))
+			}
+		}
+
+		if p.HasCalls {
+			calls, err := g.variantFunctions(p.Calls.Type)
+			if err != nil {
+				return nil, fmt.Errorf("unable to find calls of %v: %v", p.Name, err)
+			}
+			m.calls = calls
+		}
+
+		if p.HasEvents {
+			events, err := g.variantFunctions(p.Events.Type)
+			if err != nil {
+				return nil, fmt.Errorf("unable to find events of %v: %v", p.Name, err)
+			}
+			m.events = events
+		}
+
+		for _, c := range p.Constants {
+			m.constants = append(m.constants, constant{name: string(c.Name), def: registry.NewPortable(c.Type)})
+		}
+		modules[i] = m
+	}
+	return modules, nil
+}
+
+// variantFunctions converts the variants of the call or event enum of a pallet
+func (g *generator) variantFunctions(id types.Si1LookupTypeID) ([]function, error) {
+	t, ok := g.portable[id.Int64()]
+	if !ok || !t.Def.IsVariant {
+		return nil, fmt.Errorf("type %v is not a variant", id.Int64())
+	}
+
+	functions := make([]function, len(t.Def.AsVariant.Variants))
+	for i, v := range t.Def.AsVariant.Variants {
+		functions[i].name = string(v.Name)
+		for _, f := range v.Fields {
+			functions[i].args = append(functions[i].args, arg{name: string(f.Name), def: registry.NewPortable(f.Type)})
+		}
+	}
+	return functions, nil
+}
+
+func (g *generator) skip(format string, args ...interface{}) {
+	g.skipped = append(g.skipped, fmt.Sprintf(format, args...))
+}
+
+// reserveModule reserves the names of the functions and event structs of the module, so declared types do not take
+// them
+func (g *generator) reserveModule(m module) {
+	mod := exportedName(m.name)
+	for _, c := range m.calls {
+		g.names["New"+mod+exportedName(c.name)+"Call"] = true
+	}
+	for _, e := range m.events {
+		g.names["Event"+mod+exportedName(e.name)] = true
+	}
+	for _, s := range m.storage {
+		g.names[mod+exportedName(s)+"Key"] = true
+		g.names["Get"+mod+exportedName(s)] = true
+		g.names["Get"+mod+exportedName(s)+"Latest"] = true
+	}
+	for _, c := range m.constants {
+		g.names[mod+exportedName(c.name)] = true
+	}
+	g.names["EventRecords"] = true
+	g.names["State"] = true
+}
+
+func (g *generator) generateModule(m module) {
+	for _, c := range m.calls {
+		g.generateCall(m, c)
+	}
+	for _, e := range m.events {
+		g.generateEvent(m, e)
+	}
+	for _, s := range m.storage {
+		g.generateStorage(m, s)
+	}
+	for _, c := range m.constants {
+		g.generateConstant(m, c)
+	}
+}
+
+func (g *generator) generateCall(m module, c function) {
+	cp := g.checkpoint()
+	params := make([]string, len(c.args))
+	names := make([]string, len(c.args))
+	used := map[string]bool{"meta": true}
+	for i, a := range c.args {
+		typ, err := g.goType(a.def, exportedName(m.name)+exportedName(c.name)+exportedName(a.name))
+		if err != nil {
+			g.rollback(cp)
+			g.skip("call %v.%v: %v", m.name, c.name, err)
+			return
+		}
+		names[i] = uniqueName(paramName(a.name, i), used)
+		params[i] = names[i] + " " + typ
+	}
+
+	name := "New" + exportedName(m.name) + exportedName(c.name) + "Call"
+	fmt.Fprintf(&g.calls, "// %v creates a call to %v.%v\n", name, m.name, c.name)
+	fmt.Fprintf(&g.calls, "func %v(%v) (types.Call, error) {\n", name,
+		strings.Join(append([]string{"meta *types.Metadata"}, params...), ", "))
+	fmt.Fprintf(&g.calls, "return types.NewCall(%v)\n}\n\n",
+		strings.Join(append([]string{"meta", fmt.Sprintf("%q", m.name+"."+c.name)}, names...), ", "))
+}
+
+func (g *generator) generateEvent(m module, e function) {
+	cp := g.checkpoint()
+	fields := make([]string, len(e.args))
+	used := map[string]bool{"Phase": true, "Topics": true}
+	for i, a := range e.args {
+		name := exportedName(a.name)
+		if a.name == "" {
+			// arguments before metadata v14 have no names, name them after their type
+			name = exportedName(a.def.String())
+		}
+		name = uniqueName(name, used)
+		typ, err := g.goType(a.def, exportedName(m.name)+exportedName(e.name)+name)
+		if err != nil {
+			g.rollback(cp)
+			g.skip("event %v.%v: %v", m.name, e.name, err)
+			return
+		}
+		fields[i] = name + " " + typ
+	}
+
+	name := "Event" + exportedName(m.name) + exportedName(e.name)
+	fmt.Fprintf(&g.events, "// %v is emitted by %v.%v\n", name, m.name, e.name)
+	fmt.Fprintf(&g.events, "type %v struct {\nPhase types.Phase\n", name)
+	for _, f := range fields {
+		fmt.Fprintf(&g.events, "%v\n", f)
+	}
+	// event records before metadata v4 have no topics, but code generation requires v7 or later
+	fmt.Fprintf(&g.events, "Topics []types.Hash\n}\n\n")
+
+	fmt.Fprintf(&g.records, "%v_%v []%v //nolint:stylecheck,golint\n", m.name, e.name, name)
+}
+
+func (g *generator) generateStorage(m module, fn string) {
+	cp := g.checkpoint()
+	_, keys, value, err := g.reg.StorageTypes(g.meta, m.prefix, fn)
+	if err != nil {
+		g.rollback(cp)
+		g.skip("storage %v.%v: %v", m.name, fn, err)
+		return
+	}
+	if len(keys) > 2 {
+		g.skip("storage %v.%v: maps with %v keys are not supported", m.name, fn, len(keys))
+		return
+	}
+
+	base := exportedName(m.name) + exportedName(fn)
+	valueType, err := g.goType(value, base)
+	if err != nil {
+		g.rollback(cp)
+		g.skip("storage %v.%v: %v", m.name, fn, err)
+		return
+	}
+	params := make([]string, len(keys))
+	names := make([]string, len(keys))
+	used := map[string]bool{"meta": true, "state": true, "blockHash": true, "key": true, "v": true, "ok": true,
+		"err": true}
+	for i, k := range keys {
+		typ, err := g.goType(k, base+"Key")
+		if err != nil {
+			g.rollback(cp)
+			g.skip("storage %v.%v: %v", m.name, fn, err)
+			return
+		}
+		names[i] = uniqueName(paramName(ident(typ), i), used)
+		params[i] = names[i] + " " + typ
+	}
+	g.hasStorage = true
+
+	w := &g.storage
+	fmt.Fprintf(w, "// %vKey creates the storage key of %v.%v\n", base, m.name, fn)
+	fmt.Fprintf(w, "func %vKey(%v) (types.StorageKey, error) {\n", base,
+		strings.Join(append([]string{"meta *types.Metadata"}, params...), ", "))
+	args := []string{"nil", "nil"}
+	for i, n := range names {
+		args[i] = fmt.Sprintf("arg%v", i)
+		fmt.Fprintf(w, "arg%v, err := types.EncodeToBytes(%v)\nif err != nil {\nreturn nil, err\n}\n", i, n)
+	}
+	fmt.Fprintf(w, "return types.CreateStorageKey(meta, %q, %q, %v)\n}\n\n", m.prefix, fn, strings.Join(args, ", "))
+
+	keyArgs := strings.Join(append([]string{"meta"}, names...), ", ")
+	fmt.Fprintf(w, "// Get%v returns the value of the storage %v.%v at the given block, using the default value "+
+		"from the metadata if the storage is empty. Ok is only false for empty optional storage.\n", base, m.name, fn)
+	fmt.Fprintf(w, "func Get%v(%v) (v %v, ok bool, err error) {\n", base,
+		strings.Join(append(append([]string{"state State", "meta *types.Metadata"}, params...),
+			"blockHash types.Hash"), ", "), valueType)
+	fmt.Fprintf(w, "key, err := %vKey(%v)\nif err != nil {\nreturn v, false, err\n}\n", base, keyArgs)
+	fmt.Fprintf(w, "ok, err = state.GetStorageOrDefault(meta, %q, %q, key, &v, blockHash)\nreturn v, ok, err\n}\n\n",
+		m.prefix, fn)
+
+	fmt.Fprintf(w, "// Get%vLatest returns the latest value of the storage %v.%v, using the default value from the "+
+		"metadata if the storage is empty. Ok is only false for empty optional storage.\n", base, m.name, fn)
+	fmt.Fprintf(w, "func Get%vLatest(%v) (v %v, ok bool, err error) {\n", base,
+		strings.Join(append([]string{"state State", "meta *types.Metadata"}, params...), ", "), valueType)
+	fmt.Fprintf(w, "key, err := %vKey(%v)\nif err != nil {\nreturn v, false, err\n}\n", base, keyArgs)
+	fmt.Fprintf(w, "ok, err = state.GetStorageOrDefaultLatest(meta, %q, %q, key, &v)\nreturn v, ok, err\n}\n\n",
+		m.prefix, fn)
+}
+
+func (g *generator) generateConstant(m module, c constant) {
+	cp := g.checkpoint()
+	name := exportedName(m.name) + exportedName(c.name)
+	typ, err := g.goType(c.def, name)
+	if err != nil {
+		g.rollback(cp)
+		g.skip("constant %v.%v: %v", m.name, c.name, err)
+		return
+	}
+
+	w := &g.constants
+	fmt.Fprintf(w, "// %v returns the value of the constant %v.%v\n", name, m.name, c.name)
+	fmt.Fprintf(w, "func %v(meta *types.Metadata) (v %v, err error) {\n", name, typ)
+	fmt.Fprintf(w, "err = meta.DecodeConstant(%q, %q, &v)\nreturn v, err\n}\n\n", m.name, c.name)
+}
+
+func (g *generator) writeFile(w *bytes.Buffer, pkg string) {
+	fmt.Fprintf(w, "// Code generated by gsrpc-gen from metadata v%v. DO NOT EDIT.\n\n", g.meta.Version)
+	if len(g.skipped) > 0 {
+		fmt.Fprintf(w, "// The following items were skipped:\n")
+		for _, s := range g.skipped {
+			fmt.Fprintf(w, "//   %v\n", s)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+	fmt.Fprintf(w, "package %v\n\nimport (\n", pkg)
+	if g.usesFmt {
+		fmt.Fprintf(w, "\"fmt\"\n\n")
+	}
+	if g.usesScale {
+		fmt.Fprintf(w, "\"github.com/zenghq3/go-substrate-rpc-client/scale\"\n")
+	}
+	fmt.Fprintf(w, "\"github.com/zenghq3/go-substrate-rpc-client/types\"\n)\n\n")
+
+	w.Write(g.calls.Bytes())
+	w.Write(g.events.Bytes())
+	fmt.Fprintf(w, "// EventRecords holds the events of all modules, to be used with "+
+		"types.EventRecordsRaw.DecodeEventRecords\n")
+	fmt.Fprintf(w, "type EventRecords struct {\n")
+	w.Write(g.records.Bytes())
+	fmt.Fprintf(w, "}\n\n")
+	if g.hasStorage {
+		fmt.Fprintf(w, "// State retrieves storage, it is implemented by state.State\n")
+		fmt.Fprintf(w, "type State interface {\n")
+		fmt.Fprintf(w, "GetStorageOrDefault(meta *types.Metadata, module, fn string, key types.StorageKey, "+
+			"target interface{}, blockHash types.Hash) (ok bool, err error)\n")
+		fmt.Fprintf(w, "GetStorageOrDefaultLatest(meta *types.Metadata, module, fn string, key types.StorageKey, "+
+			"target interface{}) (ok bool, err error)\n}\n\n")
+	}
+	w.Write(g.storage.Bytes())
+	w.Write(g.constants.Bytes())
+	w.Write(g.decls.Bytes())
+}
@@ -0,0 +1,184 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	gotypes "go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/zenghq3/go-substrate-rpc-client/codegen"
+	"github.com/zenghq3/go-substrate-rpc-client/registry"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// sourceImporter imports the packages used by the generated code from source. It is shared to type check the packages
+// of this repository only once.
+var sourceImporter = importer.ForCompiler(token.NewFileSet(), "source", nil)
+
+// assertValidSource parses and type checks the generated code
+func assertValidSource(t *testing.T, out *Output) string {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "runtime.go", out.Source, parser.AllErrors)
+	if !assert.NoError(t, err) {
+		return string(out.Source)
+	}
+
+	conf := gotypes.Config{Importer: sourceImporter}
+	_, err = conf.Check("runtime", fset, []*ast.File{f}, nil)
+	assert.NoError(t, err)
+	return string(out.Source)
+}
+
+func TestGenerate_MetadataV8(t *testing.T) {
+	out, err := Generate(types.ExamplaryMetadataV8, Options{Package: "runtime"})
+	assert.NoError(t, err)
+	src := assertValidSource(t, out)
+
+	assert.Contains(t, src, "// Code generated by gsrpc-gen from metadata v8. DO NOT EDIT.")
+	assert.Contains(t, src, "package runtime")
+	assert.Contains(t, src, "func NewBalancesTransferCall(meta *types.Metadata, dest types.Address, "+
+		"value types.UCompact) (types.Call, error) {\n\treturn types.NewCall(meta, \"Balances.transfer\", dest, value)")
+	assert.Contains(t, src, "type EventBalancesTransfer struct {\n\tPhase      types.Phase\n"+
+		"\tAccountId  types.AccountID\n\tAccountId1 types.AccountID\n\tBalance    types.U128\n"+
+		"\tBalance1   types.U128\n\tTopics     []types.Hash\n}")
+	assert.Contains(t, src, "\tBalances_Transfer ")
+	assert.Contains(t, src, "func BalancesFreeBalanceKey(meta *types.Metadata, accountID types.AccountID) "+
+		"(types.StorageKey, error) {")
+	assert.Contains(t, src, "return types.CreateStorageKey(meta, \"Balances\", \"FreeBalance\", arg0, nil)")
+	assert.Contains(t, src, "func GetTimestampNowLatest(state State, meta *types.Metadata) (v types.Moment, ok bool, "+
+		"err error) {")
+	assert.Contains(t, src, "ok, err = state.GetStorageOrDefaultLatest(meta, \"Timestamp\", \"Now\", key, &v)")
+	assert.Contains(t, src, "func BalancesExistentialDeposit(meta *types.Metadata) (v types.U128, err error) {")
+	assert.Contains(t, src, "type VoteThreshold struct {\n\tIsSuperMajorityApprove bool\n")
+
+	assert.Contains(t, out.Skipped, "call Staking.bond: type RewardDestination is not registered")
+	assert.NotContains(t, src, "NewStakingBondCall")
+}
+
+func TestGenerate_Registry(t *testing.T) {
+	r := registry.NewRegistry()
+	assert.NoError(t, r.RegisterJSON([]byte(`{
+		"RewardDestination": {"_enum": ["Staked", "Stash", "Controller"]},
+		"Balance": "u64"
+	}`)))
+
+	out, err := Generate(types.ExamplaryMetadataV8, Options{Package: "runtime", Registry: r})
+	assert.NoError(t, err)
+	src := assertValidSource(t, out)
+
+	assert.NotContains(t, out.Skipped, "call Staking.bond: type RewardDestination is not registered")
+	assert.Contains(t, src, "func NewStakingBondCall(meta *types.Metadata, controller types.Address, "+
+		"value types.UCompact, payee RewardDestination) (types.Call, error) {")
+	assert.Contains(t, src, "func (m *RewardDestination) Decode(decoder scale.Decoder) error {")
+	assert.Contains(t, src, "func BalancesExistentialDeposit(meta *types.Metadata) (v types.U64, err error) {")
+}
+
+func TestGenerate_MetadataV14(t *testing.T) {
+	id := types.NewSi1LookupTypeID
+	field := func(name string, typ uint64) types.Si1Field {
+		return types.Si1Field{HasName: name != "", Name: types.Text(name), Type: id(typ)}
+	}
+	composite := func(path types.Si1Path, fields ...types.Si1Field) types.Si1Type {
+		return types.Si1Type{Path: path, Def: types.Si1TypeDef{IsComposite: true,
+			AsComposite: types.Si1TypeDefComposite{Fields: fields}}}
+	}
+	variant := func(path types.Si1Path, variants ...types.Si1Variant) types.Si1Type {
+		return types.Si1Type{Path: path, Def: types.Si1TypeDef{IsVariant: true,
+			AsVariant: types.Si1TypeDefVariant{Variants: variants}}}
+	}
+
+	meta := types.NewMetadataV14()
+	meta.AsMetadataV14.Lookup = types.PortableRegistry{
+		{ID: id(0), Type: types.Si1Type{Def: types.Si1TypeDef{IsPrimitive: true, AsPrimitive: types.IsU8}}},
+		{ID: id(1), Type: types.Si1Type{Def: types.Si1TypeDef{IsArray: true,
+			AsArray: types.Si1TypeDefArray{Len: 32, Type: id(0)}}}},
+		{ID: id(2), Type: composite(types.Si1Path{"sp_core", "crypto", "AccountId32"}, field("", 1))},
+		{ID: id(3), Type: types.Si1Type{Def: types.Si1TypeDef{IsPrimitive: true, AsPrimitive: types.IsU128}}},
+		{ID: id(4), Type: types.Si1Type{Path: types.Si1Path{"Option"},
+			Params: []types.Si1TypeParameter{{Name: "T", HasType: true, Type: id(2)}},
+			Def: types.Si1TypeDef{IsVariant: true, AsVariant: types.Si1TypeDefVariant{Variants: []types.Si1Variant{
+				{Name: "None", Index: 0},
+				{Name: "Some", Index: 1, Fields: []types.Si1Field{field("", 2)}},
+			}}}}},
+		{ID: id(5), Type: variant(types.Si1Path{"pallet_balances", "pallet", "Call"},
+			types.Si1Variant{Name: "transfer", Index: 0, Fields: []types.Si1Field{field("dest", 2), field("value", 6)}},
+			types.Si1Variant{Name: "set_status", Index: 1, Fields: []types.Si1Field{field("status", 9)}},
+		)},
+		{ID: id(6), Type: types.Si1Type{Def: types.Si1TypeDef{IsCompact: true,
+			AsCompact: types.Si1TypeDefCompact{Type: id(3)}}}},
+		{ID: id(7), Type: variant(types.Si1Path{"pallet_balances", "pallet", "Event"},
+			types.Si1Variant{Name: "Transfer", Index: 0, Fields: []types.Si1Field{
+				field("from", 2), field("to", 2), field("amount", 3),
+			}},
+		)},
+		{ID: id(8), Type: composite(types.Si1Path{"pallet_balances", "AccountData"},
+			field("free", 3), field("reserved", 3))},
+		{ID: id(9), Type: variant(types.Si1Path{"pallet_balances", "Status"},
+			types.Si1Variant{Name: "Active", Index: 0},
+			types.Si1Variant{Name: "Frozen", Index: 1, Fields: []types.Si1Field{field("until", 3)}},
+		)},
+	}
+	meta.AsMetadataV14.Pallets = []types.PalletMetadataV14{{
+		Name:       "Balances",
+		HasStorage: true,
+		Storage: types.StorageMetadataV14{Prefix: "Balances", Items: []types.StorageEntryMetadataV14{
+			{Name: "Account", Type: types.StorageEntryTypeV14{IsMap: true, AsMap: types.MapTypeV14{
+				Hashers: []types.StorageHasherV11{{IsBlake2_128Concat: true}}, Key: id(2), Value: id(8)}}},
+			{Name: "Owner", Type: types.StorageEntryTypeV14{IsPlainType: true, AsPlainType: id(4)}},
+		}},
+		HasCalls:  true,
+		Calls:     types.FunctionMetadataV14{Type: id(5)},
+		HasEvents: true,
+		Events:    types.EventMetadataV14{Type: id(7)},
+		Constants: []types.ConstantMetadataV14{{Name: "ExistentialDeposit", Type: id(3)}},
+		Index:     5,
+	}}
+
+	out, err := Generate(meta, Options{Package: "runtime"})
+	assert.NoError(t, err)
+	src := assertValidSource(t, out)
+	assert.Empty(t, out.Skipped)
+
+	assert.Contains(t, src, "func NewBalancesTransferCall(meta *types.Metadata, dest types.AccountID, "+
+		"value types.UCompact) (types.Call, error) {")
+	assert.Contains(t, src, "func NewBalancesSetStatusCall(meta *types.Metadata, status Status) (types.Call, error) {")
+	assert.Contains(t, src, "type Status struct {\n\tIsActive bool\n\tIsFrozen bool\n\tAsFrozen StatusFrozen\n}")
+	assert.Contains(t, src, "type StatusFrozen struct {\n\tUntil types.U128\n}")
+	assert.Contains(t, src, "type EventBalancesTransfer struct {\n\tPhase  types.Phase\n\tFrom   types.AccountID\n"+
+		"\tTo     types.AccountID\n\tAmount types.U128\n\tTopics []types.Hash\n}")
+	assert.Contains(t, src, "func GetBalancesAccount(state State, meta *types.Metadata, accountID types.AccountID, "+
+		"blockHash types.Hash) (v AccountData, ok bool, err error) {")
+	assert.Contains(t, src, "type AccountData struct {\n\tFree     types.U128\n\tReserved types.U128\n}")
+	assert.Contains(t, src, "func GetBalancesOwnerLatest(state State, meta *types.Metadata) (v OptionAccountID, "+
+		"ok bool, err error) {")
+	assert.Contains(t, src, "type OptionAccountID struct {\n\tHasValue bool\n\tValue    types.AccountID\n}")
+	assert.Contains(t, src, "func BalancesExistentialDeposit(meta *types.Metadata) (v types.U128, err error) {")
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	_, err := Generate(types.ExamplaryMetadataV4, Options{Package: "runtime"})
+	assert.EqualError(t, err, "code generation requires metadata v7 or later, but got v4")
+
+	_, err = Generate(types.ExamplaryMetadataV8, Options{})
+	assert.EqualError(t, err, "missing package name")
+}
@@ -0,0 +1,499 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/zenghq3/go-substrate-rpc-client/registry"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// primitiveTypes maps primitives to the types of the types package
+var primitiveTypes = map[string]string{
+	"bool": "types.Bool",
+	"str":  "types.Text",
+	"u8":   "types.U8",
+	"u16":  "types.U16",
+	"u32":  "types.U32",
+	"u64":  "types.U64",
+	"u128": "types.U128",
+	"u256": "types.U256",
+	"i8":   "types.I8",
+	"i16":  "types.I16",
+	"i32":  "types.I32",
+	"i64":  "types.I64",
+	"i128": "types.I128",
+	"i256": "types.I256",
+}
+
+// knownTypes maps well-known type names to the types of the types package. They are used if the name is not
+// registered, or registered with the definition of the default registry.
+var knownTypes = map[string]string{
+	"AccountId":     "types.AccountID",
+	"AccountIndex":  "types.AccountIndex",
+	"Address":       "types.Address",
+	"LookupSource":  "types.Address",
+	"Source":        "types.Address",
+	"AuthorityId":   "types.AuthorityID",
+	"BlockNumber":   "types.BlockNumber",
+	"Bytes":         "types.Bytes",
+	"DispatchError": "types.DispatchError",
+	"DispatchInfo":  "types.DispatchInfo",
+	"H160":          "types.H160",
+	"H256":          "types.H256",
+	"H512":          "types.H512",
+	"Hash":          "types.Hash",
+	"Moment":        "types.Moment",
+	"Null":          "types.Null",
+	"Phase":         "types.Phase",
+	"Signature":     "types.Signature",
+	"String":        "types.Text",
+	"Text":          "types.Text",
+	"Weight":        "types.Weight",
+}
+
+// knownPaths maps the paths of types in the portable registry of metadata v14 to the types of the types package
+var knownPaths = map[string]string{
+	"sp_core::crypto::AccountId32": "types.AccountID",
+	"primitive_types::H160":        "types.H160",
+	"primitive_types::H256":        "types.H256",
+	"primitive_types::H512":        "types.H512",
+}
+
+// setTypes maps the bit length of sets to the types of the types package
+var setTypes = map[int]string{
+	8:  "types.U8",
+	16: "types.U16",
+	32: "types.U32",
+	64: "types.U64",
+}
+
+// goType returns the Go type for the definition, declaring it if required. The hint is used as name for structs and
+// enums declared inline.
+func (g *generator) goType(def *registry.TypeDef, hint string) (string, error) {
+	switch def.Kind {
+	case registry.KindNamed:
+		return g.namedType(def.Name)
+	case registry.KindPrimitive:
+		typ, ok := primitiveTypes[def.Name]
+		if !ok {
+			return "", fmt.Errorf("unknown primitive %v", def.Name)
+		}
+		return typ, nil
+	case registry.KindVec:
+		if g.isByte(def.Params[0]) {
+			return "types.Bytes", nil
+		}
+		elem, err := g.goType(def.Params[0], hint+"Item")
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	case registry.KindArray:
+		if g.isByte(def.Params[0]) {
+			return fmt.Sprintf("[%v]byte", def.Length), nil
+		}
+		elem, err := g.goType(def.Params[0], hint+"Item")
+		if err != nil {
+			return "", err
+		}
+		if g.inProgress[elem] {
+			return "", fmt.Errorf("recursive type %v is not supported", elem)
+		}
+		return fmt.Sprintf("[%v]%v", def.Length, elem), nil
+	case registry.KindOption:
+		return g.optionType(def.Params[0], hint)
+	case registry.KindCompact:
+		return "types.UCompact", nil
+	case registry.KindTuple:
+		if len(def.Params) == 0 {
+			return "types.Null", nil
+		}
+		return g.tupleType(def)
+	case registry.KindStruct:
+		typ := g.reserve(hint)
+		return typ, g.declareStruct(typ, def, "an inline struct")
+	case registry.KindEnum:
+		typ := g.reserve(hint)
+		return typ, g.declareEnum(typ, def, "an inline enum")
+	case registry.KindSet:
+		typ, ok := setTypes[def.Length]
+		if !ok {
+			return "", fmt.Errorf("unsupported set bit length %v", def.Length)
+		}
+		return typ, nil
+	default:
+		return "", fmt.Errorf("unsupported type %v", def)
+	}
+}
+
+// namedType returns the Go type for a type registered by name
+func (g *generator) namedType(name string) (string, error) {
+	if typ, ok := g.typeNames[name]; ok {
+		return typ, nil
+	}
+
+	if g.portable != nil && strings.HasPrefix(name, "Lookup") {
+		id, err := strconv.ParseInt(strings.TrimPrefix(name, "Lookup"), 10, 64)
+		if t, ok := g.portable[id]; err == nil && ok {
+			return g.portableType(name, t)
+		}
+	}
+
+	def, registered := g.reg.Get(name)
+	if typ, ok := knownTypes[name]; ok && (!registered || g.isDefault(name, def)) {
+		return typ, nil
+	}
+	if !registered {
+		return "", fmt.Errorf("type %v is not registered", name)
+	}
+	return g.declareNamed(name, []string{exportedName(name)}, def, "the type "+name)
+}
+
+// portableType returns the Go type for a type of the portable registry of metadata v14
+func (g *generator) portableType(key string, t types.Si1Type) (string, error) {
+	path := make([]string, len(t.Path))
+	for i, p := range t.Path {
+		path[i] = string(p)
+	}
+	joined := strings.Join(path, "::")
+	if typ, ok := knownPaths[joined]; ok {
+		g.typeNames[key] = typ
+		return typ, nil
+	}
+
+	def, ok := g.reg.Get(key)
+	if !ok {
+		return "", fmt.Errorf("type %v is not registered", key)
+	}
+
+	if joined == "Option" && def.Kind == registry.KindEnum && len(def.Variants) == 2 {
+		typ, err := g.optionType(def.Variants[1].Type, "")
+		if err != nil {
+			return "", err
+		}
+		g.typeNames[key] = typ
+		return typ, nil
+	}
+
+	if len(path) == 0 || def.Kind != registry.KindStruct && def.Kind != registry.KindEnum {
+		typ, err := g.goType(def, exportedName(joined))
+		if err != nil {
+			return "", err
+		}
+		g.typeNames[key] = typ
+		return typ, nil
+	}
+
+	// generic types are named after their type parameters, e.g. Result<(), DispatchError> becomes ResultNullDispatchError
+	name := exportedName(path[len(path)-1])
+	for _, p := range t.Params {
+		if !p.HasType {
+			continue
+		}
+		typ, err := g.namedType(registry.PortableTypeName(p.Type))
+		if err != nil {
+			return "", err
+		}
+		name += ident(typ)
+	}
+	return g.declareNamed(key, []string{name, exportedName(path[0]) + name}, def, "the type "+joined)
+}
+
+// declareNamed declares a Go type for a registered type. The first free name of the candidates is used.
+func (g *generator) declareNamed(key string, candidates []string, def *registry.TypeDef, source string) (string,
+	error) {
+	switch {
+	case def.Kind == registry.KindStruct || def.Kind == registry.KindEnum ||
+		def.Kind == registry.KindTuple && len(def.Params) > 0:
+		typ := g.reserve(candidates...)
+		g.typeNames[key] = typ
+		var err error
+		switch def.Kind {
+		case registry.KindStruct:
+			err = g.declareStruct(typ, def, source)
+		case registry.KindEnum:
+			err = g.declareEnum(typ, def, source)
+		default:
+			err = g.declareTuple(typ, def, source)
+		}
+		return typ, err
+	default:
+		typ, err := g.goType(def, candidates[0])
+		if err != nil {
+			return "", err
+		}
+		g.typeNames[key] = typ
+		return typ, nil
+	}
+}
+
+func (g *generator) declareStruct(typ string, def *registry.TypeDef, source string) error {
+	g.inProgress[typ] = true
+	defer delete(g.inProgress, typ)
+
+	fields := make([]string, len(def.Fields))
+	used := make(map[string]bool)
+	for i, f := range def.Fields {
+		name := exportedName(f.Name)
+		ft, err := g.goType(f.Type, typ+name)
+		if err != nil {
+			return err
+		}
+		if g.inProgress[ft] {
+			return fmt.Errorf("recursive type %v is not supported", ft)
+		}
+		fields[i] = uniqueName(name, used) + " " + ft
+	}
+
+	fmt.Fprintf(&g.decls, "// %v is generated from %v\ntype %v struct {\n", typ, source, typ)
+	for _, f := range fields {
+		fmt.Fprintf(&g.decls, "%v\n", f)
+	}
+	fmt.Fprintf(&g.decls, "}\n\n")
+	return nil
+}
+
+func (g *generator) declareTuple(typ string, def *registry.TypeDef, source string) error {
+	fields := make([]registry.Field, len(def.Params))
+	for i, p := range def.Params {
+		fields[i] = registry.Field{Name: fmt.Sprintf("Field%v", i), Type: p}
+	}
+	return g.declareStruct(typ, registry.NewStruct(fields...), source)
+}
+
+// tupleType declares a struct for an unnamed tuple, named after its element types
+func (g *generator) tupleType(def *registry.TypeDef) (string, error) {
+	name := "Tuple"
+	elems := make([]string, len(def.Params))
+	for i, p := range def.Params {
+		var err error
+		elems[i], err = g.goType(p, "")
+		if err != nil {
+			return "", err
+		}
+		name += ident(elems[i])
+	}
+
+	// tuples with the same Go types share the declaration
+	key := "tuple (" + strings.Join(elems, ", ") + ")"
+	if typ, ok := g.typeNames[key]; ok {
+		return typ, nil
+	}
+	return g.declareNamed(key, []string{name}, def, "the tuple "+def.String())
+}
+
+func (g *generator) declareEnum(typ string, def *registry.TypeDef, source string) error {
+	g.inProgress[typ] = true
+	defer delete(g.inProgress, typ)
+	g.usesScale, g.usesFmt = true, true
+
+	var fields, decode, encode strings.Builder
+	for _, v := range def.Variants {
+		name := exportedName(v.Name)
+		fmt.Fprintf(&fields, "Is%v bool\n", name)
+		fmt.Fprintf(&decode, "case %v:\nm.Is%v = true\n", v.Index, name)
+		fmt.Fprintf(&encode, "case m.Is%v:\n", name)
+		if v.Type == nil {
+			fmt.Fprintf(&encode, "return encoder.PushByte(%v)\n", v.Index)
+			continue
+		}
+
+		vt, err := g.goType(v.Type, typ+name)
+		if err != nil {
+			return err
+		}
+		if g.inProgress[vt] {
+			// recursive enums such as calls containing calls refer to themselves by pointer
+			fmt.Fprintf(&fields, "As%v *%v\n", name, vt)
+			fmt.Fprintf(&decode, "m.As%v = new(%v)\nerr = decoder.Decode(m.As%v)\n", name, vt, name)
+		} else {
+			fmt.Fprintf(&fields, "As%v %v\n", name, vt)
+			fmt.Fprintf(&decode, "err = decoder.Decode(&m.As%v)\n", name)
+		}
+		fmt.Fprintf(&encode, "err := encoder.PushByte(%v)\nif err != nil {\nreturn err\n}\n", v.Index)
+		fmt.Fprintf(&encode, "return encoder.Encode(m.As%v)\n", name)
+	}
+
+	w := &g.decls
+	fmt.Fprintf(w, "// %v is generated from %v\ntype %v struct {\n%v}\n\n", typ, source, typ, fields.String())
+	fmt.Fprintf(w, "func (m *%v) Decode(decoder scale.Decoder) error {\n", typ)
+	fmt.Fprintf(w, "b, err := decoder.ReadOneByte()\nif err != nil {\nreturn err\n}\n\n")
+	fmt.Fprintf(w, "switch b {\n%vdefault:\nreturn fmt.Errorf(\"unknown variant %%v for %v\", b)\n}\n", decode.String(),
+		typ)
+	fmt.Fprintf(w, "return err\n}\n\n")
+	fmt.Fprintf(w, "func (m %v) Encode(encoder scale.Encoder) error {\n", typ)
+	fmt.Fprintf(w, "switch {\n%vdefault:\nreturn fmt.Errorf(\"no variant set for %v\")\n}\n}\n\n", encode.String(), typ)
+	return nil
+}
+
+// optionType returns the Go type for an optional value of the definition. Options of bool are encoded as a single
+// byte and use types.OptionBool, all others are declared as a struct with HasValue and Value.
+func (g *generator) optionType(elem *registry.TypeDef, hint string) (string, error) {
+	resolved, err := g.reg.Resolve(elem)
+	if err == nil && resolved.Kind == registry.KindPrimitive && resolved.Name == "bool" {
+		return "types.OptionBool", nil
+	}
+
+	vt, err := g.goType(elem, hint)
+	if err != nil {
+		return "", err
+	}
+	if g.inProgress[vt] {
+		return "", fmt.Errorf("recursive type %v is not supported", vt)
+	}
+
+	key := "option " + vt
+	if typ, ok := g.typeNames[key]; ok {
+		return typ, nil
+	}
+	typ := g.reserve("Option" + ident(vt))
+	g.typeNames[key] = typ
+	g.usesScale = true
+
+	w := &g.decls
+	fmt.Fprintf(w, "// %v is an optional %v\ntype %v struct {\nHasValue bool\nValue %v\n}\n\n", typ, vt, typ, vt)
+	fmt.Fprintf(w, "func (o %v) Encode(encoder scale.Encoder) error {\n", typ)
+	fmt.Fprintf(w, "return encoder.EncodeOption(o.HasValue, o.Value)\n}\n\n")
+	fmt.Fprintf(w, "func (o *%v) Decode(decoder scale.Decoder) error {\n", typ)
+	fmt.Fprintf(w, "return decoder.DecodeOption(&o.HasValue, &o.Value)\n}\n\n")
+	return typ, nil
+}
+
+func (g *generator) isByte(def *registry.TypeDef) bool {
+	resolved, err := g.reg.Resolve(def)
+	return err == nil && resolved.Kind == registry.KindPrimitive && resolved.Name == "u8"
+}
+
+// isDefault returns true if the definition equals the definition of the default registry
+func (g *generator) isDefault(name string, def *registry.TypeDef) bool {
+	d, ok := g.defaults.Get(name)
+	return ok && d.String() == def.String()
+}
+
+// reserve returns the first of the candidates that is not declared yet, or the first candidate with a number suffix
+func (g *generator) reserve(candidates ...string) string {
+	for _, c := range candidates {
+		if !g.names[c] {
+			g.names[c] = true
+			return c
+		}
+	}
+	for i := 2; ; i++ {
+		c := fmt.Sprintf("%v%v", candidates[0], i)
+		if !g.names[c] {
+			g.names[c] = true
+			return c
+		}
+	}
+}
+
+// checkpoint is the state of the generated declarations, to roll back the declarations of a skipped item
+type checkpoint struct {
+	decls     int
+	names     map[string]bool
+	typeNames map[string]string
+	usesScale bool
+	usesFmt   bool
+}
+
+func (g *generator) checkpoint() checkpoint {
+	c := checkpoint{
+		decls:     g.decls.Len(),
+		names:     make(map[string]bool, len(g.names)),
+		typeNames: make(map[string]string, len(g.typeNames)),
+		usesScale: g.usesScale,
+		usesFmt:   g.usesFmt,
+	}
+	for k, v := range g.names {
+		c.names[k] = v
+	}
+	for k, v := range g.typeNames {
+		c.typeNames[k] = v
+	}
+	return c
+}
+
+func (g *generator) rollback(c checkpoint) {
+	g.decls.Truncate(c.decls)
+	g.names, g.typeNames = c.names, c.typeNames
+	g.usesScale, g.usesFmt = c.usesScale, c.usesFmt
+}
+
+var (
+	arrayRegexp   = regexp.MustCompile(`\[(\d+)\]`)
+	keywordRegexp = regexp.MustCompile(`^(break|case|chan|const|continue|default|defer|else|fallthrough|for|func|go|` +
+		`goto|if|import|interface|map|package|range|return|select|struct|switch|type|var)$`)
+)
+
+// exportedName converts names such as transfer_keep_alive, AccountId or Vec<T::Balance> to an exported Go identifier
+func exportedName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	s := b.String()
+	if s == "" || unicode.IsDigit(rune(s[0])) {
+		s = "X" + s
+	}
+	return s
+}
+
+// paramName converts a name to an unexported Go identifier, unnamed parameters are named after their position
+func paramName(name string, i int) string {
+	if name == "" {
+		return fmt.Sprintf("arg%v", i)
+	}
+	s := exportedName(name)
+	s = strings.ToLower(s[:1]) + s[1:]
+	if keywordRegexp.MatchString(s) {
+		s += "Arg"
+	}
+	return s
+}
+
+// ident converts a Go type to an identifier, e.g. []types.AccountID becomes VecAccountID
+func ident(typ string) string {
+	typ = strings.Replace(typ, "types.", "", -1)
+	typ = strings.Replace(typ, "[]", "Vec", -1)
+	typ = strings.Replace(typ, "*", "", -1)
+	return exportedName(arrayRegexp.ReplaceAllString(typ, "Array${1}"))
+}
+
+// uniqueName returns the name, with a number suffix if already used
+func uniqueName(name string, used map[string]bool) string {
+	n := name
+	for i := 1; used[n]; i++ {
+		n = fmt.Sprintf("%v%v", name, i)
+	}
+	used[n] = true
+	return n
+}
@@ -0,0 +1,322 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package fetcher fetches ranges of blocks concurrently, for example to backfill an indexer.
+
+For every block of the range, the hash, the signed block, the System.Events storage and optionally the metadata are
+fetched by a pool of workers and assembled into a Bundle. Bundles are delivered in block number order, while the
+number of blocks fetched ahead of the next block to deliver is bounded:
+
+	f := fetcher.FetchRange(ctx, cl, 1, 100000, fetcher.Options{Workers: 16, Metadata: true})
+	defer f.Close()
+
+	for b := range f.Chan() {
+		events := types.EventRecords{}
+		err := b.Events.DecodeEventRecords(b.Metadata, &events)
+		// ...
+	}
+	if err := f.Err(); err != nil {
+		return err
+	}
+*/
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zenghq3/go-substrate-rpc-client/client"
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/chain"
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/state"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+	"github.com/zenghq3/go-substrate-rpc-client/xxhash"
+)
+
+const (
+	// DefaultWorkers is the number of blocks fetched concurrently by default
+	DefaultWorkers = 8
+	// DefaultRetries is the number of times a failed request is retried by default
+	DefaultRetries = 3
+	// DefaultRetryDelay is the delay before the first retry of a failed request by default, it doubles with every retry
+	DefaultRetryDelay = 200 * time.Millisecond
+)
+
+// eventsKey is the storage key of System.Events
+var eventsKey = types.NewStorageKey(append(xxhash.New128([]byte("System")).Sum(nil),
+	xxhash.New128([]byte("Events")).Sum(nil)...))
+
+// Options configures FetchRange. Zero values are replaced by the defaults.
+type Options struct {
+	// Workers is the number of blocks fetched concurrently
+	Workers int
+	// Buffer is the maximum number of blocks fetched or held ahead of the next block to deliver, which bounds the
+	// memory used while an earlier block is slow to fetch or the consumer is slow to receive. Defaults to twice the
+	// number of workers, and is raised to the number of workers if lower.
+	Buffer int
+	// Retries is the number of times a failed request is retried, negative values disable retries
+	Retries int
+	// RetryDelay is the delay before the first retry of a failed request, it doubles with every retry
+	RetryDelay time.Duration
+	// Metadata enables fetching the metadata of every block
+	Metadata bool
+	// MetadataProvider provides the metadata if enabled, it caches the metadata per spec version. Pass api.Metadata to
+	// share its cache, a new provider is used if nil.
+	MetadataProvider *state.MetadataProvider
+}
+
+// Bundle holds the data fetched for a block
+type Bundle struct {
+	Number types.BlockNumber
+	Hash   types.Hash
+	Block  types.SignedBlock
+	// Events holds the raw System.Events storage at the block, which is empty if there are no events
+	Events types.EventRecordsRaw
+	// Metadata is the metadata at the block if enabled in the options, or nil. Bundles of blocks with the same spec
+	// version share their metadata.
+	Metadata *types.Metadata
+}
+
+// Fetcher fetches a range of blocks, established through FetchRange
+type Fetcher struct {
+	chain   *chain.Chain
+	state   *state.State
+	opts    Options
+	channel chan Bundle
+	cancel  context.CancelFunc
+	err     error
+}
+
+// result is the outcome of fetching a single block
+type result struct {
+	bundle Bundle
+	err    error
+}
+
+// FetchRange starts fetching the blocks with the numbers from until to, both included, and delivers them in order on
+// the channel returned by Chan. Failed requests are retried, fetching stops at the first request that fails after all
+// retries, when the context is done or when Close is called.
+func FetchRange(ctx context.Context, cl client.Client, from, to types.BlockNumber, opts Options) *Fetcher {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+	if opts.Buffer == 0 {
+		opts.Buffer = 2 * opts.Workers
+	}
+	if opts.Buffer < opts.Workers {
+		opts.Buffer = opts.Workers
+	}
+	if opts.Retries == 0 {
+		opts.Retries = DefaultRetries
+	}
+	if opts.RetryDelay == 0 {
+		opts.RetryDelay = DefaultRetryDelay
+	}
+	s := state.NewState(cl)
+	if opts.MetadataProvider == nil {
+		opts.MetadataProvider = state.NewMetadataProvider(s)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	f := &Fetcher{
+		chain:   chain.NewChain(cl),
+		state:   s,
+		opts:    opts,
+		channel: make(chan Bundle),
+		cancel:  cancel,
+	}
+	go f.run(ctx, from, to)
+	return f
+}
+
+// Chan returns the channel the bundles are delivered on, in ascending order of their block numbers.
+//
+// The channel is closed when all blocks have been delivered or fetching stopped, see Err.
+func (f *Fetcher) Chan() <-chan Bundle {
+	return f.channel
+}
+
+// Err returns the error that stopped fetching, or nil if all blocks have been delivered. It must only be called after
+// the channel returned by Chan is closed.
+func (f *Fetcher) Err() error {
+	return f.err
+}
+
+// Close stops fetching. The channel returned by Chan is closed once the running requests returned.
+// It can safely be called more than once.
+func (f *Fetcher) Close() {
+	f.cancel()
+}
+
+// run dispatches the block numbers to the workers and delivers the results in order
+func (f *Fetcher) run(ctx context.Context, from, to types.BlockNumber) {
+	defer close(f.channel)
+	defer f.cancel()
+
+	if to < from {
+		return
+	}
+
+	// every block takes a slot of the window until it is delivered, which bounds the blocks held at a time
+	window := make(chan struct{}, f.opts.Buffer)
+	jobs := make(chan types.BlockNumber)
+	results := make(chan result, f.opts.Buffer)
+
+	go func() {
+		defer close(jobs)
+		for n := from; ; n++ {
+			select {
+			case <-ctx.Done():
+				return
+			case window <- struct{}{}:
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- n:
+			}
+			if n == to {
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < f.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range jobs {
+				b, err := f.fetch(ctx, n)
+				if err != nil && ctx.Err() == nil {
+					err = fmt.Errorf("fetching block %v failed: %v", n, err)
+				}
+				results <- result{b, err}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	pending := make(map[types.BlockNumber]Bundle)
+	next, done := from, false
+	for r := range results {
+		if r.err != nil {
+			f.fail(r.err)
+			continue
+		}
+		if f.err != nil {
+			continue
+		}
+
+		pending[r.bundle.Number] = r.bundle
+		for b, ok := pending[next]; ok && f.err == nil; b, ok = pending[next] {
+			delete(pending, next)
+			select {
+			case <-ctx.Done():
+				f.fail(ctx.Err())
+				continue
+			case f.channel <- b:
+				<-window
+			}
+			if next == to {
+				done = true
+				break
+			}
+			next++
+		}
+	}
+
+	if !done {
+		f.fail(ctx.Err())
+	}
+}
+
+// fail records the first error and stops fetching
+func (f *Fetcher) fail(err error) {
+	if f.err == nil {
+		f.err = err
+	}
+	f.cancel()
+}
+
+// fetch fetches the data of a single block
+func (f *Fetcher) fetch(ctx context.Context, n types.BlockNumber) (Bundle, error) {
+	b := Bundle{Number: n}
+
+	err := f.retry(ctx, func() (err error) {
+		b.Hash, err = f.chain.GetBlockHash(uint64(n))
+		return err
+	})
+	if err != nil {
+		return b, err
+	}
+
+	err = f.retry(ctx, func() error {
+		block, err := f.chain.GetBlock(b.Hash)
+		if err != nil {
+			return err
+		}
+		b.Block = *block
+		return nil
+	})
+	if err != nil {
+		return b, err
+	}
+
+	err = f.retry(ctx, func() error {
+		raw, err := f.state.GetStorageRaw(eventsKey, b.Hash)
+		if err != nil {
+			return err
+		}
+		b.Events = types.EventRecordsRaw(*raw)
+		return nil
+	})
+	if err != nil || !f.opts.Metadata {
+		return b, err
+	}
+
+	err = f.retry(ctx, func() (err error) {
+		b.Metadata, err = f.opts.MetadataProvider.At(b.Hash)
+		return err
+	})
+	return b, err
+}
+
+// retry calls fn until it succeeds, the retries are exhausted or the context is done
+func (f *Fetcher) retry(ctx context.Context, fn func() error) error {
+	delay := f.opts.RetryDelay
+	for attempt := 0; ; attempt++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err := fn()
+		if err == nil || attempt >= f.opts.Retries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
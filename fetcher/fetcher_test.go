@@ -0,0 +1,214 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenghq3/go-substrate-rpc-client/client"
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/state"
+	"github.com/zenghq3/go-substrate-rpc-client/rpcmocksrv"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// mockSrv serves a chain of blocks, the events of a block hold its number and the spec version changes every 20
+// blocks. GetBlock fails as often as given in failures for a block number.
+type mockSrv struct {
+	blocks int
+
+	mu            sync.Mutex
+	failures      map[int]int
+	maxRequested  int
+	metadataCalls int
+}
+
+func blockHash(n int) types.Hash {
+	return types.NewHash([]byte{0xaa, byte(n >> 8), byte(n)})
+}
+
+func (s *mockSrv) number(hash string) (int, error) {
+	for n := 0; n < s.blocks; n++ {
+		if blockHash(n).Hex() == hash {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown block %v", hash)
+}
+
+func (s *mockSrv) GetBlockHash(height *uint64) *string {
+	s.mu.Lock()
+	if int(*height) > s.maxRequested {
+		s.maxRequested = int(*height)
+	}
+	s.mu.Unlock()
+
+	if int(*height) >= s.blocks {
+		return nil
+	}
+	hash := blockHash(int(*height)).Hex()
+	return &hash
+}
+
+func (s *mockSrv) GetBlock(hash *string) (types.SignedBlock, error) {
+	n, err := s.number(*hash)
+	if err != nil {
+		return types.SignedBlock{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures[n] > 0 {
+		s.failures[n]--
+		return types.SignedBlock{}, fmt.Errorf("block %v temporarily unavailable", n)
+	}
+	return types.SignedBlock{Block: types.Block{Header: types.Header{Number: types.BlockNumber(n)}}}, nil
+}
+
+func (s *mockSrv) GetStorage(key string, hash *string) (string, error) {
+	if key != eventsKey.Hex() {
+		return "", fmt.Errorf("unexpected key %v", key)
+	}
+	n, err := s.number(*hash)
+	if err != nil {
+		return "", err
+	}
+	return types.HexEncodeToString([]byte{byte(n >> 8), byte(n)}), nil
+}
+
+func (s *mockSrv) GetRuntimeVersion(hash *string) (types.RuntimeVersion, error) {
+	n, err := s.number(*hash)
+	if err != nil {
+		return types.RuntimeVersion{}, err
+	}
+	return types.RuntimeVersion{SpecVersion: types.U32(n / 20)}, nil
+}
+
+func (s *mockSrv) GetMetadata(hash *string) string {
+	s.mu.Lock()
+	s.metadataCalls++
+	s.mu.Unlock()
+	return types.ExamplaryMetadataV4String
+}
+
+func newMockClient(t *testing.T, srv *mockSrv) client.Client {
+	s := rpcmocksrv.New()
+	assert.NoError(t, s.RegisterName("chain", srv))
+	assert.NoError(t, s.RegisterName("state", srv))
+	cl, err := client.Connect(s.URL)
+	assert.NoError(t, err)
+	return cl
+}
+
+func TestFetchRange(t *testing.T) {
+	srv := &mockSrv{blocks: 100, failures: map[int]int{3: 1, 17: 2, 42: 3}}
+	cl := newMockClient(t, srv)
+
+	f := FetchRange(context.Background(), cl, 2, 61, Options{Workers: 4, RetryDelay: time.Millisecond,
+		Metadata: true})
+
+	next := 2
+	bySpec := make(map[int]*types.Metadata)
+	for b := range f.Chan() {
+		assert.Equal(t, types.BlockNumber(next), b.Number)
+		assert.Equal(t, blockHash(next), b.Hash)
+		assert.Equal(t, types.BlockNumber(next), b.Block.Block.Header.Number)
+		assert.Equal(t, types.EventRecordsRaw{byte(next >> 8), byte(next)}, b.Events)
+		assert.NotNil(t, b.Metadata)
+		if meta, ok := bySpec[next/20]; ok {
+			assert.True(t, meta == b.Metadata)
+		}
+		bySpec[next/20] = b.Metadata
+		next++
+	}
+	assert.NoError(t, f.Err())
+	assert.Equal(t, 62, next)
+
+	// one call per spec version, unless workers fetch the metadata of a new spec version at the same time
+	assert.Len(t, bySpec, 4)
+	assert.True(t, srv.metadataCalls >= 4 && srv.metadataCalls <= 4*4, "%v metadata calls", srv.metadataCalls)
+}
+
+func TestFetchRange_MetadataProvider(t *testing.T) {
+	srv := &mockSrv{blocks: 100}
+	cl := newMockClient(t, srv)
+	provider := state.NewMetadataProvider(state.NewState(cl))
+
+	meta, err := provider.At(blockHash(30))
+	assert.NoError(t, err)
+
+	f := FetchRange(context.Background(), cl, 20, 39, Options{Metadata: true, MetadataProvider: provider})
+	for b := range f.Chan() {
+		assert.True(t, meta == b.Metadata)
+	}
+	assert.NoError(t, f.Err())
+	assert.Equal(t, 1, srv.metadataCalls)
+}
+
+func TestFetchRange_BoundedBuffer(t *testing.T) {
+	srv := &mockSrv{blocks: 100}
+	cl := newMockClient(t, srv)
+
+	f := FetchRange(context.Background(), cl, 10, 99, Options{Workers: 2, Buffer: 5})
+	defer f.Close()
+
+	b := <-f.Chan()
+	assert.Equal(t, types.BlockNumber(10), b.Number)
+
+	// while the consumer doesn't receive, at most Buffer blocks are fetched ahead
+	time.Sleep(50 * time.Millisecond)
+	srv.mu.Lock()
+	assert.Equal(t, 15, srv.maxRequested)
+	srv.mu.Unlock()
+
+	f.Close()
+	for range f.Chan() {
+	}
+	assert.Equal(t, context.Canceled, f.Err())
+}
+
+func TestFetchRange_Errors(t *testing.T) {
+	srv := &mockSrv{blocks: 100, failures: map[int]int{30: 5}}
+	cl := newMockClient(t, srv)
+
+	f := FetchRange(context.Background(), cl, 20, 50, Options{Workers: 3, Retries: 2, RetryDelay: time.Millisecond})
+	var received []types.BlockNumber
+	for b := range f.Chan() {
+		received = append(received, b.Number)
+	}
+	assert.EqualError(t, f.Err(), "fetching block 30 failed: block 30 temporarily unavailable")
+	for i, n := range received {
+		assert.Equal(t, types.BlockNumber(20+i), n)
+	}
+	assert.True(t, len(received) <= 10)
+
+	// blocks beyond the best block are not found
+	f = FetchRange(context.Background(), cl, 98, 101, Options{Retries: -1})
+	for range f.Chan() {
+	}
+	assert.Error(t, f.Err())
+
+	// an empty range delivers nothing
+	f = FetchRange(context.Background(), cl, 5, 4, Options{})
+	_, ok := <-f.Chan()
+	assert.False(t, ok)
+	assert.NoError(t, f.Err())
+}
@@ -0,0 +1,204 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package follower follows the blocks of a chain, turning the new and finalized heads announced by a node into an
+ordered stream of events that accounts for reorgs and skipped blocks.
+
+New heads are reported as the node imports them, so a new best block may be on another fork than the previous one or
+several blocks ahead of it, and finalized heads usually skip blocks. The follower keeps the unfinalized blocks of the
+best chain, fetches missing blocks by walking back through ParentHash and reports the blocks leaving and joining the
+best chain in order:
+
+	f, err := follower.Follow(chain.NewChain(cl), lastProcessed+1)
+	if err != nil {
+		return err
+	}
+	defer f.Unsubscribe()
+
+	for {
+		select {
+		case ev, ok := <-f.Chan():
+			if !ok {
+				return nil
+			}
+			switch ev.Type {
+			case follower.BlockImported:
+				// apply ev.Header
+			case follower.BlockRetracted:
+				// revert ev.Header
+			case follower.BlockFinalized:
+				// ev.Header won't be retracted anymore
+			}
+		case err := <-f.Err():
+			return err
+		}
+	}
+*/
+package follower
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/chain"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// EventType is the type of an Event
+type EventType int
+
+const (
+	// BlockImported is sent when a block joins the best chain
+	BlockImported EventType = iota
+	// BlockRetracted is sent when a previously imported block leaves the best chain due to a reorg
+	BlockRetracted
+	// BlockFinalized is sent when a previously imported block is finalized
+	BlockFinalized
+)
+
+// String returns the name of the event type
+func (t EventType) String() string {
+	switch t {
+	case BlockImported:
+		return "BlockImported"
+	case BlockRetracted:
+		return "BlockRetracted"
+	case BlockFinalized:
+		return "BlockFinalized"
+	}
+	return "Unknown"
+}
+
+// Event is a change of the best or finalized chain. Every block is imported before it is finalized or retracted,
+// blocks are imported in ascending and retracted in descending order of their numbers, and blocks are finalized in
+// ascending order.
+type Event struct {
+	Type   EventType
+	Hash   types.Hash
+	Header types.Header
+}
+
+// errUnsubscribed is returned by send once the follower is unsubscribed
+var errUnsubscribed = errors.New("unsubscribed")
+
+// Follower follows a chain through its new and finalized heads, established through Follow
+type Follower struct {
+	heads     *chain.NewHeadsSubscription
+	finalized *chain.FinalizedHeadsSubscription
+	channel   chan Event
+	err       chan error
+	quit      chan struct{}
+	quitOnce  sync.Once // ensures quit is closed once
+}
+
+// Follow starts following the given chain, sending events for all blocks from the given block number on. Blocks up to
+// the finalized head are fetched by number and sent as imported and finalized first, so a consumer can resume after a
+// restart by passing the number following the last block it processed. Since events for blocks that are not finalized
+// may be followed by their retraction, a consumer resuming this way should keep track of finalized blocks only.
+func Follow(c *chain.Chain, from types.BlockNumber) (*Follower, error) {
+	finalized, err := c.SubscribeFinalizedHeads()
+	if err != nil {
+		return nil, err
+	}
+	heads, err := c.SubscribeNewHeads()
+	if err != nil {
+		finalized.Unsubscribe()
+		return nil, err
+	}
+
+	f := &Follower{
+		heads:     heads,
+		finalized: finalized,
+		channel:   make(chan Event),
+		err:       make(chan error, 1),
+		quit:      make(chan struct{}),
+	}
+	go f.listen(&tracker{chain: c, from: from, emit: f.send})
+	return f, nil
+}
+
+// Chan returns the event channel.
+//
+// The channel is closed when Unsubscribe is called on the follower or the follower stopped due to an error.
+func (f *Follower) Chan() <-chan Event {
+	return f.channel
+}
+
+// Err returns the error channel. The intended use of Err is to schedule a restart when the client connection is closed
+// unexpectedly or fetching a block failed.
+//
+// The error channel receives a value when the follower has stopped due to an error, after which it has unsubscribed
+// from the new and finalized heads.
+func (f *Follower) Err() <-chan error {
+	return f.err
+}
+
+// Unsubscribe stops following the chain and closes the event channel.
+// It can safely be called more than once.
+func (f *Follower) Unsubscribe() {
+	f.quitOnce.Do(func() {
+		close(f.quit)
+		f.heads.Unsubscribe()
+		f.finalized.Unsubscribe()
+	})
+}
+
+// send sends the event, returning errUnsubscribed if the follower is unsubscribed meanwhile
+func (f *Follower) send(ev Event) error {
+	select {
+	case <-f.quit:
+		return errUnsubscribed
+	case f.channel <- ev:
+		return nil
+	}
+}
+
+// listen catches up to the finalized head and passes the headers of both subscriptions to the tracker
+func (f *Follower) listen(t *tracker) {
+	defer close(f.channel)
+	defer f.Unsubscribe()
+
+	err := t.start()
+	for err == nil {
+		select {
+		case <-f.quit:
+			return
+		case err = <-f.heads.Err():
+			if err == nil {
+				return
+			}
+		case err = <-f.finalized.Err():
+			if err == nil {
+				return
+			}
+		case head, ok := <-f.heads.Chan():
+			if !ok {
+				return
+			}
+			err = t.head(head)
+		case head, ok := <-f.finalized.Chan():
+			if !ok {
+				return
+			}
+			err = t.finalize(head)
+		}
+	}
+
+	if err != errUnsubscribed {
+		f.err <- err
+	}
+}
@@ -0,0 +1,173 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package follower
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenghq3/go-substrate-rpc-client/client"
+	gethrpc "github.com/zenghq3/go-substrate-rpc-client/gethrpc"
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/chain"
+	"github.com/zenghq3/go-substrate-rpc-client/rpcmocksrv"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// followMockSrv notifies the heads sent to newHeads and finalizedHeads through the respective subscriptions, and
+// reports the name of every subscription that is unsubscribed
+type followMockSrv struct {
+	*chainMockSrv
+	newHeads       chan types.Header
+	finalizedHeads chan types.Header
+	unsubscribed   chan string
+}
+
+func (s *followMockSrv) SubscribeNewHead(ctx context.Context) (*gethrpc.Subscription, error) {
+	return s.subscribe(ctx, s.newHeads, "newHead")
+}
+
+func (s *followMockSrv) SubscribeFinalizedHeads(ctx context.Context) (*gethrpc.Subscription, error) {
+	return s.subscribe(ctx, s.finalizedHeads, "finalizedHeads")
+}
+
+func (s *followMockSrv) subscribe(ctx context.Context, heads <-chan types.Header, name string) (
+	*gethrpc.Subscription, error) {
+	notifier, _ := gethrpc.NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+	go func() {
+		for {
+			select {
+			case head := <-heads:
+				_ = notifier.Notify(sub.ID, head)
+			case <-sub.Err():
+				s.unsubscribed <- name
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// newFollower starts following the chain of newChainMock from the given block number
+func newFollower(t *testing.T, from types.BlockNumber) (*Follower, *followMockSrv, []types.Header) {
+	chainSrv, headers := newChainMock()
+	srv := &followMockSrv{
+		chainMockSrv:   chainSrv,
+		newHeads:       make(chan types.Header),
+		finalizedHeads: make(chan types.Header),
+		unsubscribed:   make(chan string, 2),
+	}
+	s := rpcmocksrv.New()
+	assert.NoError(t, s.RegisterName("chain", srv))
+	cl, err := client.Connect(s.URL)
+	assert.NoError(t, err)
+
+	f, err := Follow(chain.NewChain(cl), from)
+	assert.NoError(t, err)
+	return f, srv, headers
+}
+
+// receive returns the next n events of the follower
+func receive(t *testing.T, f *Follower, n int) []string {
+	var events []string
+	for len(events) < n {
+		select {
+		case ev, ok := <-f.Chan():
+			if !ok {
+				t.Fatalf("channel closed after %v", events)
+			}
+			events = append(events, fmt.Sprintf("%v %v/%v", ev.Type, ev.Header.Number, ev.Header.StateRoot[0]))
+		case err := <-f.Err():
+			t.Fatalf("unexpected error after %v: %v", events, err)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out after %v", events)
+		}
+	}
+	return events
+}
+
+// assertUnsubscribed asserts that both subscriptions are unsubscribed and the event channel is closed
+func assertUnsubscribed(t *testing.T, f *Follower, srv *followMockSrv) {
+	var names []string
+	for len(names) < 2 {
+		select {
+		case name := <-srv.unsubscribed:
+			names = append(names, name)
+		case <-time.After(time.Second):
+			t.Fatalf("only %v unsubscribed", names)
+		}
+	}
+	assert.ElementsMatch(t, []string{"newHead", "finalizedHeads"}, names)
+
+	select {
+	case _, ok := <-f.Chan():
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed")
+	}
+}
+
+func TestFollow(t *testing.T) {
+	f, srv, headers := newFollower(t, 2)
+
+	assert.Equal(t, []string{
+		"BlockImported 2/0", "BlockFinalized 2/0",
+		"BlockImported 3/0", "BlockFinalized 3/0",
+		"BlockImported 4/0", "BlockFinalized 4/0",
+	}, receive(t, f, 6))
+
+	srv.newHeads <- headers[7]
+	assert.Equal(t, []string{"BlockImported 5/0", "BlockImported 6/0", "BlockImported 7/0"}, receive(t, f, 3))
+
+	// a reorg to a fork from block 5
+	fork6 := srv.add(headers[5], 1)
+	fork7 := srv.add(fork6, 1)
+	srv.newHeads <- fork7
+	assert.Equal(t, []string{
+		"BlockRetracted 7/0", "BlockRetracted 6/0", "BlockImported 6/1", "BlockImported 7/1",
+	}, receive(t, f, 4))
+
+	// a finalized head skipping blocks 5 and 6
+	srv.finalizedHeads <- fork7
+	assert.Equal(t, []string{"BlockFinalized 5/0", "BlockFinalized 6/1", "BlockFinalized 7/1"}, receive(t, f, 3))
+
+	f.Unsubscribe()
+	f.Unsubscribe()
+	assertUnsubscribed(t, f, srv)
+	assert.Empty(t, f.Err())
+}
+
+func TestFollow_Error(t *testing.T) {
+	f, srv, headers := newFollower(t, 4)
+	assert.Equal(t, []string{"BlockImported 4/0", "BlockFinalized 4/0"}, receive(t, f, 2))
+
+	// a head the parent of which is unknown to the node
+	orphan := types.Header{ParentHash: types.Hash{0xff}, Number: headers[7].Number}
+	srv.newHeads <- orphan
+
+	select {
+	case err := <-f.Err():
+		assert.EqualError(t, err, "unknown block "+types.Hash{0xff}.Hex())
+	case <-time.After(time.Second):
+		t.Fatal("no error received")
+	}
+	assertUnsubscribed(t, f, srv)
+	f.Unsubscribe()
+}
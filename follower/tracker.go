@@ -0,0 +1,189 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package follower
+
+import (
+	"fmt"
+
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/chain"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// block is a block of the best chain known to the tracker
+type block struct {
+	hash   types.Hash
+	header types.Header
+}
+
+// tracker keeps the unfinalized part of the best chain and emits the events for new and finalized heads
+type tracker struct {
+	chain *chain.Chain
+	from  types.BlockNumber
+	emit  func(Event) error
+	// blocks holds the last finalized block, followed by the imported blocks of the best chain in ascending order
+	blocks []block
+}
+
+// start emits the blocks from the first block number up to the finalized head as imported and finalized
+func (t *tracker) start() error {
+	finalizedHash, err := t.chain.GetFinalizedHead()
+	if err != nil {
+		return err
+	}
+	finalized, err := t.chain.GetHeader(finalizedHash)
+	if err != nil {
+		return err
+	}
+
+	for n := t.from; n < finalized.Number; n++ {
+		hash, err := t.chain.GetBlockHash(uint64(n))
+		if err != nil {
+			return err
+		}
+		header, err := t.chain.GetHeader(hash)
+		if err != nil {
+			return err
+		}
+		err = t.importFinalized(block{hash, *header})
+		if err != nil {
+			return err
+		}
+	}
+
+	t.blocks = []block{{finalizedHash, *finalized}}
+	return t.importFinalized(t.blocks[0])
+}
+
+// head makes the block of the given header the tip of the best chain. Missing blocks between the header and the best
+// chain are fetched, blocks of the best chain that are not ancestors of the header are retracted.
+func (t *tracker) head(header types.Header) error {
+	if header.Number <= t.blocks[0].header.Number {
+		// at or below the last finalized block, this head is outdated
+		return nil
+	}
+
+	hash, err := types.GetHash(header)
+	if err != nil {
+		return err
+	}
+	if i := t.index(hash); i >= 0 {
+		return t.retract(i + 1)
+	}
+
+	branch := []block{{hash, header}}
+	for {
+		first := branch[0].header
+		if i := t.index(first.ParentHash); i >= 0 {
+			err = t.retract(i + 1)
+			if err != nil {
+				return err
+			}
+			return t.importBlocks(branch...)
+		}
+		if first.Number <= t.blocks[0].header.Number+1 {
+			return fmt.Errorf("block %v does not descend from the finalized block %v at height %v",
+				branch[0].hash.Hex(), t.blocks[0].hash.Hex(), t.blocks[0].header.Number)
+		}
+
+		parent, err := t.chain.GetHeader(first.ParentHash)
+		if err != nil {
+			return err
+		}
+		branch = append([]block{{first.ParentHash, *parent}}, branch...)
+	}
+}
+
+// finalize finalizes the block of the given header and all of its unfinalized ancestors, importing them first if
+// they are not part of the best chain yet
+func (t *tracker) finalize(header types.Header) error {
+	if header.Number <= t.blocks[0].header.Number {
+		return nil
+	}
+
+	hash, err := types.GetHash(header)
+	if err != nil {
+		return err
+	}
+	i := t.index(hash)
+	if i < 0 {
+		err = t.head(header)
+		if err != nil {
+			return err
+		}
+		i = t.index(hash)
+	}
+
+	for _, b := range t.blocks[1 : i+1] {
+		err = t.send(BlockFinalized, b)
+		if err != nil {
+			return err
+		}
+	}
+	t.blocks = t.blocks[i:]
+	return nil
+}
+
+// index returns the index of the block with the given hash in blocks, or -1
+func (t *tracker) index(hash types.Hash) int {
+	for i, b := range t.blocks {
+		if b.hash == hash {
+			return i
+		}
+	}
+	return -1
+}
+
+// retract removes the blocks from the given index on, starting with the tip
+func (t *tracker) retract(from int) error {
+	for i := len(t.blocks) - 1; i >= from; i-- {
+		err := t.send(BlockRetracted, t.blocks[i])
+		if err != nil {
+			return err
+		}
+		t.blocks = t.blocks[:i]
+	}
+	return nil
+}
+
+// importBlocks appends the given blocks to the best chain
+func (t *tracker) importBlocks(blocks ...block) error {
+	for _, b := range blocks {
+		t.blocks = append(t.blocks, b)
+		err := t.send(BlockImported, b)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importFinalized emits the given block as imported and finalized at once
+func (t *tracker) importFinalized(b block) error {
+	err := t.send(BlockImported, b)
+	if err != nil {
+		return err
+	}
+	return t.send(BlockFinalized, b)
+}
+
+// send emits an event for the block, unless the block is below the first block number
+func (t *tracker) send(typ EventType, b block) error {
+	if b.header.Number < t.from {
+		return nil
+	}
+	return t.emit(Event{Type: typ, Hash: b.hash, Header: b.header})
+}
@@ -0,0 +1,184 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package follower
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenghq3/go-substrate-rpc-client/client"
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/chain"
+	"github.com/zenghq3/go-substrate-rpc-client/rpcmocksrv"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// chainMockSrv serves chain_getHeader for all known headers, and chain_getBlockHash and chain_getFinalizedHead for
+// the canonical chain
+type chainMockSrv struct {
+	headers   map[string]types.Header
+	canonical []types.Hash
+	finalized int
+}
+
+func (s *chainMockSrv) GetHeader(hash *string) (types.Header, error) {
+	header, ok := s.headers[*hash]
+	if !ok {
+		return header, fmt.Errorf("unknown block %v", *hash)
+	}
+	return header, nil
+}
+
+func (s *chainMockSrv) GetBlockHash(height *uint64) string {
+	return s.canonical[*height].Hex()
+}
+
+func (s *chainMockSrv) GetFinalizedHead() string {
+	return s.canonical[s.finalized].Hex()
+}
+
+// add adds a child of the given parent, the fork byte distinguishes siblings
+func (s *chainMockSrv) add(parent types.Header, fork byte) types.Header {
+	parentHash, err := types.GetHash(parent)
+	if err != nil {
+		panic(err)
+	}
+	header := types.Header{ParentHash: parentHash, Number: parent.Number + 1, StateRoot: types.Hash{fork}}
+	s.register(header)
+	return header
+}
+
+func (s *chainMockSrv) register(header types.Header) {
+	hash, err := types.GetHash(header)
+	if err != nil {
+		panic(err)
+	}
+	s.headers[hash.Hex()] = header
+}
+
+// newChainMock returns a chain of 10 canonical blocks with block 4 finalized, and the headers of the canonical chain
+func newChainMock() (*chainMockSrv, []types.Header) {
+	srv := &chainMockSrv{headers: map[string]types.Header{}, finalized: 4}
+	headers := []types.Header{{}}
+	srv.register(headers[0])
+	for i := 1; i < 10; i++ {
+		headers = append(headers, srv.add(headers[i-1], 0))
+	}
+	for _, h := range headers {
+		hash, err := types.GetHash(h)
+		if err != nil {
+			panic(err)
+		}
+		srv.canonical = append(srv.canonical, hash)
+	}
+	return srv, headers
+}
+
+// newTracker returns a tracker following the chain of newChainMock, and the headers of the canonical chain
+func newTracker(t *testing.T, from types.BlockNumber) (*tracker, *chainMockSrv, []types.Header, *[]string) {
+	srv, headers := newChainMock()
+
+	s := rpcmocksrv.New()
+	assert.NoError(t, s.RegisterName("chain", srv))
+	cl, err := client.Connect(s.URL)
+	assert.NoError(t, err)
+
+	var events []string
+	tr := &tracker{chain: chain.NewChain(cl), from: from, emit: func(ev Event) error {
+		hash, err := types.GetHash(ev.Header)
+		assert.NoError(t, err)
+		assert.Equal(t, hash, ev.Hash)
+		events = append(events, fmt.Sprintf("%v %v/%v", ev.Type, ev.Header.Number, ev.Header.StateRoot[0]))
+		return nil
+	}}
+	return tr, srv, headers, &events
+}
+
+func TestTracker(t *testing.T) {
+	tr, srv, headers, events := newTracker(t, 2)
+
+	assert.NoError(t, tr.start())
+	assert.Equal(t, []string{
+		"BlockImported 2/0", "BlockFinalized 2/0",
+		"BlockImported 3/0", "BlockFinalized 3/0",
+		"BlockImported 4/0", "BlockFinalized 4/0",
+	}, *events)
+
+	// missing blocks are fetched
+	*events = nil
+	assert.NoError(t, tr.head(headers[7]))
+	assert.Equal(t, []string{"BlockImported 5/0", "BlockImported 6/0", "BlockImported 7/0"}, *events)
+
+	// a fork from block 5 retracts blocks 6 and 7
+	*events = nil
+	fork6 := srv.add(headers[5], 1)
+	fork7 := srv.add(fork6, 1)
+	fork8 := srv.add(fork7, 1)
+	assert.NoError(t, tr.head(fork6))
+	assert.NoError(t, tr.head(fork8))
+	assert.Equal(t, []string{
+		"BlockRetracted 7/0", "BlockRetracted 6/0", "BlockImported 6/1", "BlockImported 7/1", "BlockImported 8/1",
+	}, *events)
+
+	// a best block going back retracts the blocks after it
+	*events = nil
+	assert.NoError(t, tr.head(fork7))
+	assert.NoError(t, tr.head(fork7))
+	assert.Equal(t, []string{"BlockRetracted 8/1"}, *events)
+
+	// finalizing skipped blocks finalizes all of them
+	*events = nil
+	assert.NoError(t, tr.finalize(fork7))
+	assert.Equal(t, []string{"BlockFinalized 5/0", "BlockFinalized 6/1", "BlockFinalized 7/1"}, *events)
+
+	// outdated heads are ignored
+	*events = nil
+	assert.NoError(t, tr.head(headers[6]))
+	assert.NoError(t, tr.finalize(fork6))
+	assert.Empty(t, *events)
+
+	// finalizing an unknown block imports it first
+	fork9 := srv.add(srv.add(fork7, 1), 1)
+	assert.NoError(t, tr.finalize(fork9))
+	assert.Equal(t, []string{
+		"BlockImported 8/1", "BlockImported 9/1", "BlockFinalized 8/1", "BlockFinalized 9/1",
+	}, *events)
+
+	// blocks on a fork of a finalized block can't be imported
+	head10 := srv.add(headers[9], 0)
+	hash10, err := types.GetHash(head10)
+	assert.NoError(t, err)
+	err = tr.head(head10)
+	assert.EqualError(t, err, fmt.Sprintf("block %v does not descend from the finalized block %v at height 9",
+		hash10.Hex(), tr.blocks[0].hash.Hex()))
+}
+
+func TestTracker_StartAfterFinalized(t *testing.T) {
+	tr, _, headers, events := newTracker(t, 6)
+
+	assert.NoError(t, tr.start())
+	assert.NoError(t, tr.head(headers[7]))
+	assert.NoError(t, tr.finalize(headers[6]))
+	assert.Equal(t, []string{"BlockImported 6/0", "BlockImported 7/0", "BlockFinalized 6/0"}, *events)
+}
+
+func TestEventType_String(t *testing.T) {
+	assert.Equal(t, "BlockImported", BlockImported.String())
+	assert.Equal(t, "BlockRetracted", BlockRetracted.String())
+	assert.Equal(t, "BlockFinalized", BlockFinalized.String())
+	assert.Equal(t, "Unknown", EventType(7).String())
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -315,15 +316,16 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	if callb := h.reg.subscription(msg.namespace(), msg.methodName()); callb != nil {
+		return h.handleSubstrateSubscribe(cp, msg, callb)
+	}
 	// if msg.isSubscribe() {
 	// 	return h.handleSubscribe(cp, msg)
 	// }
-	var callb *callback
-	// if msg.isUnsubscribe() {
-	// callb = h.unsubscribeCb
-	// } else {
-	callb = h.reg.callback(msg.Method)
-	// }
+	callb := h.reg.callback(msg.Method)
+	if callb == nil && msg.isUnsubscribe() {
+		callb = h.unsubscribeCb
+	}
 	if callb == nil {
 		return msg.errorResponse(&methodNotFoundError{method: msg.Method})
 	}
@@ -335,6 +337,27 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 	return h.runMethod(cp.ctx, msg, callb, args)
 }
 
+// handleSubstrateSubscribe processes subscription calls the way Substrate names them: a subscription method is called
+// like any other method, e.g. chain_subscribeNewHead, instead of passing the subscription name as first argument to
+// *_subscribe. The notifications are sent with the method name of the subscription, see Notifier.
+func (h *handler) handleSubstrateSubscribe(cp *callProc, msg *jsonrpcMessage, callb *callback) *jsonrpcMessage {
+	if !h.allowSubscribe {
+		return msg.errorResponse(ErrNotificationsUnsupported)
+	}
+
+	args, err := parsePositionalArguments(msg.Params, callb.argTypes)
+	if err != nil {
+		return msg.errorResponse(&invalidParamsError{err.Error()})
+	}
+
+	// Install notifier in context so the subscription handler can find it.
+	n := &Notifier{h: h, namespace: msg.namespace(), subscribeMethodSuffix: msg.methodName()}
+	cp.notifiers = append(cp.notifiers, n)
+	ctx := context.WithValue(cp.ctx, notifierKey{}, n)
+
+	return h.runMethod(ctx, msg, callb, args)
+}
+
 // handleSubscribe processes *_subscribe method calls.
 // func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
 // 	if !h.allowSubscribe {
@@ -377,8 +400,15 @@ func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *cal
 	return msg.response(result)
 }
 
-// unsubscribe is the callback function for all *_unsubscribe calls.
-func (h *handler) unsubscribe(ctx context.Context, id ID) (bool, error) {
+// unsubscribe is the callback function for all *_unsubscribe calls. Substrate clients pass the subscription ID as
+// string, so it is accepted as string or number.
+func (h *handler) unsubscribe(ctx context.Context, rawID json.RawMessage) (bool, error) {
+	v, err := strconv.ParseUint(strings.Trim(string(rawID), `"`), 10, 32)
+	if err != nil {
+		return false, fmt.Errorf("invalid subscription ID %s: %v", rawID, err)
+	}
+	id := ID(v)
+
 	h.subLock.Lock()
 	defer h.subLock.Unlock()
 
@@ -75,6 +75,18 @@ func (msg *jsonrpcMessage) namespace() string {
 	return elem[0]
 }
 
+func (msg *jsonrpcMessage) methodName() string {
+	elem := strings.SplitN(msg.Method, serviceMethodSeparator, 2)
+	if len(elem) != 2 {
+		return ""
+	}
+	return elem[1]
+}
+
+func (msg *jsonrpcMessage) isUnsubscribe() bool {
+	return strings.HasPrefix(msg.methodName(), "unsubscribe")
+}
+
 func (msg *jsonrpcMessage) String() string {
 	b, _ := json.Marshal(msg)
 	return string(b)
@@ -19,11 +19,14 @@ package gsrpc
 import (
 	"github.com/zenghq3/go-substrate-rpc-client/client"
 	"github.com/zenghq3/go-substrate-rpc-client/rpc"
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/state"
 )
 
 type SubstrateAPI struct {
 	RPC    *rpc.RPC
 	Client client.Client
+	// Metadata provides the metadata of the chain, cached per runtime spec version
+	Metadata *state.MetadataProvider
 }
 
 func NewSubstrateAPI(url string) (*SubstrateAPI, error) {
@@ -32,8 +35,11 @@ func NewSubstrateAPI(url string) (*SubstrateAPI, error) {
 		return nil, err
 	}
 
+	r := rpc.NewRPC(cl)
+
 	return &SubstrateAPI{
-		RPC:    rpc.NewRPC(cl),
-		Client: cl,
+		RPC:      r,
+		Client:   cl,
+		Metadata: state.NewMetadataProvider(r.State),
 	}, nil
 }
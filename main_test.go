@@ -97,7 +97,7 @@ func Example_listenToBalanceChange() {
 		panic(err)
 	}
 
-	meta, err := api.RPC.State.GetMetadataLatest()
+	meta, err := api.Metadata.Latest()
 	if err != nil {
 		panic(err)
 	}
@@ -193,7 +193,7 @@ func Example_makeASimpleTransfer() {
 		panic(err)
 	}
 
-	meta, err := api.RPC.State.GetMetadataLatest()
+	meta, err := api.Metadata.Latest()
 	if err != nil {
 		panic(err)
 	}
@@ -266,7 +266,7 @@ func Example_displaySystemEvents() {
 		panic(err)
 	}
 
-	meta, err := api.RPC.State.GetMetadataLatest()
+	meta, err := api.Metadata.Latest()
 	if err != nil {
 		panic(err)
 	}
@@ -401,7 +401,7 @@ func Example_transactionWithEvents() {
 		panic(err)
 	}
 
-	meta, err := api.RPC.State.GetMetadataLatest()
+	meta, err := api.Metadata.Latest()
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,390 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package metadiff compares two versions of metadata, for example before and after a runtime upgrade, and reports the
+added, removed and changed modules, calls, events, storage entries, constants and errors.
+
+Changes that break existing clients are flagged as breaking, such as removed items, changed call or event indices,
+changed argument types and changed storage hashers, key or value types. Added items and changed constant values or
+storage defaults are not breaking. The report can be rendered as text or marshalled to JSON, e.g. to fail a CI job
+on breaking changes:
+
+	report, err := metadiff.Compare(oldMeta, newMeta)
+	if err != nil {
+		return err
+	}
+	fmt.Print(report)
+	if report.HasBreakingChanges() {
+		os.Exit(1)
+	}
+
+Metadata v4 and later is supported and both metadata may be of different versions. Types of metadata v14 are compared
+by their layout, so that renamed types with the same encoding are equal. Types of earlier versions are only given
+as type strings and are compared by their normalized type strings. Since the type names of metadata v14 differ from
+the type strings of earlier versions, comparing metadata across v14 reports most types as changed.
+*/
+package metadiff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// Kind is the kind of item that changed
+type Kind string
+
+const (
+	KindModule   Kind = "module"
+	KindCall     Kind = "call"
+	KindEvent    Kind = "event"
+	KindStorage  Kind = "storage"
+	KindConstant Kind = "constant"
+	KindError    Kind = "error"
+)
+
+// Action describes how an item changed
+type Action string
+
+const (
+	Added   Action = "added"
+	Removed Action = "removed"
+	Changed Action = "changed"
+)
+
+// Change is a single difference between two versions of metadata. For changed items, Field names the property that
+// changed, such as "index", "args", "hashers", "keys", "value", "modifier", "default" or "type", and Old and New hold
+// its descriptions.
+type Change struct {
+	Module   string `json:"module"`
+	Kind     Kind   `json:"kind"`
+	Item     string `json:"item,omitempty"`
+	Action   Action `json:"action"`
+	Field    string `json:"field,omitempty"`
+	Old      string `json:"old,omitempty"`
+	New      string `json:"new,omitempty"`
+	Breaking bool   `json:"breaking"`
+}
+
+// String returns a single line description of the change, e.g. `Balances.transfer call changed args: (dest: Address)
+// -> (dest: MultiAddress) [breaking]`
+func (c Change) String() string {
+	var s string
+	if c.Kind == KindModule {
+		s = fmt.Sprintf("%v %v %v", c.Kind, c.Module, c.Action)
+	} else {
+		s = fmt.Sprintf("%v %v.%v %v", c.Kind, c.Module, c.Item, c.Action)
+	}
+	if c.Field != "" {
+		s += fmt.Sprintf(" %v: %v -> %v", c.Field, c.Old, c.New)
+	}
+	if c.Breaking {
+		s += " [breaking]"
+	}
+	return s
+}
+
+// Report holds the differences between two versions of metadata, ordered by module
+type Report struct {
+	OldVersion uint8    `json:"oldVersion"`
+	NewVersion uint8    `json:"newVersion"`
+	Changes    []Change `json:"changes"`
+}
+
+// HasBreakingChanges returns true if any of the changes is breaking
+func (r *Report) HasBreakingChanges() bool {
+	for _, c := range r.Changes {
+		if c.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// BreakingChanges returns the breaking changes
+func (r *Report) BreakingChanges() []Change {
+	var changes []Change
+	for _, c := range r.Changes {
+		if c.Breaking {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// String renders the report as human readable text, with one line per change
+func (r *Report) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "metadata v%v -> v%v: %v changes, %v breaking\n", r.OldVersion, r.NewVersion, len(r.Changes),
+		len(r.BreakingChanges()))
+	for _, c := range r.Changes {
+		sb.WriteString(c.String())
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Compare compares two versions of metadata, which may be of different versions
+func Compare(oldMeta, newMeta *types.Metadata) (*Report, error) {
+	oldModules, err := modules(oldMeta)
+	if err != nil {
+		return nil, err
+	}
+	newModules, err := modules(newMeta)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Report{OldVersion: oldMeta.Version, NewVersion: newMeta.Version, Changes: []Change{}}
+
+	newByName := make(map[string]module, len(newModules))
+	for _, m := range newModules {
+		newByName[m.name] = m
+	}
+	oldNames := make(map[string]bool, len(oldModules))
+	for _, o := range oldModules {
+		oldNames[o.name] = true
+		n, ok := newByName[o.name]
+		if !ok {
+			r.add(Change{Module: o.name, Kind: KindModule, Action: Removed, Breaking: true})
+			continue
+		}
+		r.compareModules(o, n)
+	}
+	for _, n := range newModules {
+		if !oldNames[n.name] {
+			r.add(Change{Module: n.name, Kind: KindModule, Action: Added})
+		}
+	}
+	return r, nil
+}
+
+func (r *Report) add(c Change) {
+	r.Changes = append(r.Changes, c)
+}
+
+func (r *Report) compareModules(o, n module) {
+	r.compareFunctions(o.name, KindCall, o.calls, n.calls)
+	r.compareFunctions(o.name, KindEvent, o.events, n.events)
+	r.compareStorage(o.name, o.storage, n.storage)
+	r.compareConstants(o.name, o.constants, n.constants)
+	r.compareFunctions(o.name, KindError, o.errors, n.errors)
+}
+
+// compareItems reports removed and added items and calls changed for items present in both, matching them by name
+func (r *Report) compareItems(mod string, kind Kind, oldNames, newNames []string, changed func(i, j int)) {
+	newIndex := make(map[string]int, len(newNames))
+	for j, name := range newNames {
+		newIndex[name] = j
+	}
+	oldIndex := make(map[string]bool, len(oldNames))
+	for i, name := range oldNames {
+		oldIndex[name] = true
+		j, ok := newIndex[name]
+		if !ok {
+			r.add(Change{Module: mod, Kind: kind, Item: name, Action: Removed, Breaking: true})
+			continue
+		}
+		changed(i, j)
+	}
+	for _, name := range newNames {
+		if !oldIndex[name] {
+			r.add(Change{Module: mod, Kind: kind, Item: name, Action: Added})
+		}
+	}
+}
+
+func (r *Report) compareFunctions(mod string, kind Kind, o, n []function) {
+	r.compareItems(mod, kind, functionNames(o), functionNames(n), func(i, j int) {
+		of, nf := o[i], n[j]
+		change := Change{Module: mod, Kind: kind, Item: of.name, Action: Changed}
+
+		if of.index != nf.index {
+			change.Field, change.Old, change.New, change.Breaking = "index", indexString(kind, of.index),
+				indexString(kind, nf.index), true
+			r.add(change)
+		}
+
+		// only the types of the arguments are relevant for the encoding, renamed arguments are not breaking
+		typesChanged := !argTypesEqual(of.args, nf.args)
+		oldArgs, newArgs := argsString(of.args, false), argsString(nf.args, false)
+		if typesChanged && oldArgs == newArgs {
+			oldArgs, newArgs = argsString(of.args, true), argsString(nf.args, true)
+		}
+		if typesChanged || oldArgs != newArgs {
+			change.Field, change.Old, change.New, change.Breaking = "args", oldArgs, newArgs, typesChanged
+			r.add(change)
+		}
+	})
+}
+
+func functionNames(functions []function) []string {
+	names := make([]string, len(functions))
+	for i, f := range functions {
+		names[i] = f.name
+	}
+	return names
+}
+
+func indexString(kind Kind, index types.CallIndex) string {
+	if kind == KindError {
+		return fmt.Sprint(index.MethodIndex)
+	}
+	return fmt.Sprintf("%v/%v", index.SectionIndex, index.MethodIndex)
+}
+
+// argsString describes arguments by the names of their types, or by their layouts
+func argsString(args []arg, layout bool) string {
+	s := make([]string, len(args))
+	for i, a := range args {
+		s[i] = a.typ.describe(layout)
+		if a.name != "" {
+			s[i] = a.name + ": " + s[i]
+		}
+	}
+	return "(" + strings.Join(s, ", ") + ")"
+}
+
+func argTypesEqual(o, n []arg) bool {
+	if len(o) != len(n) {
+		return false
+	}
+	for i := range o {
+		if !o[i].typ.equal(n[i].typ) {
+			return false
+		}
+	}
+	return true
+}
+
+// equal compares types by their layout if both have one, otherwise by their name
+func (t typeRef) equal(other typeRef) bool {
+	if t.layout != "" && other.layout != "" {
+		return t.layout == other.layout
+	}
+	return t.name == other.name
+}
+
+func (t typeRef) describe(layout bool) string {
+	if layout && t.layout != "" {
+		return t.layout
+	}
+	return t.name
+}
+
+// typeNames returns the names of changed types, or their layouts if the names are not sufficient to tell the types
+// apart
+func typeNames(o, n typeRef) (string, string) {
+	if o.name == n.name {
+		return o.describe(true), n.describe(true)
+	}
+	return o.name, n.name
+}
+
+func (r *Report) compareStorage(mod string, o, n []storageEntry) {
+	oldNames := make([]string, len(o))
+	for i, s := range o {
+		oldNames[i] = s.name
+	}
+	newNames := make([]string, len(n))
+	for i, s := range n {
+		newNames[i] = s.name
+	}
+
+	r.compareItems(mod, KindStorage, oldNames, newNames, func(i, j int) {
+		oe, ne := o[i], n[j]
+		change := Change{Module: mod, Kind: KindStorage, Item: oe.name, Action: Changed, Breaking: true}
+
+		oldHashers, newHashers := "["+strings.Join(oe.hashers, ", ")+"]", "["+strings.Join(ne.hashers, ", ")+"]"
+		if oldHashers != newHashers {
+			change.Field, change.Old, change.New = "hashers", oldHashers, newHashers
+			r.add(change)
+		}
+
+		if !typesEqual(oe.keys, ne.keys) {
+			change.Field, change.Old, change.New = "keys", typesString(oe.keys, false), typesString(ne.keys, false)
+			if change.Old == change.New {
+				change.Old, change.New = typesString(oe.keys, true), typesString(ne.keys, true)
+			}
+			r.add(change)
+		}
+
+		if !oe.value.equal(ne.value) {
+			change.Field = "value"
+			change.Old, change.New = typeNames(oe.value, ne.value)
+			r.add(change)
+		}
+
+		if oe.modifier != ne.modifier {
+			change.Field, change.Old, change.New = "modifier", oe.modifier, ne.modifier
+			r.add(change)
+		}
+
+		if oe.fallback != ne.fallback {
+			change.Field, change.Old, change.New, change.Breaking = "default", oe.fallback, ne.fallback, false
+			r.add(change)
+		}
+	})
+}
+
+func typesEqual(o, n []typeRef) bool {
+	if len(o) != len(n) {
+		return false
+	}
+	for i := range o {
+		if !o[i].equal(n[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func typesString(refs []typeRef, layout bool) string {
+	s := make([]string, len(refs))
+	for i, t := range refs {
+		s[i] = t.describe(layout)
+	}
+	return "[" + strings.Join(s, ", ") + "]"
+}
+
+func (r *Report) compareConstants(mod string, o, n []constant) {
+	oldNames := make([]string, len(o))
+	for i, c := range o {
+		oldNames[i] = c.name
+	}
+	newNames := make([]string, len(n))
+	for i, c := range n {
+		newNames[i] = c.name
+	}
+
+	r.compareItems(mod, KindConstant, oldNames, newNames, func(i, j int) {
+		oc, nc := o[i], n[j]
+		change := Change{Module: mod, Kind: KindConstant, Item: oc.name, Action: Changed}
+
+		if !oc.typ.equal(nc.typ) {
+			change.Field, change.Breaking = "type", true
+			change.Old, change.New = typeNames(oc.typ, nc.typ)
+			r.add(change)
+		}
+
+		if oc.value != nc.value {
+			change.Field, change.Old, change.New, change.Breaking = "value", oc.value, nc.value, false
+			r.add(change)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadiff_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/zenghq3/go-substrate-rpc-client/metadiff"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+func copyMetadata(t *testing.T, meta *types.Metadata) *types.Metadata {
+	bz, err := types.EncodeToBytes(meta)
+	assert.NoError(t, err)
+	var c types.Metadata
+	assert.NoError(t, types.DecodeFromBytes(bz, &c))
+	return &c
+}
+
+func TestCompare_Unchanged(t *testing.T) {
+	r, err := Compare(types.ExamplaryMetadataV8, types.ExamplaryMetadataV8)
+	assert.NoError(t, err)
+	assert.Empty(t, r.Changes)
+	assert.False(t, r.HasBreakingChanges())
+	assert.Equal(t, "metadata v8 -> v8: 0 changes, 0 breaking\n", r.String())
+}
+
+func TestCompare_MetadataV8(t *testing.T) {
+	meta := copyMetadata(t, types.ExamplaryMetadataV8)
+	modules := meta.AsMetadataV8.Modules
+	for i := range modules {
+		if modules[i].Name != "Balances" {
+			continue
+		}
+		balances := &modules[i]
+		balances.Calls[0].Args[1].Type = "T::Balance"
+		balances.Calls[1].Args[1].Name = "free"
+		balances.Storage.Items[2].Type.AsMap.Hasher = types.StorageHasher{IsTwox64Concat: true}
+		// equal type strings after normalization
+		balances.Storage.Items[3].Type.AsMap.Key = "<T as system::Trait>::AccountId"
+		balances.Constants[0].Value = types.Bytes{0x01}
+	}
+	meta.AsMetadataV8.Modules = append(modules[:len(modules)-1], types.ModuleMetadataV8{Name: "Foo"})
+
+	r, err := Compare(types.ExamplaryMetadataV8, meta)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Module: "Balances", Kind: KindCall, Item: "transfer", Action: Changed, Field: "args",
+			Old: "(dest: Source, value: Compact<Balance>)", New: "(dest: Source, value: Balance)", Breaking: true},
+		{Module: "Balances", Kind: KindCall, Item: "set_balance", Action: Changed, Field: "args",
+			Old: "(who: Source, new_free: Compact<Balance>, new_reserved: Compact<Balance>)",
+			New: "(who: Source, free: Compact<Balance>, new_reserved: Compact<Balance>)"},
+		{Module: "Balances", Kind: KindStorage, Item: "FreeBalance", Action: Changed, Field: "hashers",
+			Old: "[Blake2_256]", New: "[Twox64Concat]", Breaking: true},
+		{Module: "Balances", Kind: KindConstant, Item: "ExistentialDeposit", Action: Changed, Field: "value",
+			Old: "0x00407a10f35a00000000000000000000", New: "0x01"},
+		{Module: "RandomnessCollectiveFlip", Kind: KindModule, Action: Removed, Breaking: true},
+		{Module: "Foo", Kind: KindModule, Action: Added},
+	}, r.Changes)
+	assert.True(t, r.HasBreakingChanges())
+	assert.Len(t, r.BreakingChanges(), 3)
+
+	assert.Equal(t, "metadata v8 -> v8: 6 changes, 3 breaking\n"+
+		"call Balances.transfer changed args: (dest: Source, value: Compact<Balance>) -> "+
+		"(dest: Source, value: Balance) [breaking]\n"+
+		"call Balances.set_balance changed args: (who: Source, new_free: Compact<Balance>, "+
+		"new_reserved: Compact<Balance>) -> (who: Source, free: Compact<Balance>, new_reserved: Compact<Balance>)\n"+
+		"storage Balances.FreeBalance changed hashers: [Blake2_256] -> [Twox64Concat] [breaking]\n"+
+		"constant Balances.ExistentialDeposit changed value: 0x00407a10f35a00000000000000000000 -> 0x01\n"+
+		"module RandomnessCollectiveFlip removed [breaking]\n"+
+		"module Foo added\n", r.String())
+
+	bz, err := json.Marshal(r.Changes[4:])
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"module":"RandomnessCollectiveFlip","kind":"module","action":"removed","breaking":true},`+
+		`{"module":"Foo","kind":"module","action":"added","breaking":false}]`, string(bz))
+}
+
+func TestCompare_CallIndex(t *testing.T) {
+	meta := copyMetadata(t, types.ExamplaryMetadataV8)
+	modules := meta.AsMetadataV8.Modules
+	// removing Utility shifts the section index of the calls of all following modules
+	meta.AsMetadataV8.Modules = append([]types.ModuleMetadataV8{modules[0]}, modules[2:]...)
+
+	r, err := Compare(types.ExamplaryMetadataV8, meta)
+	assert.NoError(t, err)
+	assert.Equal(t, Change{Module: "Utility", Kind: KindModule, Action: Removed, Breaking: true}, r.Changes[0])
+	assert.Equal(t, Change{Module: "Timestamp", Kind: KindCall, Item: "set", Action: Changed, Field: "index",
+		Old: "3/0", New: "2/0", Breaking: true}, r.Changes[1])
+}
+
+func metadataV14(accountData types.Si1TypeDefComposite, errors ...types.Si1Variant) *types.Metadata {
+	id := types.NewSi1LookupTypeID
+	meta := types.NewMetadataV14()
+	meta.AsMetadataV14.Lookup = types.PortableRegistry{
+		{ID: id(0), Type: types.Si1Type{Def: types.Si1TypeDef{IsPrimitive: true, AsPrimitive: types.IsU8}}},
+		{ID: id(1), Type: types.Si1Type{Def: types.Si1TypeDef{IsArray: true,
+			AsArray: types.Si1TypeDefArray{Len: 32, Type: id(0)}}}},
+		{ID: id(2), Type: types.Si1Type{Path: types.Si1Path{"sp_core", "crypto", "AccountId32"},
+			Def: types.Si1TypeDef{IsComposite: true, AsComposite: types.Si1TypeDefComposite{
+				Fields: []types.Si1Field{{Type: id(1)}}}}}},
+		{ID: id(3), Type: types.Si1Type{Def: types.Si1TypeDef{IsPrimitive: true, AsPrimitive: types.IsU128}}},
+		{ID: id(4), Type: types.Si1Type{Path: types.Si1Path{"pallet_balances", "AccountData"},
+			Def: types.Si1TypeDef{IsComposite: true, AsComposite: accountData}}},
+		{ID: id(5), Type: types.Si1Type{Path: types.Si1Path{"pallet_balances", "pallet", "Error"},
+			Def: types.Si1TypeDef{IsVariant: true, AsVariant: types.Si1TypeDefVariant{Variants: errors}}}},
+	}
+	meta.AsMetadataV14.Pallets = []types.PalletMetadataV14{{
+		Name:       "Balances",
+		HasStorage: true,
+		Storage: types.StorageMetadataV14{Prefix: "Balances", Items: []types.StorageEntryMetadataV14{
+			{Name: "Account", Type: types.StorageEntryTypeV14{IsMap: true, AsMap: types.MapTypeV14{
+				Hashers: []types.StorageHasherV11{{IsBlake2_128Concat: true}}, Key: id(2), Value: id(4)}}},
+		}},
+		HasErrors: true,
+		Errors:    types.ErrorMetadataV14{Type: id(5)},
+		Index:     5,
+	}}
+	return meta
+}
+
+func TestCompare_MetadataV14(t *testing.T) {
+	id := types.NewSi1LookupTypeID
+	free := types.Si1Field{HasName: true, Name: "free", Type: id(3)}
+	reserved := types.Si1Field{HasName: true, Name: "reserved", Type: id(3)}
+
+	oldMeta := metadataV14(types.Si1TypeDefComposite{Fields: []types.Si1Field{free}},
+		types.Si1Variant{Name: "InsufficientBalance", Index: 0})
+	newMeta := metadataV14(types.Si1TypeDefComposite{Fields: []types.Si1Field{free, reserved}},
+		types.Si1Variant{Name: "VestingBalance", Index: 0}, types.Si1Variant{Name: "InsufficientBalance", Index: 1})
+
+	r, err := Compare(oldMeta, newMeta)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Module: "Balances", Kind: KindStorage, Item: "Account", Action: Changed, Field: "value",
+			Old: "{free: u128}", New: "{free: u128, reserved: u128}", Breaking: true},
+		{Module: "Balances", Kind: KindError, Item: "InsufficientBalance", Action: Changed, Field: "index",
+			Old: "0", New: "1", Breaking: true},
+		{Module: "Balances", Kind: KindError, Item: "VestingBalance", Action: Added},
+	}, r.Changes)
+}
+
+func TestCompare_DifferentVersions(t *testing.T) {
+	r, err := Compare(types.ExamplaryMetadataV4, types.ExamplaryMetadataV8)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(4), r.OldVersion)
+	assert.Equal(t, uint8(8), r.NewVersion)
+	assert.NotEmpty(t, r.Changes)
+
+	_, err = Compare(types.NewMetadataV3(), types.ExamplaryMetadataV8)
+	assert.EqualError(t, err, "comparing metadata requires metadata v4 or later, but got v3")
+}
@@ -0,0 +1,588 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadiff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zenghq3/go-substrate-rpc-client/registry"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// module is the part of the module metadata that is compared, with the same shape for all versions
+type module struct {
+	name      string
+	calls     []function
+	events    []function
+	storage   []storageEntry
+	constants []constant
+	errors    []function
+}
+
+// function is a call, event or error. Errors have no arguments.
+type function struct {
+	name  string
+	index types.CallIndex
+	args  []arg
+}
+
+type arg struct {
+	name string
+	typ  typeRef
+}
+
+// typeRef describes a type by its name, and for metadata v14 additionally by its layout, which describes the
+// encoding of the type independent of the names of the involved types
+type typeRef struct {
+	name   string
+	layout string
+}
+
+type storageEntry struct {
+	name     string
+	modifier string
+	hashers  []string
+	keys     []typeRef
+	value    typeRef
+	fallback string
+}
+
+type constant struct {
+	name  string
+	typ   typeRef
+	value string
+}
+
+// modules converts the modules of the metadata
+func modules(meta *types.Metadata) ([]module, error) {
+	switch {
+	case meta.IsMetadataV4:
+		mods := make([]legacyModule, len(meta.AsMetadataV4.Modules))
+		for i, m := range meta.AsMetadataV4.Modules {
+			mods[i] = legacyModule{name: string(m.Name), hasCalls: m.HasCalls, calls: m.Calls, hasEvents: m.HasEvents,
+				events: m.Events}
+			if m.HasStorage {
+				for _, s := range m.Storage {
+					mods[i].storage = append(mods[i].storage, storageV4(s))
+				}
+			}
+		}
+		return convertLegacy(mods), nil
+	case meta.IsMetadataV5:
+		mods := make([]legacyModule, len(meta.AsMetadataV5.Modules))
+		for i, m := range meta.AsMetadataV5.Modules {
+			mods[i] = legacyModule{name: string(m.Name), hasCalls: m.HasCalls, calls: m.Calls, hasEvents: m.HasEvents,
+				events: m.Events}
+			if m.HasStorage {
+				mods[i].storage = storageV5(m.Storage)
+			}
+		}
+		return convertLegacy(mods), nil
+	case meta.IsMetadataV6:
+		mods := make([]legacyModule, len(meta.AsMetadataV6.Modules))
+		for i, m := range meta.AsMetadataV6.Modules {
+			mods[i] = legacyModule{name: string(m.Name), hasCalls: m.HasCalls, calls: m.Calls, hasEvents: m.HasEvents,
+				events: m.Events, constants: m.Constants}
+			if m.HasStorage {
+				mods[i].storage = storageV5(m.Storage)
+			}
+		}
+		return convertLegacy(mods), nil
+	case meta.IsMetadataV7:
+		mods := make([]legacyModule, len(meta.AsMetadataV7.Modules))
+		for i, m := range meta.AsMetadataV7.Modules {
+			mods[i] = legacyModule{name: string(m.Name), hasCalls: m.HasCalls, calls: m.Calls, hasEvents: m.HasEvents,
+				events: m.Events, constants: m.Constants}
+			if m.HasStorage {
+				mods[i].storage = storageV5(m.Storage.Items)
+			}
+		}
+		return convertLegacy(mods), nil
+	case meta.IsMetadataV8:
+		return convertLegacy(modulesV8(meta.AsMetadataV8.Modules)), nil
+	case meta.IsMetadataV9:
+		return convertLegacy(modulesV8(meta.AsMetadataV9.Modules)), nil
+	case meta.IsMetadataV10:
+		mods := make([]legacyModule, len(meta.AsMetadataV10.Modules))
+		for i, m := range meta.AsMetadataV10.Modules {
+			mods[i] = legacyModule{name: string(m.Name), hasCalls: m.HasCalls, calls: m.Calls, hasEvents: m.HasEvents,
+				events: m.Events, constants: m.Constants, errors: m.Errors}
+			if m.HasStorage {
+				for _, s := range m.Storage.Items {
+					mods[i].storage = append(mods[i].storage, storageV10(s))
+				}
+			}
+		}
+		return convertLegacy(mods), nil
+	case meta.IsMetadataV11:
+		mods := make([]legacyModule, len(meta.AsMetadataV11.Modules))
+		for i, m := range meta.AsMetadataV11.Modules {
+			mods[i] = legacyModule{name: string(m.Name), hasCalls: m.HasCalls, calls: m.Calls, hasEvents: m.HasEvents,
+				events: m.Events, constants: m.Constants, errors: m.Errors}
+			if m.HasStorage {
+				for _, s := range m.Storage.Items {
+					mods[i].storage = append(mods[i].storage, storageV11(s))
+				}
+			}
+		}
+		return convertLegacy(mods), nil
+	case meta.IsMetadataV14:
+		return modulesV14(meta.AsMetadataV14)
+	default:
+		return nil, fmt.Errorf("comparing metadata requires metadata v4 or later, but got v%v", meta.Version)
+	}
+}
+
+// legacyModule holds the parts of a module of metadata before v14 that differ between the versions
+type legacyModule struct {
+	name      string
+	storage   []storageEntry
+	hasCalls  bool
+	calls     []types.FunctionMetadataV4
+	hasEvents bool
+	events    []types.EventMetadataV4
+	constants []types.ModuleConstantMetadataV6
+	errors    []types.ErrorMetadataV8
+}
+
+func modulesV8(mods []types.ModuleMetadataV8) []legacyModule {
+	legacy := make([]legacyModule, len(mods))
+	for i, m := range mods {
+		legacy[i] = legacyModule{name: string(m.Name), hasCalls: m.HasCalls, calls: m.Calls, hasEvents: m.HasEvents,
+			events: m.Events, constants: m.Constants, errors: m.Errors}
+		if m.HasStorage {
+			legacy[i].storage = storageV5(m.Storage.Items)
+		}
+	}
+	return legacy
+}
+
+// convertLegacy converts the modules of metadata before v14. Modules without calls or events are not counted for
+// the section index of calls and events, the same way as in Metadata.FindCallIndex.
+func convertLegacy(mods []legacyModule) []module {
+	modules := make([]module, len(mods))
+	var callSection, eventSection uint8
+	for i, m := range mods {
+		modules[i] = module{name: m.name, storage: m.storage}
+
+		if m.hasCalls {
+			for j, c := range m.calls {
+				f := function{name: string(c.Name), index: types.CallIndex{SectionIndex: callSection, MethodIndex: uint8(j)}}
+				for _, a := range c.Args {
+					f.args = append(f.args, arg{name: string(a.Name), typ: legacyType(a.Type)})
+				}
+				modules[i].calls = append(modules[i].calls, f)
+			}
+			callSection++
+		}
+
+		if m.hasEvents {
+			for j, e := range m.events {
+				f := function{name: string(e.Name), index: types.CallIndex{SectionIndex: eventSection, MethodIndex: uint8(j)}}
+				for _, a := range e.Args {
+					f.args = append(f.args, arg{typ: legacyType(a)})
+				}
+				modules[i].events = append(modules[i].events, f)
+			}
+			eventSection++
+		}
+
+		for _, c := range m.constants {
+			modules[i].constants = append(modules[i].constants, constant{name: string(c.Name), typ: legacyType(c.Type),
+				value: types.HexEncodeToString(c.Value)})
+		}
+
+		for j, e := range m.errors {
+			modules[i].errors = append(modules[i].errors, function{name: string(e.Name),
+				index: types.CallIndex{MethodIndex: uint8(j)}})
+		}
+	}
+	return modules
+}
+
+// legacyType normalizes a type string, so that e.g. `T::AccountId` and `<T as Trait>::AccountId` are equal
+func legacyType(typ types.Type) typeRef {
+	def, err := registry.ParseTypeString(string(typ))
+	if err != nil {
+		return typeRef{name: registry.Sanitize(string(typ))}
+	}
+	return typeRef{name: def.String()}
+}
+
+func newStorageEntry(name types.Text, modifier types.StorageFunctionModifierV0, fallback types.Bytes) storageEntry {
+	s := storageEntry{name: string(name), fallback: types.HexEncodeToString(fallback)}
+	switch {
+	case modifier.IsOptional:
+		s.modifier = "Optional"
+	case modifier.IsDefault:
+		s.modifier = "Default"
+	case modifier.IsRequired:
+		s.modifier = "Required"
+	}
+	return s
+}
+
+func storageV4(f types.StorageFunctionMetadataV4) storageEntry {
+	s := newStorageEntry(f.Name, f.Modifier, f.Fallback)
+	switch {
+	case f.Type.IsType:
+		s.value = legacyType(f.Type.AsType)
+	case f.Type.IsMap:
+		s.hashers = []string{hasherName(f.Type.AsMap.Hasher)}
+		s.keys = []typeRef{legacyType(f.Type.AsMap.Key)}
+		s.value = legacyType(f.Type.AsMap.Value)
+	case f.Type.IsDoubleMap:
+		m := f.Type.AsDoubleMap
+		s.hashers = []string{hasherName(m.Hasher), string(m.Key2Hasher)}
+		s.keys = []typeRef{legacyType(m.Key1), legacyType(m.Key2)}
+		s.value = legacyType(m.Value)
+	}
+	return s
+}
+
+func storageV5(items []types.StorageFunctionMetadataV5) []storageEntry {
+	entries := make([]storageEntry, len(items))
+	for i, f := range items {
+		entries[i] = newStorageEntry(f.Name, f.Modifier, f.Fallback)
+		switch {
+		case f.Type.IsType:
+			entries[i].value = legacyType(f.Type.AsType)
+		case f.Type.IsMap:
+			entries[i].hashers = []string{hasherName(f.Type.AsMap.Hasher)}
+			entries[i].keys = []typeRef{legacyType(f.Type.AsMap.Key)}
+			entries[i].value = legacyType(f.Type.AsMap.Value)
+		case f.Type.IsDoubleMap:
+			m := f.Type.AsDoubleMap
+			entries[i].hashers = []string{hasherName(m.Hasher), hasherName(m.Key2Hasher)}
+			entries[i].keys = []typeRef{legacyType(m.Key1), legacyType(m.Key2)}
+			entries[i].value = legacyType(m.Value)
+		}
+	}
+	return entries
+}
+
+func storageV10(f types.StorageFunctionMetadataV10) storageEntry {
+	s := newStorageEntry(f.Name, f.Modifier, f.Fallback)
+	switch {
+	case f.Type.IsType:
+		s.value = legacyType(f.Type.AsType)
+	case f.Type.IsMap:
+		s.hashers = []string{hasherNameV10(f.Type.AsMap.Hasher)}
+		s.keys = []typeRef{legacyType(f.Type.AsMap.Key)}
+		s.value = legacyType(f.Type.AsMap.Value)
+	case f.Type.IsDoubleMap:
+		m := f.Type.AsDoubleMap
+		s.hashers = []string{hasherNameV10(m.Hasher), hasherNameV10(m.Key2Hasher)}
+		s.keys = []typeRef{legacyType(m.Key1), legacyType(m.Key2)}
+		s.value = legacyType(m.Value)
+	}
+	return s
+}
+
+func storageV11(f types.StorageFunctionMetadataV11) storageEntry {
+	s := newStorageEntry(f.Name, f.Modifier, f.Fallback)
+	switch {
+	case f.Type.IsType:
+		s.value = legacyType(f.Type.AsType)
+	case f.Type.IsMap:
+		s.hashers = []string{hasherNameV11(f.Type.AsMap.Hasher)}
+		s.keys = []typeRef{legacyType(f.Type.AsMap.Key)}
+		s.value = legacyType(f.Type.AsMap.Value)
+	case f.Type.IsDoubleMap:
+		m := f.Type.AsDoubleMap
+		s.hashers = []string{hasherNameV11(m.Hasher), hasherNameV11(m.Key2Hasher)}
+		s.keys = []typeRef{legacyType(m.Key1), legacyType(m.Key2)}
+		s.value = legacyType(m.Value)
+	}
+	return s
+}
+
+func hasherName(h types.StorageHasher) string {
+	switch {
+	case h.IsBlake2_128:
+		return "Blake2_128"
+	case h.IsBlake2_256:
+		return "Blake2_256"
+	case h.IsTwox128:
+		return "Twox128"
+	case h.IsTwox256:
+		return "Twox256"
+	case h.IsTwox64Concat:
+		return "Twox64Concat"
+	default:
+		return ""
+	}
+}
+
+func hasherNameV10(h types.StorageHasherV10) string {
+	switch {
+	case h.IsBlake2_128:
+		return "Blake2_128"
+	case h.IsBlake2_256:
+		return "Blake2_256"
+	case h.IsBlake2_128Concat:
+		return "Blake2_128Concat"
+	case h.IsTwox128:
+		return "Twox128"
+	case h.IsTwox256:
+		return "Twox256"
+	case h.IsTwox64Concat:
+		return "Twox64Concat"
+	default:
+		return ""
+	}
+}
+
+func hasherNameV11(h types.StorageHasherV11) string {
+	if h.IsIdentity {
+		return "Identity"
+	}
+	return hasherNameV10(types.StorageHasherV10{
+		IsBlake2_128:       h.IsBlake2_128,
+		IsBlake2_256:       h.IsBlake2_256,
+		IsBlake2_128Concat: h.IsBlake2_128Concat,
+		IsTwox128:          h.IsTwox128,
+		IsTwox256:          h.IsTwox256,
+		IsTwox64Concat:     h.IsTwox64Concat,
+	})
+}
+
+func modulesV14(meta types.MetadataV14) ([]module, error) {
+	p := &portable{lookup: meta.Lookup, layouts: make(map[int64]string)}
+
+	modules := make([]module, len(meta.Pallets))
+	for i, pallet := range meta.Pallets {
+		m := module{name: string(pallet.Name)}
+		var err error
+
+		if pallet.HasStorage {
+			for _, item := range pallet.Storage.Items {
+				s, err := p.storage(item)
+				if err != nil {
+					return nil, fmt.Errorf("unable to convert storage %v.%v: %v", pallet.Name, item.Name, err)
+				}
+				m.storage = append(m.storage, s)
+			}
+		}
+
+		if pallet.HasCalls {
+			m.calls, err = p.variants(pallet.Index, pallet.Calls.Type)
+			if err != nil {
+				return nil, fmt.Errorf("unable to convert calls of %v: %v", pallet.Name, err)
+			}
+		}
+
+		if pallet.HasEvents {
+			m.events, err = p.variants(pallet.Index, pallet.Events.Type)
+			if err != nil {
+				return nil, fmt.Errorf("unable to convert events of %v: %v", pallet.Name, err)
+			}
+		}
+
+		for _, c := range pallet.Constants {
+			typ, err := p.typeRef(c.Type)
+			if err != nil {
+				return nil, fmt.Errorf("unable to convert constant %v.%v: %v", pallet.Name, c.Name, err)
+			}
+			m.constants = append(m.constants, constant{name: string(c.Name), typ: typ,
+				value: types.HexEncodeToString(c.Value)})
+		}
+
+		if pallet.HasErrors {
+			errs, err := p.variants(pallet.Index, pallet.Errors.Type)
+			if err != nil {
+				return nil, fmt.Errorf("unable to convert errors of %v: %v", pallet.Name, err)
+			}
+			// the fields of errors are not part of the error list of metadata before v14
+			for _, e := range errs {
+				m.errors = append(m.errors, function{name: e.name, index: types.CallIndex{MethodIndex: e.index.MethodIndex}})
+			}
+		}
+		modules[i] = m
+	}
+	return modules, nil
+}
+
+// portable converts types of the portable registry of metadata v14
+type portable struct {
+	lookup  types.PortableRegistry
+	layouts map[int64]string
+}
+
+func (p *portable) typeRef(id types.Si1LookupTypeID) (typeRef, error) {
+	name, err := p.lookup.TypeName(id)
+	if err != nil {
+		return typeRef{}, err
+	}
+
+	layout, ok := p.layouts[id.Int64()]
+	if !ok {
+		layout, err = p.layout(id, make(map[int64]bool))
+		if err != nil {
+			return typeRef{}, err
+		}
+		p.layouts[id.Int64()] = layout
+	}
+	return typeRef{name: name, layout: layout}, nil
+}
+
+// layout describes the encoding of a type, including the names of fields and variants but not the names of types.
+// References to a type that is being described, as in recursive types, are replaced with its name.
+func (p *portable) layout(id types.Si1LookupTypeID, visiting map[int64]bool) (string, error) {
+	if visiting[id.Int64()] {
+		return p.lookup.TypeName(id)
+	}
+	visiting[id.Int64()] = true
+	defer delete(visiting, id.Int64())
+
+	t, err := p.lookup.FindType(id)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case t.Def.IsComposite:
+		fields, err := p.fieldsLayout(t.Def.AsComposite.Fields, visiting)
+		if err != nil {
+			return "", err
+		}
+		return "{" + fields + "}", nil
+	case t.Def.IsVariant:
+		variants := make([]string, len(t.Def.AsVariant.Variants))
+		for i, v := range t.Def.AsVariant.Variants {
+			fields, err := p.fieldsLayout(v.Fields, visiting)
+			if err != nil {
+				return "", err
+			}
+			variants[i] = fmt.Sprintf("%v %v", v.Index, v.Name)
+			if len(v.Fields) > 0 {
+				variants[i] += "{" + fields + "}"
+			}
+		}
+		return "enum{" + strings.Join(variants, ", ") + "}", nil
+	case t.Def.IsSequence:
+		elem, err := p.layout(t.Def.AsSequence.Type, visiting)
+		if err != nil {
+			return "", err
+		}
+		return "Vec<" + elem + ">", nil
+	case t.Def.IsArray:
+		elem, err := p.layout(t.Def.AsArray.Type, visiting)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("[%v; %v]", elem, t.Def.AsArray.Len), nil
+	case t.Def.IsTuple:
+		elems := make([]string, len(t.Def.AsTuple))
+		for i, e := range t.Def.AsTuple {
+			elems[i], err = p.layout(e, visiting)
+			if err != nil {
+				return "", err
+			}
+		}
+		return "(" + strings.Join(elems, ", ") + ")", nil
+	case t.Def.IsCompact:
+		elem, err := p.layout(t.Def.AsCompact.Type, visiting)
+		if err != nil {
+			return "", err
+		}
+		return "Compact<" + elem + ">", nil
+	default:
+		// primitives and bit sequences
+		return p.lookup.TypeName(id)
+	}
+}
+
+func (p *portable) fieldsLayout(fields []types.Si1Field, visiting map[int64]bool) (string, error) {
+	layouts := make([]string, len(fields))
+	for i, f := range fields {
+		layout, err := p.layout(f.Type, visiting)
+		if err != nil {
+			return "", err
+		}
+		layouts[i] = layout
+		if f.HasName {
+			layouts[i] = string(f.Name) + ": " + layout
+		}
+	}
+	return strings.Join(layouts, ", "), nil
+}
+
+// variants converts the variants of the call, event or error enum of a pallet
+func (p *portable) variants(pallet uint8, id types.Si1LookupTypeID) ([]function, error) {
+	t, err := p.lookup.FindType(id)
+	if err != nil {
+		return nil, err
+	}
+	if !t.Def.IsVariant {
+		return nil, fmt.Errorf("type %v is not a variant", id.Int64())
+	}
+
+	functions := make([]function, len(t.Def.AsVariant.Variants))
+	for i, v := range t.Def.AsVariant.Variants {
+		functions[i] = function{name: string(v.Name), index: types.CallIndex{SectionIndex: pallet, MethodIndex: v.Index}}
+		for _, f := range v.Fields {
+			typ, err := p.typeRef(f.Type)
+			if err != nil {
+				return nil, err
+			}
+			functions[i].args = append(functions[i].args, arg{name: string(f.Name), typ: typ})
+		}
+	}
+	return functions, nil
+}
+
+func (p *portable) storage(item types.StorageEntryMetadataV14) (storageEntry, error) {
+	s := newStorageEntry(item.Name, item.Modifier, item.Fallback)
+	var err error
+
+	if item.Type.IsPlainType {
+		s.value, err = p.typeRef(item.Type.AsPlainType)
+		return s, err
+	}
+
+	m := item.Type.AsMap
+	for _, h := range m.Hashers {
+		s.hashers = append(s.hashers, hasherNameV11(h))
+	}
+	s.value, err = p.typeRef(m.Value)
+	if err != nil {
+		return s, err
+	}
+
+	// with more than one hasher, the key is a tuple of the keys for each hasher
+	keys := []types.Si1LookupTypeID{m.Key}
+	if len(m.Hashers) > 1 {
+		t, err := p.lookup.FindType(m.Key)
+		if err != nil {
+			return s, err
+		}
+		if !t.Def.IsTuple || len(t.Def.AsTuple) != len(m.Hashers) {
+			return s, fmt.Errorf("expected key to be a tuple of %v types", len(m.Hashers))
+		}
+		keys = t.Def.AsTuple
+	}
+	for _, k := range keys {
+		key, err := p.typeRef(k)
+		if err != nil {
+			return s, err
+		}
+		s.keys = append(s.keys, key)
+	}
+	return s, nil
+}
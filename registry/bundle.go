@@ -0,0 +1,118 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// TypesBundle holds the chain specific type definitions of several chains in the format of the polkadot-js types
+// bundle, e.g.
+//
+//	{
+//	  "spec": {
+//	    "centrifuge-chain": {
+//	      "alias": {"anchor": {"Hash": "H256"}},
+//	      "types": [
+//	        {"minmax": [0, 227], "types": {"Balance": "u64"}},
+//	        {"minmax": [228, null], "types": {"Balance": "u128"}}
+//	      ]
+//	    }
+//	  }
+//	}
+//
+// Spec definitions are looked up by the spec name of the runtime version, chain definitions by the chain name as
+// returned by system_chain.
+type TypesBundle struct {
+	Spec  map[string]BundleDefinition `json:"spec"`
+	Chain map[string]BundleDefinition `json:"chain"`
+}
+
+// BundleDefinition holds the type definitions of a single chain, by ranges of spec versions
+type BundleDefinition struct {
+	// Alias maps module names to type names that are replaced within that module only
+	Alias map[string]map[string]string `json:"alias"`
+	Types []VersionedTypes             `json:"types"`
+}
+
+// VersionedTypes holds type definitions that apply to a range of spec versions
+type VersionedTypes struct {
+	// MinMax holds the lowest and the highest spec version of the range, both inclusive. A nil bound is unbounded.
+	MinMax [2]*uint32      `json:"minmax"`
+	Types  json.RawMessage `json:"types"`
+}
+
+// ParseTypesBundle parses a JSON types bundle
+func ParseTypesBundle(bz []byte) (*TypesBundle, error) {
+	var b TypesBundle
+	err := json.Unmarshal(bz, &b)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse types bundle: %v", err)
+	}
+	return &b, nil
+}
+
+// Includes returns true if the given spec version is within the range
+func (v VersionedTypes) Includes(specVersion uint32) bool {
+	if v.MinMax[0] != nil && specVersion < *v.MinMax[0] {
+		return false
+	}
+	if v.MinMax[1] != nil && specVersion > *v.MinMax[1] {
+		return false
+	}
+	return true
+}
+
+// Registry returns a copy of the base registry extended with the type definitions of the bundle that apply to the
+// given spec name and version. Definitions of later ranges override definitions of earlier ranges. The base registry is
+// returned unchanged if the bundle holds no definitions for the spec name.
+func (b *TypesBundle) Registry(base *Registry, specName string, specVersion uint32) (*Registry, error) {
+	def, ok := b.Spec[specName]
+	if !ok {
+		return base, nil
+	}
+	return def.apply(base, specVersion)
+}
+
+// ChainRegistry is like Registry, but looks up the definitions by chain name
+func (b *TypesBundle) ChainRegistry(base *Registry, chain string, specVersion uint32) (*Registry, error) {
+	def, ok := b.Chain[chain]
+	if !ok {
+		return base, nil
+	}
+	return def.apply(base, specVersion)
+}
+
+func (d BundleDefinition) apply(base *Registry, specVersion uint32) (*Registry, error) {
+	r := base.Copy()
+	for _, v := range d.Types {
+		if !v.Includes(specVersion) {
+			continue
+		}
+		err := r.RegisterJSON(v.Types)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for module, aliases := range d.Alias {
+		for from, to := range aliases {
+			r.RegisterModuleAlias(module, from, to)
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,100 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/zenghq3/go-substrate-rpc-client/registry"
+)
+
+var testBundle = []byte(`{
+	"spec": {
+		"centrifuge-chain": {
+			"alias": {"anchor": {"Hash": "AnchorHash"}},
+			"types": [
+				{"minmax": [0, 227], "types": {"Balance": "u64", "AnchorHash": "[u8; 16]"}},
+				{"minmax": [228, null], "types": {"AnchorHash": "[u8; 20]"}},
+				{"minmax": [null, null], "types": {"Fee": "Balance"}}
+			]
+		}
+	},
+	"chain": {
+		"Development": {"types": [{"minmax": [0, null], "types": {"Balance": "u32"}}]}
+	}
+}`)
+
+func TestTypesBundle_Registry(t *testing.T) {
+	b, err := ParseTypesBundle(testBundle)
+	assert.NoError(t, err)
+
+	base := NewRegistry()
+
+	r, err := b.Registry(base, "centrifuge-chain", 227)
+	assert.NoError(t, err)
+	def, err := r.ResolveTypeString("Fee")
+	assert.NoError(t, err)
+	assert.Equal(t, "u64", def.String())
+	def, err = r.ResolveTypeString("AnchorHash")
+	assert.NoError(t, err)
+	assert.Equal(t, "[u8; 16]", def.String())
+
+	r, err = b.Registry(base, "centrifuge-chain", 228)
+	assert.NoError(t, err)
+	def, err = r.ResolveTypeString("Fee")
+	assert.NoError(t, err)
+	assert.Equal(t, "u128", def.String())
+	def, err = r.ResolveTypeString("AnchorHash")
+	assert.NoError(t, err)
+	assert.Equal(t, "[u8; 20]", def.String())
+
+	// the base registry is not modified
+	_, ok := base.Get("Fee")
+	assert.False(t, ok)
+
+	r, err = b.Registry(base, "polkadot", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, base, r)
+
+	r, err = b.ChainRegistry(base, "Development", 1)
+	assert.NoError(t, err)
+	def, err = r.ResolveTypeString("Balance")
+	assert.NoError(t, err)
+	assert.Equal(t, "u32", def.String())
+}
+
+func TestTypesBundle_ModuleAlias(t *testing.T) {
+	b, err := ParseTypesBundle(testBundle)
+	assert.NoError(t, err)
+	r, err := b.Registry(NewRegistry(), "centrifuge-chain", 300)
+	assert.NoError(t, err)
+
+	def, err := r.ParseModuleTypeString("Anchor", "Vec<T::Hash>")
+	assert.NoError(t, err)
+	assert.Equal(t, "Vec<AnchorHash>", def.String())
+
+	def, err = r.ParseModuleTypeString("System", "Vec<T::Hash>")
+	assert.NoError(t, err)
+	assert.Equal(t, "Vec<Hash>", def.String())
+}
+
+func TestParseTypesBundle_Error(t *testing.T) {
+	_, err := ParseTypesBundle([]byte(`{"spec": []}`))
+	assert.Error(t, err)
+}
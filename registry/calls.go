@@ -0,0 +1,132 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/zenghq3/go-substrate-rpc-client/scale"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// Call is a generically decoded call
+type Call struct {
+	Index  types.CallIndex
+	Module string
+	Name   string
+	Args   []CallArg
+}
+
+// CallArg is a decoded call argument
+type CallArg struct {
+	Name  string
+	Type  string
+	Value interface{}
+}
+
+// DecodeCall decodes the arguments of the call using the argument types from the metadata
+func (r *Registry) DecodeCall(meta *types.Metadata, call types.Call) (Call, error) {
+	reg, args, c, err := r.callArgs(meta, call.CallIndex)
+	if err != nil {
+		return Call{}, err
+	}
+
+	decoder := scale.NewDecoder(bytes.NewReader(call.Args))
+	for _, arg := range args {
+		a := CallArg{Name: arg.name, Type: arg.typeName}
+		a.Value, err = reg.DecodeDef(*decoder, arg.def)
+		if err != nil {
+			return Call{}, fmt.Errorf("unable to decode argument %v of type %v for %v.%v: %v", a.Name, a.Type,
+				c.Module, c.Name, err)
+		}
+		c.Args = append(c.Args, a)
+	}
+	return c, nil
+}
+
+// NewCall creates a call from generic argument values, encoded using the argument types from the metadata. The call
+// is given as `Module.call`, e.g. `Balances.transfer`.
+func (r *Registry) NewCall(meta *types.Metadata, call string, args ...interface{}) (types.Call, error) {
+	index, err := meta.FindCallIndex(call)
+	if err != nil {
+		return types.Call{}, err
+	}
+
+	reg, defs, _, err := r.callArgs(meta, index)
+	if err != nil {
+		return types.Call{}, err
+	}
+	if len(args) != len(defs) {
+		return types.Call{}, fmt.Errorf("expected %v arguments for %v, but got %v", len(defs), call, len(args))
+	}
+
+	var buffer = bytes.Buffer{}
+	encoder := scale.NewEncoder(&buffer)
+	for i, arg := range args {
+		err = reg.EncodeDef(*encoder, defs[i].def, arg)
+		if err != nil {
+			return types.Call{}, fmt.Errorf("unable to encode argument %v of type %v for %v: %v", defs[i].name,
+				defs[i].typeName, call, err)
+		}
+	}
+	return types.Call{CallIndex: index, Args: buffer.Bytes()}, nil
+}
+
+// callArgs returns the registry to use for the metadata, the argument types of the call and the call without
+// arguments
+func (r *Registry) callArgs(meta *types.Metadata, index types.CallIndex) (*Registry, []argDef, Call, error) {
+	c := Call{Index: index}
+
+	if meta.IsMetadataV14 {
+		reg, err := r.ForMetadata(meta)
+		if err != nil {
+			return nil, nil, Call{}, err
+		}
+		mod, variant, err := meta.AsMetadataV14.FindCallVariant(index)
+		if err != nil {
+			return nil, nil, Call{}, fmt.Errorf("unable to find call with index %v in metadata: %v", index, err)
+		}
+		c.Module, c.Name = string(mod), string(variant.Name)
+
+		args := make([]argDef, len(variant.Fields))
+		for i, f := range variant.Fields {
+			args[i] = argDef{name: string(f.Name), def: NewPortable(f.Type)}
+			args[i].typeName, err = meta.AsMetadataV14.Lookup.TypeName(f.Type)
+			if err != nil {
+				return nil, nil, Call{}, err
+			}
+		}
+		return reg, args, c, nil
+	}
+
+	mod, fm, err := meta.FindCallMetadata(index)
+	if err != nil {
+		return nil, nil, Call{}, fmt.Errorf("unable to find call with index %v in metadata: %v", index, err)
+	}
+	c.Module, c.Name = string(mod), string(fm.Name)
+
+	args := make([]argDef, len(fm.Args))
+	for i, a := range fm.Args {
+		args[i] = argDef{name: string(a.Name), typeName: string(a.Type)}
+		args[i].def, err = r.ParseModuleTypeString(c.Module, string(a.Type))
+		if err != nil {
+			return nil, nil, Call{}, err
+		}
+	}
+	return r, args, c, nil
+}
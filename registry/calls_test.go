@@ -0,0 +1,87 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/zenghq3/go-substrate-rpc-client/registry"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+func TestRegistry_NewCall_DecodeCall(t *testing.T) {
+	r := NewRegistry()
+	assert.NoError(t, r.RegisterJSON([]byte(`{"Source": "AccountId"}`)))
+
+	c, err := r.NewCall(types.ExamplaryMetadataV8, "Balances.transfer", bob, big.NewInt(6969))
+	assert.NoError(t, err)
+	assert.Equal(t, types.CallIndex{SectionIndex: 6, MethodIndex: 0}, c.CallIndex)
+	assert.Equal(t, "0x8eaf04151687736326c9fea17e25fc5287613693c912909cb226aa4794f26a48e56c",
+		types.HexEncodeToString(c.Args))
+
+	decoded, err := r.DecodeCall(types.ExamplaryMetadataV8, c)
+	assert.NoError(t, err)
+	assert.Equal(t, Call{
+		Index:  c.CallIndex,
+		Module: "Balances",
+		Name:   "transfer",
+		Args: []CallArg{
+			{Name: "dest", Type: "<T::Lookup as StaticLookup>::Source", Value: bob},
+			{Name: "value", Type: "Compact<T::Balance>", Value: big.NewInt(6969)},
+		},
+	}, decoded)
+}
+
+func TestRegistry_NewCall_Errors(t *testing.T) {
+	r := NewRegistry()
+
+	_, err := r.NewCall(types.ExamplaryMetadataV8, "Balances.transfer", bob)
+	assert.EqualError(t, err, "expected 2 arguments for Balances.transfer, but got 1")
+
+	_, err = r.NewCall(types.ExamplaryMetadataV8, "Balances.transfer", bob, 1)
+	assert.EqualError(t, err, "unable to encode argument dest of type <T::Lookup as StaticLookup>::Source for "+
+		"Balances.transfer: type Source is not registered")
+
+	_, err = r.DecodeCall(types.ExamplaryMetadataV8, types.Call{CallIndex: types.CallIndex{SectionIndex: 6,
+		MethodIndex: 9}})
+	assert.EqualError(t, err, "unable to find call with index {6 9} in metadata: call index 9 for module Balances "+
+		"out of range")
+}
+
+func TestRegistry_DecodeStorage(t *testing.T) {
+	r := NewRegistry()
+
+	v, err := r.DecodeStorage(types.ExamplaryMetadataV8, "Timestamp", "Now",
+		types.MustHexDecodeString("0x3930000000000000"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(12345), v)
+
+	v, err = r.DecodeStorage(types.ExamplaryMetadataV8, "Timestamp", "Now", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+
+	_, keys, value, err := r.StorageTypes(types.ExamplaryMetadataV8, "Balances", "FreeBalance")
+	assert.NoError(t, err)
+	assert.Equal(t, []*TypeDef{NewNamed("AccountId")}, keys)
+	assert.Equal(t, NewNamed("Balance"), value)
+
+	_, err = r.DecodeStorage(types.ExamplaryMetadataV8, "Timestamp", "Unknown", nil)
+	assert.EqualError(t, err, "storage Unknown not found within module Timestamp")
+}
@@ -0,0 +1,112 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/zenghq3/go-substrate-rpc-client/scale"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// DecodeConstant decodes the value of the given constant into a generic value, using the type of the constant from
+// the metadata. Use Metadata.DecodeConstant to decode a constant into a Go type instead.
+func (r *Registry) DecodeConstant(meta *types.Metadata, module, constant string) (interface{}, error) {
+	reg, constants, err := r.constantDefs(meta, module)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range constants {
+		if strings.EqualFold(c.name, constant) {
+			return reg.decodeConstant(module, c)
+		}
+	}
+	return nil, fmt.Errorf("constant %v not found within module %v", constant, module)
+}
+
+// ModuleConstants decodes all constants of the given module into generic values, keyed by the names of the constants,
+// e.g. to display them
+func (r *Registry) ModuleConstants(meta *types.Metadata, module string) (map[string]interface{}, error) {
+	reg, constants, err := r.constantDefs(meta, module)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{}, len(constants))
+	for _, c := range constants {
+		values[c.name], err = reg.decodeConstant(module, c)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+type constantDef struct {
+	name  string
+	def   *TypeDef
+	value []byte
+}
+
+// constantDefs returns the constants of the given module together with the registry that knows about their types
+func (r *Registry) constantDefs(meta *types.Metadata, module string) (*Registry, []constantDef, error) {
+	if meta.IsMetadataV14 {
+		for _, p := range meta.AsMetadataV14.Pallets {
+			if !strings.EqualFold(string(p.Name), module) {
+				continue
+			}
+			reg, err := r.ForMetadata(meta)
+			if err != nil {
+				return nil, nil, err
+			}
+			constants := make([]constantDef, len(p.Constants))
+			for i, c := range p.Constants {
+				constants[i] = constantDef{name: string(c.Name), def: NewPortable(c.Type), value: c.Value}
+			}
+			return reg, constants, nil
+		}
+		return nil, nil, fmt.Errorf("module %v not found in metadata", module)
+	}
+
+	metaConstants, err := meta.FindConstants(module)
+	if err != nil {
+		return nil, nil, err
+	}
+	constants := make([]constantDef, len(metaConstants))
+	for i, c := range metaConstants {
+		def, err := r.ParseModuleTypeString(module, string(c.Type))
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to parse type of constant %v.%v: %v", module, c.Name, err)
+		}
+		constants[i] = constantDef{name: string(c.Name), def: def, value: c.Value}
+	}
+	return r, constants, nil
+}
+
+func (r *Registry) decodeConstant(module string, c constantDef) (interface{}, error) {
+	reader := bytes.NewReader(c.value)
+	v, err := r.DecodeDef(*scale.NewDecoder(reader), c.def)
+	if err == nil && reader.Len() > 0 {
+		err = fmt.Errorf("%v bytes left after decoding", reader.Len())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode constant %v.%v of type %v: %v", module, c.name, c.def, err)
+	}
+	return v, nil
+}
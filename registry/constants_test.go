@@ -0,0 +1,78 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/zenghq3/go-substrate-rpc-client/registry"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+func TestRegistry_DecodeConstant(t *testing.T) {
+	r := NewRegistry()
+
+	v, err := r.DecodeConstant(types.ExamplaryMetadataV8, "Balances", "ExistentialDeposit")
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(100000000000000), v)
+
+	_, err = r.DecodeConstant(types.ExamplaryMetadataV8, "Balances", "Unknown")
+	assert.EqualError(t, err, "constant Unknown not found within module Balances")
+
+	_, err = r.DecodeConstant(types.ExamplaryMetadataV8, "Unknown", "ExistentialDeposit")
+	assert.EqualError(t, err, "module Unknown not found in metadata")
+}
+
+func TestRegistry_ModuleConstants(t *testing.T) {
+	values, err := NewRegistry().ModuleConstants(types.ExamplaryMetadataV8, "Timestamp")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"MinimumPeriod": uint64(1500)}, values)
+}
+
+func TestRegistry_ModuleConstants_MetadataV14(t *testing.T) {
+	id := types.NewSi1LookupTypeID
+
+	meta := types.NewMetadataV14()
+	meta.AsMetadataV14.Lookup = types.PortableRegistry{
+		{ID: id(0), Type: types.Si1Type{Def: types.Si1TypeDef{IsPrimitive: true, AsPrimitive: types.IsU32}}},
+		{ID: id(1), Type: types.Si1Type{Def: types.Si1TypeDef{IsPrimitive: true, AsPrimitive: types.IsU128}}},
+	}
+	meta.AsMetadataV14.Pallets = []types.PalletMetadataV14{{
+		Name: "Balances",
+		Constants: []types.ConstantMetadataV14{
+			{Name: "ExistentialDeposit", Type: id(1), Value: types.MustHexDecodeString(
+				"0xf4010000000000000000000000000000")},
+			{Name: "MaxLocks", Type: id(0), Value: types.MustHexDecodeString("0x32000000")},
+		},
+		Index: 5,
+	}}
+
+	values, err := NewRegistry().ModuleConstants(meta, "Balances")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"ExistentialDeposit": big.NewInt(500), "MaxLocks": uint32(50)}, values)
+
+	v, err := NewRegistry().DecodeConstant(meta, "Balances", "MaxLocks")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(50), v)
+
+	meta.AsMetadataV14.Pallets[0].Constants[1].Value = types.MustHexDecodeString("0x3200000000")
+	_, err = NewRegistry().DecodeConstant(meta, "Balances", "MaxLocks")
+	assert.EqualError(t, err, "unable to decode constant Balances.MaxLocks of type Lookup0: 1 bytes left after decoding")
+}
@@ -0,0 +1,313 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/zenghq3/go-substrate-rpc-client/scale"
+)
+
+// DecodeFromBytes decodes bz into a generic value of the type described by the type string
+func (r *Registry) DecodeFromBytes(typ string, bz []byte) (interface{}, error) {
+	return r.Decode(*scale.NewDecoder(bytes.NewReader(bz)), typ)
+}
+
+// Decode decodes a generic value of the type described by the type string from the decoder
+func (r *Registry) Decode(decoder scale.Decoder, typ string) (interface{}, error) {
+	def, err := ParseTypeString(typ)
+	if err != nil {
+		return nil, err
+	}
+	return r.DecodeDef(decoder, def)
+}
+
+// DecodeDef decodes a generic value of the given type definition from the decoder
+func (r *Registry) DecodeDef(decoder scale.Decoder, def *TypeDef) (interface{}, error) {
+	def, err := r.Resolve(def)
+	if err != nil {
+		return nil, err
+	}
+
+	switch def.Kind {
+	case KindPrimitive:
+		return decodePrimitive(decoder, def.Name)
+	case KindVec:
+		n, err := decoder.DecodeUintCompact()
+		if err != nil {
+			return nil, err
+		}
+		return r.decodeList(decoder, def.Params[0], n)
+	case KindArray:
+		return r.decodeList(decoder, def.Params[0], uint64(def.Length))
+	case KindOption:
+		return r.decodeOption(decoder, def.Params[0])
+	case KindCompact:
+		return decodeCompact(decoder)
+	case KindTuple:
+		if len(def.Params) == 0 {
+			return nil, nil
+		}
+		values := make([]interface{}, len(def.Params))
+		for i, p := range def.Params {
+			values[i], err = r.DecodeDef(decoder, p)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	case KindStruct:
+		values := make(map[string]interface{}, len(def.Fields))
+		for _, f := range def.Fields {
+			values[f.Name], err = r.DecodeDef(decoder, f.Type)
+			if err != nil {
+				return nil, fmt.Errorf("unable to decode field %v: %v", f.Name, err)
+			}
+		}
+		return values, nil
+	case KindEnum:
+		b, err := decoder.ReadOneByte()
+		if err != nil {
+			return nil, err
+		}
+		v, err := def.FindVariantByIndex(b)
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if v.Type != nil {
+			value, err = r.DecodeDef(decoder, v.Type)
+			if err != nil {
+				return nil, fmt.Errorf("unable to decode variant %v: %v", v.Name, err)
+			}
+		}
+		return map[string]interface{}{v.Name: value}, nil
+	case KindSet:
+		return decodeSet(decoder, def)
+	default:
+		return nil, fmt.Errorf("unable to decode type %v of kind %v", def, def.Kind)
+	}
+}
+
+// isByte returns true if the definition resolves to u8, in which case lists are decoded as byte slices
+func (r *Registry) isByte(def *TypeDef) bool {
+	def, err := r.Resolve(def)
+	return err == nil && def.Kind == KindPrimitive && def.Name == "u8"
+}
+
+func (r *Registry) decodeList(decoder scale.Decoder, elem *TypeDef, n uint64) (interface{}, error) {
+	if r.isByte(elem) {
+		if rem, ok := decoder.Remaining(); ok && n > uint64(rem) {
+			return nil, fmt.Errorf("unable to decode %v bytes, only %v bytes of input remaining", n, rem)
+		}
+		bz := make([]byte, 0, preallocation(decoder, n))
+		for uint64(len(bz)) < n {
+			chunk := make([]byte, minUint64(n-uint64(len(bz)), maxPreallocation))
+			err := decoder.Read(chunk)
+			if err != nil {
+				return nil, err
+			}
+			bz = append(bz, chunk...)
+		}
+		return bz, nil
+	}
+
+	values := make([]interface{}, 0, preallocation(decoder, n))
+	for i := uint64(0); i < n; i++ {
+		v, err := r.DecodeDef(decoder, elem)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode element %v of %v: %v", i, elem, err)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// maxPreallocation bounds the elements allocated in advance if the remaining input is unknown
+const maxPreallocation = 1 << 16
+
+// preallocation returns the capacity to allocate for n elements. Lengths are read from the input, so they are only
+// trusted as far as the remaining input can hold that many elements, or up to maxPreallocation if it is unknown.
+func preallocation(decoder scale.Decoder, n uint64) int {
+	if rem, ok := decoder.Remaining(); ok {
+		return int(minUint64(n, uint64(rem)))
+	}
+	return int(minUint64(n, maxPreallocation))
+}
+
+func minUint64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func (r *Registry) decodeOption(decoder scale.Decoder, elem *TypeDef) (interface{}, error) {
+	b, err := decoder.ReadOneByte()
+	if err != nil {
+		return nil, err
+	}
+
+	// Option<bool> is encoded as a single byte
+	resolved, err := r.Resolve(elem)
+	if err != nil {
+		return nil, err
+	}
+	if resolved.Kind == KindPrimitive && resolved.Name == "bool" {
+		switch b {
+		case 0:
+			return nil, nil
+		case 1:
+			return true, nil
+		case 2:
+			return false, nil
+		default:
+			return nil, fmt.Errorf("received unexpected Option<bool> byte %v", b)
+		}
+	}
+
+	switch b {
+	case 0:
+		return nil, nil
+	case 1:
+		return r.DecodeDef(decoder, elem)
+	default:
+		return nil, fmt.Errorf("received unexpected Option byte %v", b)
+	}
+}
+
+func decodePrimitive(decoder scale.Decoder, name string) (interface{}, error) {
+	var err error
+	switch name {
+	case "bool":
+		var v bool
+		err = decoder.Decode(&v)
+		return v, err
+	case "str":
+		var v string
+		err = decoder.Decode(&v)
+		return v, err
+	case "u8":
+		var v uint8
+		err = decoder.Decode(&v)
+		return v, err
+	case "u16":
+		var v uint16
+		err = decoder.Decode(&v)
+		return v, err
+	case "u32":
+		var v uint32
+		err = decoder.Decode(&v)
+		return v, err
+	case "u64":
+		var v uint64
+		err = decoder.Decode(&v)
+		return v, err
+	case "i8":
+		var v int8
+		err = decoder.Decode(&v)
+		return v, err
+	case "i16":
+		var v int16
+		err = decoder.Decode(&v)
+		return v, err
+	case "i32":
+		var v int32
+		err = decoder.Decode(&v)
+		return v, err
+	case "i64":
+		var v int64
+		err = decoder.Decode(&v)
+		return v, err
+	case "u128", "u256", "i128", "i256":
+		bz := make([]byte, primitives[name])
+		err = decoder.Read(bz)
+		if err != nil {
+			return nil, err
+		}
+		return bigIntFromLE(bz, name[0] == 'i'), nil
+	default:
+		return nil, fmt.Errorf("unknown primitive %v", name)
+	}
+}
+
+// bigIntFromLE converts little endian bytes, in two's complement if signed, to a big.Int
+func bigIntFromLE(bz []byte, signed bool) *big.Int {
+	be := make([]byte, len(bz))
+	for i, b := range bz {
+		be[len(bz)-1-i] = b
+	}
+	v := new(big.Int).SetBytes(be)
+	if signed && len(be) > 0 && be[0]&0x80 != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(len(bz)*8)))
+	}
+	return v
+}
+
+// decodeCompact decodes a compact encoded number of arbitrary size
+func decodeCompact(decoder scale.Decoder) (*big.Int, error) {
+	b, err := decoder.ReadOneByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch b & 0x03 {
+	case 0:
+		return big.NewInt(int64(b >> 2)), nil
+	case 1:
+		b2, err := decoder.ReadOneByte()
+		if err != nil {
+			return nil, err
+		}
+		return big.NewInt(int64(uint16(b)|uint16(b2)<<8) >> 2), nil
+	case 2:
+		bz := make([]byte, 3)
+		err = decoder.Read(bz)
+		if err != nil {
+			return nil, err
+		}
+		v := uint32(b) | uint32(bz[0])<<8 | uint32(bz[1])<<16 | uint32(bz[2])<<24
+		return big.NewInt(int64(v >> 2)), nil
+	default:
+		bz := make([]byte, int(b>>2)+4)
+		err = decoder.Read(bz)
+		if err != nil {
+			return nil, err
+		}
+		return bigIntFromLE(bz, false), nil
+	}
+}
+
+// decodeSet decodes a bit field into the names of the flags that are set
+func decodeSet(decoder scale.Decoder, def *TypeDef) ([]string, error) {
+	bz := make([]byte, def.Length/8)
+	err := decoder.Read(bz)
+	if err != nil {
+		return nil, err
+	}
+	v := bigIntFromLE(bz, false).Uint64()
+
+	names := []string{}
+	for _, f := range def.Flags {
+		if f.Value != 0 && v&f.Value == f.Value {
+			names = append(names, f.Name)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,388 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/zenghq3/go-substrate-rpc-client/scale"
+)
+
+// EncodeToBytes encodes the generic value as the type described by the type string
+func (r *Registry) EncodeToBytes(typ string, value interface{}) ([]byte, error) {
+	var buffer = bytes.Buffer{}
+	err := r.Encode(*scale.NewEncoder(&buffer), typ, value)
+	if err != nil {
+		return buffer.Bytes(), err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Encode encodes the generic value as the type described by the type string
+func (r *Registry) Encode(encoder scale.Encoder, typ string, value interface{}) error {
+	def, err := ParseTypeString(typ)
+	if err != nil {
+		return err
+	}
+	return r.EncodeDef(encoder, def, value)
+}
+
+// EncodeDef encodes the generic value as the given type definition
+func (r *Registry) EncodeDef(encoder scale.Encoder, def *TypeDef, value interface{}) error {
+	def, err := r.Resolve(def)
+	if err != nil {
+		return err
+	}
+
+	switch def.Kind {
+	case KindPrimitive:
+		return encodePrimitive(encoder, def.Name, value)
+	case KindVec:
+		return r.encodeList(encoder, def.Params[0], value, -1)
+	case KindArray:
+		return r.encodeList(encoder, def.Params[0], value, def.Length)
+	case KindOption:
+		return r.encodeOption(encoder, def.Params[0], value)
+	case KindCompact:
+		v, err := toBigInt(value)
+		if err != nil {
+			return err
+		}
+		return encodeCompact(encoder, v)
+	case KindTuple:
+		if len(def.Params) == 0 {
+			return nil
+		}
+		values, err := toSlice(value)
+		if err != nil {
+			return err
+		}
+		if len(values) != len(def.Params) {
+			return fmt.Errorf("expected %v values for tuple %v, but got %v", len(def.Params), def, len(values))
+		}
+		for i, p := range def.Params {
+			err = r.EncodeDef(encoder, p, values[i])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case KindStruct:
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected map[string]interface{} for struct %v, but got %T", def, value)
+		}
+		for _, f := range def.Fields {
+			v, ok := values[f.Name]
+			if !ok {
+				return fmt.Errorf("missing field %v for struct %v", f.Name, def)
+			}
+			err = r.EncodeDef(encoder, f.Type, v)
+			if err != nil {
+				return fmt.Errorf("unable to encode field %v: %v", f.Name, err)
+			}
+		}
+		return nil
+	case KindEnum:
+		return r.encodeEnum(encoder, def, value)
+	case KindSet:
+		return encodeSet(encoder, def, value)
+	default:
+		return fmt.Errorf("unable to encode type %v of kind %v", def, def.Kind)
+	}
+}
+
+func (r *Registry) encodeList(encoder scale.Encoder, elem *TypeDef, value interface{}, length int) error {
+	if r.isByte(elem) {
+		bz, err := toBytes(value)
+		if err != nil {
+			return err
+		}
+		if length >= 0 && len(bz) != length {
+			return fmt.Errorf("expected %v bytes, but got %v", length, len(bz))
+		}
+		if length < 0 {
+			err = encoder.EncodeUintCompact(uint64(len(bz)))
+			if err != nil {
+				return err
+			}
+		}
+		return encoder.Write(bz)
+	}
+
+	values, err := toSlice(value)
+	if err != nil {
+		return err
+	}
+	if length >= 0 && len(values) != length {
+		return fmt.Errorf("expected %v elements, but got %v", length, len(values))
+	}
+	if length < 0 {
+		err = encoder.EncodeUintCompact(uint64(len(values)))
+		if err != nil {
+			return err
+		}
+	}
+	for i, v := range values {
+		err = r.EncodeDef(encoder, elem, v)
+		if err != nil {
+			return fmt.Errorf("unable to encode element %v of %v: %v", i, elem, err)
+		}
+	}
+	return nil
+}
+
+func (r *Registry) encodeOption(encoder scale.Encoder, elem *TypeDef, value interface{}) error {
+	if value == nil {
+		return encoder.PushByte(0)
+	}
+
+	// Option<bool> is encoded as a single byte
+	resolved, err := r.Resolve(elem)
+	if err != nil {
+		return err
+	}
+	if resolved.Kind == KindPrimitive && resolved.Name == "bool" {
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("expected bool, but got %T", value)
+		}
+		if b {
+			return encoder.PushByte(1)
+		}
+		return encoder.PushByte(2)
+	}
+
+	err = encoder.PushByte(1)
+	if err != nil {
+		return err
+	}
+	return r.EncodeDef(encoder, elem, value)
+}
+
+func (r *Registry) encodeEnum(encoder scale.Encoder, def *TypeDef, value interface{}) error {
+	var name string
+	var inner interface{}
+	switch v := value.(type) {
+	case string:
+		name = v
+	case map[string]interface{}:
+		if len(v) != 1 {
+			return fmt.Errorf("expected a single variant for enum %v, but got %v", def, len(v))
+		}
+		for n, i := range v {
+			name, inner = n, i
+		}
+	default:
+		return fmt.Errorf("expected string or map[string]interface{} for enum %v, but got %T", def, value)
+	}
+
+	variant, err := def.FindVariantByName(name)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.PushByte(variant.Index)
+	if err != nil {
+		return err
+	}
+	if variant.Type == nil {
+		return nil
+	}
+	return r.EncodeDef(encoder, variant.Type, inner)
+}
+
+// encodeSet encodes the names of the flags that are set as a bit field
+func encodeSet(encoder scale.Encoder, def *TypeDef, value interface{}) error {
+	names, err := toSlice(value)
+	if err != nil {
+		return err
+	}
+
+	var v uint64
+	for _, n := range names {
+		found := false
+		for _, f := range def.Flags {
+			if f.Name == n {
+				v |= f.Value
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("flag %v not found in %v", n, def)
+		}
+	}
+
+	bz, err := bigIntToLE(new(big.Int).SetUint64(v), def.Length/8, false)
+	if err != nil {
+		return err
+	}
+	return encoder.Write(bz)
+}
+
+func encodePrimitive(encoder scale.Encoder, name string, value interface{}) error {
+	switch name {
+	case "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("expected bool, but got %T", value)
+		}
+		return encoder.Encode(b)
+	case "str":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected string, but got %T", value)
+		}
+		return encoder.Encode(s)
+	}
+
+	size, ok := primitives[name]
+	if !ok {
+		return fmt.Errorf("unknown primitive %v", name)
+	}
+	v, err := toBigInt(value)
+	if err != nil {
+		return err
+	}
+	bz, err := bigIntToLE(v, size, name[0] == 'i')
+	if err != nil {
+		return fmt.Errorf("unable to encode %v as %v: %v", v, name, err)
+	}
+	return encoder.Write(bz)
+}
+
+// bigIntToLE converts a big.Int to little endian bytes of the given size, in two's complement if signed
+func bigIntToLE(v *big.Int, size int, signed bool) ([]byte, error) {
+	bits := uint(size * 8)
+	min, max := big.NewInt(0), new(big.Int).Lsh(big.NewInt(1), bits)
+	if signed {
+		max.Rsh(max, 1)
+		min.Neg(max)
+	}
+	if v.Cmp(min) < 0 || v.Cmp(max) >= 0 {
+		return nil, fmt.Errorf("value out of range")
+	}
+
+	u := new(big.Int).Set(v)
+	if u.Sign() < 0 {
+		u.Add(u, new(big.Int).Lsh(big.NewInt(1), bits))
+	}
+	be := u.Bytes()
+	bz := make([]byte, size)
+	for i, b := range be {
+		bz[len(be)-1-i] = b
+	}
+	return bz, nil
+}
+
+// encodeCompact encodes a number of arbitrary size in compact encoding
+func encodeCompact(encoder scale.Encoder, v *big.Int) error {
+	if v.Sign() < 0 {
+		return fmt.Errorf("unable to compact encode negative number %v", v)
+	}
+	if v.IsUint64() && v.Uint64() < 1<<30 {
+		return encoder.EncodeUintCompact(v.Uint64())
+	}
+
+	be := v.Bytes()
+	if len(be) > 67 {
+		return fmt.Errorf("unable to compact encode %v, it exceeds 536 bits", v)
+	}
+	size := len(be)
+	if size < 4 {
+		size = 4
+	}
+	bz, err := bigIntToLE(v, size, false)
+	if err != nil {
+		return err
+	}
+	err = encoder.PushByte(byte(size-4)<<2 | 3)
+	if err != nil {
+		return err
+	}
+	return encoder.Write(bz)
+}
+
+// toBigInt converts any Go integer type or big.Int to a big.Int
+func toBigInt(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		if v == nil {
+			return nil, fmt.Errorf("expected an integer, but got nil")
+		}
+		return v, nil
+	case big.Int:
+		return &v, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Int).SetUint64(rv.Uint()), nil
+	case reflect.Struct:
+		// types such as types.U128 embed a *big.Int
+		if rv.NumField() == 1 {
+			if b, ok := rv.Field(0).Interface().(*big.Int); ok && b != nil {
+				return b, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("expected an integer, but got %T", value)
+}
+
+// toBytes converts any slice or array of bytes to a byte slice
+func toBytes(value interface{}) ([]byte, error) {
+	if bz, ok := value.([]byte); ok {
+		return bz, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	if (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Type().Elem().Kind() == reflect.Uint8 {
+		bz := make([]byte, rv.Len())
+		for i := range bz {
+			bz[i] = byte(rv.Index(i).Uint())
+		}
+		return bz, nil
+	}
+	if s, ok := value.(string); ok {
+		return []byte(s), nil
+	}
+	return nil, fmt.Errorf("expected bytes, but got %T", value)
+}
+
+// toSlice converts any slice or array to a slice of interfaces
+func toSlice(value interface{}) ([]interface{}, error) {
+	if values, ok := value.([]interface{}); ok {
+		return values, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected a slice or array, but got %T", value)
+	}
+	values := make([]interface{}, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values, nil
+}
@@ -0,0 +1,150 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/zenghq3/go-substrate-rpc-client/scale"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// Event is a generically decoded event record
+type Event struct {
+	Phase  types.Phase
+	ID     types.EventID
+	Module string
+	Name   string
+	Args   []EventArg
+	Topics []types.Hash
+}
+
+// EventArg is a decoded event argument. Name is only available for metadata v14 and later.
+type EventArg struct {
+	Name  string
+	Type  string
+	Value interface{}
+}
+
+// DecodeEvents decodes the event records from an EventRecordsRaw into a list of generic events, using the argument
+// types from the metadata. Contrary to EventRecordsRaw.DecodeEventRecords, no struct with the events of the runtime
+// needs to be defined, yet all argument types need to be known to the registry.
+func (r *Registry) DecodeEvents(meta *types.Metadata, raw types.EventRecordsRaw) ([]Event, error) {
+	reg, err := r.ForMetadata(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := scale.NewDecoder(bytes.NewReader(raw))
+
+	n, err := decoder.DecodeUintCompact()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]Event, 0, preallocation(*decoder, n))
+	for i := uint64(0); i < n; i++ {
+		var event Event
+		err = reg.decodeEvent(*decoder, meta, &event)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode event #%v: %v", i, err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (r *Registry) decodeEvent(decoder scale.Decoder, meta *types.Metadata, event *Event) error {
+	err := decoder.Decode(&event.Phase)
+	if err != nil {
+		return fmt.Errorf("unable to decode Phase: %v", err)
+	}
+
+	err = decoder.Decode(&event.ID)
+	if err != nil {
+		return fmt.Errorf("unable to decode EventID: %v", err)
+	}
+
+	args, err := r.eventArgs(meta, event)
+	if err != nil {
+		return err
+	}
+
+	for _, arg := range args {
+		a := EventArg{Name: arg.name, Type: arg.typeName}
+		a.Value, err = r.DecodeDef(decoder, arg.def)
+		if err != nil {
+			return fmt.Errorf("unable to decode argument %v of type %v for %v.%v: %v", len(event.Args), a.Type,
+				event.Module, event.Name, err)
+		}
+		event.Args = append(event.Args, a)
+	}
+
+	// event records before metadata v4 have no topics
+	if meta.Version < 4 {
+		return nil
+	}
+	err = decoder.Decode(&event.Topics)
+	if err != nil {
+		return fmt.Errorf("unable to decode Topics for %v.%v: %v", event.Module, event.Name, err)
+	}
+	return nil
+}
+
+type argDef struct {
+	name     string
+	typeName string
+	def      *TypeDef
+}
+
+// eventArgs sets the module and event names and returns the argument types of the event
+func (r *Registry) eventArgs(meta *types.Metadata, event *Event) ([]argDef, error) {
+	if meta.IsMetadataV14 {
+		mod, variant, err := meta.AsMetadataV14.FindEventVariant(event.ID)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find event with EventID %v in metadata: %v", event.ID, err)
+		}
+		event.Module, event.Name = string(mod), string(variant.Name)
+
+		args := make([]argDef, len(variant.Fields))
+		for i, f := range variant.Fields {
+			args[i] = argDef{name: string(f.Name), def: NewPortable(f.Type)}
+			args[i].typeName, err = meta.AsMetadataV14.Lookup.TypeName(f.Type)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return args, nil
+	}
+
+	mod, em, err := meta.FindEventMetadata(event.ID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find event with EventID %v in metadata: %v", event.ID, err)
+	}
+	event.Module, event.Name = string(mod), string(em.Name)
+
+	args := make([]argDef, len(em.Args))
+	for i, a := range em.Args {
+		args[i] = argDef{typeName: string(a)}
+		args[i].def, err = r.ParseModuleTypeString(string(mod), string(a))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return args, nil
+}
@@ -0,0 +1,164 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/zenghq3/go-substrate-rpc-client/registry"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+var (
+	alice = types.MustHexDecodeString("0xd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d")
+	bob   = types.MustHexDecodeString("0x8eaf04151687736326c9fea17e25fc5287613693c912909cb226aa4794f26a48")
+)
+
+func TestRegistry_DecodeEvents(t *testing.T) {
+	e := types.EventRecordsRaw(types.MustHexDecodeString(
+		"0x08" + // 2 events
+			"0001000000" + // ApplyExtrinsic(1)
+			"0302" + // Balances_Transfer
+			"d43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d" + // From
+			"8eaf04151687736326c9fea17e25fc5287613693c912909cb226aa4794f26a48" + // To
+			"391b0000000000000000000000000000" + // Value
+			"01000000000000000000000000000000" + // Fees
+			"00" + // Topics
+			"01" + // Finalization
+			"0200" + // Indices_NewAccountIndex
+			"8eaf04151687736326c9fea17e25fc5287613693c912909cb226aa4794f26a48" + // Who
+			"01000000" + // AccountIndex
+			"040102000000000000000000000000000000000000000000000000000000000000", // Topics
+	))
+
+	events, err := NewRegistry().DecodeEvents(types.ExamplaryMetadataV8, e)
+	assert.NoError(t, err)
+	assert.Equal(t, []Event{
+		{
+			Phase:  types.Phase{IsApplyExtrinsic: true, AsApplyExtrinsic: 1},
+			ID:     types.EventID{3, 2},
+			Module: "Balances",
+			Name:   "Transfer",
+			Args: []EventArg{
+				{Type: "AccountId", Value: alice},
+				{Type: "AccountId", Value: bob},
+				{Type: "Balance", Value: big.NewInt(6969)},
+				{Type: "Balance", Value: big.NewInt(1)},
+			},
+		},
+		{
+			Phase:  types.Phase{IsFinalization: true},
+			ID:     types.EventID{2, 0},
+			Module: "Indices",
+			Name:   "NewAccountIndex",
+			Args: []EventArg{
+				{Type: "AccountId", Value: bob},
+				{Type: "AccountIndex", Value: uint32(1)},
+			},
+			Topics: []types.Hash{{1, 2}},
+		},
+	}, events)
+}
+
+func TestRegistry_DecodeEvents_UnknownType(t *testing.T) {
+	e := types.EventRecordsRaw(types.MustHexDecodeString("0x04000100000003020000"))
+
+	_, err := NewEmptyRegistry().DecodeEvents(types.ExamplaryMetadataV8, e)
+	assert.EqualError(t, err, "unable to decode event #0: unable to decode argument 0 of type AccountId for "+
+		"Balances.Transfer: type AccountId is not registered")
+}
+
+func TestRegistry_DecodeEvents_WithoutTopics(t *testing.T) {
+	meta := types.NewMetadataV3()
+	meta.MagicNumber = types.MagicNumber
+	meta.AsMetadataV3.Modules = []types.ModuleMetadataV3{{
+		Name:      "session",
+		Prefix:    "Session",
+		HasEvents: true,
+		Events:    []types.EventMetadataV4{{Name: "NewSession", Args: []types.Type{"BlockNumber"}}},
+	}}
+
+	events, err := NewRegistry().DecodeEvents(meta, types.MustHexDecodeString("0x08010000050000000001000000000005000000"))
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "Session", events[0].Module)
+	assert.Equal(t, "NewSession", events[1].Name)
+	assert.Equal(t, uint32(5), events[1].Args[0].Value)
+	assert.Nil(t, events[1].Topics)
+}
+
+func TestRegistry_DecodeEvents_MetadataV14(t *testing.T) {
+	id := types.NewSi1LookupTypeID
+	field := func(name string, typ uint64, typeName string) types.Si1Field {
+		return types.Si1Field{HasName: name != "", Name: types.Text(name), Type: id(typ), HasTypeName: true,
+			TypeName: types.Text(typeName)}
+	}
+
+	meta := types.NewMetadataV14()
+	meta.MagicNumber = types.MagicNumber
+	meta.AsMetadataV14.Lookup = types.PortableRegistry{
+		{ID: id(0), Type: types.Si1Type{Def: types.Si1TypeDef{IsPrimitive: true, AsPrimitive: types.IsU8}}},
+		{ID: id(1), Type: types.Si1Type{Def: types.Si1TypeDef{IsArray: true,
+			AsArray: types.Si1TypeDefArray{Len: 32, Type: id(0)}}}},
+		{ID: id(2), Type: types.Si1Type{Path: types.Si1Path{"sp_core", "crypto", "AccountId32"},
+			Def: types.Si1TypeDef{IsComposite: true,
+				AsComposite: types.Si1TypeDefComposite{Fields: []types.Si1Field{field("", 1, "[u8; 32]")}}}}},
+		{ID: id(3), Type: types.Si1Type{Def: types.Si1TypeDef{IsPrimitive: true, AsPrimitive: types.IsU128}}},
+		{ID: id(4), Type: types.Si1Type{Path: types.Si1Path{"pallet_balances", "pallet", "Event"},
+			Def: types.Si1TypeDef{IsVariant: true, AsVariant: types.Si1TypeDefVariant{Variants: []types.Si1Variant{
+				{Name: "Endowed", Index: 0, Fields: []types.Si1Field{field("account", 2, "T::AccountId")}},
+				{Name: "Transfer", Index: 2, Fields: []types.Si1Field{
+					field("from", 2, "T::AccountId"),
+					field("to", 2, "T::AccountId"),
+					field("amount", 3, "T::Balance"),
+				}},
+			}}}}},
+	}
+	meta.AsMetadataV14.Pallets = []types.PalletMetadataV14{{
+		Name:      "Balances",
+		HasEvents: true,
+		Events:    types.EventMetadataV14{Type: id(4)},
+		Index:     5,
+	}}
+
+	e := types.EventRecordsRaw(types.MustHexDecodeString(
+		"0x04" +
+			"0001000000" + // ApplyExtrinsic(1)
+			"0502" + // Balances.Transfer
+			"d43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d" + // from
+			"8eaf04151687736326c9fea17e25fc5287613693c912909cb226aa4794f26a48" + // to
+			"391b0000000000000000000000000000" + // amount
+			"00", // Topics
+	))
+
+	events, err := NewRegistry().DecodeEvents(meta, e)
+	assert.NoError(t, err)
+	assert.Equal(t, []Event{{
+		Phase:  types.Phase{IsApplyExtrinsic: true, AsApplyExtrinsic: 1},
+		ID:     types.EventID{5, 2},
+		Module: "Balances",
+		Name:   "Transfer",
+		Args: []EventArg{
+			{Name: "from", Type: "AccountId32", Value: alice},
+			{Name: "to", Type: "AccountId32", Value: bob},
+			{Name: "amount", Type: "u128", Value: big.NewInt(6969)},
+		},
+	}}, events)
+}
@@ -0,0 +1,189 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RegisterJSON registers the type definitions of a JSON object in the format used by polkadot-js, e.g.
+//
+//	{
+//	  "Balance": "u64",
+//	  "AnchorData": {"id": "H256", "docRoot": "H256", "anchoredBlock": "u64"},
+//	  "Status": {"_enum": ["Active", "Inactive"]},
+//	  "Proof": {"_enum": {"None": null, "Hash": "H256"}},
+//	  "Permissions": {"_set": {"_bitLength": 8, "Read": 1, "Write": 2}}
+//	}
+//
+// Definitions may refer to each other regardless of their order.
+func (r *Registry) RegisterJSON(bz []byte) error {
+	entries, err := orderedObject(bz)
+	if err != nil {
+		return fmt.Errorf("unable to parse type definitions: %v", err)
+	}
+
+	defs := make(map[string]*TypeDef, len(entries))
+	for _, e := range entries {
+		defs[e.key], err = parseJSONDef(e.value)
+		if err != nil {
+			return fmt.Errorf("unable to parse type definition of %v: %v", e.key, err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, def := range defs {
+		r.types[name] = def
+	}
+	return nil
+}
+
+func parseJSONDef(raw json.RawMessage) (*TypeDef, error) {
+	var typ string
+	if json.Unmarshal(raw, &typ) == nil {
+		return ParseTypeString(typ)
+	}
+
+	entries, err := orderedObject(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) > 0 {
+		switch entries[0].key {
+		case "_enum":
+			return parseJSONEnum(entries[0].value)
+		case "_set":
+			return parseJSONSet(entries[0].value)
+		}
+	}
+
+	fields := make([]Field, 0, len(entries))
+	for _, e := range entries {
+		// keys such as _alias and _fallback have no influence on the encoding
+		if strings.HasPrefix(e.key, "_") {
+			continue
+		}
+		def, err := parseJSONDef(e.value)
+		if err != nil {
+			return nil, fmt.Errorf("field %v: %v", e.key, err)
+		}
+		fields = append(fields, Field{Name: e.key, Type: def})
+	}
+	return NewStruct(fields...), nil
+}
+
+func parseJSONEnum(raw json.RawMessage) (*TypeDef, error) {
+	var names []string
+	if json.Unmarshal(raw, &names) == nil {
+		return NewSimpleEnum(names...), nil
+	}
+
+	entries, err := orderedObject(raw)
+	if err != nil {
+		return nil, err
+	}
+	variants := make([]Variant, len(entries))
+	for i, e := range entries {
+		variants[i] = Variant{Index: uint8(i), Name: e.key}
+		if isJSONNull(e.value) {
+			continue
+		}
+		// numeric values are explicit indexes of variants without value
+		var index uint8
+		if json.Unmarshal(e.value, &index) == nil {
+			variants[i].Index = index
+			continue
+		}
+		variants[i].Type, err = parseJSONDef(e.value)
+		if err != nil {
+			return nil, fmt.Errorf("variant %v: %v", e.key, err)
+		}
+	}
+	return NewEnum(variants...), nil
+}
+
+// isJSONNull returns true for variants without value, given as null, "" or "Null"
+func isJSONNull(raw json.RawMessage) bool {
+	var s *string
+	if json.Unmarshal(raw, &s) != nil {
+		return false
+	}
+	return s == nil || *s == "" || *s == "Null"
+}
+
+func parseJSONSet(raw json.RawMessage) (*TypeDef, error) {
+	entries, err := orderedObject(raw)
+	if err != nil {
+		return nil, err
+	}
+	bitLength := 8
+	flags := make([]SetFlag, 0, len(entries))
+	for _, e := range entries {
+		var v uint64
+		err = json.Unmarshal(e.value, &v)
+		if err != nil {
+			return nil, fmt.Errorf("set flag %v: %v", e.key, err)
+		}
+		if e.key == "_bitLength" {
+			bitLength = int(v)
+			continue
+		}
+		flags = append(flags, SetFlag{Name: e.key, Value: v})
+	}
+	if bitLength%8 != 0 || bitLength == 0 || bitLength > 64 {
+		return nil, fmt.Errorf("unsupported set bit length %v", bitLength)
+	}
+	return NewSet(bitLength, flags...), nil
+}
+
+type jsonEntry struct {
+	key   string
+	value json.RawMessage
+}
+
+// orderedObject parses a JSON object into its entries, keeping the order of the keys
+func orderedObject(bz []byte) ([]jsonEntry, error) {
+	decoder := json.NewDecoder(bytes.NewReader(bz))
+	t, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := t.(json.Delim); !ok || d != '{' {
+		return nil, fmt.Errorf("expected a JSON object or string, but got %s", bz)
+	}
+
+	var entries []jsonEntry
+	for decoder.More() {
+		t, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var e jsonEntry
+		e.key = t.(string)
+		err = decoder.Decode(&e.value)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
@@ -0,0 +1,65 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/zenghq3/go-substrate-rpc-client/registry"
+)
+
+func TestRegistry_RegisterJSON(t *testing.T) {
+	r := NewRegistry()
+	err := r.RegisterJSON([]byte(`{
+		"AnchorData": {"id": "H256", "anchoredBlock": "u64", "docRoot": "Option<Hash>"},
+		"Status": {"_enum": ["Active", "Inactive"]},
+		"Proof": {"_enum": {"None": null, "Hash": "H256", "Pair": "(u8, u8)"}},
+		"Priority": {"_enum": {"Low": 1, "High": 10}},
+		"Permissions": {"_set": {"_bitLength": 16, "Read": 1, "Write": 2, "Admin": 256}},
+		"Balance": "u64"
+	}`))
+	assert.NoError(t, err)
+
+	def, ok := r.Get("AnchorData")
+	assert.True(t, ok)
+	assert.Equal(t, "{id: H256, anchoredBlock: u64, docRoot: Option<Hash>}", def.String())
+
+	assertRoundtrip(t, r, "AnchorData", map[string]interface{}{
+		"id":            make([]byte, 32),
+		"anchoredBlock": uint64(7),
+		"docRoot":       nil,
+	}, "0x0000000000000000000000000000000000000000000000000000000000000000070000000000000000")
+	assertRoundtrip(t, r, "Status", map[string]interface{}{"Inactive": nil}, "0x01")
+	assertRoundtrip(t, r, "Proof", map[string]interface{}{"Pair": []interface{}{uint8(1), uint8(2)}}, "0x020102")
+	assertRoundtrip(t, r, "Priority", map[string]interface{}{"High": nil}, "0x0a")
+	assertRoundtrip(t, r, "Permissions", []string{"Write", "Admin"}, "0x0201")
+	assertRoundtrip(t, r, "Balance", uint64(5), "0x0500000000000000")
+}
+
+func TestRegistry_RegisterJSON_Errors(t *testing.T) {
+	r := NewRegistry()
+	assert.EqualError(t, r.RegisterJSON([]byte(`[]`)),
+		"unable to parse type definitions: expected a JSON object or string, but got []")
+	assert.Error(t, r.RegisterJSON([]byte(`{"A": "Vec<"}`)))
+	assert.EqualError(t, r.RegisterJSON([]byte(`{"A": {"_set": {"_bitLength": 12}}}`)),
+		"unable to parse type definition of A: unsupported set bit length 12")
+
+	_, ok := r.Get("A")
+	assert.False(t, ok)
+}
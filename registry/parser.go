@@ -0,0 +1,241 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var (
+	traitCastRegexp = regexp.MustCompile(`<\s*[\w:]+\s+as\s+[\w:]+(<[^<>]*>)?\s*>::`)
+	lifetimeRegexp  = regexp.MustCompile(`&?'\w+\s*|&`)
+	pathRegexp      = regexp.MustCompile(`(\w+::)+`)
+	whitespace      = regexp.MustCompile(`\s+`)
+)
+
+// Sanitize removes the parts of a type string from metadata that are irrelevant for decoding, such as trait casts
+// (`<T as Trait<I>>::`), paths (`T::`, `system::`), references and lifetimes, and all whitespace. For example
+// `Vec<(T::AccountId, <T as Trait>::Balance)>` becomes `Vec<(AccountId,Balance)>`.
+func Sanitize(typ string) string {
+	typ = traitCastRegexp.ReplaceAllString(typ, "")
+	typ = lifetimeRegexp.ReplaceAllString(typ, "")
+	typ = pathRegexp.ReplaceAllString(typ, "")
+	return whitespace.ReplaceAllString(typ, "")
+}
+
+// ParseTypeString parses a type string as found in metadata into a TypeDef. Well-known generic wrappers are mapped to
+// their encoding: Vec, VecDeque, BTreeSet, BoundedVec and slices to vectors, BTreeMap and HashMap to vectors of key
+// value tuples, Result to an enum of Ok and Err, Box to its content and PhantomData to Null. Generic parameters of all
+// other types are dropped, so `BalanceOf<T>` refers to the type registered as `BalanceOf`.
+func ParseTypeString(typ string) (*TypeDef, error) {
+	p := &parser{s: Sanitize(typ)}
+	def, err := p.parseType()
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse type %v: %v", typ, err)
+	}
+	if p.pos != len(p.s) {
+		return nil, fmt.Errorf("unable to parse type %v: unexpected %q at position %v", typ, p.s[p.pos:], p.pos)
+	}
+	return def, nil
+}
+
+// MustParseTypeString parses a type string and panics on error
+func MustParseTypeString(typ string) *TypeDef {
+	def, err := ParseTypeString(typ)
+	if err != nil {
+		panic(err)
+	}
+	return def
+}
+
+type parser struct {
+	s   string
+	pos int
+}
+
+func (p *parser) peek() byte {
+	if p.pos >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *parser) expect(c byte) error {
+	if p.peek() != c {
+		if p.pos >= len(p.s) {
+			return fmt.Errorf("expected %q, but reached the end", c)
+		}
+		return fmt.Errorf("expected %q at position %v, but found %q", c, p.pos, p.s[p.pos])
+	}
+	p.pos++
+	return nil
+}
+
+func (p *parser) parseType() (*TypeDef, error) {
+	switch p.peek() {
+	case '(':
+		p.pos++
+		params, err := p.parseList(')')
+		if err != nil {
+			return nil, err
+		}
+		return NewTuple(params...), nil
+	case '[':
+		return p.parseArray()
+	default:
+		return p.parseNamed()
+	}
+}
+
+// parseList parses a comma separated list of types up to and including the closing character
+func (p *parser) parseList(closing byte) ([]*TypeDef, error) {
+	var list []*TypeDef
+	if p.peek() == closing {
+		p.pos++
+		return list, nil
+	}
+	for {
+		t, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+
+		if p.peek() == ',' {
+			p.pos++
+			// allow trailing commas, as in `(AccountId,)`
+			if p.peek() == closing {
+				p.pos++
+				return list, nil
+			}
+			continue
+		}
+		return list, p.expect(closing)
+	}
+}
+
+func (p *parser) parseArray() (*TypeDef, error) {
+	err := p.expect('[')
+	if err != nil {
+		return nil, err
+	}
+	elem, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	// slices such as `&[u8]` have no length
+	if p.peek() == ']' {
+		p.pos++
+		return NewVec(elem), nil
+	}
+	err = p.expect(';')
+	if err != nil {
+		return nil, err
+	}
+	start := p.pos
+	for p.peek() >= '0' && p.peek() <= '9' {
+		p.pos++
+	}
+	length, err := strconv.Atoi(p.s[start:p.pos])
+	if err != nil {
+		return nil, fmt.Errorf("invalid array length at position %v", start)
+	}
+	err = p.expect(']')
+	if err != nil {
+		return nil, err
+	}
+	return NewArray(elem, length), nil
+}
+
+func (p *parser) parseNamed() (*TypeDef, error) {
+	start := p.pos
+	for c := p.peek(); c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'; c = p.peek() {
+		p.pos++
+	}
+	name := p.s[start:p.pos]
+	if name == "" {
+		if p.pos >= len(p.s) {
+			return nil, fmt.Errorf("expected a type, but reached the end")
+		}
+		return nil, fmt.Errorf("expected a type at position %v, but found %q", p.pos, p.s[p.pos])
+	}
+
+	var params []*TypeDef
+	if p.peek() == '<' {
+		p.pos++
+		var err error
+		params, err = p.parseList('>')
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return fromGeneric(name, params)
+}
+
+// fromGeneric maps well-known generic types to their definitions
+func fromGeneric(name string, params []*TypeDef) (*TypeDef, error) {
+	expectParams := func(n int) error {
+		if len(params) < n {
+			return fmt.Errorf("expected %v to have %v type parameters, but got %v", name, n, len(params))
+		}
+		return nil
+	}
+
+	switch name {
+	case "Vec", "VecDeque", "BTreeSet", "BoundedVec", "WeakBoundedVec":
+		if err := expectParams(1); err != nil {
+			return nil, err
+		}
+		return NewVec(params[0]), nil
+	case "Option":
+		if err := expectParams(1); err != nil {
+			return nil, err
+		}
+		return NewOption(params[0]), nil
+	case "Compact":
+		if err := expectParams(1); err != nil {
+			return nil, err
+		}
+		return NewCompact(params[0]), nil
+	case "Box", "Cow":
+		if err := expectParams(1); err != nil {
+			return nil, err
+		}
+		return params[0], nil
+	case "BTreeMap", "HashMap":
+		if err := expectParams(2); err != nil {
+			return nil, err
+		}
+		return NewVec(NewTuple(params[0], params[1])), nil
+	case "Result":
+		if err := expectParams(2); err != nil {
+			return nil, err
+		}
+		return NewEnum(Variant{Index: 0, Name: "Ok", Type: params[0]}, Variant{Index: 1, Name: "Err", Type: params[1]}), nil
+	case "PhantomData":
+		return NewTuple(), nil
+	}
+
+	if isPrimitive(name) {
+		return NewPrimitive(name), nil
+	}
+
+	return NewNamed(name), nil
+}
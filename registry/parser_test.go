@@ -0,0 +1,84 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/zenghq3/go-substrate-rpc-client/registry"
+)
+
+func TestSanitize(t *testing.T) {
+	for typ, expected := range map[string]string{
+		"T::AccountId": "AccountId",
+		"Vec<(T::AccountId, <T as Trait>::Balance)>": "Vec<(AccountId,Balance)>",
+		"<T as Trait<I>>::Balance":                   "Balance",
+		"<T as frame_system::Config>::BlockNumber":   "BlockNumber",
+		"<T::Lookup as StaticLookup>::Source":        "Source",
+		"&'static [u8]":                              "[u8]",
+		"Option<system::Phase>":                      "Option<Phase>",
+		"Compact<BalanceOf<T>>":                      "Compact<BalanceOf<T>>",
+		"  [ u8 ; 32 ]\n":                            "[u8;32]",
+	} {
+		assert.Equal(t, expected, Sanitize(typ))
+	}
+}
+
+func TestParseTypeString(t *testing.T) {
+	for typ, expected := range map[string]string{
+		"Vec<(T::AccountId, Compact<BalanceOf<T>>)>": "Vec<(AccountId, Compact<BalanceOf>)>",
+		"[u8; 32]":                  "[u8; 32]",
+		"()":                        "()",
+		"(AccountId,)":              "(AccountId)",
+		"Option<Vec<u8>>":           "Option<Vec<u8>>",
+		"BTreeMap<u32, Balance>":    "Vec<(u32, Balance)>",
+		"Box<<T as Trait>::Call>":   "Call",
+		"Result<(), DispatchError>": "enum{Ok(()), Err(DispatchError)}",
+		"PhantomData<T>":            "()",
+		"VecDeque<[u8; 4]>":         "Vec<[u8; 4]>",
+		"&'static [u8]":             "Vec<u8>",
+	} {
+		def, err := ParseTypeString(typ)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, def.String())
+	}
+}
+
+func TestParseTypeString_Kinds(t *testing.T) {
+	def := MustParseTypeString("Vec<(T::AccountId, Compact<u128>, [u8; 4])>")
+	assert.Equal(t, KindVec, def.Kind)
+	tuple := def.Params[0]
+	assert.Equal(t, KindTuple, tuple.Kind)
+	assert.Equal(t, NewNamed("AccountId"), tuple.Params[0])
+	assert.Equal(t, NewCompact(NewPrimitive("u128")), tuple.Params[1])
+	assert.Equal(t, NewArray(NewPrimitive("u8"), 4), tuple.Params[2])
+}
+
+func TestParseTypeString_Errors(t *testing.T) {
+	for typ, expected := range map[string]string{
+		"Vec<u8":   "unable to parse type Vec<u8: expected '>', but reached the end",
+		"[u8; x]":  "unable to parse type [u8; x]: invalid array length at position 4",
+		"u8>":      "unable to parse type u8>: unexpected \">\" at position 2",
+		"Option<>": "unable to parse type Option<>: expected Option to have 1 type parameters, but got 0",
+		"":         "unable to parse type : expected a type, but reached the end",
+	} {
+		_, err := ParseTypeString(typ)
+		assert.EqualError(t, err, expected)
+	}
+}
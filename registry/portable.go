@@ -0,0 +1,150 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// PortableTypeName returns the name under which the type with the given ID of a portable registry is registered
+func PortableTypeName(id types.Si1LookupTypeID) string {
+	return fmt.Sprintf("Lookup%d", id.Int64())
+}
+
+// NewPortable creates a reference to the type with the given ID of a portable registry
+func NewPortable(id types.Si1LookupTypeID) *TypeDef {
+	return NewNamed(PortableTypeName(id))
+}
+
+// WithPortableTypes returns a copy of the registry that additionally contains all types of the portable registry of
+// metadata v14, registered by PortableTypeName
+func (r *Registry) WithPortableTypes(lookup types.PortableRegistry) (*Registry, error) {
+	c := r.Copy()
+	for _, t := range lookup {
+		def, err := fromPortableType(t.Type)
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert type with ID %v: %v", t.ID.Int64(), err)
+		}
+		c.types[PortableTypeName(t.ID)] = def
+	}
+	return c, nil
+}
+
+// maxPortableRegistries is the number of registries cached by ForMetadata
+const maxPortableRegistries = 4
+
+// portableRegistry is a registry cached by ForMetadata
+type portableRegistry struct {
+	meta *types.Metadata
+	reg  *Registry
+}
+
+// ForMetadata returns the registry to use with the given metadata. For metadata v14, this is a copy of the registry
+// with the portable types added by WithPortableTypes, which is built once and reused for the same metadata until types
+// are registered on r. Registries for a few different metadata are kept at a time. For earlier metadata versions, r
+// itself is returned.
+func (r *Registry) ForMetadata(meta *types.Metadata) (*Registry, error) {
+	if !meta.IsMetadataV14 {
+		return r, nil
+	}
+
+	r.mu.RLock()
+	generation := r.generation
+	for _, p := range r.portable {
+		if p.meta == meta {
+			r.mu.RUnlock()
+			return p.reg, nil
+		}
+	}
+	r.mu.RUnlock()
+
+	reg, err := r.WithPortableTypes(meta.AsMetadataV14.Lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.generation == generation {
+		if len(r.portable) == maxPortableRegistries {
+			r.portable = r.portable[1:]
+		}
+		r.portable = append(r.portable, portableRegistry{meta, reg})
+	}
+	return reg, nil
+}
+
+func fromPortableType(t types.Si1Type) (*TypeDef, error) {
+	switch {
+	case t.Def.IsComposite:
+		return fromPortableFields(t.Def.AsComposite.Fields), nil
+	case t.Def.IsVariant:
+		variants := make([]Variant, len(t.Def.AsVariant.Variants))
+		for i, v := range t.Def.AsVariant.Variants {
+			variants[i] = Variant{Index: v.Index, Name: string(v.Name)}
+			if len(v.Fields) > 0 {
+				variants[i].Type = fromPortableFields(v.Fields)
+			}
+		}
+		return NewEnum(variants...), nil
+	case t.Def.IsSequence:
+		return NewVec(NewPortable(t.Def.AsSequence.Type)), nil
+	case t.Def.IsArray:
+		return NewArray(NewPortable(t.Def.AsArray.Type), int(t.Def.AsArray.Len)), nil
+	case t.Def.IsTuple:
+		elems := make([]*TypeDef, len(t.Def.AsTuple))
+		for i, e := range t.Def.AsTuple {
+			elems[i] = NewPortable(e)
+		}
+		return NewTuple(elems...), nil
+	case t.Def.IsPrimitive:
+		if t.Def.AsPrimitive == types.IsChar {
+			// a Rust char is encoded as its 4 byte code point
+			return NewPrimitive("u32"), nil
+		}
+		return NewPrimitive(t.Def.AsPrimitive.String()), nil
+	case t.Def.IsCompact:
+		return NewCompact(NewPortable(t.Def.AsCompact.Type)), nil
+	case t.Def.IsBitSequence:
+		// bit sequences are not supported yet, decoding them fails as the name is not registered
+		return NewNamed("BitVec"), nil
+	default:
+		return nil, fmt.Errorf("unknown type definition")
+	}
+}
+
+// fromPortableFields converts the fields of a composite or variant to a struct if the fields are named, to the type
+// of the field if there is a single unnamed field, and to a tuple otherwise
+func fromPortableFields(fields []types.Si1Field) *TypeDef {
+	if len(fields) > 0 && fields[0].HasName {
+		s := make([]Field, len(fields))
+		for i, f := range fields {
+			s[i] = Field{Name: string(f.Name), Type: NewPortable(f.Type)}
+		}
+		return NewStruct(s...)
+	}
+	if len(fields) == 1 {
+		return NewPortable(fields[0].Type)
+	}
+	elems := make([]*TypeDef, len(fields))
+	for i, f := range fields {
+		elems[i] = NewPortable(f.Type)
+	}
+	return NewTuple(elems...)
+}
@@ -0,0 +1,339 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package registry provides a dynamic type system for the type strings found in metadata, such as
+`Vec<(T::AccountId, Compact<BalanceOf<T>>)>`. It allows to decode and encode SCALE values without defining Go structs.
+
+Decoded values form a generic value tree:
+
+	bool                    bool
+	u8, u16, u32, u64       uint8, uint16, uint32, uint64
+	i8, i16, i32, i64       int8, int16, int32, int64
+	u128, u256, i128, i256  *big.Int
+	Compact<T>              *big.Int
+	str, Text               string
+	Vec<u8>, [u8; N]        []byte
+	Vec<T>, [T; N], tuples  []interface{}
+	sets                    []string with the names of the flags that are set
+	structs                 map[string]interface{}
+	enums                   map[string]interface{} with a single entry from the variant name to its value (nil if none)
+	Option<T>, Null         nil or the value of T
+
+When encoding, integers may be given as any Go integer type or *big.Int, byte slices and byte arrays may be given as
+any slice or array of bytes and unit enum variants may be given as string.
+*/
+package registry
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// maxResolveDepth limits the number of aliases followed when resolving a named type, to detect cyclic definitions
+const maxResolveDepth = 64
+
+// Registry holds type definitions by name
+type Registry struct {
+	mu    sync.RWMutex
+	types map[string]*TypeDef
+	// moduleAliases maps lower case module names to type names that are replaced in the argument and storage types of
+	// that module only
+	moduleAliases map[string]map[string]string
+	// portable caches the registries returned by ForMetadata, it is cleared whenever types are registered
+	portable []portableRegistry
+	// generation is incremented whenever types are registered, to discard registries built from an outdated state
+	generation uint64
+}
+
+// NewRegistry creates a registry containing the primitives and well-known Substrate types
+func NewRegistry() *Registry {
+	r := NewEmptyRegistry()
+	for name, typ := range defaultAliases {
+		r.types[name] = MustParseTypeString(typ)
+	}
+	for name, def := range defaultTypes() {
+		r.types[name] = def
+	}
+	return r
+}
+
+// NewEmptyRegistry creates a registry that only knows about primitives
+func NewEmptyRegistry() *Registry {
+	return &Registry{types: make(map[string]*TypeDef), moduleAliases: make(map[string]map[string]string)}
+}
+
+// Register adds a type definition under the given name, replacing any existing definition
+func (r *Registry) Register(name string, def *TypeDef) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.types[name] = def
+	r.invalidate()
+}
+
+// RegisterAlias adds the type described by the type string under the given name, e.g.
+// `r.RegisterAlias("Balance", "u64")`
+func (r *Registry) RegisterAlias(name, typ string) error {
+	def, err := ParseTypeString(typ)
+	if err != nil {
+		return err
+	}
+	r.Register(name, def)
+	return nil
+}
+
+// RegisterModuleAlias replaces the type name from by the type name to in the types of the given module, e.g.
+// `r.RegisterModuleAlias("Assets", "Balance", "TAssetBalance")`
+func (r *Registry) RegisterModuleAlias(module, from, to string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := strings.ToLower(module)
+	if r.moduleAliases[m] == nil {
+		r.moduleAliases[m] = make(map[string]string)
+	}
+	r.moduleAliases[m][from] = to
+	r.invalidate()
+}
+
+// invalidate discards the cached registries of ForMetadata, r.mu must be locked
+func (r *Registry) invalidate() {
+	r.portable = nil
+	r.generation++
+}
+
+// ParseModuleTypeString parses a type string of the given module, applying the aliases registered for the module
+func (r *Registry) ParseModuleTypeString(module, typ string) (*TypeDef, error) {
+	def, err := ParseTypeString(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	aliases := r.moduleAliases[strings.ToLower(module)]
+	if len(aliases) == 0 {
+		return def, nil
+	}
+	return applyAliases(def, aliases), nil
+}
+
+// applyAliases returns a copy of the definition with the named references replaced as per the aliases
+func applyAliases(def *TypeDef, aliases map[string]string) *TypeDef {
+	if def == nil {
+		return nil
+	}
+	if def.Kind == KindNamed {
+		if to, ok := aliases[def.Name]; ok {
+			return NewNamed(to)
+		}
+		return def
+	}
+
+	c := *def
+	c.Params = make([]*TypeDef, len(def.Params))
+	for i, p := range def.Params {
+		c.Params[i] = applyAliases(p, aliases)
+	}
+	c.Fields = make([]Field, len(def.Fields))
+	for i, f := range def.Fields {
+		c.Fields[i] = Field{Name: f.Name, Type: applyAliases(f.Type, aliases)}
+	}
+	c.Variants = make([]Variant, len(def.Variants))
+	for i, v := range def.Variants {
+		c.Variants[i] = Variant{Index: v.Index, Name: v.Name, Type: applyAliases(v.Type, aliases)}
+	}
+	return &c
+}
+
+// Get returns the definition registered under the given name
+func (r *Registry) Get(name string) (*TypeDef, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	def, ok := r.types[name]
+	return def, ok
+}
+
+// Names returns the names of all registered types
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.types))
+	for n := range r.types {
+		names = append(names, n)
+	}
+	return names
+}
+
+// Copy returns a copy of the registry that can be extended without modifying the original
+func (r *Registry) Copy() *Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c := NewEmptyRegistry()
+	for n, def := range r.types {
+		c.types[n] = def
+	}
+	for m, aliases := range r.moduleAliases {
+		c.moduleAliases[m] = make(map[string]string, len(aliases))
+		for from, to := range aliases {
+			c.moduleAliases[m][from] = to
+		}
+	}
+	return c
+}
+
+// Resolve follows named references until reaching a definition that is not a reference
+func (r *Registry) Resolve(def *TypeDef) (*TypeDef, error) {
+	for i := 0; i < maxResolveDepth; i++ {
+		if def.Kind != KindNamed {
+			return def, nil
+		}
+		next, ok := r.Get(def.Name)
+		if !ok {
+			return nil, fmt.Errorf("type %v is not registered", def.Name)
+		}
+		def = next
+	}
+	return nil, fmt.Errorf("unable to resolve type %v, the definition is cyclic", def.Name)
+}
+
+// ResolveTypeString parses the type string and resolves it, see Resolve
+func (r *Registry) ResolveTypeString(typ string) (*TypeDef, error) {
+	def, err := ParseTypeString(typ)
+	if err != nil {
+		return nil, err
+	}
+	return r.Resolve(def)
+}
+
+var primitives = map[string]int{
+	"bool": 1,
+	"str":  0,
+	"u8":   1,
+	"u16":  2,
+	"u32":  4,
+	"u64":  8,
+	"u128": 16,
+	"u256": 32,
+	"i8":   1,
+	"i16":  2,
+	"i32":  4,
+	"i64":  8,
+	"i128": 16,
+	"i256": 32,
+}
+
+func isPrimitive(name string) bool {
+	_, ok := primitives[name]
+	return ok
+}
+
+// defaultAliases maps well-known Substrate types to their type strings
+var defaultAliases = map[string]string{
+	"Text":    "str",
+	"String":  "str",
+	"Bytes":   "Vec<u8>",
+	"Null":    "()",
+	"H160":    "[u8; 20]",
+	"H256":    "[u8; 32]",
+	"H512":    "[u8; 64]",
+	"Hash":    "H256",
+	"U8":      "u8",
+	"U16":     "u16",
+	"U32":     "u32",
+	"U64":     "u64",
+	"U128":    "u128",
+	"U256":    "u256",
+	"I8":      "i8",
+	"I16":     "i16",
+	"I32":     "i32",
+	"I64":     "i64",
+	"I128":    "i128",
+	"I256":    "i256",
+	"Bool":    "bool",
+	"USize":   "u32",
+	"usize":   "u32",
+	"Key":     "Bytes",
+	"Data":    "Bytes",
+	"Moment":  "u64",
+	"Weight":  "u32",
+	"Perbill": "u32",
+	"Permill": "u32",
+	"Percent": "u8",
+
+	"AccountId":       "[u8; 32]",
+	"AccountIndex":    "u32",
+	"AccountIdOf":     "AccountId",
+	"ValidatorId":     "AccountId",
+	"AuthorityId":     "[u8; 32]",
+	"AuthorityWeight": "u64",
+	"AuthorityList":   "Vec<(AuthorityId, AuthorityWeight)>",
+	"Balance":         "u128",
+	"BalanceOf":       "Balance",
+	"BlockNumber":     "u32",
+	"BlockHash":       "Hash",
+	"Index":           "u32",
+	"Nonce":           "Index",
+	"Signature":       "H512",
+
+	"SessionIndex":    "u32",
+	"EraIndex":        "u32",
+	"SetIndex":        "u32",
+	"VoteIndex":       "u32",
+	"PropIndex":       "u32",
+	"ReferendumIndex": "u32",
+	"ProposalIndex":   "u32",
+	"MemberCount":     "u32",
+	"ApprovalFlag":    "u32",
+	"Kind":            "[u8; 16]",
+	"OpaqueTimeSlot":  "Bytes",
+	"LockIdentifier":  "[u8; 8]",
+
+	"IdentificationTuple": "(ValidatorId, FullIdentification)",
+	"FullIdentification":  "Exposure",
+}
+
+// defaultTypes returns the well-known Substrate structs and enums
+func defaultTypes() map[string]*TypeDef {
+	return map[string]*TypeDef{
+		"Phase": NewEnum(
+			Variant{Index: 0, Name: "ApplyExtrinsic", Type: NewPrimitive("u32")},
+			Variant{Index: 1, Name: "Finalization"},
+			Variant{Index: 2, Name: "Initialization"},
+		),
+		"DispatchClass": NewSimpleEnum("Normal", "Operational"),
+		"DispatchInfo": NewStruct(
+			Field{Name: "weight", Type: NewNamed("Weight")},
+			Field{Name: "class", Type: NewNamed("DispatchClass")},
+			Field{Name: "paysFee", Type: NewPrimitive("bool")},
+		),
+		"DispatchError": NewStruct(
+			Field{Name: "module", Type: NewOption(NewPrimitive("u8"))},
+			Field{Name: "error", Type: NewPrimitive("u8")},
+		),
+		"DispatchResult": MustParseTypeString("Result<(), DispatchError>"),
+		"VoteThreshold":  NewSimpleEnum("SuperMajorityApprove", "SuperMajorityAgainst", "SimpleMajority"),
+		"Exposure": NewStruct(
+			Field{Name: "total", Type: NewCompact(NewNamed("Balance"))},
+			Field{Name: "own", Type: NewCompact(NewNamed("Balance"))},
+			Field{Name: "others", Type: NewVec(NewNamed("IndividualExposure"))},
+		),
+		"IndividualExposure": NewStruct(
+			Field{Name: "who", Type: NewNamed("AccountId")},
+			Field{Name: "value", Type: NewCompact(NewNamed("Balance"))},
+		),
+	}
+}
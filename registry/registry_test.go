@@ -0,0 +1,209 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/zenghq3/go-substrate-rpc-client/registry"
+	"github.com/zenghq3/go-substrate-rpc-client/scale"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+func assertRoundtrip(t *testing.T, r *Registry, typ string, value interface{}, encoded string) {
+	bz, err := r.EncodeToBytes(typ, value)
+	assert.NoError(t, err)
+	assert.Equal(t, encoded, types.HexEncodeToString(bz))
+
+	decoded, err := r.DecodeFromBytes(typ, bz)
+	assert.NoError(t, err)
+	assert.Equal(t, value, decoded)
+}
+
+func TestRegistry_Resolve(t *testing.T) {
+	r := NewRegistry()
+
+	def, err := r.ResolveTypeString("T::Hash")
+	assert.NoError(t, err)
+	assert.Equal(t, NewArray(NewPrimitive("u8"), 32), def)
+
+	_, err = r.ResolveTypeString("Unknown")
+	assert.EqualError(t, err, "type Unknown is not registered")
+
+	r.Register("A", NewNamed("B"))
+	r.Register("B", NewNamed("A"))
+	_, err = r.ResolveTypeString("A")
+	assert.EqualError(t, err, "unable to resolve type A, the definition is cyclic")
+}
+
+func TestRegistry_Copy(t *testing.T) {
+	r := NewRegistry()
+	c := r.Copy()
+	assert.NoError(t, c.RegisterAlias("Balance", "u64"))
+
+	def, err := c.ResolveTypeString("BalanceOf<T>")
+	assert.NoError(t, err)
+	assert.Equal(t, NewPrimitive("u64"), def)
+
+	def, err = r.ResolveTypeString("BalanceOf<T>")
+	assert.NoError(t, err)
+	assert.Equal(t, NewPrimitive("u128"), def)
+}
+
+func TestRegistry_Primitives(t *testing.T) {
+	r := NewRegistry()
+	assertRoundtrip(t, r, "bool", true, "0x01")
+	assertRoundtrip(t, r, "u8", uint8(7), "0x07")
+	assertRoundtrip(t, r, "u16", uint16(258), "0x0201")
+	assertRoundtrip(t, r, "u32", uint32(1), "0x01000000")
+	assertRoundtrip(t, r, "u64", uint64(1), "0x0100000000000000")
+	assertRoundtrip(t, r, "i8", int8(-1), "0xff")
+	assertRoundtrip(t, r, "i32", int32(-2), "0xfeffffff")
+	assertRoundtrip(t, r, "Balance", big.NewInt(1000), "0xe8030000000000000000000000000000")
+	assertRoundtrip(t, r, "i128", big.NewInt(-1), "0xffffffffffffffffffffffffffffffff")
+	assertRoundtrip(t, r, "Text", "abc", "0x0c616263")
+	assertRoundtrip(t, r, "Null", nil, "0x")
+}
+
+func TestRegistry_Compact(t *testing.T) {
+	r := NewRegistry()
+	assertRoundtrip(t, r, "Compact<u32>", big.NewInt(1), "0x04")
+	assertRoundtrip(t, r, "Compact<u32>", big.NewInt(69), "0x1501")
+	assertRoundtrip(t, r, "Compact<u32>", big.NewInt(16384), "0x02000100")
+	assertRoundtrip(t, r, "Compact<u64>", big.NewInt(1<<32), "0x070000000001")
+
+	max128, _ := new(big.Int).SetString("340282366920938463463374607431768211455", 10)
+	assertRoundtrip(t, r, "Compact<Balance>", max128, "0x33ffffffffffffffffffffffffffffffff")
+}
+
+func TestRegistry_Collections(t *testing.T) {
+	r := NewRegistry()
+	assertRoundtrip(t, r, "Vec<u8>", []byte{1, 2}, "0x080102")
+	assertRoundtrip(t, r, "Bytes", []byte{}, "0x00")
+	assertRoundtrip(t, r, "[u8; 4]", []byte{1, 2, 3, 4}, "0x01020304")
+	assertRoundtrip(t, r, "Vec<u16>", []interface{}{uint16(1), uint16(2)}, "0x0801000200")
+	assertRoundtrip(t, r, "(u8, bool)", []interface{}{uint8(1), false}, "0x0100")
+	assertRoundtrip(t, r, "Option<u8>", nil, "0x00")
+	assertRoundtrip(t, r, "Option<u8>", uint8(5), "0x0105")
+	assertRoundtrip(t, r, "Option<bool>", false, "0x02")
+	assertRoundtrip(t, r, "BTreeMap<u8, u8>", []interface{}{[]interface{}{uint8(1), uint8(2)}}, "0x040102")
+}
+
+func TestRegistry_StructsAndEnums(t *testing.T) {
+	r := NewRegistry()
+	assertRoundtrip(t, r, "Phase", map[string]interface{}{"ApplyExtrinsic": uint32(2)}, "0x0002000000")
+	assertRoundtrip(t, r, "Phase", map[string]interface{}{"Finalization": nil}, "0x01")
+	assertRoundtrip(t, r, "DispatchInfo", map[string]interface{}{
+		"weight":  uint32(10000),
+		"class":   map[string]interface{}{"Operational": nil},
+		"paysFee": true,
+	}, "0x102700000101")
+	assertRoundtrip(t, r, "DispatchResult", map[string]interface{}{
+		"Err": map[string]interface{}{"module": uint8(3), "error": uint8(1)},
+	}, "0x01010301")
+	assertRoundtrip(t, r, "IndividualExposure", map[string]interface{}{
+		"who":   make([]byte, 32),
+		"value": big.NewInt(1),
+	}, "0x000000000000000000000000000000000000000000000000000000000000000004")
+}
+
+func TestRegistry_EncodeGoValues(t *testing.T) {
+	r := NewRegistry()
+
+	bz, err := r.EncodeToBytes("(AccountId, Balance)", []interface{}{
+		types.NewAccountID(make([]byte, 32)),
+		types.NewU128(*big.NewInt(1)),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, append(make([]byte, 32), 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0), bz)
+
+	bz, err = r.EncodeToBytes("DispatchClass", "Operational")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1}, bz)
+
+	_, err = r.EncodeToBytes("u8", 256)
+	assert.EqualError(t, err, "unable to encode 256 as u8: value out of range")
+
+	_, err = r.EncodeToBytes("DispatchInfo", map[string]interface{}{"weight": 1})
+	assert.EqualError(t, err, "missing field class for struct {weight: Weight, class: DispatchClass, paysFee: bool}")
+}
+
+func TestRegistry_DecodeErrors(t *testing.T) {
+	r := NewRegistry()
+
+	_, err := r.DecodeFromBytes("Phase", []byte{5})
+	assert.EqualError(t, err, "variant with index 5 not found in enum{ApplyExtrinsic(u32), Finalization, Initialization}")
+
+	_, err = r.DecodeFromBytes("Vec<u32>", []byte{4, 1})
+	assert.Error(t, err)
+
+	// lengths exceeding the input don't allocate the claimed number of elements
+	huge := types.MustHexDecodeString("0x13ffffffffffffff7f01")
+	_, err = r.DecodeFromBytes("Vec<u8>", huge)
+	assert.EqualError(t, err, "unable to decode 9223372036854775807 bytes, only 1 bytes of input remaining")
+	_, err = r.DecodeFromBytes("Vec<u32>", huge)
+	assert.Error(t, err)
+	_, err = r.Decode(*scale.NewDecoder(struct{ io.Reader }{bytes.NewReader(huge)}), "Vec<u8>")
+	assert.Error(t, err)
+	_, err = r.Decode(*scale.NewDecoder(struct{ io.Reader }{bytes.NewReader(huge)}), "Vec<u16>")
+	assert.Error(t, err)
+
+	decoded, err := r.Decode(*scale.NewDecoder(struct{ io.Reader }{bytes.NewReader([]byte{8, 1, 2})}), "Vec<u8>")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1, 2}, decoded)
+}
+
+func TestRegistry_ForMetadata(t *testing.T) {
+	r := NewRegistry()
+
+	reg, err := r.ForMetadata(types.ExamplaryMetadataV10)
+	assert.NoError(t, err)
+	assert.True(t, reg == r)
+
+	meta := types.NewMetadataV14()
+	meta.AsMetadataV14.Lookup = types.PortableRegistry{{ID: types.NewSi1LookupTypeID(0),
+		Type: types.Si1Type{Def: types.Si1TypeDef{IsPrimitive: true, AsPrimitive: types.IsU32}}}}
+
+	reg, err = r.ForMetadata(meta)
+	assert.NoError(t, err)
+	_, ok := reg.Get("Lookup0")
+	assert.True(t, ok)
+	_, ok = r.Get("Lookup0")
+	assert.False(t, ok)
+
+	// the registry is reused for the same metadata
+	again, err := r.ForMetadata(meta)
+	assert.NoError(t, err)
+	assert.True(t, again == reg)
+
+	other, err := r.ForMetadata(types.NewMetadataV14())
+	assert.NoError(t, err)
+	assert.False(t, other == reg)
+
+	// registering types discards the cached registries
+	r.Register("Custom", NewPrimitive("u8"))
+	again, err = r.ForMetadata(meta)
+	assert.NoError(t, err)
+	assert.False(t, again == reg)
+	_, ok = again.Get("Custom")
+	assert.True(t, ok)
+}
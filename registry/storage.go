@@ -0,0 +1,261 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/zenghq3/go-substrate-rpc-client/scale"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// DecodeStorage decodes the raw storage data of the given storage entry into a generic value, using the value type
+// from the metadata. It returns nil if the data is empty, as it is for keys without a value.
+func (r *Registry) DecodeStorage(meta *types.Metadata, module, fn string, data types.StorageDataRaw) (interface{},
+	error) {
+	reg, _, value, err := r.StorageTypes(meta, module, fn)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return reg.DecodeDef(*scale.NewDecoder(bytes.NewReader(data)), value)
+}
+
+// StorageTypes returns the types of the keys and of the value of the given storage entry, together with the registry
+// that knows about these types. There are no keys for plain storage entries, one key for maps and two keys for double
+// maps, or as many keys as hashers for maps of metadata v14.
+func (r *Registry) StorageTypes(meta *types.Metadata, module, fn string) (*Registry, []*TypeDef, *TypeDef, error) {
+	entry, err := meta.FindStorageEntryMetadata(module, fn)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if s, ok := entry.(types.StorageEntryMetadataV14); ok {
+		reg, err := r.ForMetadata(meta)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		keys, value, err := reg.portableStorageTypes(s)
+		return reg, keys, value, err
+	}
+
+	var keys []types.Type
+	var value types.Type
+	switch s := entry.(type) {
+	case types.StorageFunctionMetadataV0:
+		keys, value = storageTypesV0(s.Type)
+	case types.StorageFunctionMetadataV2:
+		keys, value = storageTypesV2(s.Type)
+	case types.StorageFunctionMetadataV3:
+		keys, value = storageTypesV3(s.Type)
+	case types.StorageFunctionMetadataV4:
+		keys, value = storageTypesV4(s.Type)
+	case types.StorageFunctionMetadataV5:
+		keys, value = storageTypesV5(s.Type)
+	case types.StorageFunctionMetadataV10:
+		keys, value = storageTypesV10(s.Type)
+	case types.StorageFunctionMetadataV11:
+		keys, value = storageTypesV11(s.Type)
+	default:
+		return nil, nil, nil, fmt.Errorf("unsupported storage entry metadata %T", entry)
+	}
+
+	keyDefs := make([]*TypeDef, len(keys))
+	for i, k := range keys {
+		keyDefs[i], err = r.ParseModuleTypeString(module, string(k))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	valueDef, err := r.ParseModuleTypeString(module, string(value))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return r, keyDefs, valueDef, nil
+}
+
+func (r *Registry) portableStorageTypes(s types.StorageEntryMetadataV14) ([]*TypeDef, *TypeDef, error) {
+	if s.Type.IsPlainType {
+		return nil, NewPortable(s.Type.AsPlainType), nil
+	}
+
+	key := NewPortable(s.Type.AsMap.Key)
+	if len(s.Type.AsMap.Hashers) == 1 {
+		return []*TypeDef{key}, NewPortable(s.Type.AsMap.Value), nil
+	}
+
+	// with multiple hashers, the key is a tuple of the keys
+	def, err := r.Resolve(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if def.Kind != KindTuple || len(def.Params) != len(s.Type.AsMap.Hashers) {
+		return nil, nil, fmt.Errorf("expected a tuple of %v keys for storage %v, but got %v",
+			len(s.Type.AsMap.Hashers), s.Name, def)
+	}
+	return def.Params, NewPortable(s.Type.AsMap.Value), nil
+}
+
+func storageTypesV0(t types.StorageFunctionTypeV0) ([]types.Type, types.Type) {
+	if t.IsMap {
+		return []types.Type{t.AsMap.Key}, t.AsMap.Value
+	}
+	return nil, t.AsType
+}
+
+func storageTypesV2(t types.StorageFunctionTypeV2) ([]types.Type, types.Type) {
+	if t.IsMap {
+		return []types.Type{t.AsMap.Key}, t.AsMap.Value
+	}
+	return nil, t.AsType
+}
+
+func storageTypesV3(t types.StorageFunctionTypeV3) ([]types.Type, types.Type) {
+	switch {
+	case t.IsMap:
+		return []types.Type{t.AsMap.Key}, t.AsMap.Value
+	case t.IsDoubleMap:
+		return []types.Type{t.AsDoubleMap.Key1, t.AsDoubleMap.Key2}, t.AsDoubleMap.Value
+	default:
+		return nil, t.AsType
+	}
+}
+
+func storageTypesV4(t types.StorageFunctionTypeV4) ([]types.Type, types.Type) {
+	switch {
+	case t.IsMap:
+		return []types.Type{t.AsMap.Key}, t.AsMap.Value
+	case t.IsDoubleMap:
+		return []types.Type{t.AsDoubleMap.Key1, t.AsDoubleMap.Key2}, t.AsDoubleMap.Value
+	default:
+		return nil, t.AsType
+	}
+}
+
+func storageTypesV5(t types.StorageFunctionTypeV5) ([]types.Type, types.Type) {
+	switch {
+	case t.IsMap:
+		return []types.Type{t.AsMap.Key}, t.AsMap.Value
+	case t.IsDoubleMap:
+		return []types.Type{t.AsDoubleMap.Key1, t.AsDoubleMap.Key2}, t.AsDoubleMap.Value
+	default:
+		return nil, t.AsType
+	}
+}
+
+func storageTypesV10(t types.StorageFunctionTypeV10) ([]types.Type, types.Type) {
+	switch {
+	case t.IsMap:
+		return []types.Type{t.AsMap.Key}, t.AsMap.Value
+	case t.IsDoubleMap:
+		return []types.Type{t.AsDoubleMap.Key1, t.AsDoubleMap.Key2}, t.AsDoubleMap.Value
+	default:
+		return nil, t.AsType
+	}
+}
+
+func storageTypesV11(t types.StorageFunctionTypeV11) ([]types.Type, types.Type) {
+	switch {
+	case t.IsMap:
+		return []types.Type{t.AsMap.Key}, t.AsMap.Value
+	case t.IsDoubleMap:
+		return []types.Type{t.AsDoubleMap.Key1, t.AsDoubleMap.Key2}, t.AsDoubleMap.Value
+	default:
+		return nil, t.AsType
+	}
+}
+
+// DecodedStorageKey is a generically decoded storage key
+type DecodedStorageKey struct {
+	Module string
+	Method string
+	// Keys holds the decoded map keys. It is nil for keys hashed with a hasher that does not retain the plain key.
+	Keys []interface{}
+	// Hashes holds the hash of every map key, it is empty for the identity hasher
+	Hashes [][]byte
+}
+
+// DecodeStorageKey finds the storage entry the given storage key belongs to and decodes the map keys into generic
+// values, as far as they can be recovered from the key. This requires metadata v9 or later.
+func (r *Registry) DecodeStorageKey(meta *types.Metadata, key types.StorageKey) (*DecodedStorageKey, error) {
+	parts, err := types.SplitStorageKey(meta, key)
+	if err != nil {
+		return nil, err
+	}
+
+	reg, keyDefs, _, err := r.StorageTypes(meta, string(parts.Module), string(parts.Method))
+	if err != nil {
+		return nil, err
+	}
+	if len(keyDefs) != len(parts.Hashers) {
+		return nil, fmt.Errorf("expected %v key types for storage %v.%v, but got %v", len(parts.Hashers),
+			parts.Module, parts.Method, len(keyDefs))
+	}
+
+	decoded := DecodedStorageKey{
+		Module: string(parts.Module),
+		Method: string(parts.Method),
+		Keys:   make([]interface{}, len(keyDefs)),
+		Hashes: make([][]byte, len(keyDefs)),
+	}
+	err = parts.DecodeKeysWith(func(i int, hash []byte, decoder scale.Decoder) error {
+		decoded.Hashes[i] = hash
+		if !parts.Hashers[i].Concat {
+			return nil
+		}
+		v, err := reg.DecodeDef(decoder, keyDefs[i])
+		if err != nil {
+			return fmt.Errorf("unable to decode key %v of %v.%v of type %v: %v", i, parts.Module, parts.Method,
+				keyDefs[i], err)
+		}
+		decoded.Keys[i] = v
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &decoded, nil
+}
+
+// CreateStorageKey creates the storage key of the given storage entry, encoding the map keys according to the key
+// types in the metadata. The number of keys must match the storage entry: none for plain storage entries, one for
+// maps, two for double maps, or as many as hashers for maps of metadata v14.
+func (r *Registry) CreateStorageKey(meta *types.Metadata, module, fn string, keys ...interface{}) (types.StorageKey,
+	error) {
+	reg, keyDefs, _, err := r.StorageTypes(meta, module, fn)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) != len(keyDefs) {
+		return nil, fmt.Errorf("%v.%v requires %v keys, but got %v", module, fn, len(keyDefs), len(keys))
+	}
+
+	args := make([][]byte, len(keys))
+	for i, k := range keys {
+		var buffer = bytes.Buffer{}
+		err = reg.EncodeDef(*scale.NewEncoder(&buffer), keyDefs[i], k)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode key %v of %v.%v as %v: %v", i, module, fn, keyDefs[i], err)
+		}
+		args[i] = buffer.Bytes()
+	}
+
+	return types.CreateStorageKeyArgs(meta, module, fn, args...)
+}
@@ -0,0 +1,106 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/zenghq3/go-substrate-rpc-client/registry"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+func TestRegistry_DecodeStorageKey(t *testing.T) {
+	key, err := types.CreateStorageKey(types.ExamplaryMetadataV10, "Session", "NextKeys",
+		types.MustHexDecodeString("0x343a73657373696f6e3a6b657973"),
+		types.MustHexDecodeString("0xd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d"))
+	assert.NoError(t, err)
+
+	decoded, err := NewRegistry().DecodeStorageKey(types.ExamplaryMetadataV10, key)
+	assert.NoError(t, err)
+	assert.Equal(t, &DecodedStorageKey{
+		Module: "Session",
+		Method: "NextKeys",
+		Keys:   []interface{}{[]byte(":session:keys"), nil},
+		Hashes: [][]byte{key[32:40], key[54:]},
+	}, decoded)
+}
+
+func TestRegistry_DecodeStorageKey_Plain(t *testing.T) {
+	key, err := types.CreateStorageKey(types.ExamplaryMetadataV10, "Timestamp", "Now", nil, nil)
+	assert.NoError(t, err)
+
+	decoded, err := NewRegistry().DecodeStorageKey(types.ExamplaryMetadataV10, key)
+	assert.NoError(t, err)
+	assert.Equal(t, "Timestamp", decoded.Module)
+	assert.Equal(t, "Now", decoded.Method)
+	assert.Empty(t, decoded.Keys)
+}
+
+func TestRegistry_CreateStorageKey(t *testing.T) {
+	r := NewRegistry()
+	alice := types.MustHexDecodeString("0xd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d")
+
+	key, err := r.CreateStorageKey(types.ExamplaryMetadataV10, "System", "AccountNonce", types.NewAccountID(alice))
+	assert.NoError(t, err)
+	expected, err := types.CreateStorageKey(types.ExamplaryMetadataV10, "System", "AccountNonce", alice, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, key)
+
+	_, err = r.CreateStorageKey(types.ExamplaryMetadataV10, "System", "AccountNonce")
+	assert.EqualError(t, err, "System.AccountNonce requires 1 keys, but got 0")
+
+	_, err = r.CreateStorageKey(types.ExamplaryMetadataV10, "System", "AccountNonce", "alice")
+	assert.Error(t, err)
+}
+
+func TestRegistry_CreateStorageKey_NMap(t *testing.T) {
+	id := types.NewSi1LookupTypeID
+
+	meta := types.NewMetadataV14()
+	meta.AsMetadataV14.Lookup = types.PortableRegistry{
+		{ID: id(0), Type: types.Si1Type{Def: types.Si1TypeDef{IsPrimitive: true, AsPrimitive: types.IsU32}}},
+		{ID: id(1), Type: types.Si1Type{Def: types.Si1TypeDef{IsTuple: true,
+			AsTuple: types.Si1TypeDefTuple{id(0), id(0), id(0)}}}},
+	}
+	meta.AsMetadataV14.Pallets = []types.PalletMetadataV14{{
+		Name:       "Assets",
+		HasStorage: true,
+		Storage: types.StorageMetadataV14{Prefix: "Assets", Items: []types.StorageEntryMetadataV14{
+			{Name: "Approvals", Type: types.StorageEntryTypeV14{IsMap: true, AsMap: types.MapTypeV14{
+				Hashers: []types.StorageHasherV11{{IsBlake2_128Concat: true}, {IsTwox64Concat: true},
+					{IsIdentity: true}},
+				Key:   id(1),
+				Value: id(0),
+			}}},
+		}},
+	}}
+
+	key, err := NewRegistry().CreateStorageKey(meta, "Assets", "Approvals", 1, uint32(2), types.U32(3))
+	assert.NoError(t, err)
+
+	parts, err := types.SplitStorageKey(meta, key)
+	assert.NoError(t, err)
+	var a, b, c types.U32
+	assert.NoError(t, parts.DecodeKeys(&a, &b, &c))
+	assert.Equal(t, []types.U32{1, 2, 3}, []types.U32{a, b, c})
+	assert.Len(t, key, 32+16+4+8+4+4)
+
+	_, err = NewRegistry().CreateStorageKey(meta, "Assets", "Approvals", 1, 2)
+	assert.EqualError(t, err, "Assets.Approvals requires 3 keys, but got 2")
+}
@@ -0,0 +1,238 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TypeKind describes the shape of a TypeDef
+type TypeKind uint8
+
+const (
+	// KindNamed is a reference to a type registered by name, such as AccountId or Balance
+	KindNamed TypeKind = iota
+	// KindPrimitive is one of the primitives bool, str, u8 - u256 and i8 - i256
+	KindPrimitive
+	// KindVec is a length prefixed list of Params[0]
+	KindVec
+	// KindOption is an optional Params[0]
+	KindOption
+	// KindCompact is the compact encoding of the number type Params[0]
+	KindCompact
+	// KindTuple is a list of Params with different types, the empty tuple is Null
+	KindTuple
+	// KindArray is a list of Length elements of Params[0] without length prefix
+	KindArray
+	// KindStruct is a list of named Fields
+	KindStruct
+	// KindEnum is one of the Variants, prefixed with the variant index
+	KindEnum
+	// KindSet is a bit field of Length bits with the named Flags
+	KindSet
+)
+
+func (k TypeKind) String() string {
+	switch k {
+	case KindNamed:
+		return "named"
+	case KindPrimitive:
+		return "primitive"
+	case KindVec:
+		return "vec"
+	case KindOption:
+		return "option"
+	case KindCompact:
+		return "compact"
+	case KindTuple:
+		return "tuple"
+	case KindArray:
+		return "array"
+	case KindStruct:
+		return "struct"
+	case KindEnum:
+		return "enum"
+	case KindSet:
+		return "set"
+	default:
+		return fmt.Sprintf("unknown kind %d", k)
+	}
+}
+
+// TypeDef is the definition of a type, either parsed from a type string in metadata such as
+// `Vec<(T::AccountId, Compact<BalanceOf<T>>)>` or registered in a Registry
+type TypeDef struct {
+	Kind     TypeKind
+	Name     string
+	Params   []*TypeDef
+	Length   int
+	Fields   []Field
+	Variants []Variant
+	Flags    []SetFlag
+}
+
+// Field is a named field of a struct
+type Field struct {
+	Name string
+	Type *TypeDef
+}
+
+// Variant is a variant of an enum. Type is nil for variants without a value.
+type Variant struct {
+	Index uint8
+	Name  string
+	Type  *TypeDef
+}
+
+// SetFlag is a named flag of a set, Value is the bit mask of the flag
+type SetFlag struct {
+	Name  string
+	Value uint64
+}
+
+// NewNamed creates a reference to the type registered under the given name
+func NewNamed(name string) *TypeDef {
+	return &TypeDef{Kind: KindNamed, Name: name}
+}
+
+// NewPrimitive creates a primitive type, name must be one of bool, str, u8 - u256 or i8 - i256
+func NewPrimitive(name string) *TypeDef {
+	return &TypeDef{Kind: KindPrimitive, Name: name}
+}
+
+// NewVec creates a length prefixed list of elements of the given type
+func NewVec(elem *TypeDef) *TypeDef {
+	return &TypeDef{Kind: KindVec, Params: []*TypeDef{elem}}
+}
+
+// NewOption creates an optional value of the given type
+func NewOption(elem *TypeDef) *TypeDef {
+	return &TypeDef{Kind: KindOption, Params: []*TypeDef{elem}}
+}
+
+// NewCompact creates the compact encoding of the given number type
+func NewCompact(elem *TypeDef) *TypeDef {
+	return &TypeDef{Kind: KindCompact, Params: []*TypeDef{elem}}
+}
+
+// NewTuple creates a tuple of the given types, an empty tuple is Null
+func NewTuple(elems ...*TypeDef) *TypeDef {
+	return &TypeDef{Kind: KindTuple, Params: elems}
+}
+
+// NewArray creates a fixed length list of elements of the given type
+func NewArray(elem *TypeDef, length int) *TypeDef {
+	return &TypeDef{Kind: KindArray, Params: []*TypeDef{elem}, Length: length}
+}
+
+// NewStruct creates a struct with the given fields
+func NewStruct(fields ...Field) *TypeDef {
+	return &TypeDef{Kind: KindStruct, Fields: fields}
+}
+
+// NewEnum creates an enum with the given variants
+func NewEnum(variants ...Variant) *TypeDef {
+	return &TypeDef{Kind: KindEnum, Variants: variants}
+}
+
+// NewSimpleEnum creates an enum where none of the variants has a value, indexed in the given order
+func NewSimpleEnum(names ...string) *TypeDef {
+	variants := make([]Variant, len(names))
+	for i, n := range names {
+		variants[i] = Variant{Index: uint8(i), Name: n}
+	}
+	return NewEnum(variants...)
+}
+
+// NewSet creates a bit field of the given length in bits with the given flags
+func NewSet(bitLength int, flags ...SetFlag) *TypeDef {
+	return &TypeDef{Kind: KindSet, Length: bitLength, Flags: flags}
+}
+
+// FindVariantByIndex returns the variant of an enum with the given index
+func (t *TypeDef) FindVariantByIndex(index uint8) (Variant, error) {
+	for _, v := range t.Variants {
+		if v.Index == index {
+			return v, nil
+		}
+	}
+	return Variant{}, fmt.Errorf("variant with index %v not found in %v", index, t)
+}
+
+// FindVariantByName returns the variant of an enum with the given name
+func (t *TypeDef) FindVariantByName(name string) (Variant, error) {
+	for _, v := range t.Variants {
+		if v.Name == name {
+			return v, nil
+		}
+	}
+	return Variant{}, fmt.Errorf("variant %v not found in %v", name, t)
+}
+
+// String returns a type string for the definition, e.g. `Vec<(AccountId, Compact<Balance>)>`
+func (t *TypeDef) String() string {
+	if t == nil {
+		return "<nil>"
+	}
+
+	switch t.Kind {
+	case KindNamed, KindPrimitive:
+		return t.Name
+	case KindVec:
+		return "Vec<" + t.Params[0].String() + ">"
+	case KindOption:
+		return "Option<" + t.Params[0].String() + ">"
+	case KindCompact:
+		return "Compact<" + t.Params[0].String() + ">"
+	case KindTuple:
+		return "(" + joinTypes(t.Params) + ")"
+	case KindArray:
+		return fmt.Sprintf("[%v; %v]", t.Params[0], t.Length)
+	case KindStruct:
+		fields := make([]string, len(t.Fields))
+		for i, f := range t.Fields {
+			fields[i] = f.Name + ": " + f.Type.String()
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	case KindEnum:
+		variants := make([]string, len(t.Variants))
+		for i, v := range t.Variants {
+			variants[i] = v.Name
+			if v.Type != nil {
+				variants[i] += "(" + v.Type.String() + ")"
+			}
+		}
+		return "enum{" + strings.Join(variants, ", ") + "}"
+	case KindSet:
+		flags := make([]string, len(t.Flags))
+		for i, f := range t.Flags {
+			flags[i] = f.Name
+		}
+		return "set{" + strings.Join(flags, ", ") + "}"
+	default:
+		return t.Kind.String()
+	}
+}
+
+func joinTypes(types []*TypeDef) string {
+	s := make([]string, len(types))
+	for i, t := range types {
+		s[i] = t.String()
+	}
+	return strings.Join(s, ", ")
+}
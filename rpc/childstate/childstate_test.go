@@ -0,0 +1,145 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package childstate
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/zenghq3/go-substrate-rpc-client/client"
+	"github.com/zenghq3/go-substrate-rpc-client/rpcmocksrv"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+var childState *ChildState
+
+func TestMain(m *testing.M) {
+	s := rpcmocksrv.New()
+	err := s.RegisterName("childstate", &mockSrv)
+	if err != nil {
+		panic(err)
+	}
+	err = s.RegisterName("chain", &mockSrv)
+	if err != nil {
+		panic(err)
+	}
+
+	cl, err := client.Connect(s.URL)
+	if err != nil {
+		panic(err)
+	}
+	childState = NewChildState(cl)
+
+	os.Exit(m.Run())
+}
+
+// MockSrv holds data and methods exposed by the RPC Mock Server used in integration tests
+type MockSrv struct {
+	blockHashLatest types.Hash
+	childStorageKey types.StorageKey
+	keys            []string          // sorted hex keys of the child storage
+	values          map[string]string // hex values by hex key
+	storageHashHex  string
+	hashes          []string // the block hashes requested
+}
+
+func (s *MockSrv) GetKeys(childStorageKey, prefix string, hash *string) []string {
+	return s.GetKeysPaged(childStorageKey, prefix, uint32(len(s.keys)), nil, hash)
+}
+
+func (s *MockSrv) GetKeysPaged(childStorageKey, prefix string, count uint32, startKey *string, hash *string) []string {
+	s.recordHash(hash)
+	if childStorageKey != s.childStorageKey.Hex() {
+		return []string{}
+	}
+
+	keys := []string{}
+	for _, k := range s.keys {
+		if strings.HasPrefix(k, prefix) && (startKey == nil || k > *startKey) && len(keys) < int(count) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func (s *MockSrv) GetStorage(childStorageKey, key string, hash *string) *string {
+	s.recordHash(hash)
+	if childStorageKey != s.childStorageKey.Hex() {
+		return nil
+	}
+	value, ok := s.values[key]
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+func (s *MockSrv) GetStorageEntries(childStorageKey string, keys []string, hash *string) []*string {
+	values := make([]*string, len(keys))
+	for i, key := range keys {
+		values[i] = s.GetStorage(childStorageKey, key, hash)
+	}
+	return values
+}
+
+func (s *MockSrv) GetStorageHash(childStorageKey, key string, hash *string) string {
+	return s.storageHashHex
+}
+
+func (s *MockSrv) GetStorageSize(childStorageKey, key string, hash *string) *types.U64 {
+	value := s.GetStorage(childStorageKey, key, hash)
+	if value == nil {
+		return nil
+	}
+	size := types.U64(len(types.MustHexDecodeString(*value)))
+	return &size
+}
+
+func (s *MockSrv) GetBlockHash(height *uint64) string {
+	return s.blockHashLatest.Hex()
+}
+
+func (s *MockSrv) recordHash(hash *string) {
+	if hash == nil {
+		s.hashes = append(s.hashes, "")
+		return
+	}
+	s.hashes = append(s.hashes, *hash)
+}
+
+// mockSrv holds the child storage used in tests
+var mockSrv = newMockSrv(10)
+
+// newMockSrv returns a mock of a default child storage holding n entries with the keys 0x0100, 0x0101, … and the U32
+// values 0, 10, 20, … for n of at most 25
+func newMockSrv(n int) MockSrv {
+	s := MockSrv{
+		blockHashLatest: types.NewHash([]byte{0x01, 0x02, 0x03}),
+		childStorageKey: types.NewDefaultChildStorageKey([]byte{0xaa, 0xbb}),
+		values:          make(map[string]string),
+		storageHashHex:  types.NewHash([]byte{0x0a, 0x0b, 0x0c}).Hex(),
+	}
+	for i := 0; i < n; i++ {
+		key := types.StorageKey{0x01, byte(i)}.Hex()
+		s.keys = append(s.keys, key)
+		s.values[key] = types.HexEncodeToString([]byte{byte(i * 10), 0, 0, 0})
+	}
+	sort.Strings(s.keys)
+	return s
+}
@@ -0,0 +1,56 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package childstate
+
+import (
+	"github.com/zenghq3/go-substrate-rpc-client/client"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// GetKeys retreives the keys with the given prefix of a specific child storage
+func (c *ChildState) GetKeys(childStorageKey, prefix types.StorageKey, blockHash types.Hash) ([]types.StorageKey,
+	error) {
+	return c.getKeys(childStorageKey, prefix, &blockHash)
+}
+
+// GetKeysLatest retreives the keys with the given prefix of a specific child storage for the latest block height
+func (c *ChildState) GetKeysLatest(childStorageKey, prefix types.StorageKey) ([]types.StorageKey, error) {
+	return c.getKeys(childStorageKey, prefix, nil)
+}
+
+func (c *ChildState) getKeys(childStorageKey, prefix types.StorageKey, blockHash *types.Hash) ([]types.StorageKey,
+	error) {
+	var res []string
+	err := client.CallWithBlockHash(c.client, &res, "childstate_getKeys", blockHash, childStorageKey.Hex(),
+		prefix.Hex())
+	if err != nil {
+		return nil, err
+	}
+	return decodeKeys(res)
+}
+
+// decodeKeys decodes hex encoded storage keys
+func decodeKeys(res []string) ([]types.StorageKey, error) {
+	keys := make([]types.StorageKey, len(res))
+	for i, r := range res {
+		err := types.DecodeFromHexString(r, &keys[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
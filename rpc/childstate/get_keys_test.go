@@ -0,0 +1,41 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package childstate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+func TestChildState_GetKeysLatest(t *testing.T) {
+	keys, err := childState.GetKeysLatest(mockSrv.childStorageKey, types.StorageKey{0x01, 0x02})
+	assert.NoError(t, err)
+	assert.Equal(t, []types.StorageKey{{0x01, 0x02}}, keys)
+}
+
+func TestChildState_GetKeys(t *testing.T) {
+	keys, err := childState.GetKeys(mockSrv.childStorageKey, types.StorageKey{0x01}, mockSrv.blockHashLatest)
+	assert.NoError(t, err)
+	assert.Len(t, keys, len(mockSrv.keys))
+
+	keys, err = childState.GetKeys(types.NewDefaultChildStorageKey([]byte{0x01}), types.StorageKey{0x01},
+		mockSrv.blockHashLatest)
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+}
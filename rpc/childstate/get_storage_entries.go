@@ -0,0 +1,65 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package childstate
+
+import (
+	"github.com/zenghq3/go-substrate-rpc-client/client"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// GetStorageEntries retreives the values of the given keys of a specific child storage with a single request. The
+// result holds one entry per key, in the order of the keys, without data for keys that are not set.
+func (c *ChildState) GetStorageEntries(childStorageKey types.StorageKey, keys []types.StorageKey,
+	blockHash types.Hash) ([]types.KeyValueOption, error) {
+	return c.getStorageEntries(childStorageKey, keys, &blockHash)
+}
+
+// GetStorageEntriesLatest retreives the values of the given keys of a specific child storage for the latest block
+// height with a single request
+func (c *ChildState) GetStorageEntriesLatest(childStorageKey types.StorageKey, keys []types.StorageKey) (
+	[]types.KeyValueOption, error) {
+	return c.getStorageEntries(childStorageKey, keys, nil)
+}
+
+func (c *ChildState) getStorageEntries(childStorageKey types.StorageKey, keys []types.StorageKey,
+	blockHash *types.Hash) ([]types.KeyValueOption, error) {
+	hexKeys := make([]string, len(keys))
+	for i, key := range keys {
+		hexKeys[i] = key.Hex()
+	}
+
+	var res []*string
+	err := client.CallWithBlockHash(c.client, &res, "childstate_getStorageEntries", blockHash, childStorageKey.Hex(),
+		hexKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]types.KeyValueOption, len(keys))
+	for i, key := range keys {
+		entries[i].StorageKey = key
+		if i >= len(res) || res[i] == nil {
+			continue
+		}
+		entries[i].HasStorageData = true
+		entries[i].StorageData, err = types.HexDecodeString(*res[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,43 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package childstate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+func TestChildState_GetStorageEntriesLatest(t *testing.T) {
+	keys := []types.StorageKey{{0x01, 0x01}, {0x02}, {0x01, 0x02}}
+	entries, err := childState.GetStorageEntriesLatest(mockSrv.childStorageKey, keys)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.KeyValueOption{
+		{StorageKey: keys[0], HasStorageData: true, StorageData: types.StorageDataRaw{0x0a, 0x00, 0x00, 0x00}},
+		{StorageKey: keys[1]},
+		{StorageKey: keys[2], HasStorageData: true, StorageData: types.StorageDataRaw{0x14, 0x00, 0x00, 0x00}},
+	}, entries)
+}
+
+func TestChildState_GetStorageEntries(t *testing.T) {
+	entries, err := childState.GetStorageEntries(mockSrv.childStorageKey, []types.StorageKey{{0x01, 0x00}},
+		mockSrv.blockHashLatest)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.KeyValueOption{{StorageKey: types.StorageKey{0x01, 0x00}, HasStorageData: true,
+		StorageData: types.StorageDataRaw{0x00, 0x00, 0x00, 0x00}}}, entries)
+}
@@ -0,0 +1,42 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package childstate
+
+import (
+	"github.com/zenghq3/go-substrate-rpc-client/client"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// GetStorageSize retreives the child storage size for the given key
+func (c *ChildState) GetStorageSize(childStorageKey, key types.StorageKey, blockHash types.Hash) (types.U64, error) {
+	return c.getStorageSize(childStorageKey, key, &blockHash)
+}
+
+// GetStorageSizeLatest retreives the child storage size for the given key for the latest block height
+func (c *ChildState) GetStorageSizeLatest(childStorageKey, key types.StorageKey) (types.U64, error) {
+	return c.getStorageSize(childStorageKey, key, nil)
+}
+
+func (c *ChildState) getStorageSize(childStorageKey, key types.StorageKey, blockHash *types.Hash) (types.U64, error) {
+	var res types.U64
+	err := client.CallWithBlockHash(c.client, &res, "childstate_getStorageSize", blockHash, childStorageKey.Hex(),
+		key.Hex())
+	if err != nil {
+		return 0, err
+	}
+	return res, err
+}
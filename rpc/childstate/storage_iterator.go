@@ -0,0 +1,122 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package childstate
+
+import (
+	"context"
+
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/chain"
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/internal/paging"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// DefaultStorageIteratorPageSize is the number of keys a StorageIterator fetches per request unless PageSize is set
+const DefaultStorageIteratorPageSize = paging.DefaultPageSize
+
+// StorageEntry is an entry of a child storage, as returned by a StorageIterator
+type StorageEntry struct {
+	Key   types.StorageKey
+	Value types.StorageDataRaw
+}
+
+// DecodeValue decodes the value of the entry into target
+func (e StorageEntry) DecodeValue(target interface{}) error {
+	return types.DecodeFromBytes(e.Value, target)
+}
+
+// StorageIterator iterates over all entries with a given prefix of a child storage at a pinned block. Keys are fetched
+// page by page with childstate_getKeysPaged and the values of each page with a single childstate_getStorageEntries
+// request. Requests are only made when all entries fetched so far have been consumed.
+//
+// Use it like this:
+//
+//	childKey := types.NewDefaultChildStorageKey(trieID)
+//	it, err := api.RPC.ChildState.IterateLatest(ctx, childKey, nil)
+//	if err != nil {
+//		return err
+//	}
+//	for it.Next() {
+//		entry := it.Entry()
+//		...
+//	}
+//	if it.Err() != nil {
+//		return it.Err()
+//	}
+//
+// The embedded iterator provides Next, Err and the PageSize, the number of keys fetched per request, which may be
+// changed before the first call to Next.
+type StorageIterator struct {
+	*paging.Iterator
+
+	childState      *ChildState
+	childStorageKey types.StorageKey
+	prefix          types.StorageKey
+	blockHash       types.Hash
+}
+
+// Iterate returns an iterator over all entries with the given prefix of a specific child storage at the given block.
+// Pass an empty prefix to iterate over all entries. The iteration stops with the error of the context once it is done.
+func (c *ChildState) Iterate(ctx context.Context, childStorageKey, prefix types.StorageKey,
+	blockHash types.Hash) *StorageIterator {
+	it := &StorageIterator{childState: c, childStorageKey: childStorageKey, prefix: prefix, blockHash: blockHash}
+	it.Iterator = paging.NewIterator(ctx, it.listKeys, it.fetchEntries)
+	return it
+}
+
+// IterateLatest returns an iterator over all entries with the given prefix of a specific child storage, pinned to the
+// latest block
+func (c *ChildState) IterateLatest(ctx context.Context, childStorageKey, prefix types.StorageKey) (*StorageIterator,
+	error) {
+	blockHash, err := chain.NewChain(c.client).GetBlockHashLatest()
+	if err != nil {
+		return nil, err
+	}
+	return c.Iterate(ctx, childStorageKey, prefix, blockHash), nil
+}
+
+// Entry returns the current entry
+func (it *StorageIterator) Entry() StorageEntry {
+	entry, _ := it.Iterator.Entry().(StorageEntry)
+	return entry
+}
+
+// BlockHash returns the hash of the block the iteration is pinned to
+func (it *StorageIterator) BlockHash() types.Hash {
+	return it.blockHash
+}
+
+// listKeys lists the keys of the next page with childstate_getKeysPaged
+func (it *StorageIterator) listKeys(pageSize uint32, startKey types.StorageKey) ([]types.StorageKey, error) {
+	return it.childState.getKeysPaged(it.childStorageKey, it.prefix, pageSize, startKey, &it.blockHash)
+}
+
+// fetchEntries fetches the values of the keys with a single childstate_getStorageEntries request
+func (it *StorageIterator) fetchEntries(keys []types.StorageKey) ([]interface{}, error) {
+	values, err := it.childState.getStorageEntries(it.childStorageKey, keys, &it.blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []interface{}
+	for _, v := range values {
+		if !v.HasStorageData {
+			continue
+		}
+		entries = append(entries, StorageEntry{Key: v.StorageKey, Value: v.StorageData})
+	}
+	return entries, nil
+}
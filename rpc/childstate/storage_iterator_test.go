@@ -0,0 +1,67 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package childstate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+func TestChildState_IterateLatest(t *testing.T) {
+	mockSrv.hashes = nil
+	it, err := childState.IterateLatest(context.Background(), mockSrv.childStorageKey, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, mockSrv.blockHashLatest, it.BlockHash())
+	it.PageSize = 3
+
+	var values []types.U32
+	for it.Next() {
+		var value types.U32
+		assert.NoError(t, it.Entry().DecodeValue(&value))
+		assert.Equal(t, types.StorageKey{0x01, byte(value / 10)}, it.Entry().Key)
+		values = append(values, value)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []types.U32{0, 10, 20, 30, 40, 50, 60, 70, 80, 90}, values)
+
+	// 4 pages of keys, and the values of all 10 keys, each recorded by the mock
+	assert.Len(t, mockSrv.hashes, 4+10)
+	for _, hash := range mockSrv.hashes {
+		assert.Equal(t, mockSrv.blockHashLatest.Hex(), hash)
+	}
+}
+
+func TestChildState_Iterate_Prefix(t *testing.T) {
+	it := childState.Iterate(context.Background(), mockSrv.childStorageKey, types.StorageKey{0x01, 0x05},
+		mockSrv.blockHashLatest)
+	assert.True(t, it.Next())
+	assert.Equal(t, types.StorageKey{0x01, 0x05}, it.Entry().Key)
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Err())
+}
+
+func TestChildState_Iterate_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	it := childState.Iterate(ctx, mockSrv.childStorageKey, nil, mockSrv.blockHashLatest)
+	assert.True(t, it.Next())
+	cancel()
+	assert.False(t, it.Next())
+	assert.Equal(t, context.Canceled, it.Err())
+}
@@ -0,0 +1,147 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package childstate
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/chain"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// StorageSubscription is a subscription to the values of keys of a child storage, established through SubscribeStorage
+type StorageSubscription struct {
+	heads    *chain.NewHeadsSubscription
+	channel  chan types.StorageChangeSet
+	err      chan error
+	quit     chan struct{}
+	quitOnce sync.Once // ensures quit is closed once
+}
+
+// Chan returns the subscription channel.
+//
+// The channel is closed when Unsubscribe is called on the subscription or the subscription ended due to an error.
+func (s *StorageSubscription) Chan() <-chan types.StorageChangeSet {
+	return s.channel
+}
+
+// Err returns the subscription error channel. The intended use of Err is to schedule resubscription when the client
+// connection is closed unexpectedly or a child storage query failed.
+//
+// The error channel receives a value when the subscription has ended due to an error.
+func (s *StorageSubscription) Err() <-chan error {
+	return s.err
+}
+
+// Unsubscribe unsubscribes the notification and closes the subscription channel.
+// It can safely be called more than once.
+func (s *StorageSubscription) Unsubscribe() {
+	s.quitOnce.Do(func() {
+		close(s.quit)
+		s.heads.Unsubscribe()
+	})
+}
+
+// SubscribeStorage subscribes the values of the given keys of a specific child storage, returning a subscription that
+// will receive a change set for every new best block changing any of the values. The first change set holds the values
+// of all keys at the best block when subscribing.
+//
+// Nodes do not notify about changes of child storages, so the subscription follows new best blocks and fetches the
+// values of all keys with a single childstate_getStorageEntries request per block.
+func (c *ChildState) SubscribeStorage(childStorageKey types.StorageKey, keys []types.StorageKey) (
+	*StorageSubscription, error) {
+	heads, err := chain.NewChain(c.client).SubscribeNewHeads()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &StorageSubscription{
+		heads:   heads,
+		channel: make(chan types.StorageChangeSet),
+		err:     make(chan error, 1),
+		quit:    make(chan struct{}),
+	}
+	go s.listen(c, childStorageKey, keys)
+	return s, nil
+}
+
+// listen fetches the values of the keys for every new head and sends the changed values. The heads are unsubscribed
+// when listening stops.
+func (s *StorageSubscription) listen(c *ChildState, childStorageKey types.StorageKey, keys []types.StorageKey) {
+	defer close(s.channel)
+	defer s.heads.Unsubscribe()
+
+	var last []types.KeyValueOption
+	for {
+		var head types.Header
+		var ok bool
+		select {
+		case <-s.quit:
+			return
+		case err := <-s.heads.Err():
+			if err != nil {
+				s.err <- err
+			}
+			return
+		case head, ok = <-s.heads.Chan():
+			if !ok {
+				return
+			}
+		}
+
+		// new heads include fork blocks, so the hash is computed from the header rather than looked up by number
+		blockHash, err := types.GetHash(head)
+		if err != nil {
+			s.err <- err
+			return
+		}
+		values, err := c.getStorageEntries(childStorageKey, keys, &blockHash)
+		if err != nil {
+			s.err <- err
+			return
+		}
+
+		changes := changedValues(last, values)
+		last = values
+		if len(changes) == 0 {
+			continue
+		}
+
+		select {
+		case <-s.quit:
+			return
+		case s.channel <- types.StorageChangeSet{Block: blockHash, Changes: changes}:
+		}
+	}
+}
+
+// changedValues returns the values that differ from the previous values of the same keys, or all values if there are
+// no previous values
+func changedValues(previous, values []types.KeyValueOption) []types.KeyValueOption {
+	if previous == nil {
+		return values
+	}
+	var changes []types.KeyValueOption
+	for i, v := range values {
+		p := previous[i]
+		if p.HasStorageData != v.HasStorageData || !bytes.Equal(p.StorageData, v.StorageData) {
+			changes = append(changes, v)
+		}
+	}
+	return changes
+}
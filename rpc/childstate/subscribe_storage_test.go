@@ -0,0 +1,131 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package childstate
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenghq3/go-substrate-rpc-client/client"
+	gethrpc "github.com/zenghq3/go-substrate-rpc-client/gethrpc"
+	"github.com/zenghq3/go-substrate-rpc-client/rpcmocksrv"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// headsMockSrv notifies scripted new heads and serves a child storage value per block hash
+type headsMockSrv struct {
+	heads        []types.Header
+	values       map[types.Hash]string
+	unsubscribed chan struct{}
+}
+
+func (s *headsMockSrv) SubscribeNewHead(ctx context.Context) (*gethrpc.Subscription, error) {
+	notifier, _ := gethrpc.NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+	go func() {
+		for _, head := range s.heads {
+			_ = notifier.Notify(sub.ID, head)
+		}
+		<-sub.Err()
+		close(s.unsubscribed)
+	}()
+	return sub, nil
+}
+
+// GetBlockHash returns the hash of the first head of the given height, which is the canonical one
+func (s *headsMockSrv) GetBlockHash(height *uint64) (string, error) {
+	for _, head := range s.heads {
+		if uint64(head.Number) == *height {
+			hash, err := types.GetHash(head)
+			return hash.Hex(), err
+		}
+	}
+	return "", fmt.Errorf("unknown height %v", *height)
+}
+
+func (s *headsMockSrv) GetStorageEntries(childStorageKey string, keys []string, hash string) ([]*string, error) {
+	value, ok := s.values[types.NewHash(types.MustHexDecodeString(hash))]
+	if !ok {
+		return nil, fmt.Errorf("unknown block %v", hash)
+	}
+	return []*string{&value}, nil
+}
+
+func TestChildState_SubscribeStorage(t *testing.T) {
+	heads := []types.Header{
+		{Number: 1, StateRoot: types.NewHash([]byte{0x01})},
+		// a fork of the first block
+		{Number: 1, StateRoot: types.NewHash([]byte{0x02})},
+		// a block the storage of which is not available
+		{Number: 2},
+	}
+	hashes := make([]types.Hash, len(heads))
+	for i, head := range heads {
+		var err error
+		hashes[i], err = types.GetHash(head)
+		assert.NoError(t, err)
+	}
+	srv := &headsMockSrv{
+		heads:        heads,
+		values:       map[types.Hash]string{hashes[0]: "0x01", hashes[1]: "0x02"},
+		unsubscribed: make(chan struct{}),
+	}
+	s := rpcmocksrv.New()
+	assert.NoError(t, s.RegisterName("chain", srv))
+	assert.NoError(t, s.RegisterName("childstate", srv))
+	cl, err := client.Connect(s.URL)
+	assert.NoError(t, err)
+
+	key := types.StorageKey{0x01}
+	sub, err := NewChildState(cl).SubscribeStorage(mockSrv.childStorageKey, []types.StorageKey{key})
+	assert.NoError(t, err)
+
+	for i, value := range []types.StorageDataRaw{{0x01}, {0x02}} {
+		set := <-sub.Chan()
+		assert.Equal(t, hashes[i], set.Block)
+		assert.Equal(t, []types.KeyValueOption{{StorageKey: key, HasStorageData: true, StorageData: value}},
+			set.Changes)
+	}
+
+	assert.Error(t, <-sub.Err())
+	_, ok := <-sub.Chan()
+	assert.False(t, ok)
+	select {
+	case <-srv.unsubscribed:
+	case <-time.After(time.Second):
+		t.Fatal("new heads have not been unsubscribed")
+	}
+}
+
+func TestChangedValues(t *testing.T) {
+	values := []types.KeyValueOption{
+		{StorageKey: types.StorageKey{0x01}, HasStorageData: true, StorageData: types.StorageDataRaw{0x01}},
+		{StorageKey: types.StorageKey{0x02}},
+	}
+	assert.Equal(t, values, changedValues(nil, values))
+	assert.Empty(t, changedValues(values, values))
+
+	changed := []types.KeyValueOption{
+		{StorageKey: types.StorageKey{0x01}, HasStorageData: true, StorageData: types.StorageDataRaw{0x02}},
+		{StorageKey: types.StorageKey{0x02}, HasStorageData: true, StorageData: types.StorageDataRaw{}},
+	}
+	assert.Equal(t, changed, changedValues(values, changed))
+	assert.Equal(t, changed[1:], changedValues([]types.KeyValueOption{changed[0], values[1]}, changed))
+}
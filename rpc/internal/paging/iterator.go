@@ -0,0 +1,124 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package paging implements the iteration over storage entries page by page shared by the storage iterators of the
+// state and childstate RPCs.
+package paging
+
+import (
+	"context"
+
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// DefaultPageSize is the number of keys an Iterator fetches per request, which is the maximum Substrate nodes allow
+const DefaultPageSize = 1000
+
+// ListKeysFunc returns up to pageSize keys following startKey, or the first keys if startKey is nil
+type ListKeysFunc func(pageSize uint32, startKey types.StorageKey) ([]types.StorageKey, error)
+
+// FetchEntriesFunc returns the entries of the given keys in the order of the keys, leaving out keys without a value
+type FetchEntriesFunc func(keys []types.StorageKey) ([]interface{}, error)
+
+// Iterator iterates over storage entries. Keys are listed page by page and the entries of each page are fetched at
+// once, only when all entries fetched so far have been consumed.
+type Iterator struct {
+	// PageSize is the number of keys fetched per request, it may be changed before the first call to Next
+	PageSize uint32
+
+	ctx          context.Context
+	listKeys     ListKeysFunc
+	fetchEntries FetchEntriesFunc
+
+	startKey types.StorageKey
+	done     bool
+	entries  []interface{}
+	entry    interface{}
+	err      error
+}
+
+// NewIterator creates a new Iterator listing keys and fetching their entries with the given functions. The iteration
+// stops with the error of the context once it is done.
+func NewIterator(ctx context.Context, listKeys ListKeysFunc, fetchEntries FetchEntriesFunc) *Iterator {
+	return &Iterator{
+		PageSize:     DefaultPageSize,
+		ctx:          ctx,
+		listKeys:     listKeys,
+		fetchEntries: fetchEntries,
+	}
+}
+
+// Next advances the iterator to the next entry, fetching more entries if needed. It returns false when all entries
+// have been iterated or an error occurred, check Err to tell both apart.
+func (it *Iterator) Next() bool {
+	if it.err == nil {
+		it.err = it.ctx.Err()
+	}
+	if it.err != nil {
+		return false
+	}
+
+	for len(it.entries) == 0 {
+		if it.done {
+			return false
+		}
+		it.err = it.fetch()
+		if it.err != nil {
+			return false
+		}
+	}
+
+	it.entry, it.entries = it.entries[0], it.entries[1:]
+	return true
+}
+
+// Entry returns the current entry, as returned by the FetchEntriesFunc
+func (it *Iterator) Entry() interface{} {
+	return it.entry
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// fetch fetches the next page of keys together with their entries
+func (it *Iterator) fetch() error {
+	pageSize := it.PageSize
+	if pageSize == 0 {
+		pageSize = DefaultPageSize
+	}
+
+	keys, err := it.listKeys(pageSize, it.startKey)
+	if err != nil {
+		return err
+	}
+	if len(keys) < int(pageSize) {
+		it.done = true
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	it.startKey = keys[len(keys)-1]
+
+	err = it.ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	it.entries, err = it.fetchEntries(keys)
+	return err
+}
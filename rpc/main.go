@@ -20,27 +20,30 @@ import (
 	"github.com/zenghq3/go-substrate-rpc-client/client"
 	"github.com/zenghq3/go-substrate-rpc-client/rpc/author"
 	"github.com/zenghq3/go-substrate-rpc-client/rpc/chain"
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/childstate"
 	"github.com/zenghq3/go-substrate-rpc-client/rpc/payment"
 	"github.com/zenghq3/go-substrate-rpc-client/rpc/state"
 	"github.com/zenghq3/go-substrate-rpc-client/rpc/system"
 )
 
 type RPC struct {
-	Author  *author.Author
-	Chain   *chain.Chain
-	State   *state.State
-	System  *system.System
-	Payment *payment.Payment
-	client  client.Client
+	Author     *author.Author
+	Chain      *chain.Chain
+	State      *state.State
+	ChildState *childstate.ChildState
+	System     *system.System
+	Payment    *payment.Payment
+	client     client.Client
 }
 
 func NewRPC(cl client.Client) *RPC {
 	return &RPC{
-		Author:  author.NewAuthor(cl),
-		Chain:   chain.NewChain(cl),
-		State:   state.NewState(cl),
-		System:  system.NewSystem(cl),
-		Payment: payment.NewPayment(cl),
-		client:  cl,
+		Author:     author.NewAuthor(cl),
+		Chain:      chain.NewChain(cl),
+		State:      state.NewState(cl),
+		ChildState: childstate.NewChildState(cl),
+		System:     system.NewSystem(cl),
+		Payment:    payment.NewPayment(cl),
+		client:     cl,
 	}
 }
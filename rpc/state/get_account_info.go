@@ -0,0 +1,84 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// GetAccountInfo retreives the frame_system AccountInfo of the given account from System.Account. The layout is taken
+// from the metadata, or detected from the length for metadata before v14. Absent accounts are decoded from the default
+// value in the metadata.
+func (s *State) GetAccountInfo(meta *types.Metadata, account types.AccountID, blockHash types.Hash) (
+	types.AccountInfo, error) {
+	return s.getAccountInfo(meta, account, &blockHash)
+}
+
+// GetAccountInfoLatest retreives the frame_system AccountInfo of the given account for the latest block height, see
+// GetAccountInfo
+func (s *State) GetAccountInfoLatest(meta *types.Metadata, account types.AccountID) (types.AccountInfo, error) {
+	return s.getAccountInfo(meta, account, nil)
+}
+
+// GetBalances retreives the free, reserved, locked and transferable balances of the given account
+func (s *State) GetBalances(meta *types.Metadata, account types.AccountID, blockHash types.Hash) (
+	types.AccountBalances, error) {
+	return s.getBalances(meta, account, &blockHash)
+}
+
+// GetBalancesLatest retreives the free, reserved, locked and transferable balances of the given account for the latest
+// block height
+func (s *State) GetBalancesLatest(meta *types.Metadata, account types.AccountID) (types.AccountBalances, error) {
+	return s.getBalances(meta, account, nil)
+}
+
+func (s *State) getAccountInfo(meta *types.Metadata, account types.AccountID, blockHash *types.Hash) (
+	types.AccountInfo, error) {
+	entry, err := meta.FindStorageEntryMetadata("System", "Account")
+	if err != nil {
+		return types.AccountInfo{}, err
+	}
+	key, err := types.CreateStorageKey(meta, "System", "Account", account[:], nil)
+	if err != nil {
+		return types.AccountInfo{}, err
+	}
+
+	raw, ok, err := s.getStorageRawOrDefault(entry, key, blockHash)
+	if err != nil || !ok {
+		return types.AccountInfo{}, err
+	}
+
+	var layout types.AccountInfoLayout
+	if meta.IsMetadataV14 {
+		layout, err = types.AccountInfoLayoutFromMetadata(meta)
+	} else {
+		layout, err = types.AccountInfoLayoutFromLength(len(raw))
+	}
+	if err != nil {
+		return types.AccountInfo{}, err
+	}
+	return types.DecodeAccountInfo(raw, layout)
+}
+
+func (s *State) getBalances(meta *types.Metadata, account types.AccountID, blockHash *types.Hash) (
+	types.AccountBalances, error) {
+	info, err := s.getAccountInfo(meta, account, blockHash)
+	if err != nil {
+		return types.AccountBalances{}, err
+	}
+	return info.Data.Balances(), nil
+}
@@ -0,0 +1,97 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenghq3/go-substrate-rpc-client/client"
+	"github.com/zenghq3/go-substrate-rpc-client/rpcmocksrv"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// accountMockSrv serves state_getStorage from a map of storage keys to values
+type accountMockSrv struct {
+	values map[string]string
+}
+
+func (s *accountMockSrv) GetStorage(key string, hash *string) *string {
+	value, ok := s.values[key]
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+func newAccountState(t *testing.T, values map[string]string) *State {
+	s := rpcmocksrv.New()
+	assert.NoError(t, s.RegisterName("state", &accountMockSrv{values}))
+	cl, err := client.Connect(s.URL)
+	assert.NoError(t, err)
+
+	return NewState(cl)
+}
+
+func TestState_GetAccountInfo(t *testing.T) {
+	meta := types.ExamplaryMetadataV11Substrate
+	alice := types.NewAccountID(types.MustHexDecodeString(
+		"0xd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d"))
+	key, err := types.CreateStorageKey(meta, "System", "Account", alice[:], nil)
+	assert.NoError(t, err)
+
+	stored := types.AccountInfo{
+		Nonce:       3,
+		Consumers:   1,
+		Providers:   1,
+		Sufficients: 0,
+		Data: types.AccountData{
+			Free:       types.NewU128(*big.NewInt(1000)),
+			Reserved:   types.NewU128(*big.NewInt(100)),
+			MiscFrozen: types.NewU128(*big.NewInt(400)),
+			FeeFrozen:  types.NewU128(*big.NewInt(0)),
+		},
+	}
+	storedHex, err := types.EncodeToHexString(stored)
+	assert.NoError(t, err)
+	state := newAccountState(t, map[string]string{key.Hex(): storedHex})
+
+	info, err := state.GetAccountInfo(meta, alice, types.Hash{0x01})
+	assert.NoError(t, err)
+	assert.Equal(t, stored, info)
+
+	balances, err := state.GetBalancesLatest(meta, alice)
+	assert.NoError(t, err)
+	assert.Equal(t, types.AccountBalances{
+		Free:         types.NewU128(*big.NewInt(1000)),
+		Reserved:     types.NewU128(*big.NewInt(100)),
+		Locked:       types.NewU128(*big.NewInt(400)),
+		Transferable: types.NewU128(*big.NewInt(600)),
+	}, balances)
+
+	// absent accounts are decoded from the default value, which has the u8 reference count layout in this metadata
+	info, err = state.GetAccountInfoLatest(meta, types.AccountID{})
+	assert.NoError(t, err)
+	assert.Equal(t, types.NewU32(0), info.Nonce)
+	assert.Equal(t, types.NewU32(0), info.RefCount)
+	assert.Equal(t, types.NewU128(*big.NewInt(0)), info.Data.Transferable())
+	assert.Equal(t, types.NewU128(*big.NewInt(0)), info.Data.Transferable())
+
+	_, err = state.GetBalancesLatest(types.ExamplaryMetadataV4, alice)
+	assert.Error(t, err)
+}
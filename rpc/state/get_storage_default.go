@@ -0,0 +1,69 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// GetStorageOrDefault retreives the stored data of the given storage entry and decodes them into the provided
+// interface. Like in the runtime, absent values are decoded from the default value in the metadata, unless the entry is
+// optional. Ok is only false for absent values of optional entries.
+func (s *State) GetStorageOrDefault(meta *types.Metadata, module, fn string, key types.StorageKey, target interface{},
+	blockHash types.Hash) (ok bool, err error) {
+	return s.getStorageOrDefault(meta, module, fn, key, target, &blockHash)
+}
+
+// GetStorageOrDefaultLatest retreives the stored data of the given storage entry for the latest block height and
+// decodes them into the provided interface. Like in the runtime, absent values are decoded from the default value in
+// the metadata, unless the entry is optional. Ok is only false for absent values of optional entries.
+func (s *State) GetStorageOrDefaultLatest(meta *types.Metadata, module, fn string, key types.StorageKey,
+	target interface{}) (ok bool, err error) {
+	return s.getStorageOrDefault(meta, module, fn, key, target, nil)
+}
+
+func (s *State) getStorageOrDefault(meta *types.Metadata, module, fn string, key types.StorageKey, target interface{},
+	blockHash *types.Hash) (bool, error) {
+	entry, err := meta.FindStorageEntryMetadata(module, fn)
+	if err != nil {
+		return false, err
+	}
+
+	raw, ok, err := s.getStorageRawOrDefault(entry, key, blockHash)
+	if err != nil || !ok {
+		return false, err
+	}
+	return true, types.DecodeFromBytes(raw, target)
+}
+
+// getStorageRawOrDefault retreives the stored data of the given storage entry, falling back to the default value of
+// the entry if it is absent. Ok is only false for absent values of optional entries.
+func (s *State) getStorageRawOrDefault(entry types.StorageEntryMetadata, key types.StorageKey,
+	blockHash *types.Hash) (types.StorageDataRaw, bool, error) {
+	raw, err := s.getStorageRaw(key, blockHash)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(*raw) == 0 {
+		if entry.IsOptional() {
+			return nil, false, nil
+		}
+		return types.StorageDataRaw(entry.DefaultValue()), true, nil
+	}
+	return *raw, true, nil
+}
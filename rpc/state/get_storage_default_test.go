@@ -0,0 +1,51 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+func TestState_GetStorageOrDefaultLatest(t *testing.T) {
+	var decoded types.U64
+	ok, err := state.GetStorageOrDefaultLatest(types.ExamplaryMetadataV4, "Timestamp", "Now",
+		types.MustHexDecodeString(mockSrv.storageKeyHex), &decoded)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, types.U64(0x5d892db8), decoded)
+}
+
+func TestState_GetStorageOrDefault(t *testing.T) {
+	var count types.U32
+	ok, err := state.GetStorageOrDefault(types.ExamplaryMetadataV10, "Staking", "MinimumValidatorCount",
+		types.MustHexDecodeString(mockSrv.storageKeyHexEmpty), &count, mockSrv.blockHashLatest)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, types.U32(4), count)
+
+	ok, err = state.GetStorageOrDefault(types.ExamplaryMetadataV10, "System", "ExtrinsicCount",
+		types.MustHexDecodeString(mockSrv.storageKeyHexEmpty), &count, mockSrv.blockHashLatest)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = state.GetStorageOrDefault(types.ExamplaryMetadataV10, "System", "Unknown",
+		types.MustHexDecodeString(mockSrv.storageKeyHexEmpty), &count, mockSrv.blockHashLatest)
+	assert.Error(t, err)
+}
@@ -0,0 +1,56 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"github.com/zenghq3/go-substrate-rpc-client/registry"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// defaultRegistry is used by GetStorageEntry if no registry is given. It is shared, so the types it builds for metadata
+// v14 are reused across calls.
+var defaultRegistry = registry.NewRegistry()
+
+// GetStorageEntry retreives the value of the given storage entry and decodes it into the provided interface. The map
+// keys are given as Go values and encoded according to the key types in the metadata, using the given registry or the
+// default registry if reg is nil. Absent values are decoded from the default value in the metadata, unless the entry
+// is optional. Ok is only false for absent values of optional entries.
+func (s *State) GetStorageEntry(reg *registry.Registry, meta *types.Metadata, module, fn string, target interface{},
+	blockHash types.Hash, keys ...interface{}) (ok bool, err error) {
+	return s.getStorageEntry(reg, meta, module, fn, target, &blockHash, keys)
+}
+
+// GetStorageEntryLatest retreives the value of the given storage entry for the latest block height and decodes it into
+// the provided interface, see GetStorageEntry
+func (s *State) GetStorageEntryLatest(reg *registry.Registry, meta *types.Metadata, module, fn string,
+	target interface{}, keys ...interface{}) (ok bool, err error) {
+	return s.getStorageEntry(reg, meta, module, fn, target, nil, keys)
+}
+
+func (s *State) getStorageEntry(reg *registry.Registry, meta *types.Metadata, module, fn string, target interface{},
+	blockHash *types.Hash, keys []interface{}) (bool, error) {
+	if reg == nil {
+		reg = defaultRegistry
+	}
+
+	key, err := reg.CreateStorageKey(meta, module, fn, keys...)
+	if err != nil {
+		return false, err
+	}
+
+	return s.getStorageOrDefault(meta, module, fn, key, target, blockHash)
+}
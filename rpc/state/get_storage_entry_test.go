@@ -0,0 +1,47 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenghq3/go-substrate-rpc-client/registry"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+func TestState_GetStorageEntryLatest(t *testing.T) {
+	var decoded types.U64
+	ok, err := state.GetStorageEntryLatest(nil, types.ExamplaryMetadataV4, "Timestamp", "Now", &decoded)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, types.U64(0x5d892db8), decoded)
+}
+
+func TestState_GetStorageEntry(t *testing.T) {
+	var nonce types.U32
+	ok, err := state.GetStorageEntry(registry.NewRegistry(), types.ExamplaryMetadataV10, "System", "AccountNonce",
+		&nonce, mockSrv.blockHashLatest, types.NewAccountID(types.MustHexDecodeString(
+			"0xd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d")))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, types.U32(0), nonce)
+
+	_, err = state.GetStorageEntry(nil, types.ExamplaryMetadataV10, "System", "AccountNonce", &nonce,
+		mockSrv.blockHashLatest)
+	assert.EqualError(t, err, "System.AccountNonce requires 1 keys, but got 0")
+}
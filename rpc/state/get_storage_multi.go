@@ -0,0 +1,80 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"fmt"
+
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// GetStorageMulti retreives the stored data of many keys at the given block in a single request and decodes them into
+// the provided targets, one per key. For every key, ok is true if the value is not empty.
+func (s *State) GetStorageMulti(keys []types.StorageKey, targets []interface{}, blockHash types.Hash) (
+	ok []bool, err error) {
+	return s.getStorageMulti(keys, targets, &blockHash)
+}
+
+// GetStorageMultiLatest retreives the stored data of many keys for the latest block height in a single request and
+// decodes them into the provided targets, one per key. For every key, ok is true if the value is not empty.
+func (s *State) GetStorageMultiLatest(keys []types.StorageKey, targets []interface{}) (ok []bool, err error) {
+	return s.getStorageMulti(keys, targets, nil)
+}
+
+func (s *State) getStorageMulti(keys []types.StorageKey, targets []interface{}, blockHash *types.Hash) (
+	[]bool, error) {
+	if len(keys) != len(targets) {
+		return nil, fmt.Errorf("expected one target per key, but got %v keys and %v targets", len(keys), len(targets))
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	sets, err := s.queryStorageAt(keys, blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	values := storageValues(sets)
+
+	ok := make([]bool, len(keys))
+	for i, key := range keys {
+		value := values[string(key)]
+		if len(value) == 0 {
+			continue
+		}
+		ok[i] = true
+		err = types.DecodeFromBytes(value, targets[i])
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode storage of key %v: %v", key.Hex(), err)
+		}
+	}
+	return ok, nil
+}
+
+// storageValues returns the values of all changes with data, keyed by their storage key
+func storageValues(sets []types.StorageChangeSet) map[string]types.StorageDataRaw {
+	values := make(map[string]types.StorageDataRaw)
+	for _, set := range sets {
+		for _, change := range set.Changes {
+			if change.HasStorageData {
+				values[string(change.StorageKey)] = change.StorageData
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,48 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+func TestState_GetStorageMultiLatest(t *testing.T) {
+	keys := []types.StorageKey{
+		types.MustHexDecodeString(mockSrv.storageKeyHexEmpty),
+		types.MustHexDecodeString(mockSrv.storageKeyHex),
+	}
+	var empty, decoded types.U64
+	ok, err := state.GetStorageMultiLatest(keys, []interface{}{&empty, &decoded})
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false, true}, ok)
+	assert.Equal(t, types.U64(0x5d892db8), decoded)
+}
+
+func TestState_GetStorageMulti(t *testing.T) {
+	keys := []types.StorageKey{types.MustHexDecodeString(mockSrv.storageKeyHex)}
+	var decoded types.U64
+	ok, err := state.GetStorageMulti(keys, []interface{}{&decoded}, mockSrv.blockHashLatest)
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true}, ok)
+	assert.Equal(t, types.U64(0x5d892db8), decoded)
+
+	_, err = state.GetStorageMulti(keys, nil, mockSrv.blockHashLatest)
+	assert.EqualError(t, err, "expected one target per key, but got 1 keys and 0 targets")
+}
@@ -0,0 +1,216 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"sync"
+
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/chain"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// MetadataProvider provides decoded metadata and caches it per spec version of the runtime, so metadata is only
+// fetched and decoded again after a runtime upgrade. It is safe for concurrent use.
+//
+// On first use, the provider subscribes to runtime version updates and refreshes the latest metadata as soon as an
+// upgrade is reported. If the subscription is not available, for example when connected over HTTP, the latest spec
+// version is queried on every call instead. The latest spec version and its metadata are always queried at the same
+// block, so an upgrade in between cannot cache metadata under the wrong spec version.
+type MetadataProvider struct {
+	state *State
+
+	lock       sync.RWMutex
+	bySpec     map[types.U32]*types.Metadata
+	latestSpec types.U32
+	// hasLatest is set while latestSpec is kept up to date by the runtime version subscription
+	hasLatest bool
+
+	watchLock sync.Mutex
+	sub       runtimeVersionSubscription
+	// noWatch is set when subscribing is not supported or the provider has been closed
+	noWatch   bool
+	subscribe func() (runtimeVersionSubscription, error)
+}
+
+// runtimeVersionSubscription is the part of RuntimeVersionSubscription used by MetadataProvider
+type runtimeVersionSubscription interface {
+	Chan() <-chan types.RuntimeVersion
+	Err() <-chan error
+	Unsubscribe()
+}
+
+// NewMetadataProvider creates a new MetadataProvider that fetches metadata through the given State
+func NewMetadataProvider(s *State) *MetadataProvider {
+	return &MetadataProvider{
+		state:  s,
+		bySpec: make(map[types.U32]*types.Metadata),
+		subscribe: func() (runtimeVersionSubscription, error) {
+			sub, err := s.SubscribeRuntimeVersion()
+			if err != nil {
+				return nil, err
+			}
+			return sub, nil
+		},
+	}
+}
+
+// Latest returns the metadata of the latest runtime
+func (p *MetadataProvider) Latest() (*types.Metadata, error) {
+	p.lock.RLock()
+	if p.hasLatest {
+		meta := p.bySpec[p.latestSpec]
+		p.lock.RUnlock()
+		return meta, nil
+	}
+	p.lock.RUnlock()
+
+	p.watch()
+
+	_, meta, err := p.latest()
+	return meta, err
+}
+
+// At returns the metadata of the runtime at the given block. Metadata is only fetched when no metadata for the spec
+// version of that block is cached yet.
+func (p *MetadataProvider) At(blockHash types.Hash) (*types.Metadata, error) {
+	rv, err := p.state.GetRuntimeVersion(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.forSpec(rv.SpecVersion, blockHash)
+}
+
+// Close stops listening to runtime version updates. Cached metadata remains available, but the latest spec version is
+// queried on every call to Latest afterwards.
+func (p *MetadataProvider) Close() {
+	p.watchLock.Lock()
+	p.noWatch = true
+	sub := p.sub
+	p.sub = nil
+	p.watchLock.Unlock()
+
+	if sub != nil {
+		sub.Unsubscribe()
+	}
+
+	p.lock.Lock()
+	p.hasLatest = false
+	p.lock.Unlock()
+}
+
+// latest returns the spec version and the metadata of the latest block, pinning all queries to the same block
+func (p *MetadataProvider) latest() (types.U32, *types.Metadata, error) {
+	blockHash, err := chain.NewChain(p.state.client).GetBlockHashLatest()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	rv, err := p.state.GetRuntimeVersion(blockHash)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	meta, err := p.forSpec(rv.SpecVersion, blockHash)
+	return rv.SpecVersion, meta, err
+}
+
+// forSpec returns the cached metadata for the given spec version or fetches it at the given block
+func (p *MetadataProvider) forSpec(specVersion types.U32, blockHash types.Hash) (*types.Metadata, error) {
+	p.lock.RLock()
+	meta, ok := p.bySpec[specVersion]
+	p.lock.RUnlock()
+	if ok {
+		return meta, nil
+	}
+
+	meta, err := p.state.getMetadata(&blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if cached, ok := p.bySpec[specVersion]; ok {
+		return cached, nil
+	}
+	p.bySpec[specVersion] = meta
+
+	return meta, nil
+}
+
+// watch subscribes to runtime version updates, unless a subscription is already active or not supported
+func (p *MetadataProvider) watch() {
+	p.watchLock.Lock()
+	defer p.watchLock.Unlock()
+
+	if p.sub != nil || p.noWatch {
+		return
+	}
+
+	sub, err := p.subscribe()
+	if err != nil {
+		p.noWatch = true
+		return
+	}
+	p.sub = sub
+
+	go p.listen(sub)
+}
+
+// listen refreshes the latest metadata whenever the subscription reports a runtime version
+func (p *MetadataProvider) listen(sub runtimeVersionSubscription) {
+	for {
+		select {
+		case _, ok := <-sub.Chan():
+			if !ok {
+				return
+			}
+			p.refresh(sub)
+		case <-sub.Err():
+			// the subscription has ended, fall back to querying the latest spec version until it is re-established
+			p.watchLock.Lock()
+			if p.sub == sub {
+				p.sub = nil
+			}
+			p.watchLock.Unlock()
+
+			p.lock.Lock()
+			p.hasLatest = false
+			p.lock.Unlock()
+			return
+		}
+	}
+}
+
+// refresh makes the metadata of the latest block the latest one, fetching it if needed. The spec version reported by
+// the subscription is not used, as it is not tied to a block. The update is dropped if the subscription has been
+// replaced or closed in the meantime.
+func (p *MetadataProvider) refresh(sub runtimeVersionSubscription) {
+	specVersion, _, err := p.latest()
+
+	p.watchLock.Lock()
+	defer p.watchLock.Unlock()
+	if p.sub != sub {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.latestSpec = specVersion
+	p.hasLatest = err == nil
+}
@@ -0,0 +1,270 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenghq3/go-substrate-rpc-client/client"
+	"github.com/zenghq3/go-substrate-rpc-client/rpcmocksrv"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// runtimeMockSrv serves a chain whose runtime is upgraded by the tests. The hash of a block is its number, every block
+// records the spec version of its runtime. Spec version 1 has metadata v4, spec version 2 metadata v10.
+type runtimeMockSrv struct {
+	mu    sync.Mutex
+	specs []types.U32
+	// onRuntimeVersion is called after the runtime version has been queried
+	onRuntimeVersion func()
+
+	runtimeVersionCalls int32
+	metadataCalls       int32
+}
+
+var runtimeMockMetadata = map[types.U32]string{
+	1: types.ExamplaryMetadataV4String,
+	2: types.ExamplaryMetadataV10String,
+}
+
+func (s *runtimeMockSrv) GetBlockHash(height *uint64) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return types.NewHash([]byte{byte(len(s.specs) - 1)}).Hex()
+}
+
+func (s *runtimeMockSrv) GetRuntimeVersion(hash *string) (types.RuntimeVersion, error) {
+	atomic.AddInt32(&s.runtimeVersionCalls, 1)
+	spec, err := s.specAt(hash)
+	if s.onRuntimeVersion != nil {
+		s.onRuntimeVersion()
+	}
+	return types.RuntimeVersion{SpecName: "node", SpecVersion: spec}, err
+}
+
+func (s *runtimeMockSrv) GetMetadata(hash *string) (string, error) {
+	atomic.AddInt32(&s.metadataCalls, 1)
+	spec, err := s.specAt(hash)
+	return runtimeMockMetadata[spec], err
+}
+
+// upgrade adds a block with the given spec version
+func (s *runtimeMockSrv) upgrade(spec types.U32) {
+	s.mu.Lock()
+	s.specs = append(s.specs, spec)
+	s.mu.Unlock()
+}
+
+func (s *runtimeMockSrv) specAt(hash *string) (types.U32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hash == nil {
+		return s.specs[len(s.specs)-1], nil
+	}
+	n := int(types.MustHexDecodeString(*hash)[0])
+	if n >= len(s.specs) {
+		return 0, fmt.Errorf("unknown block %v", *hash)
+	}
+	return s.specs[n], nil
+}
+
+// fakeRuntimeVersionSubscription is a runtime version subscription driven by the tests
+type fakeRuntimeVersionSubscription struct {
+	channel      chan types.RuntimeVersion
+	err          chan error
+	unsubscribed chan struct{}
+}
+
+func (s *fakeRuntimeVersionSubscription) Chan() <-chan types.RuntimeVersion {
+	return s.channel
+}
+
+func (s *fakeRuntimeVersionSubscription) Err() <-chan error {
+	return s.err
+}
+
+func (s *fakeRuntimeVersionSubscription) Unsubscribe() {
+	close(s.unsubscribed)
+}
+
+// newRuntimeMock returns a provider for the chain of a runtimeMockSrv. If sub is nil, subscribing fails.
+func newRuntimeMock(t *testing.T, sub *fakeRuntimeVersionSubscription) (*MetadataProvider, *runtimeMockSrv) {
+	srv := &runtimeMockSrv{specs: []types.U32{1}}
+	s := rpcmocksrv.New()
+	assert.NoError(t, s.RegisterName("state", srv))
+	assert.NoError(t, s.RegisterName("chain", srv))
+	cl, err := client.Connect(s.URL)
+	assert.NoError(t, err)
+
+	p := NewMetadataProvider(NewState(cl))
+	p.subscribe = func() (runtimeVersionSubscription, error) {
+		if sub == nil {
+			return nil, fmt.Errorf("subscriptions not supported")
+		}
+		return sub, nil
+	}
+	return p, srv
+}
+
+func TestMetadataProvider(t *testing.T) {
+	p, srv := newRuntimeMock(t, nil)
+	defer p.Close()
+
+	latest, err := p.Latest()
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(4), latest.Version)
+
+	at, err := p.At(types.NewHash([]byte{0}))
+	assert.NoError(t, err)
+	assert.True(t, latest == at)
+
+	latest, err = p.Latest()
+	assert.NoError(t, err)
+	assert.True(t, at == latest)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&srv.metadataCalls))
+}
+
+func TestMetadataProvider_SpecVersionChange(t *testing.T) {
+	p, srv := newRuntimeMock(t, nil)
+	defer p.Close()
+
+	before, err := p.Latest()
+	assert.NoError(t, err)
+
+	srv.upgrade(2)
+
+	after, err := p.Latest()
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(10), after.Version)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&srv.metadataCalls))
+
+	again, err := p.At(types.NewHash([]byte{1}))
+	assert.NoError(t, err)
+	assert.True(t, after == again)
+
+	old, err := p.At(types.NewHash([]byte{0}))
+	assert.NoError(t, err)
+	assert.True(t, before == old)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&srv.metadataCalls))
+}
+
+func TestMetadataProvider_UpgradeBetweenQueries(t *testing.T) {
+	p, srv := newRuntimeMock(t, nil)
+	defer p.Close()
+
+	// the runtime is upgraded right after the spec version has been queried, the metadata must still be the one of
+	// the queried block
+	srv.onRuntimeVersion = func() {
+		srv.onRuntimeVersion = nil
+		srv.upgrade(2)
+	}
+
+	meta, err := p.Latest()
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(4), meta.Version)
+
+	meta, err = p.Latest()
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(10), meta.Version)
+}
+
+// eventually waits up to a second for the condition to become true
+func eventually(t *testing.T, condition func() bool) {
+	for deadline := time.Now().Add(time.Second); !condition(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within a second")
+		}
+	}
+}
+
+func TestMetadataProvider_Subscription(t *testing.T) {
+	sub := &fakeRuntimeVersionSubscription{
+		channel:      make(chan types.RuntimeVersion),
+		err:          make(chan error, 1),
+		unsubscribed: make(chan struct{}),
+	}
+	p, srv := newRuntimeMock(t, sub)
+
+	meta, err := p.Latest()
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(4), meta.Version)
+
+	// once the subscription has reported the current version, the latest metadata is served without queries
+	sub.channel <- types.RuntimeVersion{SpecName: "node", SpecVersion: 1}
+	eventually(t, func() bool {
+		p.lock.RLock()
+		defer p.lock.RUnlock()
+		return p.hasLatest
+	})
+	calls := atomic.LoadInt32(&srv.runtimeVersionCalls)
+	meta, err = p.Latest()
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(4), meta.Version)
+	assert.Equal(t, calls, atomic.LoadInt32(&srv.runtimeVersionCalls))
+
+	// an upgrade refreshes the latest metadata
+	srv.upgrade(2)
+	sub.channel <- types.RuntimeVersion{SpecName: "node", SpecVersion: 2}
+	eventually(t, func() bool {
+		meta, err := p.Latest()
+		return err == nil && meta.Version == 10
+	})
+
+	// after closing, the subscription is unsubscribed and the latest spec version is queried again
+	p.Close()
+	select {
+	case <-sub.unsubscribed:
+	case <-time.After(time.Second):
+		t.Fatal("not unsubscribed")
+	}
+	calls = atomic.LoadInt32(&srv.runtimeVersionCalls)
+	meta, err = p.Latest()
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(10), meta.Version)
+	assert.Equal(t, calls+1, atomic.LoadInt32(&srv.runtimeVersionCalls))
+}
+
+func TestMetadataProvider_SubscriptionError(t *testing.T) {
+	sub := &fakeRuntimeVersionSubscription{
+		channel:      make(chan types.RuntimeVersion),
+		err:          make(chan error, 1),
+		unsubscribed: make(chan struct{}),
+	}
+	p, srv := newRuntimeMock(t, sub)
+	defer p.Close()
+
+	_, err := p.Latest()
+	assert.NoError(t, err)
+	sub.channel <- types.RuntimeVersion{SpecName: "node", SpecVersion: 1}
+
+	// when the subscription ends, the latest spec version is queried on every call again
+	sub.err <- fmt.Errorf("connection closed")
+	eventually(t, func() bool {
+		p.lock.RLock()
+		defer p.lock.RUnlock()
+		return !p.hasLatest
+	})
+	srv.upgrade(2)
+	meta, err := p.Latest()
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(10), meta.Version)
+}
@@ -0,0 +1,44 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+func TestState_QueryStorageAtLatest(t *testing.T) {
+	key := types.NewStorageKey(types.MustHexDecodeString(mockSrv.storageKeyHex))
+	sets, err := state.QueryStorageAtLatest([]types.StorageKey{key})
+	assert.NoError(t, err)
+	assert.Equal(t, []types.StorageChangeSet{{Block: mockSrv.blockHashLatest, Changes: []types.KeyValueOption{{
+		StorageKey:     key,
+		HasStorageData: true,
+		StorageData:    types.MustHexDecodeString(mockSrv.storageDataHex),
+	}}}}, sets)
+}
+
+func TestState_QueryStorageAt(t *testing.T) {
+	key := types.NewStorageKey(types.MustHexDecodeString(mockSrv.storageKeyHexEmpty))
+	sets, err := state.QueryStorageAt([]types.StorageKey{key}, mockSrv.blockHashLatest)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.StorageChangeSet{{Block: mockSrv.blockHashLatest, Changes: []types.KeyValueOption{{
+		StorageKey: key,
+	}}}}, sets)
+}
@@ -0,0 +1,239 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/chain"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+const (
+	// DefaultQueryStorageRangeChunkSize is the number of blocks QueryStorageRange queries per request by default
+	DefaultQueryStorageRangeChunkSize = 1000
+	// DefaultQueryStorageRangeConcurrency is the number of requests QueryStorageRange runs at a time by default
+	DefaultQueryStorageRangeConcurrency = 4
+)
+
+// QueryStorageRangeOptions configures QueryStorageRange. Zero values are replaced by the defaults.
+type QueryStorageRangeOptions struct {
+	// ChunkSize is the number of blocks queried per request after its first block, which is the last block of the
+	// previous request
+	ChunkSize uint32
+	// Concurrency is the maximum number of requests running at a time
+	Concurrency int
+}
+
+// QueryStorageRange queries the historical storage entries of the given keys from the block with the number from until
+// the block with the number to, both included. Unlike QueryStorage, the range is split into chunks of blocks, each
+// queried with a separate state_queryStorage request, so nodes do not time out on large ranges. Consecutive chunks
+// overlap in one block, so the hash of every chunk boundary is looked up only once. The chunks are queried
+// concurrently and merged in order, which gives the same result as a single request: the first change set holds the
+// values of all keys at the first block, every following change set only the values that changed at its block.
+func (s *State) QueryStorageRange(ctx context.Context, keys []types.StorageKey, from, to types.BlockNumber,
+	opts QueryStorageRangeOptions) ([]types.StorageChangeSet, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid block range, from %v is after to %v", from, to)
+	}
+	chunkSize := uint64(opts.ChunkSize)
+	if chunkSize == 0 {
+		chunkSize = DefaultQueryStorageRangeChunkSize
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultQueryStorageRangeConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// consecutive chunks share their boundary block, so the hash of every boundary is looked up once
+	n := uint64(1)
+	if to > from {
+		n = (uint64(to) - uint64(from) + chunkSize - 1) / chunkSize
+	}
+	hashes := newBoundaryHashes(chain.NewChain(s.client), uint64(from), uint64(to), chunkSize, n+1)
+	chunks := make([][]types.StorageChangeSet, n)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	// the first error cancels all other requests, which then fail with the error of the context
+	var errOnce sync.Once
+	var firstErr error
+
+	for i := uint64(0); i < n; i++ {
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i uint64) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			var err error
+			chunks[i], err = s.queryStorageChunk(ctx, keys, hashes, i)
+			if err != nil {
+				errOnce.Do(func() { firstErr = err })
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return mergeChangeSets(chunks), nil
+}
+
+// queryStorageChunk queries the storage entries of the keys from the boundary block i until the boundary block i+1
+func (s *State) queryStorageChunk(ctx context.Context, keys []types.StorageKey, hashes *boundaryHashes, i uint64) (
+	[]types.StorageChangeSet, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	startHash, err := hashes.get(i)
+	if err != nil {
+		return nil, err
+	}
+	endHash, err := hashes.get(i + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	sets, err := s.queryStorage(keys, startHash, &endHash)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query storage from block %v to %v: %v", hashes.number(i),
+			hashes.number(i+1), err)
+	}
+	return sets, nil
+}
+
+// boundaryHashes looks up the hashes of the chunk boundaries of QueryStorageRange, each at most once. The boundary i
+// is the block chunkSize * i blocks after from, or the block to for the last boundary.
+type boundaryHashes struct {
+	chain     *chain.Chain
+	from, to  uint64
+	chunkSize uint64
+	once      []sync.Once
+	hashes    []types.Hash
+	errs      []error
+}
+
+func newBoundaryHashes(ch *chain.Chain, from, to, chunkSize, n uint64) *boundaryHashes {
+	return &boundaryHashes{chain: ch, from: from, to: to, chunkSize: chunkSize, once: make([]sync.Once, n),
+		hashes: make([]types.Hash, n), errs: make([]error, n)}
+}
+
+func (b *boundaryHashes) number(i uint64) uint64 {
+	n := b.from + i*b.chunkSize
+	if n > b.to {
+		return b.to
+	}
+	return n
+}
+
+func (b *boundaryHashes) get(i uint64) (types.Hash, error) {
+	b.once[i].Do(func() {
+		n := b.number(i)
+		b.hashes[i], b.errs[i] = b.chain.GetBlockHash(n)
+		if b.errs[i] != nil {
+			b.errs[i] = fmt.Errorf("unable to get hash of block %v: %v", n, b.errs[i])
+		}
+	})
+	return b.hashes[i], b.errs[i]
+}
+
+// mergeChangeSets concatenates the change sets of consecutive chunks. The first change set of every chunk holds the
+// values of all keys, the values that did not change since the previous chunk are removed from it, and the change set
+// is dropped if no value changed.
+func mergeChangeSets(chunks [][]types.StorageChangeSet) []types.StorageChangeSet {
+	var merged []types.StorageChangeSet
+	last := make(map[string]types.KeyValueOption)
+	for i, sets := range chunks {
+		for j, set := range sets {
+			if i > 0 && j == 0 {
+				var changes []types.KeyValueOption
+				for _, c := range set.Changes {
+					p, ok := last[string(c.StorageKey)]
+					if !ok || p.HasStorageData != c.HasStorageData || !bytes.Equal(p.StorageData, c.StorageData) {
+						changes = append(changes, c)
+					}
+				}
+				set.Changes = changes
+			}
+			for _, c := range set.Changes {
+				last[string(c.StorageKey)] = c
+			}
+			if len(set.Changes) > 0 || len(merged) == 0 {
+				merged = append(merged, set)
+			}
+		}
+	}
+	return merged
+}
+
+// DecodedStorageChange is a change of a storage value with the value decoded, see DecodeStorageChanges
+type DecodedStorageChange struct {
+	Block types.Hash
+	Key   types.StorageKey
+	// HasValue is false if the key was removed at the block
+	HasValue bool
+	// Value holds the decoded value, as returned by the newTarget function passed to DecodeStorageChanges
+	Value interface{}
+}
+
+// DecodeStorageChanges decodes every change of the change sets, in order. The values are decoded into the targets
+// created by newTarget, which must return a new pointer for every call.
+//
+// To get the balance history of an account, pass a function returning a new pointer to the account info:
+//
+//	changes, err := state.DecodeStorageChanges(sets, func() interface{} { return new(types.AccountInfo) })
+func DecodeStorageChanges(sets []types.StorageChangeSet, newTarget func() interface{}) ([]DecodedStorageChange,
+	error) {
+	var changes []DecodedStorageChange
+	for _, set := range sets {
+		for _, c := range set.Changes {
+			change := DecodedStorageChange{Block: set.Block, Key: c.StorageKey, HasValue: c.HasStorageData}
+			if c.HasStorageData {
+				change.Value = newTarget()
+				err := types.DecodeFromBytes(c.StorageData, change.Value)
+				if err != nil {
+					return nil, fmt.Errorf("unable to decode the value of %v at block %v: %v", c.StorageKey.Hex(),
+						set.Block.Hex(), err)
+				}
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
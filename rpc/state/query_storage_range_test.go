@@ -0,0 +1,200 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenghq3/go-substrate-rpc-client/client"
+	"github.com/zenghq3/go-substrate-rpc-client/rpcmocksrv"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// historyMockSrv serves a chain of blocks through chain_getBlockHash and state_queryStorage. The key 0x01 holds the
+// block number divided by 7 as U32, the key 0x02 holds 0 until block 50 and is removed after.
+type historyMockSrv struct {
+	blocks  int
+	failAt  int // the start block of requests that fail, or 0
+	mu      sync.Mutex
+	running int
+	maxRun  int
+	lookups map[uint64]int // the number of chain_getBlockHash calls per block
+}
+
+func historyBlockHash(n int) types.Hash {
+	return types.NewHash([]byte{0xbb, byte(n >> 8), byte(n)})
+}
+
+func (s *historyMockSrv) number(hash string) (int, error) {
+	for n := 0; n < s.blocks; n++ {
+		if historyBlockHash(n).Hex() == hash {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown block %v", hash)
+}
+
+func (s *historyMockSrv) value(key string, n int) (string, bool) {
+	switch key {
+	case "0x01":
+		return types.HexEncodeToString([]byte{byte(n / 7), 0, 0, 0}), true
+	case "0x02":
+		return "0x00000000", n <= 50
+	}
+	return "", false
+}
+
+func (s *historyMockSrv) GetBlockHash(height *uint64) string {
+	s.mu.Lock()
+	s.lookups[*height]++
+	s.mu.Unlock()
+	return historyBlockHash(int(*height)).Hex()
+}
+
+func (s *historyMockSrv) QueryStorage(keys []string, from string, to *string) ([]map[string]interface{}, error) {
+	s.mu.Lock()
+	s.running++
+	if s.running > s.maxRun {
+		s.maxRun = s.running
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.running--
+		s.mu.Unlock()
+	}()
+	time.Sleep(time.Millisecond)
+
+	start, err := s.number(from)
+	if err != nil {
+		return nil, err
+	}
+	end, err := s.number(*to)
+	if err != nil {
+		return nil, err
+	}
+	if s.failAt != 0 && start == s.failAt {
+		return nil, fmt.Errorf("request timed out")
+	}
+
+	var sets []map[string]interface{}
+	for n := start; n <= end; n++ {
+		var changes [][]interface{}
+		for _, key := range keys {
+			value, ok := s.value(key, n)
+			previous, wasOk := s.value(key, n-1)
+			if n != start && value == previous && ok == wasOk {
+				continue
+			}
+			if ok {
+				changes = append(changes, []interface{}{key, value})
+			} else {
+				changes = append(changes, []interface{}{key})
+			}
+		}
+		if n == start || len(changes) > 0 {
+			sets = append(sets, map[string]interface{}{"block": historyBlockHash(n).Hex(), "changes": changes})
+		}
+	}
+	return sets, nil
+}
+
+func newHistoryMock(t *testing.T, blocks int) (*State, *historyMockSrv) {
+	srv := &historyMockSrv{blocks: blocks, lookups: make(map[uint64]int)}
+	s := rpcmocksrv.New()
+	assert.NoError(t, s.RegisterName("chain", srv))
+	assert.NoError(t, s.RegisterName("state", srv))
+	cl, err := client.Connect(s.URL)
+	assert.NoError(t, err)
+	return NewState(cl), srv
+}
+
+func TestState_QueryStorageRange(t *testing.T) {
+	st, srv := newHistoryMock(t, 200)
+	keys := []types.StorageKey{{0x01}, {0x02}}
+
+	expected, err := st.QueryStorage(keys, historyBlockHash(10), historyBlockHash(150))
+	assert.NoError(t, err)
+	// the first block and every 7th block change the first key, block 51 removes the second key
+	assert.Len(t, expected, 1+20+1)
+
+	for _, chunkSize := range []uint32{1, 7, 13, 50, 141, 1000} {
+		srv.maxRun = 0
+		srv.lookups = make(map[uint64]int)
+		sets, err := st.QueryStorageRange(context.Background(), keys, 10, 150,
+			QueryStorageRangeOptions{ChunkSize: chunkSize, Concurrency: 3})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, sets, "chunk size %v", chunkSize)
+		assert.True(t, srv.maxRun <= 3)
+
+		// every chunk boundary is looked up once
+		boundaries := map[uint64]int{150: 1}
+		for n := uint64(10); n < 150; n += uint64(chunkSize) {
+			boundaries[n] = 1
+		}
+		assert.Equal(t, boundaries, srv.lookups, "chunk size %v", chunkSize)
+	}
+
+	expected, err = st.QueryStorage(keys, historyBlockHash(14), historyBlockHash(14))
+	assert.NoError(t, err)
+	sets, err := st.QueryStorageRange(context.Background(), keys, 14, 14, QueryStorageRangeOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, sets)
+}
+
+func TestState_QueryStorageRange_Errors(t *testing.T) {
+	st, srv := newHistoryMock(t, 200)
+	keys := []types.StorageKey{{0x01}}
+
+	_, err := st.QueryStorageRange(context.Background(), keys, 20, 10, QueryStorageRangeOptions{})
+	assert.EqualError(t, err, "invalid block range, from 20 is after to 10")
+
+	srv.failAt = 60
+	_, err = st.QueryStorageRange(context.Background(), keys, 0, 199, QueryStorageRangeOptions{ChunkSize: 20})
+	assert.EqualError(t, err, "unable to query storage from block 60 to 80: request timed out")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	srv.lookups = make(map[uint64]int)
+	_, err = st.QueryStorageRange(ctx, keys, 0, 199, QueryStorageRangeOptions{})
+	assert.Equal(t, context.Canceled, err)
+	assert.Empty(t, srv.lookups)
+}
+
+func TestDecodeStorageChanges(t *testing.T) {
+	st, _ := newHistoryMock(t, 30)
+	sets, err := st.QueryStorageRange(context.Background(), []types.StorageKey{{0x01}, {0x02}}, 0, 29,
+		QueryStorageRangeOptions{ChunkSize: 10})
+	assert.NoError(t, err)
+
+	changes, err := DecodeStorageChanges(sets, func() interface{} { return new(types.U32) })
+	assert.NoError(t, err)
+	u32 := func(v types.U32) *types.U32 { return &v }
+	assert.Equal(t, []DecodedStorageChange{
+		{Block: historyBlockHash(0), Key: types.StorageKey{0x01}, HasValue: true, Value: u32(0)},
+		{Block: historyBlockHash(0), Key: types.StorageKey{0x02}, HasValue: true, Value: u32(0)},
+		{Block: historyBlockHash(7), Key: types.StorageKey{0x01}, HasValue: true, Value: u32(1)},
+		{Block: historyBlockHash(14), Key: types.StorageKey{0x01}, HasValue: true, Value: u32(2)},
+		{Block: historyBlockHash(21), Key: types.StorageKey{0x01}, HasValue: true, Value: u32(3)},
+		{Block: historyBlockHash(28), Key: types.StorageKey{0x01}, HasValue: true, Value: u32(4)},
+	}, changes)
+}
@@ -81,6 +81,30 @@ func (s *MockSrv) GetKeys(key string, hash *string) []string {
 	return []string{mockSrv.storageKeyHex}
 }
 
+func (s *MockSrv) GetKeysPaged(key string, count uint32, startKey *string, hash *string) []string {
+	if !strings.HasPrefix(mockSrv.storageKeyHex, key) || count == 0 ||
+		(startKey != nil && *startKey >= mockSrv.storageKeyHex) {
+		return []string{}
+	}
+	return []string{mockSrv.storageKeyHex}
+}
+
+func (s *MockSrv) QueryStorageAt(keys []string, hash *string) []types.StorageChangeSet {
+	changes := make([]types.KeyValueOption, len(keys))
+	for i, key := range keys {
+		changes[i] = types.KeyValueOption{StorageKey: types.MustHexDecodeString(key)}
+		if key == mockSrv.storageKeyHex {
+			changes[i].HasStorageData = true
+			changes[i].StorageData = types.MustHexDecodeString(mockSrv.storageDataHex)
+		}
+	}
+	return []types.StorageChangeSet{{Block: mockSrv.blockHashLatest, Changes: changes}}
+}
+
+func (s *MockSrv) GetReadProof(keys []string, hash *string) types.ReadProof {
+	return types.ReadProof{At: mockSrv.blockHashLatest, Proof: []types.Bytes{{0x00}}}
+}
+
 func (s *MockSrv) GetStorage(key string, hash *string) string {
 	if key != s.storageKeyHex {
 		return ""
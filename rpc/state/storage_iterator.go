@@ -0,0 +1,165 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/chain"
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/internal/paging"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+	"github.com/zenghq3/go-substrate-rpc-client/xxhash"
+)
+
+// DefaultStorageIteratorPageSize is the number of keys a StorageIterator fetches per request unless PageSize is set
+const DefaultStorageIteratorPageSize = paging.DefaultPageSize
+
+// StorageEntry is a storage entry, as returned by a StorageIterator
+type StorageEntry struct {
+	Key types.StorageKey
+	// KeyParts holds the parts of the key, which allow to recover the map keys stored in plain text. It is only set
+	// when iterating over a storage map with IterateStorage.
+	KeyParts *types.StorageKeyParts
+	Value    types.StorageDataRaw
+}
+
+// DecodeKeys decodes the map keys of the entry into the given targets, see types.StorageKeyParts.DecodeKeys
+func (e StorageEntry) DecodeKeys(targets ...interface{}) error {
+	return e.KeyParts.DecodeKeys(targets...)
+}
+
+// DecodeValue decodes the value of the entry into target
+func (e StorageEntry) DecodeValue(target interface{}) error {
+	return types.DecodeFromBytes(e.Value, target)
+}
+
+// StorageIterator iterates over all entries of a storage map or double map, or over all entries with a given key
+// prefix, at a pinned block. Keys are fetched page by page with state_getKeysPaged and the values of each page with a single state_queryStorageAt request. Requests
+// are only made when all entries fetched so far have been consumed, so the memory used does not depend on the size of
+// the map.
+//
+// Use it like this:
+//
+//	it, err := api.RPC.State.IterateStorageLatest(ctx, meta, "System", "Account")
+//	if err != nil {
+//		return err
+//	}
+//	for it.Next() {
+//		var account types.AccountID
+//		err = it.Entry().DecodeKeys(&account)
+//		...
+//	}
+//	if it.Err() != nil {
+//		return it.Err()
+//	}
+//
+// The embedded iterator provides Next, Err and the PageSize, the number of keys fetched per request, which may be
+// changed before the first call to Next.
+type StorageIterator struct {
+	*paging.Iterator
+
+	state     *State
+	meta      *types.Metadata
+	prefix    types.StorageKey
+	blockHash types.Hash
+}
+
+// IterateStorage returns an iterator over all entries of the given storage map at the given block. The iteration stops
+// with the error of the context once it is done.
+func (s *State) IterateStorage(ctx context.Context, meta *types.Metadata, module, fn string, blockHash types.Hash) (
+	*StorageIterator, error) {
+	if meta.Version < 9 {
+		return nil, fmt.Errorf("iterating storage maps requires metadata v9 or later, but got v%v", meta.Version)
+	}
+
+	entry, err := meta.FindStorageEntryMetadata(module, fn)
+	if err != nil {
+		return nil, err
+	}
+	if entry.IsPlain() {
+		return nil, fmt.Errorf("%v.%v is not a storage map", module, fn)
+	}
+
+	prefix := append(xxhash.New128([]byte(module)).Sum(nil), xxhash.New128([]byte(fn)).Sum(nil)...)
+
+	it := s.IterateKeys(ctx, prefix, blockHash)
+	it.meta = meta
+	return it, nil
+}
+
+// IterateKeys returns an iterator over all entries with the given key prefix at the given block, in the order of their
+// keys. Pass an empty prefix to iterate over the whole state. The entries have no KeyParts. The iteration stops with the
+// error of the context once it is done.
+func (s *State) IterateKeys(ctx context.Context, prefix types.StorageKey, blockHash types.Hash) *StorageIterator {
+	it := &StorageIterator{state: s, prefix: prefix, blockHash: blockHash}
+	it.Iterator = paging.NewIterator(ctx, it.listKeys, it.fetchEntries)
+	return it
+}
+
+// IterateStorageLatest returns an iterator over all entries of the given storage map, pinned to the latest block
+func (s *State) IterateStorageLatest(ctx context.Context, meta *types.Metadata, module, fn string) (
+	*StorageIterator, error) {
+	blockHash, err := chain.NewChain(s.client).GetBlockHashLatest()
+	if err != nil {
+		return nil, err
+	}
+	return s.IterateStorage(ctx, meta, module, fn, blockHash)
+}
+
+// Entry returns the current entry
+func (it *StorageIterator) Entry() StorageEntry {
+	entry, _ := it.Iterator.Entry().(StorageEntry)
+	return entry
+}
+
+// BlockHash returns the hash of the block the iteration is pinned to
+func (it *StorageIterator) BlockHash() types.Hash {
+	return it.blockHash
+}
+
+// listKeys lists the keys of the next page with state_getKeysPaged
+func (it *StorageIterator) listKeys(pageSize uint32, startKey types.StorageKey) ([]types.StorageKey, error) {
+	return it.state.getKeysPaged(it.prefix, pageSize, startKey, &it.blockHash)
+}
+
+// fetchEntries fetches the values of the keys with a single state_queryStorageAt request and splits the keys of storage
+// maps
+func (it *StorageIterator) fetchEntries(keys []types.StorageKey) ([]interface{}, error) {
+	sets, err := it.state.queryStorageAt(keys, &it.blockHash)
+	if err != nil {
+		return nil, err
+	}
+	values := storageValues(sets)
+
+	var entries []interface{}
+	for _, key := range keys {
+		value, ok := values[string(key)]
+		if !ok {
+			continue
+		}
+		entry := StorageEntry{Key: key, Value: value}
+		if it.meta != nil {
+			entry.KeyParts, err = types.SplitStorageKey(it.meta, key)
+			if err != nil {
+				return nil, err
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
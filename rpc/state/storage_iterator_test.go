@@ -0,0 +1,199 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenghq3/go-substrate-rpc-client/client"
+	"github.com/zenghq3/go-substrate-rpc-client/rpcmocksrv"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// storageMapMockSrv serves the entries of a single storage map through state_getKeysPaged and state_queryStorageAt
+type storageMapMockSrv struct {
+	blockHash types.Hash
+	keys      []string // sorted hex keys
+	values    map[string]string
+	hashes    []string // the block hashes requested
+}
+
+func (s *storageMapMockSrv) GetKeysPaged(prefix string, count uint32, startKey *string, hash *string) []string {
+	s.hashes = append(s.hashes, *hash)
+
+	var keys []string
+	for _, k := range s.keys {
+		if strings.HasPrefix(k, prefix) && (startKey == nil || k > *startKey) && len(keys) < int(count) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func (s *storageMapMockSrv) QueryStorageAt(keys []string, hash *string) []map[string]interface{} {
+	s.hashes = append(s.hashes, *hash)
+
+	changes := make([][]string, len(keys))
+	for i, k := range keys {
+		changes[i] = []string{k, s.values[k]}
+	}
+	return []map[string]interface{}{{"block": *hash, "changes": changes}}
+}
+
+func (s *storageMapMockSrv) GetBlockHash(height *uint64) string {
+	return s.blockHash.Hex()
+}
+
+// storageMapMetadata returns metadata with the storage map Bounties.Entries, mapping u32 to u32 with Twox64Concat
+func storageMapMetadata() *types.Metadata {
+	meta := types.NewMetadataV14()
+	meta.AsMetadataV14.Lookup = types.PortableRegistry{
+		{ID: types.NewSi1LookupTypeID(0), Type: types.Si1Type{Def: types.Si1TypeDef{IsPrimitive: true,
+			AsPrimitive: types.IsU32}}},
+	}
+	meta.AsMetadataV14.Pallets = []types.PalletMetadataV14{{
+		Name:       "Bounties",
+		HasStorage: true,
+		Storage: types.StorageMetadataV14{Prefix: "Bounties", Items: []types.StorageEntryMetadataV14{
+			{Name: "Count", Type: types.StorageEntryTypeV14{IsPlainType: true, AsPlainType: types.NewSi1LookupTypeID(0)}},
+			{Name: "Entries", Type: types.StorageEntryTypeV14{IsMap: true, AsMap: types.MapTypeV14{
+				Hashers: []types.StorageHasherV11{{IsTwox64Concat: true}},
+				Key:     types.NewSi1LookupTypeID(0),
+				Value:   types.NewSi1LookupTypeID(0),
+			}}},
+		}},
+	}}
+	return meta
+}
+
+func newStorageMapMock(t *testing.T, meta *types.Metadata, n int) (*State, *storageMapMockSrv) {
+	srv := &storageMapMockSrv{
+		blockHash: types.NewHash([]byte{0x01, 0x02}),
+		values:    make(map[string]string),
+	}
+	for i := 0; i < n; i++ {
+		arg, err := types.EncodeToBytes(types.U32(i))
+		assert.NoError(t, err)
+		key, err := types.CreateStorageKey(meta, "Bounties", "Entries", arg, nil)
+		assert.NoError(t, err)
+		value, err := types.EncodeToHexString(types.U32(i * 10))
+		assert.NoError(t, err)
+		srv.keys = append(srv.keys, key.Hex())
+		srv.values[key.Hex()] = value
+	}
+	sort.Strings(srv.keys)
+
+	s := rpcmocksrv.New()
+	assert.NoError(t, s.RegisterName("state", srv))
+	assert.NoError(t, s.RegisterName("chain", srv))
+	cl, err := client.Connect(s.URL)
+	assert.NoError(t, err)
+
+	return NewState(cl), srv
+}
+
+func TestState_IterateStorage(t *testing.T) {
+	meta := storageMapMetadata()
+	s, srv := newStorageMapMock(t, meta, 25)
+
+	it, err := s.IterateStorageLatest(context.Background(), meta, "Bounties", "Entries")
+	assert.NoError(t, err)
+	assert.Equal(t, srv.blockHash, it.BlockHash())
+	it.PageSize = 10
+
+	values := make(map[types.U32]types.U32)
+	for it.Next() {
+		var key, value types.U32
+		assert.NoError(t, it.Entry().DecodeKeys(&key))
+		assert.NoError(t, it.Entry().DecodeValue(&value))
+		values[key] = value
+	}
+	assert.NoError(t, it.Err())
+
+	assert.Len(t, values, 25)
+	for k, v := range values {
+		assert.Equal(t, k*10, v)
+	}
+
+	// 3 pages of keys and values, all at the pinned block
+	assert.Len(t, srv.hashes, 6)
+	for _, h := range srv.hashes {
+		assert.Equal(t, srv.blockHash.Hex(), h)
+	}
+}
+
+func TestState_IterateStorage_Cancel(t *testing.T) {
+	meta := storageMapMetadata()
+	s, srv := newStorageMapMock(t, meta, 25)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it, err := s.IterateStorage(ctx, meta, "Bounties", "Entries", srv.blockHash)
+	assert.NoError(t, err)
+	it.PageSize = 10
+
+	n := 0
+	for it.Next() {
+		n++
+		if n == 5 {
+			cancel()
+		}
+	}
+	assert.Equal(t, 5, n)
+	assert.Equal(t, context.Canceled, it.Err())
+	assert.Len(t, srv.hashes, 2)
+}
+
+func TestState_IterateKeys(t *testing.T) {
+	meta := storageMapMetadata()
+	s, srv := newStorageMapMock(t, meta, 25)
+	srv.keys = append(srv.keys, "0x3a636f6465")
+	sort.Strings(srv.keys)
+	srv.values["0x3a636f6465"] = "0x0102"
+
+	it := s.IterateKeys(context.Background(), nil, srv.blockHash)
+	it.PageSize = 10
+
+	var keys []string
+	for it.Next() {
+		assert.Nil(t, it.Entry().KeyParts)
+		assert.Equal(t, srv.values[it.Entry().Key.Hex()], it.Entry().Value.Hex())
+		keys = append(keys, it.Entry().Key.Hex())
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, srv.keys, keys)
+	assert.Len(t, srv.hashes, 6)
+
+	it = s.IterateKeys(context.Background(), types.StorageKey{0x3a}, srv.blockHash)
+	assert.True(t, it.Next())
+	assert.Equal(t, "0x3a636f6465", it.Entry().Key.Hex())
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Err())
+}
+
+func TestState_IterateStorage_Errors(t *testing.T) {
+	_, err := state.IterateStorage(context.Background(), storageMapMetadata(), "Bounties", "Count", types.Hash{})
+	assert.EqualError(t, err, "Bounties.Count is not a storage map")
+
+	_, err = state.IterateStorage(context.Background(), types.ExamplaryMetadataV4, "System", "AccountNonce",
+		types.Hash{})
+	assert.EqualError(t, err, "iterating storage maps requires metadata v9 or later, but got v4")
+}
@@ -17,19 +17,38 @@
 package rpcmocksrv
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	gethrpc "github.com/zenghq3/go-substrate-rpc-client/gethrpc"
 	"github.com/stretchr/testify/assert"
 )
 
 type TestService struct {
+	unsubscribed chan struct{}
 }
 
 func (ts *TestService) Ping(s string) string {
 	return s
 }
 
+// SubscribeCount notifies the numbers from 1 to n
+func (ts *TestService) SubscribeCount(ctx context.Context, n int) (*gethrpc.Subscription, error) {
+	notifier, _ := gethrpc.NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+	go func() {
+		for i := 1; i <= n; i++ {
+			_ = notifier.Notify(sub.ID, i)
+		}
+		<-sub.Err()
+		if ts.unsubscribed != nil {
+			close(ts.unsubscribed)
+		}
+	}()
+	return sub, nil
+}
+
 func TestServer(t *testing.T) {
 	s := New()
 
@@ -46,3 +65,41 @@ func TestServer(t *testing.T) {
 
 	assert.Equal(t, "hello", res)
 }
+
+func TestServer_Subscribe(t *testing.T) {
+	s := New()
+	ts := &TestService{unsubscribed: make(chan struct{})}
+	assert.NoError(t, s.RegisterName("testserv4", ts))
+
+	c, err := gethrpc.Dial(s.URL)
+	assert.NoError(t, err)
+
+	ch := make(chan int)
+	sub, err := c.Subscribe(context.Background(), "testserv4", "subscribeCount", "unsubscribeCount", "count", ch, 3)
+	assert.NoError(t, err)
+	for i := 1; i <= 3; i++ {
+		assert.Equal(t, i, <-ch)
+	}
+
+	sub.Unsubscribe()
+	assert.NoError(t, <-sub.Err())
+
+	select {
+	case <-ts.unsubscribed:
+	case <-time.After(time.Second):
+		t.Fatal("subscription not unsubscribed on the server")
+	}
+}
+
+func TestServer_Unsubscribe(t *testing.T) {
+	s := New()
+	assert.NoError(t, s.RegisterName("testserv5", new(TestService)))
+
+	c, err := gethrpc.Dial(s.URL)
+	assert.NoError(t, err)
+
+	var res bool
+	assert.EqualError(t, c.Call(&res, "testserv5_unsubscribeCount", "42"), "subscription not found")
+	assert.EqualError(t, c.Call(&res, "testserv5_unsubscribeCount", "0xab"),
+		`invalid subscription ID "0xab": strconv.ParseUint: parsing "0xab": invalid syntax`)
+}
@@ -291,6 +291,15 @@ func (pd Decoder) Read(bytes []byte) error {
 	return nil
 }
 
+// Remaining returns the number of unread bytes of the stream if the reader reports it, like bytes.Reader and
+// bytes.Buffer do. Ok is false for other readers.
+func (pd Decoder) Remaining() (n int, ok bool) {
+	if r, ok := pd.reader.(interface{ Len() int }); ok {
+		return r.Len(), true
+	}
+	return 0, false
+}
+
 // ReadOneByte reads a next byte from the stream.
 // Named so to avoid a linter warning about a clash with io.ByteReader.ReadByte
 func (pd Decoder) ReadOneByte() (byte, error) {
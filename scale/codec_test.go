@@ -17,6 +17,7 @@ package scale
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"math"
 	"reflect"
 	"strings"
@@ -254,3 +255,18 @@ func TestCompactIntegersEncodedAsExpected(t *testing.T) {
 		assertEqual(t, decoded, value)
 	}
 }
+
+func TestDecoder_Remaining(t *testing.T) {
+	decoder := NewDecoder(bytes.NewReader([]byte{1, 2, 3}))
+	_, err := decoder.ReadOneByte()
+	assert.NoError(t, err)
+	n, ok := decoder.Remaining()
+	assert.True(t, ok)
+	assert.Equal(t, 2, n)
+
+	_, ok = NewDecoder(strings.NewReader("abc")).Remaining()
+	assert.True(t, ok)
+
+	_, ok = NewDecoder(struct{ io.Reader }{bytes.NewReader(nil)}).Remaining()
+	assert.False(t, ok)
+}
@@ -0,0 +1,195 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/zenghq3/go-substrate-rpc-client/client"
+	gethrpc "github.com/zenghq3/go-substrate-rpc-client/gethrpc"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// Client is a read-only client.Client serving RPC calls from a snapshot. It supports state_getStorage,
+// state_getStorageSize, state_getKeys, state_getKeysPaged, state_queryStorageAt and state_getMetadata, as well as
+// chain_getBlockHash without block number, which returns the block of the snapshot. Calls without block hash are served
+// from the snapshot as well, calls with the hash of another block fail, as do calls for keys the snapshot does not
+// cover.
+type Client struct {
+	snapshot *Snapshot
+}
+
+var _ client.Client = &Client{}
+
+// NewClient creates a new Client serving the given snapshot
+func NewClient(s *Snapshot) *Client {
+	return &Client{s}
+}
+
+// URL returns a pseudo URL naming the block of the snapshot
+func (c *Client) URL() string {
+	return "snapshot:" + c.snapshot.BlockHash.Hex()
+}
+
+// Subscribe fails, a snapshot does not change
+func (c *Client) Subscribe(ctx context.Context, namespace, subscribeMethodSuffix, unsubscribeMethodSuffix,
+	notificationMethodSuffix string, channel interface{}, args ...interface{}) (*gethrpc.ClientSubscription, error) {
+	return nil, fmt.Errorf("%v_%v is not supported by snapshots", namespace, subscribeMethodSuffix)
+}
+
+// Call performs the call against the snapshot and stores the result in result, like a node would
+func (c *Client) Call(result interface{}, method string, args ...interface{}) error {
+	res, err := c.call(method, args)
+	if err != nil {
+		return err
+	}
+	// round trip through JSON to fill result exactly as a client connected to a node does
+	bz, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bz, result)
+}
+
+func (c *Client) call(method string, args []interface{}) (interface{}, error) {
+	var hash *string
+	switch method {
+	case "state_getStorage", "state_getStorageSize":
+		var key types.Bytes
+		err := c.params(method, args, &hash, &key)
+		if err != nil {
+			return nil, err
+		}
+		value, ok, err := c.get(key)
+		if err != nil || !ok {
+			return nil, err
+		}
+		if method == "state_getStorageSize" {
+			return types.U64(len(value)), nil
+		}
+		return types.Bytes(value), nil
+	case "state_getKeys":
+		var prefix types.Bytes
+		err := c.params(method, args, &hash, &prefix)
+		if err != nil {
+			return nil, err
+		}
+		return c.keys(prefix, nil, -1)
+	case "state_getKeysPaged":
+		var prefix, startKey types.Bytes
+		var count uint32
+		err := c.params(method, args, &hash, &prefix, &count, &startKey)
+		if err != nil {
+			return nil, err
+		}
+		return c.keys(prefix, startKey, int(count))
+	case "state_queryStorageAt":
+		var keys []types.Bytes
+		err := c.params(method, args, &hash, &keys)
+		if err != nil {
+			return nil, err
+		}
+		set := types.StorageChangeSet{Block: c.snapshot.BlockHash, Changes: make([]types.KeyValueOption, len(keys))}
+		for i, key := range keys {
+			value, ok, err := c.get(key)
+			if err != nil {
+				return nil, err
+			}
+			set.Changes[i] = types.KeyValueOption{StorageKey: types.StorageKey(key), HasStorageData: ok,
+				StorageData: value}
+		}
+		return []types.StorageChangeSet{set}, nil
+	case "state_getMetadata":
+		err := c.params(method, args, &hash)
+		if err != nil {
+			return nil, err
+		}
+		return c.snapshot.Metadata, nil
+	case "chain_getBlockHash":
+		if len(args) > 0 && args[0] != nil {
+			return nil, fmt.Errorf("chain_getBlockHash with a block number is not supported by snapshots")
+		}
+		return c.snapshot.BlockHash, nil
+	default:
+		return nil, fmt.Errorf("%v is not supported by snapshots", method)
+	}
+}
+
+// params decodes the arguments of a call into the targets, like a node does. Missing trailing arguments leave their
+// targets unchanged. The argument following the targets is the optional block hash, which must be the hash of the block
+// of the snapshot.
+func (c *Client) params(method string, args []interface{}, hash **string, targets ...interface{}) error {
+	if len(args) > len(targets)+1 {
+		return fmt.Errorf("too many params for %v, expected at most %v, got %v", method, len(targets)+1, len(args))
+	}
+	targets = append(targets, hash)
+	for i, arg := range args {
+		bz, err := json.Marshal(arg)
+		if err != nil {
+			return err
+		}
+		err = json.Unmarshal(bz, targets[i])
+		if err != nil {
+			return fmt.Errorf("invalid param %v for %v: %v", i, method, err)
+		}
+	}
+
+	if *hash != nil && **hash != c.snapshot.BlockHash.Hex() {
+		return fmt.Errorf("snapshot holds the state at block %v, not at block %v", c.snapshot.BlockHash.Hex(), **hash)
+	}
+	return nil
+}
+
+// get returns the value of key, or false if key is not set
+func (c *Client) get(key []byte) ([]byte, bool, error) {
+	if !c.snapshot.Covers(key) {
+		return nil, false, fmt.Errorf("key %#x is not covered by the snapshot", key)
+	}
+	entries := c.snapshot.Entries
+	i := sort.Search(len(entries), func(i int) bool { return bytes.Compare(entries[i].Key, key) >= 0 })
+	if i < len(entries) && bytes.Equal(entries[i].Key, key) {
+		return entries[i].Value, true, nil
+	}
+	return nil, false, nil
+}
+
+// keys returns at most count keys with the given prefix after startKey, or all keys if count is negative
+func (c *Client) keys(prefix, startKey []byte, count int) ([]types.Bytes, error) {
+	if !c.snapshot.Covers(prefix) {
+		return nil, fmt.Errorf("prefix %#x is not covered by the snapshot", prefix)
+	}
+	entries := c.snapshot.Entries
+	from := prefix
+	if bytes.Compare(startKey, prefix) > 0 {
+		from = startKey
+	}
+	i := sort.Search(len(entries), func(i int) bool { return bytes.Compare(entries[i].Key, from) >= 0 })
+
+	keys := []types.Bytes{}
+	for ; i < len(entries) && bytes.HasPrefix(entries[i].Key, prefix) && count != 0; i++ {
+		if bytes.Equal(entries[i].Key, startKey) {
+			continue
+		}
+		keys = append(keys, entries[i].Key)
+		count--
+	}
+	return keys, nil
+}
@@ -0,0 +1,103 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/state"
+	. "github.com/zenghq3/go-substrate-rpc-client/snapshot"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+func newReplayState(t *testing.T, prefixes ...types.StorageKey) (*state.State, *Snapshot) {
+	cl, srv := newStateMock(t)
+	snap, err := Export(context.Background(), cl, srv.blockHash, prefixes...)
+	assert.NoError(t, err)
+	return state.NewState(NewClient(snap)), snap
+}
+
+func TestClient_GetStorage(t *testing.T) {
+	s, snap := newReplayState(t, types.StorageKey{0x01})
+
+	var value types.U32
+	ok, err := s.GetStorageLatest(types.StorageKey{0x01, 0x02}, &value)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, types.U32(2), value)
+
+	ok, err = s.GetStorage(types.StorageKey{0x01, 0x0a}, &value, snap.BlockHash)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	size, err := s.GetStorageSizeLatest(types.StorageKey{0x01, 0x02})
+	assert.NoError(t, err)
+	assert.Equal(t, types.U64(4), size)
+
+	_, err = s.GetStorage(types.StorageKey{0x01, 0x02}, &value, types.NewHash([]byte{0x09}))
+	assert.EqualError(t, err, "snapshot holds the state at block "+snap.BlockHash.Hex()+", not at block "+
+		types.NewHash([]byte{0x09}).Hex())
+
+	_, err = s.GetStorageLatest(types.StorageKey{0x02, 0x00}, &value)
+	assert.EqualError(t, err, "key 0x0200 is not covered by the snapshot")
+}
+
+func TestClient_GetKeys(t *testing.T) {
+	s, snap := newReplayState(t)
+
+	keys, err := s.GetKeysLatest(types.StorageKey{0x01})
+	assert.NoError(t, err)
+	assert.Len(t, keys, 10)
+	assert.Equal(t, types.StorageKey{0x01, 0x00}, keys[0])
+
+	keys, err = s.GetKeysPaged(types.StorageKey{0x01}, 3, types.StorageKey{0x01, 0x08}, snap.BlockHash)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.StorageKey{{0x01, 0x09}}, keys)
+
+	keys, err = s.GetKeysPagedLatest(nil, 3, types.StorageKey{0x01, 0x09})
+	assert.NoError(t, err)
+	assert.Equal(t, []types.StorageKey{{0x02, 0x00}, {0x03, 0x00}}, keys)
+}
+
+func TestClient_GetMetadata(t *testing.T) {
+	s, _ := newReplayState(t, types.StorageKey{0x01})
+	meta, err := s.GetMetadataLatest()
+	assert.NoError(t, err)
+	assert.Equal(t, types.ExamplaryMetadataV11Substrate, meta)
+}
+
+func TestClient_QueryStorageAt(t *testing.T) {
+	s, snap := newReplayState(t, types.StorageKey{0x01})
+	sets, err := s.QueryStorageAtLatest([]types.StorageKey{{0x01, 0x01}, {0x01, 0x0a}})
+	assert.NoError(t, err)
+	assert.Equal(t, []types.StorageChangeSet{{Block: snap.BlockHash, Changes: []types.KeyValueOption{
+		{StorageKey: types.StorageKey{0x01, 0x01}, HasStorageData: true, StorageData: types.StorageDataRaw{1, 0, 0, 0}},
+		{StorageKey: types.StorageKey{0x01, 0x0a}},
+	}}}, sets)
+}
+
+func TestClient_Unsupported(t *testing.T) {
+	cl := NewClient(&Snapshot{})
+	var res string
+	assert.EqualError(t, cl.Call(&res, "author_submitExtrinsic", "0x00"),
+		"author_submitExtrinsic is not supported by snapshots")
+	_, err := cl.Subscribe(context.Background(), "state", "subscribeStorage", "unsubscribeStorage", "storage",
+		make(chan types.StorageChangeSet))
+	assert.EqualError(t, err, "state_subscribeStorage is not supported by snapshots")
+}
@@ -0,0 +1,172 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package snapshot exports the storage of a block to a file and replays it offline.
+
+A snapshot holds all storage entries under a set of key prefixes, or the whole state, together with the hash of the
+block and the metadata at that block. Client serves the state RPC calls from a snapshot, so code written against
+rpc.State runs against a snapshot unchanged:
+
+	snap, err := snapshot.ReadFile("state.json")
+	if err != nil {
+		return err
+	}
+	s := state.NewState(snapshot.NewClient(snap))
+*/
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/zenghq3/go-substrate-rpc-client/client"
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/state"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// FormatVersion is the version of the file format written by Write. Read rejects files of other versions.
+const FormatVersion = 1
+
+// Snapshot holds the storage entries under a set of key prefixes at a block
+type Snapshot struct {
+	Version   uint32     `json:"version"`
+	BlockHash types.Hash `json:"blockHash"`
+	// Metadata holds the SCALE encoded metadata at the block, as returned by state_getMetadata
+	Metadata types.Bytes `json:"metadata"`
+	// Prefixes holds the prefixes of the keys exported, no prefixes mean the whole state was exported
+	Prefixes []types.Bytes `json:"prefixes"`
+	// Entries holds the storage entries, sorted by key
+	Entries []Entry `json:"entries"`
+}
+
+// Entry is a storage entry of a snapshot
+type Entry struct {
+	Key   types.Bytes `json:"key"`
+	Value types.Bytes `json:"value"`
+}
+
+// Export reads all storage entries under the given prefixes, or the whole state if no prefixes are given, and the
+// metadata at the given block. Entries are read with State.IterateKeys, page by page.
+func Export(ctx context.Context, cl client.Client, blockHash types.Hash, prefixes ...types.StorageKey) (*Snapshot,
+	error) {
+	snap := &Snapshot{Version: FormatVersion, BlockHash: blockHash}
+
+	var metadata string
+	err := client.CallWithBlockHash(cl, &metadata, "state_getMetadata", &blockHash)
+	if err != nil {
+		return nil, err
+	}
+	snap.Metadata, err = types.HexDecodeString(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	exportPrefixes := prefixes
+	if len(exportPrefixes) == 0 {
+		exportPrefixes = []types.StorageKey{{}}
+	}
+
+	s := state.NewState(cl)
+	values := make(map[string][]byte)
+	for _, prefix := range exportPrefixes {
+		if len(prefixes) > 0 {
+			snap.Prefixes = append(snap.Prefixes, types.Bytes(prefix))
+		}
+
+		it := s.IterateKeys(ctx, prefix, blockHash)
+		for it.Next() {
+			values[string(it.Entry().Key)] = it.Entry().Value
+		}
+		if it.Err() != nil {
+			return nil, it.Err()
+		}
+	}
+
+	snap.Entries = make([]Entry, 0, len(values))
+	for k, v := range values {
+		snap.Entries = append(snap.Entries, Entry{Key: types.Bytes(k), Value: v})
+	}
+	sort.Slice(snap.Entries, func(i, j int) bool {
+		return bytes.Compare(snap.Entries[i].Key, snap.Entries[j].Key) < 0
+	})
+	return snap, nil
+}
+
+// Write writes the snapshot as JSON
+func (s *Snapshot) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(s)
+}
+
+// WriteFile writes the snapshot as JSON to the file with the given name, creating or truncating it
+func (s *Snapshot) WriteFile(name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	err = s.Write(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read reads a snapshot written by Write
+func Read(r io.Reader) (*Snapshot, error) {
+	var s Snapshot
+	err := json.NewDecoder(r).Decode(&s)
+	if err != nil {
+		return nil, err
+	}
+	if s.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %v, expected %v", s.Version, FormatVersion)
+	}
+	// files may have been edited by hand
+	sort.Slice(s.Entries, func(i, j int) bool {
+		return bytes.Compare(s.Entries[i].Key, s.Entries[j].Key) < 0
+	})
+	return &s, nil
+}
+
+// ReadFile reads a snapshot from the file with the given name
+func ReadFile(name string) (*Snapshot, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Covers returns whether the snapshot holds all entries of keys with the given prefix, that is whether the prefix
+// starts with one of the prefixes exported
+func (s *Snapshot) Covers(prefix []byte) bool {
+	if len(s.Prefixes) == 0 {
+		return true
+	}
+	for _, p := range s.Prefixes {
+		if bytes.HasPrefix(prefix, p) {
+			return true
+		}
+	}
+	return false
+}
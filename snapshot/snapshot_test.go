@@ -0,0 +1,156 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenghq3/go-substrate-rpc-client/client"
+	"github.com/zenghq3/go-substrate-rpc-client/rpcmocksrv"
+	. "github.com/zenghq3/go-substrate-rpc-client/snapshot"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// stateMockSrv serves a state with the keys 0x0100 to 0x0109, 0x0200 and 0x0300 through state_getKeysPaged,
+// state_queryStorageAt and state_getMetadata
+type stateMockSrv struct {
+	blockHash types.Hash
+	metadata  string
+	keys      []string
+	values    map[string]string
+}
+
+func newStateMockSrv(t *testing.T) *stateMockSrv {
+	metadata, err := types.EncodeToHexString(types.ExamplaryMetadataV11Substrate)
+	assert.NoError(t, err)
+	srv := &stateMockSrv{
+		blockHash: types.NewHash([]byte{0x01, 0x02, 0x03}),
+		metadata:  metadata,
+		values:    make(map[string]string),
+	}
+	for _, key := range [][]byte{{0x02, 0x00}, {0x03, 0x00}} {
+		srv.keys = append(srv.keys, types.HexEncodeToString(key))
+	}
+	for i := 0; i < 10; i++ {
+		srv.keys = append(srv.keys, types.HexEncodeToString([]byte{0x01, byte(i)}))
+	}
+	sort.Strings(srv.keys)
+	for i, key := range srv.keys {
+		srv.values[key] = types.HexEncodeToString([]byte{byte(i), 0, 0, 0})
+	}
+	return srv
+}
+
+func (s *stateMockSrv) GetKeysPaged(prefix string, count uint32, startKey *string, hash *string) []string {
+	if *hash != s.blockHash.Hex() {
+		panic("unexpected block hash " + *hash)
+	}
+	keys := []string{}
+	for _, k := range s.keys {
+		if strings.HasPrefix(k, prefix) && (startKey == nil || k > *startKey) && len(keys) < int(count) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func (s *stateMockSrv) QueryStorageAt(keys []string, hash *string) []map[string]interface{} {
+	changes := make([][]string, len(keys))
+	for i, k := range keys {
+		changes[i] = []string{k, s.values[k]}
+	}
+	return []map[string]interface{}{{"block": *hash, "changes": changes}}
+}
+
+func (s *stateMockSrv) GetMetadata(hash *string) string {
+	return s.metadata
+}
+
+func newStateMock(t *testing.T) (client.Client, *stateMockSrv) {
+	srv := newStateMockSrv(t)
+	s := rpcmocksrv.New()
+	assert.NoError(t, s.RegisterName("state", srv))
+	cl, err := client.Connect(s.URL)
+	assert.NoError(t, err)
+	return cl, srv
+}
+
+func TestExport(t *testing.T) {
+	cl, srv := newStateMock(t)
+
+	snap, err := Export(context.Background(), cl, srv.blockHash, types.StorageKey{0x01}, types.StorageKey{0x02})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(FormatVersion), snap.Version)
+	assert.Equal(t, srv.blockHash, snap.BlockHash)
+	assert.Equal(t, types.MustHexDecodeString(srv.metadata), []byte(snap.Metadata))
+	assert.Equal(t, []types.Bytes{{0x01}, {0x02}}, snap.Prefixes)
+	assert.Len(t, snap.Entries, 11)
+	for i, e := range snap.Entries {
+		assert.Equal(t, srv.keys[i], types.HexEncodeToString(e.Key))
+		assert.Equal(t, srv.values[srv.keys[i]], types.HexEncodeToString(e.Value))
+	}
+
+	snap, err = Export(context.Background(), cl, srv.blockHash)
+	assert.NoError(t, err)
+	assert.Empty(t, snap.Prefixes)
+	assert.Len(t, snap.Entries, 12)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Export(ctx, cl, srv.blockHash)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestSnapshot_WriteRead(t *testing.T) {
+	cl, srv := newStateMock(t)
+	snap, err := Export(context.Background(), cl, srv.blockHash, types.StorageKey{0x01})
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, snap.Write(&buf))
+	read, err := Read(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, snap, read)
+
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "snapshot.json")
+	assert.NoError(t, snap.WriteFile(name))
+	read, err = ReadFile(name)
+	assert.NoError(t, err)
+	assert.Equal(t, snap, read)
+
+	_, err = Read(strings.NewReader(`{"version":2}`))
+	assert.EqualError(t, err, "unsupported snapshot version 2, expected 1")
+}
+
+func TestSnapshot_Covers(t *testing.T) {
+	snap := &Snapshot{Prefixes: []types.Bytes{{0x01, 0x02}}}
+	assert.True(t, snap.Covers([]byte{0x01, 0x02}))
+	assert.True(t, snap.Covers([]byte{0x01, 0x02, 0x03}))
+	assert.False(t, snap.Covers([]byte{0x01}))
+	assert.True(t, (&Snapshot{}).Covers(nil))
+}
@@ -0,0 +1,283 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package storagediff compares the storage of two blocks, for example before and after a runtime migration, and reports
+the added, removed and changed keys.
+
+The entries of both blocks are read page by page with State.IterateKeys and compared in the order of their keys, so only
+a page per block is held in memory. Keys and values are decoded through the metadata of the respective block when possible, keys that do not belong to a storage
+entry of the metadata, like :code, are reported with their raw values only. The report can be rendered as text or
+marshalled to JSON:
+
+	report, err := storagediff.Compare(ctx, api.RPC.State, before, after, storagediff.Options{
+		Prefixes: []types.StorageKey{prefix},
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Print(report)
+*/
+package storagediff
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zenghq3/go-substrate-rpc-client/registry"
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/state"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// Action describes how the value of a key changed
+type Action string
+
+const (
+	Added   Action = "added"
+	Removed Action = "removed"
+	Changed Action = "changed"
+)
+
+// Change is a key with different values at both blocks. Old is empty for added keys, New is empty for removed keys.
+// Module, Method, MapKeys, OldValue and NewValue are only set if the key and values could be decoded.
+type Change struct {
+	Key    types.Bytes `json:"key"`
+	Action Action      `json:"action"`
+	Old    types.Bytes `json:"old,omitempty"`
+	New    types.Bytes `json:"new,omitempty"`
+	// Module and Method name the storage entry the key belongs to
+	Module string `json:"module,omitempty"`
+	Method string `json:"method,omitempty"`
+	// MapKeys holds the decoded map keys, as far as they can be recovered from the key
+	MapKeys  []interface{} `json:"mapKeys,omitempty"`
+	OldValue interface{}   `json:"oldValue,omitempty"`
+	NewValue interface{}   `json:"newValue,omitempty"`
+}
+
+// String returns a single line description of the change, e.g. `changed System.Account[0x…]: {…} -> {…}`
+func (c Change) String() string {
+	name := types.HexEncodeToString(c.Key)
+	if c.Module != "" {
+		name = fmt.Sprintf("%v.%v", c.Module, c.Method)
+		if len(c.MapKeys) > 0 {
+			name += fmt.Sprint(c.MapKeys)
+		}
+	}
+
+	oldValue, newValue := interface{}(types.HexEncodeToString(c.Old)), interface{}(types.HexEncodeToString(c.New))
+	if c.OldValue != nil {
+		oldValue = c.OldValue
+	}
+	if c.NewValue != nil {
+		newValue = c.NewValue
+	}
+
+	switch c.Action {
+	case Added:
+		return fmt.Sprintf("%v %v: %v", c.Action, name, newValue)
+	case Removed:
+		return fmt.Sprintf("%v %v: %v", c.Action, name, oldValue)
+	default:
+		return fmt.Sprintf("%v %v: %v -> %v", c.Action, name, oldValue, newValue)
+	}
+}
+
+// Report holds the differences of the storage between two blocks, ordered by key
+type Report struct {
+	From types.Hash `json:"from"`
+	To   types.Hash `json:"to"`
+	// Prefixes holds the prefixes of the keys compared, no prefixes mean the whole state was compared
+	Prefixes []types.Bytes `json:"prefixes"`
+	Changes  []Change      `json:"changes"`
+}
+
+// ByAction returns the changes with the given action
+func (r *Report) ByAction(action Action) []Change {
+	var changes []Change
+	for _, c := range r.Changes {
+		if c.Action == action {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// String renders the report as human readable text, with one line per change
+func (r *Report) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "storage %v -> %v: %v added, %v removed, %v changed\n", r.From.Hex(), r.To.Hex(),
+		len(r.ByAction(Added)), len(r.ByAction(Removed)), len(r.ByAction(Changed)))
+	for _, c := range r.Changes {
+		sb.WriteString(c.String())
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Options configures Compare
+type Options struct {
+	// Prefixes limits the comparison to keys with one of the prefixes, the whole state is compared if empty
+	Prefixes []types.StorageKey
+	// PageSize is the number of keys fetched per request, state.DefaultStorageIteratorPageSize if zero
+	PageSize uint32
+	// SkipDecoding disables decoding keys and values through the metadata
+	SkipDecoding bool
+	// Registry is used to decode keys and values, registry.NewRegistry() if nil
+	Registry *registry.Registry
+}
+
+// Compare compares the storage at the block from with the storage at the block to
+func Compare(ctx context.Context, s *state.State, from, to types.Hash, opts Options) (*Report, error) {
+	r := &Report{From: from, To: to, Changes: []Change{}}
+	prefixes := opts.Prefixes
+	if len(prefixes) == 0 {
+		prefixes = []types.StorageKey{{}}
+	}
+	for _, p := range opts.Prefixes {
+		r.Prefixes = append(r.Prefixes, types.Bytes(p))
+	}
+
+	for _, prefix := range disjointPrefixes(prefixes) {
+		err := r.compare(ctx, s, prefix, opts.PageSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !opts.SkipDecoding && len(r.Changes) > 0 {
+		err := r.decode(s, opts.Registry)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// disjointPrefixes returns the prefixes in order, leaving out prefixes that start with another prefix, so every key
+// matches at most one of them
+func disjointPrefixes(prefixes []types.StorageKey) []types.StorageKey {
+	sorted := append([]types.StorageKey(nil), prefixes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i], sorted[j]) < 0
+	})
+
+	var disjoint []types.StorageKey
+	for _, p := range sorted {
+		if len(disjoint) > 0 && bytes.HasPrefix(p, disjoint[len(disjoint)-1]) {
+			continue
+		}
+		disjoint = append(disjoint, p)
+	}
+	return disjoint
+}
+
+// compare walks the entries with the given prefix at both blocks in parallel, in the order of their keys, and adds a
+// change for every key with different values. Only a page of entries per block is held at a time.
+func (r *Report) compare(ctx context.Context, s *state.State, prefix types.StorageKey, pageSize uint32) error {
+	from, to := s.IterateKeys(ctx, prefix, r.From), s.IterateKeys(ctx, prefix, r.To)
+	from.PageSize, to.PageSize = pageSize, pageSize
+
+	hasOld, hasNew := from.Next(), to.Next()
+	for hasOld || hasNew {
+		cmp := 0
+		switch {
+		case !hasNew:
+			cmp = -1
+		case !hasOld:
+			cmp = 1
+		default:
+			cmp = bytes.Compare(from.Entry().Key, to.Entry().Key)
+		}
+
+		switch {
+		case cmp < 0:
+			o := from.Entry()
+			r.Changes = append(r.Changes, Change{Key: types.Bytes(o.Key), Action: Removed, Old: types.Bytes(o.Value)})
+			hasOld = from.Next()
+		case cmp > 0:
+			n := to.Entry()
+			r.Changes = append(r.Changes, Change{Key: types.Bytes(n.Key), Action: Added, New: types.Bytes(n.Value)})
+			hasNew = to.Next()
+		default:
+			o, n := from.Entry(), to.Entry()
+			if !bytes.Equal(o.Value, n.Value) {
+				r.Changes = append(r.Changes, Change{Key: types.Bytes(o.Key), Action: Changed, Old: types.Bytes(o.Value),
+					New: types.Bytes(n.Value)})
+			}
+			hasOld, hasNew = from.Next(), to.Next()
+		}
+	}
+
+	if from.Err() != nil {
+		return from.Err()
+	}
+	return to.Err()
+}
+
+// decode decodes the keys and values of the changes through the metadata at both blocks. Keys are decoded through the
+// metadata of the block they are present at, values through the metadata of the block they belong to.
+func (r *Report) decode(s *state.State, reg *registry.Registry) error {
+	if reg == nil {
+		reg = registry.NewRegistry()
+	}
+	fromMeta, err := s.GetMetadata(r.From)
+	if err != nil {
+		return err
+	}
+	toMeta := fromMeta
+	if r.To != r.From {
+		toMeta, err = s.GetMetadata(r.To)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range r.Changes {
+		c := &r.Changes[i]
+		keyMeta := toMeta
+		if c.Action == Removed {
+			keyMeta = fromMeta
+		}
+		// keys that do not belong to a storage entry, or values of unknown types, are left undecoded
+		key, err := reg.DecodeStorageKey(keyMeta, types.StorageKey(c.Key))
+		if err != nil {
+			continue
+		}
+		c.Module, c.Method = key.Module, key.Method
+		for _, k := range key.Keys {
+			if k != nil {
+				c.MapKeys = key.Keys
+				break
+			}
+		}
+		if c.Action != Added {
+			v, err := reg.DecodeStorage(fromMeta, c.Module, c.Method, types.StorageDataRaw(c.Old))
+			if err == nil {
+				c.OldValue = v
+			}
+		}
+		if c.Action != Removed {
+			v, err := reg.DecodeStorage(toMeta, c.Module, c.Method, types.StorageDataRaw(c.New))
+			if err == nil {
+				c.NewValue = v
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storagediff_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenghq3/go-substrate-rpc-client/client"
+	"github.com/zenghq3/go-substrate-rpc-client/rpc/state"
+	"github.com/zenghq3/go-substrate-rpc-client/rpcmocksrv"
+	. "github.com/zenghq3/go-substrate-rpc-client/storagediff"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+var (
+	blockA = types.NewHash([]byte{0x0a})
+	blockB = types.NewHash([]byte{0x0b})
+	code   = types.StorageKey(":code")
+)
+
+// storageMapMetadata returns metadata with the storage map Bounties.Entries, mapping u32 to u32 with Twox64Concat
+func storageMapMetadata() *types.Metadata {
+	meta := types.NewMetadataV14()
+	meta.MagicNumber = types.MagicNumber
+	meta.AsMetadataV14.Lookup = types.PortableRegistry{
+		{ID: types.NewSi1LookupTypeID(0), Type: types.Si1Type{Def: types.Si1TypeDef{IsPrimitive: true,
+			AsPrimitive: types.IsU32}}},
+	}
+	meta.AsMetadataV14.Pallets = []types.PalletMetadataV14{{
+		Name:       "Bounties",
+		HasStorage: true,
+		Storage: types.StorageMetadataV14{Prefix: "Bounties", Items: []types.StorageEntryMetadataV14{
+			{Name: "Entries", Modifier: types.StorageFunctionModifierV0{IsOptional: true},
+				Type: types.StorageEntryTypeV14{IsMap: true, AsMap: types.MapTypeV14{
+					Hashers: []types.StorageHasherV11{{IsTwox64Concat: true}},
+					Key:     types.NewSi1LookupTypeID(0),
+					Value:   types.NewSi1LookupTypeID(0),
+				}}},
+		}},
+	}}
+	return meta
+}
+
+// blocksMockSrv serves the storage of two blocks through state_getKeysPaged, state_queryStorageAt and
+// state_getMetadata, and records the storage requests
+type blocksMockSrv struct {
+	metadata string
+	storage  map[string]map[string]string // hex values by hex key by block hash
+	requests []string
+}
+
+func (s *blocksMockSrv) GetKeysPaged(prefix string, count uint32, startKey *string, hash *string) []string {
+	s.requests = append(s.requests, "keys "+*hash)
+
+	var all []string
+	for k := range s.storage[*hash] {
+		all = append(all, k)
+	}
+	sort.Strings(all)
+
+	keys := []string{}
+	for _, k := range all {
+		if strings.HasPrefix(k, prefix) && (startKey == nil || k > *startKey) && len(keys) < int(count) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func (s *blocksMockSrv) QueryStorageAt(keys []string, hash *string) []map[string]interface{} {
+	s.requests = append(s.requests, "values "+*hash)
+
+	changes := make([][]string, len(keys))
+	for i, k := range keys {
+		if v, ok := s.storage[*hash][k]; ok {
+			changes[i] = []string{k, v}
+		} else {
+			changes[i] = []string{k}
+		}
+	}
+	return []map[string]interface{}{{"block": *hash, "changes": changes}}
+}
+
+func (s *blocksMockSrv) GetMetadata(hash *string) string {
+	return s.metadata
+}
+
+func entryKey(t *testing.T, meta *types.Metadata, i uint32) string {
+	arg, err := types.EncodeToBytes(types.U32(i))
+	assert.NoError(t, err)
+	key, err := types.CreateStorageKeyArgs(meta, "Bounties", "Entries", arg)
+	assert.NoError(t, err)
+	return key.Hex()
+}
+
+func u32Hex(v uint32) string {
+	return types.HexEncodeToString([]byte{byte(v), byte(v >> 8), 0, 0})
+}
+
+// newBlocksMock returns a state with Bounties.Entries 0 to 4 at block A and 1 to 5 at block B, where entry 2 changed,
+// and :code changed
+func newBlocksMock(t *testing.T) (*state.State, *blocksMockSrv, *types.Metadata) {
+	meta := storageMapMetadata()
+	metaHex, err := types.EncodeToHexString(meta)
+	assert.NoError(t, err)
+
+	srv := &blocksMockSrv{metadata: metaHex, storage: map[string]map[string]string{
+		blockA.Hex(): {code.Hex(): "0x01"},
+		blockB.Hex(): {code.Hex(): "0x02"},
+	}}
+	for i := uint32(0); i < 5; i++ {
+		srv.storage[blockA.Hex()][entryKey(t, meta, i)] = u32Hex(i * 10)
+		srv.storage[blockB.Hex()][entryKey(t, meta, i+1)] = u32Hex((i + 1) * 10)
+	}
+	srv.storage[blockB.Hex()][entryKey(t, meta, 2)] = u32Hex(200)
+
+	s := rpcmocksrv.New()
+	assert.NoError(t, s.RegisterName("state", srv))
+	cl, err := client.Connect(s.URL)
+	assert.NoError(t, err)
+	return state.NewState(cl), srv, meta
+}
+
+func TestCompare(t *testing.T) {
+	s, srv, meta := newBlocksMock(t)
+
+	r, err := Compare(context.Background(), s, blockA, blockB, Options{PageSize: 2})
+	assert.NoError(t, err)
+	assert.Len(t, r.Changes, 4)
+
+	removed := r.ByAction(Removed)
+	assert.Len(t, removed, 1)
+	assert.Equal(t, entryKey(t, meta, 0), types.HexEncodeToString(removed[0].Key))
+	assert.Equal(t, "Bounties", removed[0].Module)
+	assert.Equal(t, "Entries", removed[0].Method)
+	assert.Equal(t, "removed Bounties.Entries[0]: 0", removed[0].String())
+
+	added := r.ByAction(Added)
+	assert.Len(t, added, 1)
+	assert.Equal(t, "added Bounties.Entries[5]: 50", added[0].String())
+
+	changed := r.ByAction(Changed)
+	assert.Len(t, changed, 2)
+	var lines []string
+	for _, c := range changed {
+		lines = append(lines, c.String())
+	}
+	assert.ElementsMatch(t, []string{"changed Bounties.Entries[2]: 20 -> 200", "changed " + code.Hex() + ": 0x01 -> 0x02"},
+		lines)
+
+	assert.Contains(t, r.String(), "storage "+blockA.Hex()+" -> "+blockB.Hex()+
+		": 1 added, 1 removed, 2 changed\n")
+
+	for i := 1; i < len(r.Changes); i++ {
+		assert.True(t, bytes.Compare(r.Changes[i-1].Key, r.Changes[i].Key) < 0)
+	}
+
+	// 6 keys per block in pages of 2 and a last empty page, the first page of both blocks is compared before any other
+	// page is read
+	a, b := blockA.Hex(), blockB.Hex()
+	assert.Len(t, srv.requests, 14)
+	assert.Equal(t, []string{"keys " + a, "values " + a, "keys " + b, "values " + b}, srv.requests[:4])
+}
+
+func TestCompare_Prefixes(t *testing.T) {
+	s, _, meta := newBlocksMock(t)
+
+	r, err := Compare(context.Background(), s, blockA, blockB, Options{Prefixes: []types.StorageKey{code},
+		SkipDecoding: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Bytes{types.Bytes(code)}, r.Prefixes)
+	assert.Equal(t, []Change{{Key: types.Bytes(code), Action: Changed, Old: types.Bytes{0x01},
+		New: types.Bytes{0x02}}}, r.Changes)
+
+	prefix := types.MustHexDecodeString(entryKey(t, meta, 0))[:32]
+	r, err = Compare(context.Background(), s, blockA, blockB, Options{Prefixes: []types.StorageKey{prefix, prefix}})
+	assert.NoError(t, err)
+	assert.Len(t, r.Changes, 3)
+
+	// overlapping prefixes compare each key once, in the order of the keys
+	r, err = Compare(context.Background(), s, blockA, blockB, Options{Prefixes: []types.StorageKey{prefix, code,
+		prefix[:16]}, SkipDecoding: true})
+	assert.NoError(t, err)
+	assert.Len(t, r.Changes, 4)
+	assert.Equal(t, types.Bytes(code), r.Changes[0].Key)
+
+	r, err = Compare(context.Background(), s, blockA, blockA, Options{})
+	assert.NoError(t, err)
+	assert.Empty(t, r.Changes)
+}
+
+func TestReport_JSON(t *testing.T) {
+	s, _, _ := newBlocksMock(t)
+	r, err := Compare(context.Background(), s, blockA, blockB, Options{Prefixes: []types.StorageKey{code}})
+	assert.NoError(t, err)
+
+	bz, err := json.Marshal(r)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"from":"`+blockA.Hex()+`","to":"`+blockB.Hex()+`","prefixes":["`+code.Hex()+`"],`+
+		`"changes":[{"key":"`+code.Hex()+`","action":"changed","old":"0x01","new":"0x02"}]}`, string(bz))
+
+	var decoded Report
+	assert.NoError(t, json.Unmarshal(bz, &decoded))
+	assert.Equal(t, r.Changes[0].Key, decoded.Changes[0].Key)
+}
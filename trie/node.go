@@ -0,0 +1,220 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package trie implements the base-16 Patricia-Merkle trie used by Substrate for storage and extrinsics, with the node
+codec of sp-trie and blake2-256 as hasher.
+
+Nodes are either leaves or branches with up to 16 children. Every node starts with a header containing its type and the
+number of nibbles of its partial key. Values are SCALE encoded byte vectors, or, for nodes of the trie layout v1, the
+hash of a value stored separately. Children are referenced by the hash of their encoding, unless the encoding is shorter
+than a hash, in which case it is stored inline.
+
+Trie computes the root of a set of key/value pairs, VerifyProof looks up keys in a proof created by a node.
+*/
+package trie
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/zenghq3/go-substrate-rpc-client/scale"
+)
+
+const hashLength = 32
+
+// node header prefixes of the node codec
+const (
+	emptyTrie                = 0x00
+	leafPrefix               = 0x40 // 0b01 followed by 6 bits of nibble count
+	branchWithoutValuePrefix = 0x80 // 0b10 followed by 6 bits of nibble count
+	branchWithValuePrefix    = 0xc0 // 0b11 followed by 6 bits of nibble count
+	hashedValueLeafPrefix    = 0x20 // 0b001 followed by 5 bits of nibble count
+	hashedValueBranchPrefix  = 0x10 // 0b0001 followed by 4 bits of nibble count
+)
+
+// node is a decoded trie node
+type node struct {
+	isEmpty  bool
+	isBranch bool
+	// partial holds the nibbles of the partial key
+	partial  []byte
+	hasValue bool
+	// value holds the value, or its hash if valueHashed is set
+	value       []byte
+	valueHashed bool
+	// children holds the hashes or inline encodings of the children of a branch, nil for missing children
+	children [16][]byte
+}
+
+// decodeNode decodes a node encoded with the node codec
+func decodeNode(data []byte) (*node, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty node encoding")
+	}
+
+	reader := bytes.NewReader(data)
+	decoder := scale.NewDecoder(reader)
+	header, err := decoder.ReadOneByte()
+	if err != nil {
+		return nil, err
+	}
+
+	n := node{}
+	var prefixBits uint
+	switch {
+	case header == emptyTrie:
+		if len(data) != 1 {
+			return nil, fmt.Errorf("unexpected data after empty trie node")
+		}
+		return &node{isEmpty: true}, nil
+	case header&0xc0 == leafPrefix:
+		n.hasValue, prefixBits = true, 2
+	case header&0xc0 == branchWithoutValuePrefix:
+		n.isBranch, prefixBits = true, 2
+	case header&0xc0 == branchWithValuePrefix:
+		n.isBranch, n.hasValue, prefixBits = true, true, 2
+	case header&0xe0 == hashedValueLeafPrefix:
+		n.hasValue, n.valueHashed, prefixBits = true, true, 3
+	case header&0xf0 == hashedValueBranchPrefix:
+		n.isBranch, n.hasValue, n.valueHashed, prefixBits = true, true, true, 4
+	default:
+		return nil, fmt.Errorf("invalid node header %#x", header)
+	}
+
+	count, err := decodeNibbleCount(header, prefixBits, *decoder)
+	if err != nil {
+		return nil, err
+	}
+	n.partial, err = decodePartialKey(count, *decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	var bitmap uint16
+	if n.isBranch {
+		err = decoder.Decode(&bitmap)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode children bitmap: %v", err)
+		}
+		if bitmap == 0 {
+			return nil, fmt.Errorf("branch without children")
+		}
+	}
+
+	if n.hasValue {
+		n.value, err = decodeValue(n.valueHashed, *decoder)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode value: %v", err)
+		}
+	}
+
+	for i := range n.children {
+		if bitmap&(1<<uint(i)) == 0 {
+			continue
+		}
+		n.children[i], err = decodeBytes(*decoder)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode child %v: %v", i, err)
+		}
+		if len(n.children[i]) > hashLength {
+			return nil, fmt.Errorf("child %v of %v bytes is neither a hash nor an inline node", i, len(n.children[i]))
+		}
+	}
+
+	if reader.Len() > 0 {
+		return nil, fmt.Errorf("%v bytes left after decoding node", reader.Len())
+	}
+	return &n, nil
+}
+
+// decodeNibbleCount decodes the number of nibbles of the partial key from the header and the following bytes. If the
+// bits after the prefix are all set, the count continues with the following bytes, up to the first byte below 255.
+func decodeNibbleCount(header byte, prefixBits uint, decoder scale.Decoder) (int, error) {
+	max := int(byte(0xff) >> prefixBits)
+	count := int(header) & max
+	if count < max {
+		return count, nil
+	}
+	for {
+		b, err := decoder.ReadOneByte()
+		if err != nil {
+			return 0, fmt.Errorf("unable to decode nibble count: %v", err)
+		}
+		count += int(b)
+		if b < 255 {
+			return count, nil
+		}
+	}
+}
+
+// decodePartialKey decodes count nibbles. If count is odd, the first nibble is stored in the low half of the first
+// byte, the high half is padding.
+func decodePartialKey(count int, decoder scale.Decoder) ([]byte, error) {
+	if count == 0 {
+		return nil, nil
+	}
+	if rem, ok := decoder.Remaining(); ok && (count+1)/2 > rem {
+		return nil, fmt.Errorf("unable to decode partial key of %v nibbles, only %v bytes of input remaining", count,
+			rem)
+	}
+	packed := make([]byte, (count+1)/2)
+	err := decoder.Read(packed)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode partial key: %v", err)
+	}
+	if count%2 == 1 && packed[0]&0xf0 != 0 {
+		return nil, fmt.Errorf("invalid padding of partial key")
+	}
+	return toNibbles(packed)[count%2:], nil
+}
+
+// decodeValue decodes a length prefixed value, or a value hash
+func decodeValue(hashed bool, decoder scale.Decoder) ([]byte, error) {
+	if !hashed {
+		return decodeBytes(decoder)
+	}
+	hash := make([]byte, hashLength)
+	err := decoder.Read(hash)
+	return hash, err
+}
+
+// decodeBytes decodes a byte slice prefixed with its compact encoded length. The length is checked against the remaining
+// input before allocating, as nodes come from untrusted proofs.
+func decodeBytes(decoder scale.Decoder) ([]byte, error) {
+	n, err := decoder.DecodeUintCompact()
+	if err != nil {
+		return nil, err
+	}
+	if rem, ok := decoder.Remaining(); !ok || n > uint64(rem) {
+		return nil, fmt.Errorf("unable to decode %v bytes, only %v bytes of input remaining", n, rem)
+	}
+	b := make([]byte, n)
+	if n > 0 {
+		err = decoder.Read(b)
+	}
+	return b, err
+}
+
+// toNibbles splits every byte of key into two nibbles, the high nibble first
+func toNibbles(key []byte) []byte {
+	nibbles := make([]byte, 2*len(key))
+	for i, b := range key {
+		nibbles[2*i] = b >> 4
+		nibbles[2*i+1] = b & 0x0f
+	}
+	return nibbles
+}
@@ -0,0 +1,123 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trie
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+	"golang.org/x/crypto/blake2b"
+)
+
+// VerifyReadProof verifies a read proof as returned by state_getReadProof against the given state root, usually the
+// StateRoot of the header of the block the proof was created at. See VerifyProof.
+func VerifyReadProof(stateRoot types.Hash, proof types.ReadProof, keys []types.StorageKey) ([]types.KeyValueOption,
+	error) {
+	nodes := make([][]byte, len(proof.Proof))
+	for i, n := range proof.Proof {
+		nodes[i] = n
+	}
+	return VerifyProof(stateRoot, nodes, keys)
+}
+
+// VerifyProof looks up the given keys in the trie with the given root, which only consists of the encoded nodes of the
+// proof. It returns the value of every key, or no value for keys the proof shows to be absent. An error is returned if
+// the proof lacks a node required to look up a key, which is also the case if a node has been tampered with, since its
+// hash no longer matches.
+func VerifyProof(root types.Hash, proof [][]byte, keys []types.StorageKey) ([]types.KeyValueOption, error) {
+	db := make(map[types.Hash][]byte, len(proof))
+	for _, n := range proof {
+		db[blake2b.Sum256(n)] = n
+	}
+
+	values := make([]types.KeyValueOption, len(keys))
+	for i, key := range keys {
+		value, ok, err := lookup(db, root, toNibbles(key), nil)
+		if err != nil {
+			return nil, fmt.Errorf("unable to verify key %v: %v", key.Hex(), err)
+		}
+		values[i] = types.KeyValueOption{StorageKey: key, HasStorageData: ok, StorageData: value}
+	}
+	return values, nil
+}
+
+// lookup returns the value of the given key in the trie with the given root, or false if the key is not in the trie.
+// If visit is not nil, it is called with the hash of every node and value taken from db.
+func lookup(db map[types.Hash][]byte, root types.Hash, key []byte, visit func(types.Hash)) ([]byte, bool, error) {
+	encoded, ok := db[root]
+	if !ok {
+		return nil, false, fmt.Errorf("proof is incomplete, root node %#x not found", root)
+	}
+	if visit != nil {
+		visit(root)
+	}
+
+	for {
+		n, err := decodeNode(encoded)
+		if err != nil {
+			return nil, false, fmt.Errorf("unable to decode node %#x: %v", encoded, err)
+		}
+		if n.isEmpty || !bytes.HasPrefix(key, n.partial) {
+			return nil, false, nil
+		}
+		key = key[len(n.partial):]
+
+		if len(key) == 0 {
+			if !n.hasValue {
+				return nil, false, nil
+			}
+			return nodeValue(db, n, visit)
+		}
+		if !n.isBranch {
+			return nil, false, nil
+		}
+
+		child := n.children[key[0]]
+		if child == nil {
+			return nil, false, nil
+		}
+		key = key[1:]
+
+		if len(child) < hashLength {
+			encoded = child
+			continue
+		}
+		encoded, ok = db[types.NewHash(child)]
+		if !ok {
+			return nil, false, fmt.Errorf("proof is incomplete, node %#x not found", child)
+		}
+		if visit != nil {
+			visit(types.NewHash(child))
+		}
+	}
+}
+
+// nodeValue returns the value of the node, looking it up in db if only its hash is stored in the node
+func nodeValue(db map[types.Hash][]byte, n *node, visit func(types.Hash)) ([]byte, bool, error) {
+	if !n.valueHashed {
+		return n.value, true, nil
+	}
+	value, ok := db[types.NewHash(n.value)]
+	if !ok {
+		return nil, false, fmt.Errorf("proof is incomplete, value %#x not found", n.value)
+	}
+	if visit != nil {
+		visit(types.NewHash(n.value))
+	}
+	return value, true, nil
+}
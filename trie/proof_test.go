@@ -0,0 +1,132 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trie_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/zenghq3/go-substrate-rpc-client/trie"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+	"golang.org/x/crypto/blake2b"
+)
+
+// a trie with the keys 0x0102 and 0x0103, as a branch with the partial key 0x010 and two leaves. The leaf of 0x0102 is
+// inlined, the leaf of 0x0103 is referenced by its hash.
+var (
+	longValue = bytes.Repeat([]byte{0xab}, 40)
+	leaf2     = []byte{0x40, 0x04, 'a'}
+	leaf3     = append([]byte{0x40, 0xa0}, longValue...)
+	leaf3Hash = blake2b.Sum256(leaf3)
+	branch    = append(append([]byte{0x83, 0x00, 0x10, 0x0c, 0x00, 0x0c}, leaf2...), append([]byte{0x80},
+		leaf3Hash[:]...)...)
+	root = types.Hash(blake2b.Sum256(branch))
+)
+
+func TestVerifyProof(t *testing.T) {
+	keys := []types.StorageKey{{0x01, 0x02}, {0x01, 0x03}, {0x01, 0x04}, {0x01}, {0x01, 0x02, 0x03}}
+	values, err := VerifyProof(root, [][]byte{branch, leaf3}, keys)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.KeyValueOption{
+		{StorageKey: keys[0], HasStorageData: true, StorageData: []byte("a")},
+		{StorageKey: keys[1], HasStorageData: true, StorageData: longValue},
+		{StorageKey: keys[2]},
+		{StorageKey: keys[3]},
+		{StorageKey: keys[4]},
+	}, values)
+}
+
+func TestVerifyProof_Incomplete(t *testing.T) {
+	values, err := VerifyProof(root, [][]byte{branch}, []types.StorageKey{{0x01, 0x02}})
+	assert.NoError(t, err)
+	assert.Equal(t, types.StorageDataRaw("a"), values[0].StorageData)
+
+	_, err = VerifyProof(root, [][]byte{branch}, []types.StorageKey{{0x01, 0x03}})
+	assert.EqualError(t, err, "unable to verify key 0x0103: proof is incomplete, node "+
+		types.HexEncodeToString(leaf3Hash[:])+" not found")
+
+	_, err = VerifyProof(root, [][]byte{leaf3}, []types.StorageKey{{0x01, 0x02}})
+	assert.EqualError(t, err, "unable to verify key 0x0102: proof is incomplete, root node "+root.Hex()+" not found")
+}
+
+func TestVerifyProof_Tampered(t *testing.T) {
+	tampered := append([]byte{}, leaf3...)
+	tampered[len(tampered)-1] = 0xac
+	_, err := VerifyProof(root, [][]byte{branch, tampered}, []types.StorageKey{{0x01, 0x03}})
+	assert.Error(t, err)
+
+	tampered = append([]byte{}, branch...)
+	tampered[len(leaf2)+5] = 'b'
+	_, err = VerifyProof(root, [][]byte{tampered, leaf3}, []types.StorageKey{{0x01, 0x02}})
+	assert.Error(t, err)
+}
+
+func TestVerifyProof_LongPartialKey(t *testing.T) {
+	key := bytes.Repeat([]byte{0x12}, 40)
+	// 80 nibbles, 63 in the header and 17 in the following byte
+	leaf := append(append([]byte{0x7f, 0x11}, key...), 0x08, 0x01, 0x02)
+
+	values, err := VerifyProof(blake2b.Sum256(leaf), [][]byte{leaf}, []types.StorageKey{key, key[1:]})
+	assert.NoError(t, err)
+	assert.Equal(t, types.StorageDataRaw{0x01, 0x02}, values[0].StorageData)
+	assert.False(t, values[1].HasStorageData)
+}
+
+func TestVerifyProof_HashedValue(t *testing.T) {
+	valueHash := blake2b.Sum256(longValue)
+	// leaf of the trie layout v1 with the key 0x0a and a hashed value
+	leaf := append([]byte{0x22, 0x0a}, valueHash[:]...)
+
+	values, err := VerifyProof(blake2b.Sum256(leaf), [][]byte{leaf, longValue}, []types.StorageKey{{0x0a}})
+	assert.NoError(t, err)
+	assert.Equal(t, types.StorageDataRaw(longValue), values[0].StorageData)
+
+	_, err = VerifyProof(blake2b.Sum256(leaf), [][]byte{leaf}, []types.StorageKey{{0x0a}})
+	assert.Error(t, err)
+}
+
+func TestVerifyProof_InvalidLength(t *testing.T) {
+	for _, node := range [][]byte{
+		// leaf with a value length of 2^62
+		{0x40, 0x13, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x0f},
+		// branch with a child length of 2^62
+		{0x80, 0x01, 0x00, 0x13, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x0f},
+		// leaf with a partial key of 3060 nibbles
+		append([]byte{0x7f}, bytes.Repeat([]byte{0xff}, 12)...),
+	} {
+		_, err := VerifyProof(blake2b.Sum256(node), [][]byte{node}, []types.StorageKey{{0x01}})
+		assert.Error(t, err)
+	}
+}
+
+func TestVerifyProof_EmptyTrie(t *testing.T) {
+	empty := []byte{0x00}
+	assert.Equal(t, "0x03170a2e7597b7b7e3d84c05391d139a62b157e78786d8c082f29dcf4c111314",
+		types.Hash(blake2b.Sum256(empty)).Hex())
+
+	values, err := VerifyProof(blake2b.Sum256(empty), [][]byte{empty}, []types.StorageKey{{0x01}})
+	assert.NoError(t, err)
+	assert.False(t, values[0].HasStorageData)
+}
+
+func TestVerifyReadProof(t *testing.T) {
+	proof := types.ReadProof{Proof: []types.Bytes{branch, leaf3}}
+	values, err := VerifyReadProof(root, proof, []types.StorageKey{{0x01, 0x03}})
+	assert.NoError(t, err)
+	assert.Equal(t, types.StorageDataRaw(longValue), values[0].StorageData)
+}
@@ -0,0 +1,74 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trie
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/zenghq3/go-substrate-rpc-client/scale"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+// Root returns the root hash of the trie holding the given key/value pairs, like the storage root of a block
+func Root(version Version, entries map[string][]byte) types.Hash {
+	t := New(version)
+	for k, v := range entries {
+		t.Put([]byte(k), v)
+	}
+	return t.Hash()
+}
+
+// OrderedRoot returns the root hash of the trie holding the given values, keyed by the compact encoded index of each
+// value
+func OrderedRoot(version Version, values [][]byte) types.Hash {
+	t := New(version)
+	for i, v := range values {
+		var key bytes.Buffer
+		// writing to a bytes.Buffer does not fail
+		_ = scale.NewEncoder(&key).EncodeUintCompact(uint64(i))
+		t.Put(key.Bytes(), v)
+	}
+	return t.Hash()
+}
+
+// ExtrinsicsRoot returns the extrinsics root of a block holding the given extrinsics, the ordered root of the encoded
+// extrinsics. Runtimes use the trie layout v0 for the extrinsics root, unless their runtime version states otherwise.
+func ExtrinsicsRoot(version Version, extrinsics []types.Extrinsic) (types.Hash, error) {
+	values := make([][]byte, len(extrinsics))
+	for i, ext := range extrinsics {
+		var err error
+		values[i], err = types.EncodeToBytes(ext)
+		if err != nil {
+			return types.Hash{}, fmt.Errorf("unable to encode extrinsic %v: %v", i, err)
+		}
+	}
+	return OrderedRoot(version, values), nil
+}
+
+// VerifyExtrinsicsRoot returns an error if the extrinsics of the block do not match the extrinsics root of its header
+func VerifyExtrinsicsRoot(version Version, block types.Block) error {
+	root, err := ExtrinsicsRoot(version, block.Extrinsics)
+	if err != nil {
+		return err
+	}
+	if root != block.Header.ExtrinsicsRoot {
+		return fmt.Errorf("extrinsics root %v does not match the extrinsics root %v of the header", root.Hex(),
+			block.Header.ExtrinsicsRoot.Hex())
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trie
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/zenghq3/go-substrate-rpc-client/scale"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Version is the trie layout, the state version of the runtime
+type Version uint8
+
+const (
+	// V0 is the trie layout storing all values in the nodes
+	V0 Version = iota
+	// V1 is the trie layout storing values of at least 33 bytes as separate nodes, referenced by their hash
+	V1
+)
+
+// maxInlineValue is the maximum length of values stored in the nodes of the trie layout v1
+const maxInlineValue = 32
+
+// Trie is an in-memory trie holding key/value pairs. Its root is computed on demand.
+type Trie struct {
+	version Version
+	entries map[string][]byte
+}
+
+// New creates an empty trie with the given layout
+func New(version Version) *Trie {
+	return &Trie{version: version, entries: make(map[string][]byte)}
+}
+
+// Put sets the value of key, replacing any previous value
+func (t *Trie) Put(key, value []byte) {
+	t.entries[string(key)] = append([]byte{}, value...)
+}
+
+// Get returns the value of key, or false if key is not in the trie
+func (t *Trie) Get(key []byte) ([]byte, bool) {
+	value, ok := t.entries[string(key)]
+	return value, ok
+}
+
+// Delete removes key from the trie
+func (t *Trie) Delete(key []byte) {
+	delete(t.entries, string(key))
+}
+
+// Len returns the number of keys in the trie
+func (t *Trie) Len() int {
+	return len(t.entries)
+}
+
+// Hash returns the root hash of the trie
+func (t *Trie) Hash() types.Hash {
+	root, _ := t.build()
+	return root
+}
+
+// Proof returns the encoded nodes required to look up the given keys, including nodes showing the absence of keys not
+// in the trie. The proof can be verified against the root hash of the trie with VerifyProof.
+func (t *Trie) Proof(keys ...[]byte) [][]byte {
+	root, db := t.build()
+	seen := make(map[types.Hash]bool)
+	var proof [][]byte
+	visit := func(hash types.Hash) {
+		if !seen[hash] {
+			seen[hash] = true
+			proof = append(proof, db[hash])
+		}
+	}
+	for _, key := range keys {
+		// all nodes are in db, the lookup cannot fail
+		_, _, _ = lookup(db, root, toNibbles(key), visit)
+	}
+	return proof
+}
+
+// entry is a key/value pair of the trie, with the key split into nibbles
+type entry struct {
+	key   []byte
+	value []byte
+}
+
+// build encodes all nodes of the trie and returns the root hash and all nodes and values referenced by their hash
+func (t *Trie) build() (types.Hash, map[types.Hash][]byte) {
+	entries := make([]entry, 0, len(t.entries))
+	for k, v := range t.entries {
+		entries = append(entries, entry{key: toNibbles([]byte(k)), value: v})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+
+	db := make(map[types.Hash][]byte)
+	encoded := []byte{emptyTrie}
+	if len(entries) > 0 {
+		encoded = t.encodeNode(entries, 0, db)
+	}
+	root := types.Hash(blake2b.Sum256(encoded))
+	db[root] = encoded
+	return root, db
+}
+
+// encodeNode encodes the node holding the given sorted entries, whose keys share their first depth nibbles. Child nodes
+// and values referenced by their hash are added to db.
+func (t *Trie) encodeNode(entries []entry, depth int, db map[types.Hash][]byte) []byte {
+	if len(entries) == 1 {
+		partial := entries[0].key[depth:]
+		buf := t.encodeHeader(false, true, entries[0].value, len(partial))
+		encodePartialKey(buf, partial)
+		t.encodeValue(buf, entries[0].value, db)
+		return buf.Bytes()
+	}
+
+	first, last := entries[0].key[depth:], entries[len(entries)-1].key[depth:]
+	common := 0
+	for common < len(first) && common < len(last) && first[common] == last[common] {
+		common++
+	}
+	partial := first[:common]
+	depth += common
+
+	// a key ending at the branch sorts before all keys continuing below it
+	var value []byte
+	hasValue := len(entries[0].key) == depth
+	if hasValue {
+		value = entries[0].value
+		entries = entries[1:]
+	}
+
+	var children [16][]byte
+	var bitmap uint16
+	for len(entries) > 0 {
+		nibble := entries[0].key[depth]
+		n := 1
+		for n < len(entries) && entries[n].key[depth] == nibble {
+			n++
+		}
+		children[nibble] = t.encodeNode(entries[:n], depth+1, db)
+		if len(children[nibble]) >= hashLength {
+			hash := types.Hash(blake2b.Sum256(children[nibble]))
+			db[hash] = children[nibble]
+			children[nibble] = hash[:]
+		}
+		bitmap |= 1 << uint(nibble)
+		entries = entries[n:]
+	}
+
+	buf := t.encodeHeader(true, hasValue, value, len(partial))
+	encodePartialKey(buf, partial)
+	buf.Write([]byte{byte(bitmap), byte(bitmap >> 8)})
+	if hasValue {
+		t.encodeValue(buf, value, db)
+	}
+	for _, child := range children {
+		if child != nil {
+			encodeBytes(buf, child)
+		}
+	}
+	return buf.Bytes()
+}
+
+// hashValue returns whether the value is stored as separate node referenced by its hash
+func (t *Trie) hashValue(value []byte) bool {
+	return t.version == V1 && len(value) > maxInlineValue
+}
+
+// encodeHeader returns a buffer holding the node header for the given node type and number of nibbles of the partial
+// key. Nibble counts that do not fit into the bits after the prefix are continued in the following bytes.
+func (t *Trie) encodeHeader(isBranch, hasValue bool, value []byte, count int) *bytes.Buffer {
+	var prefix byte
+	var prefixBits uint
+	switch {
+	case hasValue && t.hashValue(value) && isBranch:
+		prefix, prefixBits = hashedValueBranchPrefix, 4
+	case hasValue && t.hashValue(value):
+		prefix, prefixBits = hashedValueLeafPrefix, 3
+	case isBranch && hasValue:
+		prefix, prefixBits = branchWithValuePrefix, 2
+	case isBranch:
+		prefix, prefixBits = branchWithoutValuePrefix, 2
+	default:
+		prefix, prefixBits = leafPrefix, 2
+	}
+
+	buf := new(bytes.Buffer)
+	max := int(byte(0xff) >> prefixBits)
+	if count < max {
+		buf.WriteByte(prefix | byte(count))
+		return buf
+	}
+	buf.WriteByte(prefix | byte(max))
+	for count -= max; count >= 255; count -= 255 {
+		buf.WriteByte(255)
+	}
+	buf.WriteByte(byte(count))
+	return buf
+}
+
+// encodeValue writes the length prefixed value, or the hash of the value if it is stored as separate node
+func (t *Trie) encodeValue(buf *bytes.Buffer, value []byte, db map[types.Hash][]byte) {
+	if !t.hashValue(value) {
+		encodeBytes(buf, value)
+		return
+	}
+	hash := types.Hash(blake2b.Sum256(value))
+	db[hash] = value
+	buf.Write(hash[:])
+}
+
+// encodePartialKey writes the nibbles of the partial key, two per byte. If the number of nibbles is odd, the first
+// nibble is written to the low half of the first byte.
+func encodePartialKey(buf *bytes.Buffer, partial []byte) {
+	if len(partial)%2 == 1 {
+		buf.WriteByte(partial[0])
+		partial = partial[1:]
+	}
+	for i := 0; i < len(partial); i += 2 {
+		buf.WriteByte(partial[i]<<4 | partial[i+1])
+	}
+}
+
+// encodeBytes writes b prefixed with its compact encoded length
+func encodeBytes(buf *bytes.Buffer, b []byte) {
+	// writing to a bytes.Buffer does not fail
+	_ = scale.NewEncoder(buf).EncodeUintCompact(uint64(len(b)))
+	buf.Write(b)
+}
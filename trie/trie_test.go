@@ -0,0 +1,210 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trie_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/zenghq3/go-substrate-rpc-client/trie"
+	"github.com/zenghq3/go-substrate-rpc-client/types"
+	"golang.org/x/crypto/blake2b"
+)
+
+func TestTrie_Hash(t *testing.T) {
+	tr := New(V0)
+	assert.Equal(t, "0x03170a2e7597b7b7e3d84c05391d139a62b157e78786d8c082f29dcf4c111314", tr.Hash().Hex())
+
+	tr.Put([]byte{0x01, 0x02}, []byte("a"))
+	tr.Put([]byte{0x01, 0x03}, longValue)
+	assert.Equal(t, root, tr.Hash())
+	assert.Equal(t, [][]byte{branch, leaf3}, tr.Proof([]byte{0x01, 0x03}))
+	assert.Equal(t, [][]byte{branch}, tr.Proof([]byte{0x01, 0x02}, []byte{0x01, 0x04}))
+}
+
+func TestTrie_Hash_LongPartialKey(t *testing.T) {
+	key := bytes.Repeat([]byte{0x12}, 40)
+	leaf := append(append([]byte{0x7f, 0x11}, key...), 0x08, 0x01, 0x02)
+
+	tr := New(V0)
+	tr.Put(key, []byte{0x01, 0x02})
+	assert.Equal(t, types.Hash(blake2b.Sum256(leaf)), tr.Hash())
+}
+
+func TestTrie_Hash_V1(t *testing.T) {
+	valueHash := blake2b.Sum256(longValue)
+	leaf := append([]byte{0x22, 0x0a}, valueHash[:]...)
+
+	tr := New(V1)
+	tr.Put([]byte{0x0a}, longValue)
+	assert.Equal(t, types.Hash(blake2b.Sum256(leaf)), tr.Hash())
+	assert.Equal(t, [][]byte{leaf, longValue}, tr.Proof([]byte{0x0a}))
+
+	// values of up to 32 bytes are stored in the node in both layouts
+	v0, v1 := New(V0), New(V1)
+	v0.Put([]byte{0x0a}, longValue[:32])
+	v1.Put([]byte{0x0a}, longValue[:32])
+	assert.Equal(t, v0.Hash(), v1.Hash())
+}
+
+func TestTrie_PutGetDelete(t *testing.T) {
+	tr := New(V0)
+	tr.Put([]byte{0x01}, []byte{0x02})
+	before := tr.Hash()
+
+	tr.Put([]byte{0x01, 0x02}, []byte{0x03})
+	value, ok := tr.Get([]byte{0x01, 0x02})
+	assert.True(t, ok)
+	assert.Equal(t, []byte{0x03}, value)
+	assert.Equal(t, 2, tr.Len())
+	assert.NotEqual(t, before, tr.Hash())
+
+	tr.Delete([]byte{0x01, 0x02})
+	_, ok = tr.Get([]byte{0x01, 0x02})
+	assert.False(t, ok)
+	assert.Equal(t, before, tr.Hash())
+}
+
+func TestTrie_Proof(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, version := range []Version{V0, V1} {
+		tr := New(version)
+		var keys []types.StorageKey
+		for i := 0; i < 500; i++ {
+			key := make([]byte, 1+rnd.Intn(4))
+			rnd.Read(key)
+			value := make([]byte, rnd.Intn(64))
+			rnd.Read(value)
+			tr.Put(key, value)
+			keys = append(keys, key)
+		}
+		keys = append(keys, types.StorageKey{0x01, 0x02, 0x03, 0x04, 0x05})
+
+		proofKeys := make([][]byte, len(keys))
+		for i, key := range keys {
+			proofKeys[i] = key
+		}
+		values, err := VerifyProof(tr.Hash(), tr.Proof(proofKeys...), keys)
+		assert.NoError(t, err)
+		for i, key := range keys {
+			expected, ok := tr.Get(key)
+			assert.Equal(t, ok, values[i].HasStorageData)
+			assert.Equal(t, types.StorageDataRaw(expected), values[i].StorageData)
+		}
+	}
+}
+
+func TestRoot(t *testing.T) {
+	entries := map[string][]byte{"\x01\x02": []byte("a"), "\x01\x03": longValue}
+	assert.Equal(t, root, Root(V0, entries))
+	assert.Equal(t, New(V0).Hash(), Root(V0, nil))
+}
+
+// The expected roots below were computed with a separate implementation of the trie node encoding described in the
+// Polkadot host specification, not with this package.
+func TestRoot_Vectors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		entries map[string][]byte
+		v0, v1  string
+	}{
+		{
+			// a branch with inline children only, the same in both layouts
+			name:    "inline children",
+			entries: map[string][]byte{"doe": []byte("reindeer"), "dog": []byte("puppy"), "dogglesworth": []byte("cat")},
+			v0:      "0x39245109cef3758c2eed2ccba8d9b370a917850af3824bc8348d505df2c298fa",
+			v1:      "0x39245109cef3758c2eed2ccba8d9b370a917850af3824bc8348d505df2c298fa",
+		},
+		{
+			// branches with values, hashed children and values of 33 and 40 bytes, hashed in V1
+			name: "hashed values",
+			entries: map[string][]byte{"a": []byte("1"), "ab": bytes.Repeat([]byte{2}, 40), "ac": []byte("3"),
+				"abc": bytes.Repeat([]byte{4}, 33)},
+			v0: "0x83362de66e9f31b92de3496d75bac8e99204ffdbd1b595e9364e22c6eea4b826",
+			v1: "0xb6624d6df9eed491983e0058117912bb202c2b76fb56d2b49431bdba17084514",
+		},
+		{
+			// partial keys longer than 63 and 255 nibbles and a 32 byte value, stored in the node in V1
+			name: "long partial keys",
+			entries: map[string][]byte{string(bytes.Repeat([]byte{0x12}, 40)): {1, 2},
+				string(bytes.Repeat([]byte{0x12}, 200)): bytes.Repeat([]byte{5}, 32)},
+			v0: "0x8626b34d11701c23caadcc0acc1b50c021096dd42bc38f335259ed8c264e6342",
+			v1: "0x8626b34d11701c23caadcc0acc1b50c021096dd42bc38f335259ed8c264e6342",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.v0, Root(V0, test.entries).Hex())
+			assert.Equal(t, test.v1, Root(V1, test.entries).Hex())
+		})
+	}
+}
+
+func TestOrderedRoot(t *testing.T) {
+	assert.Equal(t, New(V0).Hash(), OrderedRoot(V0, nil))
+
+	// a leaf with the key 0x00, the compact encoded index 0
+	leaf := []byte{0x42, 0x00, 0x04, 'a'}
+	assert.Equal(t, types.Hash(blake2b.Sum256(leaf)), OrderedRoot(V0, [][]byte{[]byte("a")}))
+
+	tr := New(V0)
+	for i := 0; i < 100; i++ {
+		key, err := types.EncodeToBytes(types.UCompact(i))
+		assert.NoError(t, err)
+		tr.Put(key, []byte{byte(i)})
+	}
+	values := make([][]byte, 100)
+	for i := range values {
+		values[i] = []byte{byte(i)}
+	}
+	assert.Equal(t, tr.Hash(), OrderedRoot(V0, values))
+
+	// keys of one and two bytes, with values of up to 49 bytes, expected roots computed as in TestRoot_Vectors
+	values = make([][]byte, 100)
+	for i := range values {
+		values[i] = bytes.Repeat([]byte{byte(i)}, i%50)
+	}
+	assert.Equal(t, "0x64e46efb4cf0467d32ab4a3672e7ab27e8b8dc59cec03f48d4bdd23a49ff88cf", OrderedRoot(V0, values).Hex())
+	assert.Equal(t, "0xcad29da36d41ff1b48f33410959292231aa3d82631876103b4ad9890249ba73c", OrderedRoot(V1, values).Hex())
+}
+
+// The expected extrinsics roots were computed as in TestRoot_Vectors.
+func TestVerifyExtrinsicsRoot(t *testing.T) {
+	extrinsics := []types.Extrinsic{types.ExamplaryExtrinsic, types.ExamplaryExtrinsic}
+
+	extrinsicsRoot, err := ExtrinsicsRoot(V0, extrinsics)
+	assert.NoError(t, err)
+	assert.Equal(t, "0xb1f296780f7dba91a30d6f5d6b29fb4ea0a86778c73ad94361bfd06e0bd9d0a7", extrinsicsRoot.Hex())
+
+	block := types.Block{Header: types.ExamplaryHeader, Extrinsics: extrinsics}
+	block.Header.ExtrinsicsRoot = mustHash("0xb1f296780f7dba91a30d6f5d6b29fb4ea0a86778c73ad94361bfd06e0bd9d0a7")
+	assert.NoError(t, VerifyExtrinsicsRoot(V0, block))
+
+	block.Extrinsics = extrinsics[:1]
+	assert.EqualError(t, VerifyExtrinsicsRoot(V0, block), "extrinsics root "+
+		"0x42af34be003bb29c0522951b868af7f613082fd6183b02c8ec1509ee4119fbd9 does not match the extrinsics root "+
+		"0xb1f296780f7dba91a30d6f5d6b29fb4ea0a86778c73ad94361bfd06e0bd9d0a7 of the header")
+}
+
+func mustHash(s string) types.Hash {
+	hash, err := types.NewHashFromHexString(s)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+}
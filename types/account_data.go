@@ -0,0 +1,132 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"math/big"
+
+	"github.com/zenghq3/go-substrate-rpc-client/scale"
+)
+
+// accountDataNewLogicBit is the bit of Flags that marks account data using the Frozen layout
+const accountDataNewLogicBit = 127
+
+// AccountData contains the balances of an account as kept by the balances pallet in the data of AccountInfo. Newer
+// runtimes replaced MiscFrozen and FeeFrozen with Frozen and Flags, using the highest bit of Flags to mark accounts
+// that have been migrated. Both layouts have the same length, so the one of each account is detected when decoding
+// and only the fields of that layout are set.
+type AccountData struct {
+	Free       U128
+	Reserved   U128
+	MiscFrozen U128
+	FeeFrozen  U128
+	Frozen     U128
+	Flags      U128
+}
+
+// Decode implements decoding for AccountData
+func (d *AccountData) Decode(decoder scale.Decoder) error {
+	var fields [4]U128
+	for i := range fields {
+		err := decoder.Decode(&fields[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	data := AccountData{Free: fields[0], Reserved: fields[1]}
+	if fields[3].Bit(accountDataNewLogicBit) == 1 {
+		data.Frozen, data.Flags = fields[2], fields[3]
+	} else {
+		data.MiscFrozen, data.FeeFrozen = fields[2], fields[3]
+	}
+
+	*d = data
+	return nil
+}
+
+// Encode implements encoding for AccountData
+func (d AccountData) Encode(encoder scale.Encoder) error {
+	fields := []U128{d.Free, d.Reserved, d.MiscFrozen, d.FeeFrozen}
+	if d.IsNewLogic() {
+		fields[2], fields[3] = d.Frozen, d.Flags
+	}
+
+	for _, f := range fields {
+		err := encoder.Encode(U128{bigOrZero(f)})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsNewLogic returns true if the account data uses the layout with Frozen and Flags
+func (d AccountData) IsNewLogic() bool {
+	return d.Flags.Int != nil && d.Flags.Bit(accountDataNewLogicBit) == 1
+}
+
+// Locked returns the amount of the free balance that can't be transferred, which is the larger of MiscFrozen and
+// FeeFrozen or Frozen, depending on the layout
+func (d AccountData) Locked() U128 {
+	if d.IsNewLogic() {
+		return U128{new(big.Int).Set(bigOrZero(d.Frozen))}
+	}
+	return U128{new(big.Int).Set(maxBig(bigOrZero(d.MiscFrozen), bigOrZero(d.FeeFrozen)))}
+}
+
+// Transferable returns the amount of the free balance that can be transferred. With Frozen, reserved balance counts
+// towards the frozen amount, so only the part of Frozen exceeding Reserved is subtracted.
+func (d AccountData) Transferable() U128 {
+	locked := d.Locked().Int
+	if d.IsNewLogic() {
+		locked = maxBig(new(big.Int).Sub(locked, bigOrZero(d.Reserved)), big.NewInt(0))
+	}
+	return U128{maxBig(new(big.Int).Sub(bigOrZero(d.Free), locked), big.NewInt(0))}
+}
+
+// Balances returns the free, reserved, locked and transferable balances of the account data
+func (d AccountData) Balances() AccountBalances {
+	return AccountBalances{
+		Free:         U128{new(big.Int).Set(bigOrZero(d.Free))},
+		Reserved:     U128{new(big.Int).Set(bigOrZero(d.Reserved))},
+		Locked:       d.Locked(),
+		Transferable: d.Transferable(),
+	}
+}
+
+// AccountBalances summarizes the balances of an account
+type AccountBalances struct {
+	Free         U128
+	Reserved     U128
+	Locked       U128
+	Transferable U128
+}
+
+func bigOrZero(u U128) *big.Int {
+	if u.Int == nil {
+		return big.NewInt(0)
+	}
+	return u.Int
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) < 0 {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,89 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+func newU128(i int64) U128 {
+	return NewU128(*big.NewInt(i))
+}
+
+var exampleAccountData = AccountData{
+	Free:       newU128(1000),
+	Reserved:   newU128(200),
+	MiscFrozen: newU128(300),
+	FeeFrozen:  newU128(100),
+}
+
+var exampleAccountDataNewLogic = AccountData{
+	Free:     newU128(1000),
+	Reserved: newU128(200),
+	Frozen:   newU128(300),
+	Flags:    NewU128(*new(big.Int).Lsh(big.NewInt(1), 127)),
+}
+
+func TestAccountData_EncodeDecode(t *testing.T) {
+	assertRoundtrip(t, exampleAccountData)
+	assertRoundtrip(t, exampleAccountDataNewLogic)
+}
+
+func TestAccountData_EncodedLength(t *testing.T) {
+	assertEncodedLength(t, []encodedLengthAssert{
+		{exampleAccountData, 64},
+		{exampleAccountDataNewLogic, 64},
+		{AccountData{}, 64},
+	})
+}
+
+func TestAccountData_Decode(t *testing.T) {
+	bz := MustHexDecodeString("0xe8030000000000000000000000000000" + "c8000000000000000000000000000000" +
+		"2c010000000000000000000000000000" + "00000000000000000000000000000080")
+
+	var data AccountData
+	err := DecodeFromBytes(bz, &data)
+	assert.NoError(t, err)
+	assert.True(t, data.IsNewLogic())
+	assert.Equal(t, exampleAccountDataNewLogic, data)
+}
+
+func TestAccountData_Balances(t *testing.T) {
+	assert.Equal(t, AccountBalances{
+		Free:         newU128(1000),
+		Reserved:     newU128(200),
+		Locked:       newU128(300),
+		Transferable: newU128(700),
+	}, exampleAccountData.Balances())
+
+	// with the new logic only the part of the frozen balance that exceeds the reserved balance is subtracted
+	assert.Equal(t, AccountBalances{
+		Free:         newU128(1000),
+		Reserved:     newU128(200),
+		Locked:       newU128(300),
+		Transferable: newU128(900),
+	}, exampleAccountDataNewLogic.Balances())
+
+	frozen := AccountData{Free: newU128(100), MiscFrozen: newU128(300)}
+	assert.Equal(t, newU128(0), frozen.Transferable())
+
+	assert.Equal(t, newU128(0), AccountData{}.Transferable())
+}
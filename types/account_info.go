@@ -16,13 +16,225 @@
 
 package types
 
-// Deprecated: AccountInfo is an account information structure for contracts
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/zenghq3/go-substrate-rpc-client/scale"
+)
+
+// AccountInfoLayout identifies one of the historical encodings of frame_system's AccountInfo
+type AccountInfoLayout uint8
+
+const (
+	// AccountInfoWithTripleRefCount is the current layout with consumers, providers and sufficients
+	AccountInfoWithTripleRefCount AccountInfoLayout = iota
+	// AccountInfoWithDualRefCount is the layout with consumers and providers
+	AccountInfoWithDualRefCount
+	// AccountInfoWithRefCount is the layout with a single u32 reference count
+	AccountInfoWithRefCount
+	// AccountInfoWithRefCountU8 is the oldest layout with a single u8 reference count
+	AccountInfoWithRefCountU8
+)
+
+// accountDataLength is the encoded length of AccountData, which is the same for all of its layouts
+const accountDataLength = 4 * 16
+
+// accountInfoLengths maps the encoded length of an AccountInfo to its layout
+var accountInfoLengths = map[int]AccountInfoLayout{
+	4 + 1 + accountDataLength:     AccountInfoWithRefCountU8,
+	4 + 4 + accountDataLength:     AccountInfoWithRefCount,
+	4 + 4 + 4 + accountDataLength: AccountInfoWithDualRefCount,
+	4 + 12 + accountDataLength:    AccountInfoWithTripleRefCount,
+}
+
+// AccountInfo contains information of an account as stored in System.Account. Decode and Encode use the current
+// layout, AccountInfoWithTripleRefCount. Values stored by older runtimes are decoded with DecodeAccountInfo. The
+// layouts only differ in their reference counters: counters that a layout doesn't have are zero, for the layouts with a
+// single reference count it is available as RefCount and Consumers.
 type AccountInfo struct {
-	TrieID           []byte
-	CurrentMemStored uint64
+	Nonce       U32
+	RefCount    U32
+	Consumers   U32
+	Providers   U32
+	Sufficients U32
+	Data        AccountData
+}
+
+// Decode implements decoding for AccountInfo, which has the layout AccountInfoWithTripleRefCount
+func (a *AccountInfo) Decode(decoder scale.Decoder) error {
+	return a.decode(decoder, AccountInfoWithTripleRefCount)
+}
+
+// Encode implements encoding for AccountInfo, which has the layout AccountInfoWithTripleRefCount
+func (a AccountInfo) Encode(encoder scale.Encoder) error {
+	return a.encode(encoder, AccountInfoWithTripleRefCount)
+}
+
+// DecodeAccountInfo decodes an AccountInfo encoded with the given layout, see AccountInfoLayoutFromMetadata
+func DecodeAccountInfo(bz []byte, layout AccountInfoLayout) (AccountInfo, error) {
+	var info AccountInfo
+	reader := bytes.NewReader(bz)
+	err := info.decode(*scale.NewDecoder(reader), layout)
+	if err != nil {
+		return AccountInfo{}, err
+	}
+	if reader.Len() > 0 {
+		return AccountInfo{}, fmt.Errorf("%v bytes left after decoding AccountInfo", reader.Len())
+	}
+	return info, nil
+}
+
+// EncodeAccountInfo encodes the AccountInfo with the given layout
+func EncodeAccountInfo(info AccountInfo, layout AccountInfoLayout) ([]byte, error) {
+	var buf bytes.Buffer
+	err := info.encode(*scale.NewEncoder(&buf), layout)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (a *AccountInfo) decode(decoder scale.Decoder, layout AccountInfoLayout) error {
+	var info AccountInfo
+	err := decoder.Decode(&info.Nonce)
+	if err != nil {
+		return err
+	}
+
+	var counters []*U32
+	switch layout {
+	case AccountInfoWithRefCountU8:
+		var refCount U8
+		err = decoder.Decode(&refCount)
+		if err != nil {
+			return err
+		}
+		info.RefCount = U32(refCount)
+	case AccountInfoWithRefCount:
+		counters = []*U32{&info.RefCount}
+	case AccountInfoWithDualRefCount:
+		counters = []*U32{&info.Consumers, &info.Providers}
+	case AccountInfoWithTripleRefCount:
+		counters = []*U32{&info.Consumers, &info.Providers, &info.Sufficients}
+	default:
+		return fmt.Errorf("unknown AccountInfo layout %d", layout)
+	}
+
+	for _, c := range counters {
+		err = decoder.Decode(c)
+		if err != nil {
+			return err
+		}
+	}
+	if layout == AccountInfoWithRefCountU8 || layout == AccountInfoWithRefCount {
+		info.Consumers = info.RefCount
+	}
+
+	err = decoder.Decode(&info.Data)
+	if err != nil {
+		return err
+	}
+
+	*a = info
+	return nil
+}
+
+func (a AccountInfo) encode(encoder scale.Encoder, layout AccountInfoLayout) error {
+	err := encoder.Encode(a.Nonce)
+	if err != nil {
+		return err
+	}
+
+	var counters []interface{}
+	switch layout {
+	case AccountInfoWithRefCountU8:
+		counters = []interface{}{U8(a.RefCount)}
+	case AccountInfoWithRefCount:
+		counters = []interface{}{a.RefCount}
+	case AccountInfoWithDualRefCount:
+		counters = []interface{}{a.Consumers, a.Providers}
+	case AccountInfoWithTripleRefCount:
+		counters = []interface{}{a.Consumers, a.Providers, a.Sufficients}
+	default:
+		return fmt.Errorf("unknown AccountInfo layout %d", layout)
+	}
+
+	for _, c := range counters {
+		err = encoder.Encode(c)
+		if err != nil {
+			return err
+		}
+	}
+
+	return encoder.Encode(a.Data)
+}
+
+// AccountInfoLayoutFromLength returns the layout of an AccountInfo with the given encoded length. Use it for metadata
+// before v14, which does not describe the fields of AccountInfo.
+func AccountInfoLayoutFromLength(n int) (AccountInfoLayout, error) {
+	layout, ok := accountInfoLengths[n]
+	if !ok {
+		return 0, fmt.Errorf("unexpected length %d of an encoded AccountInfo", n)
+	}
+	return layout, nil
+}
+
+// AccountInfoLayoutFromMetadata returns the layout of AccountInfo used by the runtime of the given metadata, found from
+// the fields of the value type of System.Account. Metadata before v14 names the type without its fields, for it see
+// AccountInfoLayoutFromLength.
+func AccountInfoLayoutFromMetadata(meta *Metadata) (AccountInfoLayout, error) {
+	if !meta.IsMetadataV14 {
+		return 0, fmt.Errorf("metadata v%v does not describe the fields of System.Account", meta.Version)
+	}
+
+	entry, err := meta.FindStorageEntryMetadata("System", "Account")
+	if err != nil {
+		return 0, err
+	}
+	s, ok := entry.(StorageEntryMetadataV14)
+	if !ok || !s.Type.IsMap {
+		return 0, fmt.Errorf("System.Account is not a storage map")
+	}
+
+	lookup := meta.AsMetadataV14.Lookup
+	t, err := lookup.FindType(s.Type.AsMap.Value)
+	if err != nil {
+		return 0, err
+	}
+	if !t.Def.IsComposite {
+		return 0, fmt.Errorf("the value of System.Account is not a struct")
+	}
+
+	fields := make(map[Text]Si1LookupTypeID)
+	for _, f := range t.Def.AsComposite.Fields {
+		fields[f.Name] = f.Type
+	}
+
+	switch {
+	case hasFields(fields, "consumers", "providers", "sufficients"):
+		return AccountInfoWithTripleRefCount, nil
+	case hasFields(fields, "consumers", "providers"):
+		return AccountInfoWithDualRefCount, nil
+	case hasFields(fields, "refcount"):
+		name, err := lookup.TypeName(fields["refcount"])
+		if err != nil {
+			return 0, err
+		}
+		if name == "u8" {
+			return AccountInfoWithRefCountU8, nil
+		}
+		return AccountInfoWithRefCount, nil
+	default:
+		return 0, fmt.Errorf("unknown layout of System.Account")
+	}
 }
 
-// Deprecated: NewAccountInfo creates a new AccountInfo type
-func NewAccountInfo(trieID []byte, currentMemStored uint64) AccountInfo {
-	return AccountInfo{trieID, currentMemStored}
+func hasFields(fields map[Text]Si1LookupTypeID, names ...Text) bool {
+	for _, n := range names {
+		if _, ok := fields[n]; !ok {
+			return false
+		}
+	}
+	return true
 }
@@ -17,50 +17,118 @@
 package types_test
 
 import (
+	"math/big"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	. "github.com/zenghq3/go-substrate-rpc-client/types"
 )
 
+var exampleAccountInfo = AccountInfo{
+	Nonce:       NewU32(7),
+	Consumers:   NewU32(1),
+	Providers:   NewU32(2),
+	Sufficients: NewU32(3),
+	Data:        exampleAccountData,
+}
+
 func TestAccountInfo_EncodeDecode(t *testing.T) {
-	assertRoundtrip(t, NewAccountInfo([]byte{1, 2, 3}, 13))
+	assertRoundtrip(t, exampleAccountInfo)
 }
 
 func TestAccountInfo_EncodedLength(t *testing.T) {
-	assertEncodedLength(t, []encodedLengthAssert{
-		{NewAccountInfo([]byte{1, 2, 3}, 13), 12},
-	})
+	assertEncodedLength(t, []encodedLengthAssert{{exampleAccountInfo, 80}})
 }
 
 func TestAccountInfo_Encode(t *testing.T) {
 	assertEncode(t, []encodingAssert{
-		{NewAccountInfo([]byte{1, 2, 3}, 13), MustHexDecodeString("0x0c0102030d00000000000000")},
+		{AccountInfo{Nonce: 7, Consumers: 1, Providers: 2, Sufficients: 3}, append(
+			MustHexDecodeString("0x07000000010000000200000003000000"), make([]byte, 64)...)},
 	})
 }
 
-func TestAccountInfo_Hash(t *testing.T) {
-	assertHash(t, []hashAssert{
-		{NewAccountInfo([]byte{1, 2, 3}, 13), MustHexDecodeString(
-			"0x4fac0dfeb9b4efd2518c762e7d097fafaffaf8d56a2e784f9fc9919c22277804")},
-	})
-}
+func TestAccountInfo_DecodeNested(t *testing.T) {
+	bz, err := EncodeToBytes([]AccountInfo{exampleAccountInfo, exampleAccountInfo})
+	assert.NoError(t, err)
+	var infos []AccountInfo
+	assert.NoError(t, DecodeFromBytes(bz, &infos))
+	assert.Equal(t, []AccountInfo{exampleAccountInfo, exampleAccountInfo}, infos)
 
-func TestAccountInfo_Hex(t *testing.T) {
-	assertEncodeToHex(t, []encodeToHexAssert{
-		{NewAccountInfo([]byte{1, 2, 3}, 13), "0x0c0102030d00000000000000"},
-	})
+	var tuple struct {
+		Info  AccountInfo
+		Index U32
+	}
+	bz, err = EncodeToBytes(exampleAccountInfo)
+	assert.NoError(t, err)
+	assert.NoError(t, DecodeFromBytes(append(bz, 5, 0, 0, 0), &tuple))
+	assert.Equal(t, exampleAccountInfo, tuple.Info)
+	assert.Equal(t, NewU32(5), tuple.Index)
 }
 
-func TestAccountInfo_String(t *testing.T) {
-	assertString(t, []stringAssert{
-		{NewAccountInfo([]byte{1, 2, 3}, 13), "{[1 2 3] 13}"},
-	})
+func TestDecodeAccountInfo(t *testing.T) {
+	for _, c := range []struct {
+		info   AccountInfo
+		layout AccountInfoLayout
+		length int
+	}{
+		{exampleAccountInfo, AccountInfoWithTripleRefCount, 80},
+		{AccountInfo{Nonce: 7, Consumers: 1, Providers: 2, Data: exampleAccountDataNewLogic},
+			AccountInfoWithDualRefCount, 76},
+		{AccountInfo{Nonce: 7, RefCount: 4, Consumers: 4, Data: exampleAccountData}, AccountInfoWithRefCount, 72},
+		{AccountInfo{Nonce: 7, RefCount: 4, Consumers: 4, Data: exampleAccountData}, AccountInfoWithRefCountU8, 69},
+	} {
+		bz, err := EncodeAccountInfo(c.info, c.layout)
+		assert.NoError(t, err)
+		assert.Len(t, bz, c.length)
+
+		layout, err := AccountInfoLayoutFromLength(len(bz))
+		assert.NoError(t, err)
+		assert.Equal(t, c.layout, layout)
+
+		info, err := DecodeAccountInfo(bz, c.layout)
+		assert.NoError(t, err)
+		assert.Equal(t, c.info, info)
+	}
+
+	bz := append(MustHexDecodeString("0x0700000004"), make([]byte, 64)...)
+	info, err := DecodeAccountInfo(bz, AccountInfoWithRefCountU8)
+	assert.NoError(t, err)
+	zero := NewU128(*big.NewInt(0))
+	assert.Equal(t, AccountInfo{Nonce: 7, RefCount: 4, Consumers: 4, Data: AccountData{Free: zero, Reserved: zero,
+		MiscFrozen: zero, FeeFrozen: zero}}, info)
+
+	_, err = DecodeAccountInfo(append(bz, 0), AccountInfoWithRefCountU8)
+	assert.EqualError(t, err, "1 bytes left after decoding AccountInfo")
+	_, err = DecodeAccountInfo(bz, AccountInfoLayout(7))
+	assert.EqualError(t, err, "unknown AccountInfo layout 7")
+	_, err = AccountInfoLayoutFromLength(70)
+	assert.EqualError(t, err, "unexpected length 70 of an encoded AccountInfo")
 }
 
-func TestAccountInfo_Eq(t *testing.T) {
-	assertEq(t, []eqAssert{
-		{NewAccountInfo([]byte{1, 2, 3}, 13), NewAccountInfo([]byte{1, 2, 3}, 13), true},
-		{NewAccountInfo([]byte{1, 2, 3}, 13), NewAccountInfo([]byte{1, 2, 2}, 13), false},
-		{NewAccountInfo([]byte{1, 2, 3}, 13), NewBool(false), false},
-	})
+func TestAccountInfoLayoutFromMetadata(t *testing.T) {
+	_, err := AccountInfoLayoutFromMetadata(ExamplaryMetadataV11Substrate)
+	assert.EqualError(t, err, "metadata v11 does not describe the fields of System.Account")
+
+	meta := exampleMetadataV14
+	meta.AsMetadataV14.Lookup = append(PortableRegistry{}, exampleMetadataV14.AsMetadataV14.Lookup...)
+	for _, c := range []struct {
+		fields []Si1Field
+		layout AccountInfoLayout
+	}{
+		{[]Si1Field{{Name: "nonce"}, {Name: "consumers"}, {Name: "providers"}, {Name: "sufficients"}, {Name: "data"}},
+			AccountInfoWithTripleRefCount},
+		{[]Si1Field{{Name: "nonce"}, {Name: "consumers"}, {Name: "providers"}, {Name: "data"}},
+			AccountInfoWithDualRefCount},
+		{[]Si1Field{{Name: "nonce"}, {Name: "refcount", Type: NewSi1LookupTypeID(3)}, {Name: "data"}},
+			AccountInfoWithRefCount},
+	} {
+		meta.AsMetadataV14.Lookup[10].Type.Def.AsComposite.Fields = c.fields
+		layout, err := AccountInfoLayoutFromMetadata(&meta)
+		assert.NoError(t, err)
+		assert.Equal(t, c.layout, layout)
+	}
+
+	// the example type has neither reference counts nor data
+	_, err = AccountInfoLayoutFromMetadata(&exampleMetadataV14)
+	assert.EqualError(t, err, "unknown layout of System.Account")
 }
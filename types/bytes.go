@@ -17,6 +17,7 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/zenghq3/go-substrate-rpc-client/scale"
@@ -30,6 +31,22 @@ func NewBytes(b []byte) Bytes {
 	return Bytes(b)
 }
 
+// UnmarshalJSON fills b with the JSON encoded hex string given by bz
+func (b *Bytes) UnmarshalJSON(bz []byte) error {
+	var tmp string
+	err := json.Unmarshal(bz, &tmp)
+	if err != nil {
+		return err
+	}
+	*b, err = HexDecodeString(tmp)
+	return err
+}
+
+// MarshalJSON returns a JSON encoded hex string of b
+func (b Bytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(HexEncodeToString(b))
+}
+
 // BytesBare represents byte slices that will be encoded bare, i. e. without a compact length prefix. This makes it
 // impossible to decode the bytes, but is used as the payload for signing.
 type BytesBare []byte
@@ -0,0 +1,38 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import "bytes"
+
+// DefaultChildStorageKeyPrefix is the prefix of the keys under which the roots of default child tries are stored in
+// the main trie. The key of a default child trie is this prefix followed by the id of the child trie.
+const DefaultChildStorageKeyPrefix = ":child_storage:default:"
+
+// NewDefaultChildStorageKey creates the prefixed key of the default child trie with the given id, like the trie id of
+// a contract, as expected by the child storage RPC calls
+func NewDefaultChildStorageKey(id []byte) StorageKey {
+	return append([]byte(DefaultChildStorageKeyPrefix), id...)
+}
+
+// DefaultChildStorageID returns the id of the default child trie the key refers to, or false if the key is not the key
+// of a default child trie
+func (s StorageKey) DefaultChildStorageID() ([]byte, bool) {
+	if !bytes.HasPrefix(s, []byte(DefaultChildStorageKeyPrefix)) {
+		return nil, false
+	}
+	return s[len(DefaultChildStorageKeyPrefix):], true
+}
@@ -0,0 +1,66 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types_test
+
+import (
+	"testing"
+
+	. "github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+func TestContractAccountInfo_EncodeDecode(t *testing.T) {
+	assertRoundtrip(t, NewContractAccountInfo([]byte{1, 2, 3}, 13))
+}
+
+func TestContractAccountInfo_EncodedLength(t *testing.T) {
+	assertEncodedLength(t, []encodedLengthAssert{
+		{NewContractAccountInfo([]byte{1, 2, 3}, 13), 12},
+	})
+}
+
+func TestContractAccountInfo_Encode(t *testing.T) {
+	assertEncode(t, []encodingAssert{
+		{NewContractAccountInfo([]byte{1, 2, 3}, 13), MustHexDecodeString("0x0c0102030d00000000000000")},
+	})
+}
+
+func TestContractAccountInfo_Hash(t *testing.T) {
+	assertHash(t, []hashAssert{
+		{NewContractAccountInfo([]byte{1, 2, 3}, 13), MustHexDecodeString(
+			"0x4fac0dfeb9b4efd2518c762e7d097fafaffaf8d56a2e784f9fc9919c22277804")},
+	})
+}
+
+func TestContractAccountInfo_Hex(t *testing.T) {
+	assertEncodeToHex(t, []encodeToHexAssert{
+		{NewContractAccountInfo([]byte{1, 2, 3}, 13), "0x0c0102030d00000000000000"},
+	})
+}
+
+func TestContractAccountInfo_String(t *testing.T) {
+	assertString(t, []stringAssert{
+		{NewContractAccountInfo([]byte{1, 2, 3}, 13), "{[1 2 3] 13}"},
+	})
+}
+
+func TestContractAccountInfo_Eq(t *testing.T) {
+	assertEq(t, []eqAssert{
+		{NewContractAccountInfo([]byte{1, 2, 3}, 13), NewContractAccountInfo([]byte{1, 2, 3}, 13), true},
+		{NewContractAccountInfo([]byte{1, 2, 3}, 13), NewContractAccountInfo([]byte{1, 2, 2}, 13), false},
+		{NewContractAccountInfo([]byte{1, 2, 3}, 13), NewBool(false), false},
+	})
+}
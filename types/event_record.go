@@ -180,8 +180,18 @@ func (e EventRecordsRaw) DecodeEventRecords(m *Metadata, t interface{}) error {
 		// set the phase we decoded earlier
 		phaseField.Set(reflect.ValueOf(phase))
 
+		// event records before metadata v4 have no topics, the Topics field is left empty for them
+		lastField := numFields
+		if m.Version < 4 {
+			if name := holder.Elem().Type().Field(numFields - 1).Name; name != "Topics" {
+				return fmt.Errorf("expected the last field of event #%v with EventID %v, field %v_%v to be Topics, "+
+					"which is skipped for metadata v%v, but got %v", i, id, moduleName, eventName, m.Version, name)
+			}
+			lastField = numFields - 1
+		}
+
 		// set the remaining fields
-		for j := 1; j < numFields; j++ {
+		for j := 1; j < lastField; j++ {
 			err = decoder.Decode(holder.Elem().FieldByIndex([]int{j}).Addr().Interface())
 			if err != nil {
 				return fmt.Errorf("unable to decode field %v event #%v with EventID %v, field %v_%v: %v", j, i, id, moduleName,
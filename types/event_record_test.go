@@ -295,6 +295,35 @@ func TestEventRecordsRaw_Decode(t *testing.T) {
 	assert.Equal(t, exp, events)
 }
 
+func TestEventRecordsRaw_Decode_WithoutTopics(t *testing.T) {
+	e := EventRecordsRaw(MustHexDecodeString("0x04002a000000000005000000"))
+
+	events := struct {
+		Module1_myEvent []struct { //nolint:stylecheck,golint
+			Phase  Phase
+			Value  uint32
+			Topics []Hash
+		}
+	}{}
+	err := e.DecodeEventRecords(&exampleMetadataV3, &events)
+	assert.NoError(t, err)
+	assert.Len(t, events.Module1_myEvent, 1)
+	assert.Equal(t, examplePhaseApp, events.Module1_myEvent[0].Phase)
+	assert.Equal(t, uint32(5), events.Module1_myEvent[0].Value)
+	assert.Nil(t, events.Module1_myEvent[0].Topics)
+
+	withoutTopics := struct {
+		Module1_myEvent []struct { //nolint:stylecheck,golint
+			Phase  Phase
+			Value  uint32
+			Hashes []Hash
+		}
+	}{}
+	err = e.DecodeEventRecords(&exampleMetadataV3, &withoutTopics)
+	assert.EqualError(t, err, "expected the last field of event #0 with EventID [0 0], field Module1_myEvent to be "+
+		"Topics, which is skipped for metadata v3, but got Hashes")
+}
+
 func TestDispatchError(t *testing.T) {
 	assertRoundtrip(t, DispatchError{HasModule: true, Module: 0xf1, Error: 0xa2})
 	assertRoundtrip(t, DispatchError{HasModule: false, Error: 0xa2})
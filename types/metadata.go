@@ -17,7 +17,11 @@
 package types
 
 import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
 	"fmt"
+	"io"
 
 	"github.com/zenghq3/go-substrate-rpc-client/scale"
 )
@@ -29,8 +33,20 @@ const MagicNumber uint32 = 0x6174656d
 type Metadata struct {
 	MagicNumber   uint32
 	Version       uint8
+	IsMetadataV0  bool
+	AsMetadataV0  MetadataV0
+	IsMetadataV1  bool
+	AsMetadataV1  MetadataV1
+	IsMetadataV2  bool
+	AsMetadataV2  MetadataV2
+	IsMetadataV3  bool
+	AsMetadataV3  MetadataV3
 	IsMetadataV4  bool
 	AsMetadataV4  MetadataV4
+	IsMetadataV5  bool
+	AsMetadataV5  MetadataV5
+	IsMetadataV6  bool
+	AsMetadataV6  MetadataV6
 	IsMetadataV7  bool
 	AsMetadataV7  MetadataV7
 	IsMetadataV8  bool
@@ -41,12 +57,38 @@ type Metadata struct {
 	AsMetadataV10 MetadataV10
 	IsMetadataV11 bool
 	AsMetadataV11 MetadataV11
+	IsMetadataV14 bool
+	AsMetadataV14 MetadataV14
+}
+
+func NewMetadataV0() *Metadata {
+	return &Metadata{IsMetadataV0: true, AsMetadataV0: MetadataV0{Modules: make([]RuntimeModuleMetadataV0, 0)}}
+}
+
+func NewMetadataV1() *Metadata {
+	return &Metadata{Version: 1, IsMetadataV1: true, AsMetadataV1: MetadataV1{make([]ModuleMetadataV1, 0)}}
+}
+
+func NewMetadataV2() *Metadata {
+	return &Metadata{Version: 2, IsMetadataV2: true, AsMetadataV2: MetadataV2{make([]ModuleMetadataV2, 0)}}
+}
+
+func NewMetadataV3() *Metadata {
+	return &Metadata{Version: 3, IsMetadataV3: true, AsMetadataV3: MetadataV3{make([]ModuleMetadataV3, 0)}}
 }
 
 func NewMetadataV4() *Metadata {
 	return &Metadata{Version: 4, IsMetadataV4: true, AsMetadataV4: MetadataV4{make([]ModuleMetadataV4, 0)}}
 }
 
+func NewMetadataV5() *Metadata {
+	return &Metadata{Version: 5, IsMetadataV5: true, AsMetadataV5: MetadataV5{make([]ModuleMetadataV5, 0)}}
+}
+
+func NewMetadataV6() *Metadata {
+	return &Metadata{Version: 6, IsMetadataV6: true, AsMetadataV6: MetadataV6{make([]ModuleMetadataV6, 0)}}
+}
+
 func NewMetadataV7() *Metadata {
 	return &Metadata{Version: 7, IsMetadataV7: true, AsMetadataV7: MetadataV7{make([]ModuleMetadataV7, 0)}}
 }
@@ -71,13 +113,35 @@ func NewMetadataV11() *Metadata {
 	}
 }
 
+func NewMetadataV14() *Metadata {
+	return &Metadata{
+		Version:       14,
+		IsMetadataV14: true,
+		AsMetadataV14: MetadataV14{Pallets: make([]PalletMetadataV14, 0)},
+	}
+}
+
 func (m *Metadata) Decode(decoder scale.Decoder) error {
-	err := decoder.Decode(&m.MagicNumber)
+	var magic [4]byte
+	err := decoder.Read(magic[:])
+	if err != nil {
+		return err
+	}
+	err = DecodeFromBytes(magic[:], &m.MagicNumber)
 	if err != nil {
 		return err
 	}
 	if m.MagicNumber != MagicNumber {
-		return fmt.Errorf("magic number mismatch: expected %#x, found %#x", MagicNumber, m.MagicNumber)
+		// Metadata v0 is not prefixed with the magic number and the version, the bytes read so far are part of it
+		m.MagicNumber = 0
+		err = decodeMetadataV0(magic[:], decoder, &m.AsMetadataV0)
+		if err != nil {
+			return fmt.Errorf("magic number mismatch: expected %#x, found %#x, and could not decode metadata v0: %v",
+				MagicNumber, binary.LittleEndian.Uint32(magic[:]), err)
+		}
+		m.Version = 0
+		m.IsMetadataV0 = true
+		return nil
 	}
 
 	err = decoder.Decode(&m.Version)
@@ -86,9 +150,24 @@ func (m *Metadata) Decode(decoder scale.Decoder) error {
 	}
 
 	switch m.Version {
+	case 1:
+		m.IsMetadataV1 = true
+		err = decoder.Decode(&m.AsMetadataV1)
+	case 2:
+		m.IsMetadataV2 = true
+		err = decoder.Decode(&m.AsMetadataV2)
+	case 3:
+		m.IsMetadataV3 = true
+		err = decoder.Decode(&m.AsMetadataV3)
 	case 4:
 		m.IsMetadataV4 = true
 		err = decoder.Decode(&m.AsMetadataV4)
+	case 5:
+		m.IsMetadataV5 = true
+		err = decoder.Decode(&m.AsMetadataV5)
+	case 6:
+		m.IsMetadataV6 = true
+		err = decoder.Decode(&m.AsMetadataV6)
 	case 7:
 		m.IsMetadataV7 = true
 		err = decoder.Decode(&m.AsMetadataV7)
@@ -104,6 +183,9 @@ func (m *Metadata) Decode(decoder scale.Decoder) error {
 	case 11:
 		m.IsMetadataV11 = true
 		err = decoder.Decode(&m.AsMetadataV11)
+	case 14:
+		m.IsMetadataV14 = true
+		err = decoder.Decode(&m.AsMetadataV14)
 	default:
 		return fmt.Errorf("unsupported metadata version %v", m.Version)
 	}
@@ -112,6 +194,10 @@ func (m *Metadata) Decode(decoder scale.Decoder) error {
 }
 
 func (m Metadata) Encode(encoder scale.Encoder) error {
+	if m.IsMetadataV0 {
+		return encoder.Encode(m.AsMetadataV0)
+	}
+
 	err := encoder.Encode(m.MagicNumber)
 	if err != nil {
 		return err
@@ -123,8 +209,18 @@ func (m Metadata) Encode(encoder scale.Encoder) error {
 	}
 
 	switch m.Version {
+	case 1:
+		err = encoder.Encode(m.AsMetadataV1)
+	case 2:
+		err = encoder.Encode(m.AsMetadataV2)
+	case 3:
+		err = encoder.Encode(m.AsMetadataV3)
 	case 4:
 		err = encoder.Encode(m.AsMetadataV4)
+	case 5:
+		err = encoder.Encode(m.AsMetadataV5)
+	case 6:
+		err = encoder.Encode(m.AsMetadataV6)
 	case 7:
 		err = encoder.Encode(m.AsMetadataV7)
 	case 8:
@@ -135,6 +231,8 @@ func (m Metadata) Encode(encoder scale.Encoder) error {
 		err = encoder.Encode(m.AsMetadataV10)
 	case 11:
 		err = encoder.Encode(m.AsMetadataV11)
+	case 14:
+		err = encoder.Encode(m.AsMetadataV14)
 	default:
 		return fmt.Errorf("unsupported metadata version %v", m.Version)
 	}
@@ -142,10 +240,138 @@ func (m Metadata) Encode(encoder scale.Encoder) error {
 	return err
 }
 
+// metadataJSON is the JSON encoding of Metadata, with the versioned metadata keyed by its version, e.g. "v14"
+type metadataJSON struct {
+	MagicNumber uint32                     `json:"magicNumber"`
+	Metadata    map[string]json.RawMessage `json:"metadata"`
+}
+
+// UnmarshalJSON fills m with the JSON encoded metadata given by b, in the format of polkadot-js
+func (m *Metadata) UnmarshalJSON(b []byte) error {
+	var tmp metadataJSON
+	err := json.Unmarshal(b, &tmp)
+	if err != nil {
+		return err
+	}
+	if len(tmp.Metadata) != 1 {
+		return fmt.Errorf("expected metadata of a single version, but got %v", len(tmp.Metadata))
+	}
+
+	for key, raw := range tmp.Metadata {
+		var version uint8
+		_, err = fmt.Sscanf(key, "v%d", &version)
+		if err != nil {
+			return fmt.Errorf("unable to parse metadata version %v: %v", key, err)
+		}
+
+		*m = Metadata{MagicNumber: tmp.MagicNumber, Version: version}
+		switch version {
+		case 0:
+			m.IsMetadataV0 = true
+			err = json.Unmarshal(raw, &m.AsMetadataV0)
+		case 1:
+			m.IsMetadataV1 = true
+			err = json.Unmarshal(raw, &m.AsMetadataV1)
+		case 2:
+			m.IsMetadataV2 = true
+			err = json.Unmarshal(raw, &m.AsMetadataV2)
+		case 3:
+			m.IsMetadataV3 = true
+			err = json.Unmarshal(raw, &m.AsMetadataV3)
+		case 4:
+			m.IsMetadataV4 = true
+			err = json.Unmarshal(raw, &m.AsMetadataV4)
+		case 5:
+			m.IsMetadataV5 = true
+			err = json.Unmarshal(raw, &m.AsMetadataV5)
+		case 6:
+			m.IsMetadataV6 = true
+			err = json.Unmarshal(raw, &m.AsMetadataV6)
+		case 7:
+			m.IsMetadataV7 = true
+			err = json.Unmarshal(raw, &m.AsMetadataV7)
+		case 8:
+			m.IsMetadataV8 = true
+			err = json.Unmarshal(raw, &m.AsMetadataV8)
+		case 9:
+			m.IsMetadataV9 = true
+			err = json.Unmarshal(raw, &m.AsMetadataV9)
+		case 10:
+			m.IsMetadataV10 = true
+			err = json.Unmarshal(raw, &m.AsMetadataV10)
+		case 11:
+			m.IsMetadataV11 = true
+			err = json.Unmarshal(raw, &m.AsMetadataV11)
+		case 14:
+			m.IsMetadataV14 = true
+			err = json.Unmarshal(raw, &m.AsMetadataV14)
+		default:
+			return fmt.Errorf("unsupported metadata version %v", version)
+		}
+	}
+	return err
+}
+
+// MarshalJSON returns the JSON encoding of m in the format of polkadot-js
+func (m Metadata) MarshalJSON() ([]byte, error) {
+	var meta interface{}
+	switch {
+	case m.IsMetadataV0:
+		meta = m.AsMetadataV0
+	case m.IsMetadataV1:
+		meta = m.AsMetadataV1
+	case m.IsMetadataV2:
+		meta = m.AsMetadataV2
+	case m.IsMetadataV3:
+		meta = m.AsMetadataV3
+	case m.IsMetadataV4:
+		meta = m.AsMetadataV4
+	case m.IsMetadataV5:
+		meta = m.AsMetadataV5
+	case m.IsMetadataV6:
+		meta = m.AsMetadataV6
+	case m.IsMetadataV7:
+		meta = m.AsMetadataV7
+	case m.IsMetadataV8:
+		meta = m.AsMetadataV8
+	case m.IsMetadataV9:
+		meta = m.AsMetadataV9
+	case m.IsMetadataV10:
+		meta = m.AsMetadataV10
+	case m.IsMetadataV11:
+		meta = m.AsMetadataV11
+	case m.IsMetadataV14:
+		meta = m.AsMetadataV14
+	default:
+		return nil, fmt.Errorf("unsupported metadata version %v", m.Version)
+	}
+
+	bz, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(metadataJSON{
+		MagicNumber: m.MagicNumber,
+		Metadata:    map[string]json.RawMessage{fmt.Sprintf("v%v", m.Version): bz},
+	})
+}
+
 func (m *Metadata) FindCallIndex(call string) (CallIndex, error) {
 	switch {
+	case m.IsMetadataV0:
+		return m.AsMetadataV0.FindCallIndex(call)
+	case m.IsMetadataV1:
+		return m.AsMetadataV1.FindCallIndex(call)
+	case m.IsMetadataV2:
+		return m.AsMetadataV2.FindCallIndex(call)
+	case m.IsMetadataV3:
+		return m.AsMetadataV3.FindCallIndex(call)
 	case m.IsMetadataV4:
 		return m.AsMetadataV4.FindCallIndex(call)
+	case m.IsMetadataV5:
+		return m.AsMetadataV5.FindCallIndex(call)
+	case m.IsMetadataV6:
+		return m.AsMetadataV6.FindCallIndex(call)
 	case m.IsMetadataV7:
 		return m.AsMetadataV7.FindCallIndex(call)
 	case m.IsMetadataV8:
@@ -156,15 +382,63 @@ func (m *Metadata) FindCallIndex(call string) (CallIndex, error) {
 		return m.AsMetadataV10.FindCallIndex(call)
 	case m.IsMetadataV11:
 		return m.AsMetadataV11.FindCallIndex(call)
+	case m.IsMetadataV14:
+		return m.AsMetadataV14.FindCallIndex(call)
 	default:
 		return CallIndex{}, fmt.Errorf("unsupported metadata version")
 	}
 }
 
+// FindCallMetadata returns the module name together with the metadata of the call with the given index, including the
+// argument names and types. It is not supported for metadata v14, where the arguments are described by
+// MetadataV14.FindCallVariant.
+func (m *Metadata) FindCallMetadata(index CallIndex) (Text, FunctionMetadataV4, error) {
+	switch {
+	case m.IsMetadataV0:
+		return m.AsMetadataV0.FindCallMetadata(index)
+	case m.IsMetadataV1:
+		return m.AsMetadataV1.FindCallMetadata(index)
+	case m.IsMetadataV2:
+		return m.AsMetadataV2.FindCallMetadata(index)
+	case m.IsMetadataV3:
+		return m.AsMetadataV3.FindCallMetadata(index)
+	case m.IsMetadataV4:
+		return m.AsMetadataV4.FindCallMetadata(index)
+	case m.IsMetadataV5:
+		return m.AsMetadataV5.FindCallMetadata(index)
+	case m.IsMetadataV6:
+		return m.AsMetadataV6.FindCallMetadata(index)
+	case m.IsMetadataV7:
+		return m.AsMetadataV7.FindCallMetadata(index)
+	case m.IsMetadataV8:
+		return m.AsMetadataV8.FindCallMetadata(index)
+	case m.IsMetadataV9:
+		return m.AsMetadataV9.FindCallMetadata(index)
+	case m.IsMetadataV10:
+		return m.AsMetadataV10.FindCallMetadata(index)
+	case m.IsMetadataV11:
+		return m.AsMetadataV11.FindCallMetadata(index)
+	default:
+		return "", FunctionMetadataV4{}, fmt.Errorf("unsupported metadata version")
+	}
+}
+
 func (m *Metadata) FindEventNamesForEventID(eventID EventID) (Text, Text, error) {
 	switch {
+	case m.IsMetadataV0:
+		return m.AsMetadataV0.FindEventNamesForEventID(eventID)
+	case m.IsMetadataV1:
+		return m.AsMetadataV1.FindEventNamesForEventID(eventID)
+	case m.IsMetadataV2:
+		return m.AsMetadataV2.FindEventNamesForEventID(eventID)
+	case m.IsMetadataV3:
+		return m.AsMetadataV3.FindEventNamesForEventID(eventID)
 	case m.IsMetadataV4:
 		return m.AsMetadataV4.FindEventNamesForEventID(eventID)
+	case m.IsMetadataV5:
+		return m.AsMetadataV5.FindEventNamesForEventID(eventID)
+	case m.IsMetadataV6:
+		return m.AsMetadataV6.FindEventNamesForEventID(eventID)
 	case m.IsMetadataV7:
 		return m.AsMetadataV7.FindEventNamesForEventID(eventID)
 	case m.IsMetadataV8:
@@ -175,15 +449,62 @@ func (m *Metadata) FindEventNamesForEventID(eventID EventID) (Text, Text, error)
 		return m.AsMetadataV10.FindEventNamesForEventID(eventID)
 	case m.IsMetadataV11:
 		return m.AsMetadataV11.FindEventNamesForEventID(eventID)
+	case m.IsMetadataV14:
+		return m.AsMetadataV14.FindEventNamesForEventID(eventID)
 	default:
 		return "", "", fmt.Errorf("unsupported metadata version")
 	}
 }
 
+// FindEventMetadata returns the module name together with the metadata of the event, including the argument types.
+// It is not supported for metadata v14, where the arguments are described by MetadataV14.FindEventVariant.
+func (m *Metadata) FindEventMetadata(eventID EventID) (Text, EventMetadataV4, error) {
+	switch {
+	case m.IsMetadataV0:
+		return m.AsMetadataV0.FindEventMetadata(eventID)
+	case m.IsMetadataV1:
+		return m.AsMetadataV1.FindEventMetadata(eventID)
+	case m.IsMetadataV2:
+		return m.AsMetadataV2.FindEventMetadata(eventID)
+	case m.IsMetadataV3:
+		return m.AsMetadataV3.FindEventMetadata(eventID)
+	case m.IsMetadataV4:
+		return m.AsMetadataV4.FindEventMetadata(eventID)
+	case m.IsMetadataV5:
+		return m.AsMetadataV5.FindEventMetadata(eventID)
+	case m.IsMetadataV6:
+		return m.AsMetadataV6.FindEventMetadata(eventID)
+	case m.IsMetadataV7:
+		return m.AsMetadataV7.FindEventMetadata(eventID)
+	case m.IsMetadataV8:
+		return m.AsMetadataV8.FindEventMetadata(eventID)
+	case m.IsMetadataV9:
+		return m.AsMetadataV9.FindEventMetadata(eventID)
+	case m.IsMetadataV10:
+		return m.AsMetadataV10.FindEventMetadata(eventID)
+	case m.IsMetadataV11:
+		return m.AsMetadataV11.FindEventMetadata(eventID)
+	default:
+		return "", EventMetadataV4{}, fmt.Errorf("unsupported metadata version")
+	}
+}
+
 func (m *Metadata) FindStorageEntryMetadata(module string, fn string) (StorageEntryMetadata, error) {
 	switch {
+	case m.IsMetadataV0:
+		return m.AsMetadataV0.FindStorageEntryMetadata(module, fn)
+	case m.IsMetadataV1:
+		return m.AsMetadataV1.FindStorageEntryMetadata(module, fn)
+	case m.IsMetadataV2:
+		return m.AsMetadataV2.FindStorageEntryMetadata(module, fn)
+	case m.IsMetadataV3:
+		return m.AsMetadataV3.FindStorageEntryMetadata(module, fn)
 	case m.IsMetadataV4:
 		return m.AsMetadataV4.FindStorageEntryMetadata(module, fn)
+	case m.IsMetadataV5:
+		return m.AsMetadataV5.FindStorageEntryMetadata(module, fn)
+	case m.IsMetadataV6:
+		return m.AsMetadataV6.FindStorageEntryMetadata(module, fn)
 	case m.IsMetadataV7:
 		return m.AsMetadataV7.FindStorageEntryMetadata(module, fn)
 	case m.IsMetadataV8:
@@ -194,6 +515,8 @@ func (m *Metadata) FindStorageEntryMetadata(module string, fn string) (StorageEn
 		return m.AsMetadataV10.FindStorageEntryMetadata(module, fn)
 	case m.IsMetadataV11:
 		return m.AsMetadataV11.FindStorageEntryMetadata(module, fn)
+	case m.IsMetadataV14:
+		return m.AsMetadataV14.FindStorageEntryMetadata(module, fn)
 	default:
 		return nil, fmt.Errorf("unsupported metadata version")
 	}
@@ -201,6 +524,8 @@ func (m *Metadata) FindStorageEntryMetadata(module string, fn string) (StorageEn
 
 func (m *Metadata) FindConstantMetadata(module string, constant string) (ModuleConstantMetadataV6, error) {
 	switch {
+	case m.IsMetadataV6:
+		return m.AsMetadataV6.FindConstantMetadata(module, constant)
 	case m.IsMetadataV7:
 		return m.AsMetadataV7.FindConstantMetadata(module, constant)
 	case m.IsMetadataV8:
@@ -211,7 +536,83 @@ func (m *Metadata) FindConstantMetadata(module string, constant string) (ModuleC
 		return m.AsMetadataV10.FindConstantMetadata(module, constant)
 	case m.IsMetadataV11:
 		return m.AsMetadataV11.FindConstantMetadata(module, constant)
+	case m.IsMetadataV14:
+		return m.AsMetadataV14.FindConstantMetadata(module, constant)
 	default:
 		return ModuleConstantMetadataV6{}, fmt.Errorf("unsupported metadata version")
 	}
 }
+
+// FindConstants returns all constants of the given module, for metadata v14 converted to the format of earlier
+// metadata versions
+func (m *Metadata) FindConstants(module string) ([]ModuleConstantMetadataV6, error) {
+	switch {
+	case m.IsMetadataV6:
+		return m.AsMetadataV6.FindConstants(module)
+	case m.IsMetadataV7:
+		return m.AsMetadataV7.FindConstants(module)
+	case m.IsMetadataV8:
+		return m.AsMetadataV8.FindConstants(module)
+	case m.IsMetadataV9:
+		return m.AsMetadataV9.FindConstants(module)
+	case m.IsMetadataV10:
+		return m.AsMetadataV10.FindConstants(module)
+	case m.IsMetadataV11:
+		return m.AsMetadataV11.FindConstants(module)
+	case m.IsMetadataV14:
+		return m.AsMetadataV14.FindConstants(module)
+	default:
+		return nil, fmt.Errorf("unsupported metadata version")
+	}
+}
+
+// DecodeConstant decodes the value of the given constant into target, which must be a pointer to a type matching the
+// type of the constant and consume all of its bytes, for example
+//
+//	var existentialDeposit U128
+//	err := meta.DecodeConstant("Balances", "ExistentialDeposit", &existentialDeposit)
+func (m *Metadata) DecodeConstant(module string, constant string, target interface{}) error {
+	c, err := m.FindConstantMetadata(module, constant)
+	if err != nil {
+		return err
+	}
+
+	reader := bytes.NewReader(c.Value)
+	err = scale.NewDecoder(reader).Decode(target)
+	if err == nil && reader.Len() > 0 {
+		err = fmt.Errorf("%v bytes left after decoding", reader.Len())
+	}
+	if err != nil {
+		return fmt.Errorf("unable to decode constant %v.%v of type %v: %v", module, constant, c.Type, err)
+	}
+	return nil
+}
+
+// decodeMetadataV0 decodes metadata v0 from the given prefix, which has already been read from the decoder, followed by
+// the remaining bytes of the decoder
+func decodeMetadataV0(prefix []byte, decoder scale.Decoder, m *MetadataV0) error {
+	r := io.MultiReader(bytes.NewReader(prefix), decoderReader{decoder})
+	return scale.NewDecoder(fullReader{r}).Decode(m)
+}
+
+// decoderReader exposes a scale.Decoder as an io.Reader
+type decoderReader struct {
+	decoder scale.Decoder
+}
+
+func (r decoderReader) Read(p []byte) (int, error) {
+	err := r.decoder.Read(p)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// fullReader fills the whole buffer on every read, as expected by scale.Decoder
+type fullReader struct {
+	reader io.Reader
+}
+
+func (r fullReader) Read(p []byte) (int, error) {
+	return io.ReadFull(r.reader, p)
+}
@@ -0,0 +1,443 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/zenghq3/go-substrate-rpc-client/scale"
+	"github.com/zenghq3/go-substrate-rpc-client/xxhash"
+)
+
+// MetadataV0 is the metadata of the earliest runtimes. Contrary to later versions, it is not prefixed with the magic
+// number and the version, and events and calls are described by the outer event and outer dispatch enums of the
+// runtime instead of the modules.
+//
+// Modelled after packages/types/src/Metadata/v0/Metadata.ts
+type MetadataV0 struct {
+	OuterEvent    OuterEventMetadataV0      `json:"outerEvent"`
+	Modules       []RuntimeModuleMetadataV0 `json:"modules"`
+	OuterDispatch OuterDispatchMetadataV0   `json:"outerDispatch"`
+}
+
+func (m *MetadataV0) FindCallIndex(call string) (CallIndex, error) {
+	s := strings.Split(call, ".")
+	for _, mod := range m.Modules {
+		if string(mod.Prefix) != s[0] {
+			continue
+		}
+		for _, c := range m.OuterDispatch.Calls {
+			if c.Prefix != mod.Prefix {
+				continue
+			}
+			for _, f := range mod.Module.Call.Functions {
+				if string(f.Name) == s[1] {
+					return CallIndex{uint8(c.Index), uint8(f.ID)}, nil
+				}
+			}
+			return CallIndex{}, fmt.Errorf("method %v not found within module %v for call %v", s[1], mod.Prefix, call)
+		}
+		return CallIndex{}, fmt.Errorf("module %v has no calls in metadata for call %v", s[0], call)
+	}
+	return CallIndex{}, fmt.Errorf("module %v not found in metadata for call %v", s[0], call)
+}
+
+func (m *MetadataV0) FindCallMetadata(index CallIndex) (Text, FunctionMetadataV4, error) {
+	for _, c := range m.OuterDispatch.Calls {
+		if c.Index != uint16(index.SectionIndex) {
+			continue
+		}
+		for _, mod := range m.Modules {
+			if mod.Prefix != c.Prefix {
+				continue
+			}
+			for _, f := range mod.Module.Call.Functions {
+				if f.ID == uint16(index.MethodIndex) {
+					return mod.Prefix, FunctionMetadataV4{Name: f.Name, Args: f.Args, Documentation: f.Documentation}, nil
+				}
+			}
+			return "", FunctionMetadataV4{}, fmt.Errorf("call index %v for module %v out of range", index.MethodIndex,
+				mod.Prefix)
+		}
+	}
+	return "", FunctionMetadataV4{}, fmt.Errorf("module index %v out of range", index.SectionIndex)
+}
+
+// FindEventNamesForEventID returns the module name as listed in the outer event, together with the event name
+func (m *MetadataV0) FindEventNamesForEventID(eventID EventID) (Text, Text, error) {
+	mod, event, err := m.FindEventMetadata(eventID)
+	return mod, event.Name, err
+}
+
+// FindEventMetadata returns the module name as listed in the outer event, together with the event metadata
+func (m *MetadataV0) FindEventMetadata(eventID EventID) (Text, EventMetadataV4, error) {
+	if int(eventID[0]) >= len(m.OuterEvent.Events) {
+		return "", EventMetadataV4{}, fmt.Errorf("module index %v out of range", eventID[0])
+	}
+	mod := m.OuterEvent.Events[eventID[0]]
+	if int(eventID[1]) >= len(mod.Events) {
+		return "", EventMetadataV4{}, fmt.Errorf("event index %v for module %v out of range", eventID[1], mod.Name)
+	}
+	return mod.Name, mod.Events[eventID[1]], nil
+}
+
+func (m *MetadataV0) FindStorageEntryMetadata(module string, fn string) (StorageEntryMetadata, error) {
+	for _, mod := range m.Modules {
+		if !mod.HasStorage {
+			continue
+		}
+		if string(mod.Storage.Prefix) != module {
+			continue
+		}
+		for _, s := range mod.Storage.Functions {
+			if string(s.Name) != fn {
+				continue
+			}
+			return s, nil
+		}
+		return nil, fmt.Errorf("storage %v not found within module %v", fn, module)
+	}
+	return nil, fmt.Errorf("module %v not found in metadata", module)
+}
+
+func (m MetadataV0) MarshalJSON() ([]byte, error) {
+	type plain MetadataV0
+	if m.Modules == nil {
+		m.Modules = []RuntimeModuleMetadataV0{}
+	}
+	return json.Marshal(plain(m))
+}
+
+type OuterEventMetadataV0 struct {
+	Name   Text                        `json:"name"`
+	Events []OuterEventEventMetadataV0 `json:"events"`
+}
+
+func (m OuterEventMetadataV0) MarshalJSON() ([]byte, error) {
+	type plain OuterEventMetadataV0
+	if m.Events == nil {
+		m.Events = []OuterEventEventMetadataV0{}
+	}
+	return json.Marshal(plain(m))
+}
+
+// OuterEventEventMetadataV0 lists the events of a single module, its index in OuterEventMetadataV0 is the module
+// index of an EventID
+type OuterEventEventMetadataV0 struct {
+	Name   Text
+	Events []EventMetadataV4
+}
+
+// UnmarshalJSON fills m with the JSON encoded tuple of the module name and its events given by b
+func (m *OuterEventEventMetadataV0) UnmarshalJSON(b []byte) error {
+	tmp := []interface{}{&m.Name, &m.Events}
+	wantLen := len(tmp)
+	err := json.Unmarshal(b, &tmp)
+	if err != nil {
+		return err
+	}
+	if len(tmp) != wantLen {
+		return fmt.Errorf("expected tuple of %v elements, but got %v", wantLen, len(tmp))
+	}
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of m as tuple of the module name and its events, with [] for no events
+func (m OuterEventEventMetadataV0) MarshalJSON() ([]byte, error) {
+	if m.Events == nil {
+		m.Events = []EventMetadataV4{}
+	}
+	return json.Marshal([]interface{}{m.Name, m.Events})
+}
+
+type RuntimeModuleMetadataV0 struct {
+	Prefix     Text
+	Module     ModuleMetadataV0
+	HasStorage bool
+	Storage    StorageMetadataV0
+}
+
+func (m *RuntimeModuleMetadataV0) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&m.Prefix)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Module)
+	if err != nil {
+		return err
+	}
+
+	return decoder.DecodeOption(&m.HasStorage, &m.Storage)
+}
+
+func (m RuntimeModuleMetadataV0) Encode(encoder scale.Encoder) error {
+	err := encoder.Encode(m.Prefix)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.Encode(m.Module)
+	if err != nil {
+		return err
+	}
+
+	return encoder.EncodeOption(m.HasStorage, m.Storage)
+}
+
+// UnmarshalJSON fills m with the JSON encoded module metadata given by b
+func (m *RuntimeModuleMetadataV0) UnmarshalJSON(b []byte) error {
+	var tmp struct {
+		Prefix  Text             `json:"prefix"`
+		Module  ModuleMetadataV0 `json:"module"`
+		Storage json.RawMessage  `json:"storage"`
+	}
+	err := json.Unmarshal(b, &tmp)
+	if err != nil {
+		return err
+	}
+
+	*m = RuntimeModuleMetadataV0{Prefix: tmp.Prefix, Module: tmp.Module}
+	m.HasStorage, err = unmarshalOptionJSON(tmp.Storage, &m.Storage)
+	return err
+}
+
+// MarshalJSON returns the JSON encoding of m, with null for missing options
+func (m RuntimeModuleMetadataV0) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Prefix  Text             `json:"prefix"`
+		Module  ModuleMetadataV0 `json:"module"`
+		Storage interface{}      `json:"storage"`
+	}{
+		Prefix:  m.Prefix,
+		Module:  m.Module,
+		Storage: optionJSON(m.HasStorage, m.Storage),
+	})
+}
+
+type ModuleMetadataV0 struct {
+	Name Text           `json:"name"`
+	Call CallMetadataV0 `json:"call"`
+}
+
+type CallMetadataV0 struct {
+	Name      Text                 `json:"name"`
+	Functions []FunctionMetadataV0 `json:"functions"`
+}
+
+func (m CallMetadataV0) MarshalJSON() ([]byte, error) {
+	type plain CallMetadataV0
+	if m.Functions == nil {
+		m.Functions = []FunctionMetadataV0{}
+	}
+	return json.Marshal(plain(m))
+}
+
+type FunctionMetadataV0 struct {
+	ID            uint16                     `json:"id"`
+	Name          Text                       `json:"name"`
+	Args          []FunctionArgumentMetadata `json:"arguments"`
+	Documentation []Text                     `json:"documentation"`
+}
+
+func (m FunctionMetadataV0) MarshalJSON() ([]byte, error) {
+	type plain FunctionMetadataV0
+	if m.Args == nil {
+		m.Args = []FunctionArgumentMetadata{}
+	}
+	if m.Documentation == nil {
+		m.Documentation = []Text{}
+	}
+	return json.Marshal(plain(m))
+}
+
+type StorageMetadataV0 struct {
+	Prefix    Text                        `json:"prefix"`
+	Functions []StorageFunctionMetadataV0 `json:"functions"`
+}
+
+func (s StorageMetadataV0) MarshalJSON() ([]byte, error) {
+	type plain StorageMetadataV0
+	if s.Functions == nil {
+		s.Functions = []StorageFunctionMetadataV0{}
+	}
+	return json.Marshal(plain(s))
+}
+
+type StorageFunctionMetadataV0 struct {
+	Name          Text                      `json:"name"`
+	Modifier      StorageFunctionModifierV0 `json:"modifier"`
+	Type          StorageFunctionTypeV0     `json:"type"`
+	Fallback      Bytes                     `json:"fallback"`
+	Documentation []Text                    `json:"documentation"`
+}
+
+func (s StorageFunctionMetadataV0) IsPlain() bool {
+	return s.Type.IsType
+}
+
+func (s StorageFunctionMetadataV0) IsMap() bool {
+	return s.Type.IsMap
+}
+
+func (s StorageFunctionMetadataV0) IsDoubleMap() bool {
+	return false
+}
+
+// Hasher returns Twox128 for both plain values and maps, metadata before v4 had no configurable hashers
+func (s StorageFunctionMetadataV0) Hasher() (hash.Hash, error) {
+	return xxhash.New128(nil), nil
+}
+
+func (s StorageFunctionMetadataV0) Hasher2() (hash.Hash, error) {
+	return nil, fmt.Errorf("only DoubleMaps have a Hasher2")
+}
+
+func (s StorageFunctionMetadataV0) IsOptional() bool {
+	return s.Modifier.IsOptional
+}
+
+func (s StorageFunctionMetadataV0) DefaultValue() Bytes {
+	return s.Fallback
+}
+
+func (s StorageFunctionMetadataV0) MarshalJSON() ([]byte, error) {
+	type plain StorageFunctionMetadataV0
+	if s.Documentation == nil {
+		s.Documentation = []Text{}
+	}
+	return json.Marshal(plain(s))
+}
+
+type StorageFunctionTypeV0 struct {
+	IsType bool
+	AsType Type // 0
+	IsMap  bool
+	AsMap  MapTypeV0 // 1
+}
+
+func (s *StorageFunctionTypeV0) Decode(decoder scale.Decoder) error {
+	var t uint8
+	err := decoder.Decode(&t)
+	if err != nil {
+		return err
+	}
+
+	switch t {
+	case 0:
+		s.IsType = true
+		err = decoder.Decode(&s.AsType)
+		if err != nil {
+			return err
+		}
+	case 1:
+		s.IsMap = true
+		err = decoder.Decode(&s.AsMap)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("received unexpected type %v", t)
+	}
+	return nil
+}
+
+func (s StorageFunctionTypeV0) Encode(encoder scale.Encoder) error {
+	switch {
+	case s.IsType:
+		err := encoder.PushByte(0)
+		if err != nil {
+			return err
+		}
+		err = encoder.Encode(s.AsType)
+		if err != nil {
+			return err
+		}
+	case s.IsMap:
+		err := encoder.PushByte(1)
+		if err != nil {
+			return err
+		}
+		err = encoder.Encode(s.AsMap)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("expected to be either type or map, but none was set: %v", s)
+	}
+	return nil
+}
+
+// UnmarshalJSON fills s with the JSON encoded storage function type given by b
+func (s *StorageFunctionTypeV0) UnmarshalJSON(b []byte) error {
+	variant, value, err := unmarshalEnumJSON(b)
+	if err != nil {
+		return err
+	}
+
+	*s = StorageFunctionTypeV0{}
+	switch variant {
+	case "plain":
+		s.IsType = true
+		return json.Unmarshal(value, &s.AsType)
+	case "map":
+		s.IsMap = true
+		return json.Unmarshal(value, &s.AsMap)
+	default:
+		return fmt.Errorf("received unexpected storage function type %v", variant)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of s
+func (s StorageFunctionTypeV0) MarshalJSON() ([]byte, error) {
+	switch {
+	case s.IsType:
+		return marshalEnumJSON("plain", s.AsType)
+	case s.IsMap:
+		return marshalEnumJSON("map", s.AsMap)
+	default:
+		return nil, fmt.Errorf("expected storage function type, but none was set: %v", s)
+	}
+}
+
+type MapTypeV0 struct {
+	Key   Type `json:"key"`
+	Value Type `json:"value"`
+}
+
+type OuterDispatchMetadataV0 struct {
+	Name  Text                  `json:"name"`
+	Calls []OuterDispatchCallV0 `json:"calls"`
+}
+
+func (m OuterDispatchMetadataV0) MarshalJSON() ([]byte, error) {
+	type plain OuterDispatchMetadataV0
+	if m.Calls == nil {
+		m.Calls = []OuterDispatchCallV0{}
+	}
+	return json.Marshal(plain(m))
+}
+
+// OuterDispatchCallV0 maps a module prefix to the section index of its calls
+type OuterDispatchCallV0 struct {
+	Name   Text   `json:"name"`
+	Prefix Text   `json:"prefix"`
+	Index  uint16 `json:"index"`
+}
@@ -0,0 +1,117 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+var exampleMetadataV0 = Metadata{
+	IsMetadataV0: true,
+	AsMetadataV0: MetadataV0{
+		OuterEvent: OuterEventMetadataV0{
+			Name: "Event",
+			Events: []OuterEventEventMetadataV0{
+				{Name: "system", Events: []EventMetadataV4{{Name: "ExtrinsicSuccess", Documentation: []Text{"doc"}}}},
+				{Name: "balances", Events: []EventMetadataV4{exampleEventMetadataV4}},
+			},
+		},
+		Modules: []RuntimeModuleMetadataV0{
+			{
+				Prefix: "system",
+				Module: ModuleMetadataV0{Name: "system", Call: CallMetadataV0{Name: "Call"}},
+			},
+			{
+				Prefix: "balances",
+				Module: ModuleMetadataV0{
+					Name: "balances",
+					Call: CallMetadataV0{
+						Name: "Call",
+						Functions: []FunctionMetadataV0{
+							{ID: 0, Name: "transfer", Args: []FunctionArgumentMetadata{exampleFunctionArgumentMetadata}},
+							{ID: 1, Name: "set_balance"},
+						},
+					},
+				},
+				HasStorage: true,
+				Storage: StorageMetadataV0{
+					Prefix:    "Balances",
+					Functions: []StorageFunctionMetadataV0{exampleStorageFunctionMetadataV0Map},
+				},
+			},
+		},
+		OuterDispatch: OuterDispatchMetadataV0{
+			Name:  "Call",
+			Calls: []OuterDispatchCallV0{{Name: "Balances", Prefix: "balances", Index: 3}},
+		},
+	},
+}
+
+var exampleStorageFunctionMetadataV0Map = StorageFunctionMetadataV0{
+	Name:          "FreeBalance",
+	Modifier:      StorageFunctionModifierV0{IsDefault: true},
+	Type:          StorageFunctionTypeV0{IsMap: true, AsMap: MapTypeV0{Key: "AccountId", Value: "Balance"}},
+	Fallback:      []byte{0},
+	Documentation: []Text{"The free balance"},
+}
+
+func TestMetadataV0_EncodeDecode(t *testing.T) {
+	assertRoundtrip(t, exampleMetadataV0)
+}
+
+func TestMetadataV0_EncodeWithoutMagicNumber(t *testing.T) {
+	enc, err := EncodeToBytes(exampleMetadataV0)
+	assert.NoError(t, err)
+
+	encV0, err := EncodeToBytes(exampleMetadataV0.AsMetadataV0)
+	assert.NoError(t, err)
+	assert.Equal(t, encV0, enc)
+}
+
+func TestMetadataV0_Decode_Invalid(t *testing.T) {
+	var m Metadata
+	err := DecodeFromBytes([]byte{0x01, 0x02, 0x03, 0x04}, &m)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "magic number mismatch: expected 0x6174656d, found 0x4030201")
+}
+
+func TestMetadataV0_FindCallIndex(t *testing.T) {
+	callIndex, err := exampleMetadataV0.FindCallIndex("balances.set_balance")
+	assert.NoError(t, err)
+	assert.Equal(t, CallIndex{SectionIndex: 3, MethodIndex: 1}, callIndex)
+
+	_, err = exampleMetadataV0.FindCallIndex("system.remark")
+	assert.EqualError(t, err, "module system has no calls in metadata for call system.remark")
+}
+
+func TestMetadataV0_FindEventNamesForEventID(t *testing.T) {
+	module, event, err := exampleMetadataV0.FindEventNamesForEventID(EventID([2]byte{1, 0}))
+	assert.NoError(t, err)
+	assert.Equal(t, Text("balances"), module)
+	assert.Equal(t, exampleEventMetadataV4.Name, event)
+}
+
+func TestMetadataV0_FindStorageEntryMetadata(t *testing.T) {
+	entry, err := exampleMetadataV0.FindStorageEntryMetadata("Balances", "FreeBalance")
+	assert.NoError(t, err)
+	assert.Equal(t, exampleStorageFunctionMetadataV0Map, entry)
+	assert.True(t, entry.IsMap())
+}
@@ -0,0 +1,294 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/zenghq3/go-substrate-rpc-client/scale"
+)
+
+// MetadataV1 is the first metadata version prefixed with the magic number. Calls and events are described per
+// module, storage items are the same as in MetadataV0.
+//
+// Modelled after packages/types/src/Metadata/v1/Metadata.ts
+type MetadataV1 struct {
+	Modules []ModuleMetadataV1 `json:"modules"`
+}
+
+func (m *MetadataV1) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&m.Modules)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m MetadataV1) Encode(encoder scale.Encoder) error {
+	err := encoder.Encode(m.Modules)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *MetadataV1) FindCallIndex(call string) (CallIndex, error) {
+	s := strings.Split(call, ".")
+	mi := uint8(0)
+	for _, mod := range m.Modules {
+		if !mod.HasCalls {
+			continue
+		}
+		if string(mod.Name) != s[0] {
+			mi++
+			continue
+		}
+		for ci, f := range mod.Calls {
+			if string(f.Name) == s[1] {
+				return CallIndex{mi, uint8(ci)}, nil
+			}
+		}
+		return CallIndex{}, fmt.Errorf("method %v not found within module %v for call %v", s[1], mod.Name, call)
+	}
+	return CallIndex{}, fmt.Errorf("module %v not found in metadata for call %v", s[0], call)
+}
+
+func (m *MetadataV1) FindCallMetadata(index CallIndex) (Text, FunctionMetadataV4, error) {
+	mi := uint8(0)
+	for _, mod := range m.Modules {
+		if !mod.HasCalls {
+			continue
+		}
+		if mi != index.SectionIndex {
+			mi++
+			continue
+		}
+		if int(index.MethodIndex) >= len(mod.Calls) {
+			return "", FunctionMetadataV4{}, fmt.Errorf("call index %v for module %v out of range", index.MethodIndex,
+				mod.Name)
+		}
+		return mod.Name, mod.Calls[index.MethodIndex], nil
+	}
+	return "", FunctionMetadataV4{}, fmt.Errorf("module index %v out of range", index.SectionIndex)
+}
+
+func (m *MetadataV1) FindEventNamesForEventID(eventID EventID) (Text, Text, error) {
+	mod, event, err := m.FindEventMetadata(eventID)
+	return mod, event.Name, err
+}
+
+func (m *MetadataV1) FindEventMetadata(eventID EventID) (Text, EventMetadataV4, error) {
+	mi := uint8(0)
+	for _, mod := range m.Modules {
+		if !mod.HasEvents {
+			continue
+		}
+		if mi != eventID[0] {
+			mi++
+			continue
+		}
+		if int(eventID[1]) >= len(mod.Events) {
+			return "", EventMetadataV4{}, fmt.Errorf("event index %v for module %v out of range", eventID[1], mod.Name)
+		}
+		return mod.Prefix, mod.Events[eventID[1]], nil
+	}
+	return "", EventMetadataV4{}, fmt.Errorf("module index %v out of range", eventID[0])
+}
+
+func (m *MetadataV1) FindStorageEntryMetadata(module string, fn string) (StorageEntryMetadata, error) {
+	for _, mod := range m.Modules {
+		if !mod.HasStorage {
+			continue
+		}
+		if string(mod.Prefix) != module {
+			continue
+		}
+		for _, s := range mod.Storage {
+			if string(s.Name) != fn {
+				continue
+			}
+			return s, nil
+		}
+		return nil, fmt.Errorf("storage %v not found within module %v", fn, module)
+	}
+	return nil, fmt.Errorf("module %v not found in metadata", module)
+}
+
+func (m MetadataV1) MarshalJSON() ([]byte, error) {
+	type plain MetadataV1
+	if m.Modules == nil {
+		m.Modules = []ModuleMetadataV1{}
+	}
+	return json.Marshal(plain(m))
+}
+
+type ModuleMetadataV1 struct {
+	Name       Text
+	Prefix     Text
+	HasStorage bool
+	Storage    []StorageFunctionMetadataV0
+	HasCalls   bool
+	Calls      []FunctionMetadataV4
+	HasEvents  bool
+	Events     []EventMetadataV4
+}
+
+func (m *ModuleMetadataV1) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&m.Name)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Prefix)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.HasStorage)
+	if err != nil {
+		return err
+	}
+
+	if m.HasStorage {
+		err = decoder.Decode(&m.Storage)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = decoder.Decode(&m.HasCalls)
+	if err != nil {
+		return err
+	}
+
+	if m.HasCalls {
+		err = decoder.Decode(&m.Calls)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = decoder.Decode(&m.HasEvents)
+	if err != nil {
+		return err
+	}
+
+	if m.HasEvents {
+		err = decoder.Decode(&m.Events)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m ModuleMetadataV1) Encode(encoder scale.Encoder) error {
+	err := encoder.Encode(m.Name)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.Encode(m.Prefix)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.Encode(m.HasStorage)
+	if err != nil {
+		return err
+	}
+
+	if m.HasStorage {
+		err = encoder.Encode(m.Storage)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = encoder.Encode(m.HasCalls)
+	if err != nil {
+		return err
+	}
+
+	if m.HasCalls {
+		err = encoder.Encode(m.Calls)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = encoder.Encode(m.HasEvents)
+	if err != nil {
+		return err
+	}
+
+	if m.HasEvents {
+		err = encoder.Encode(m.Events)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalJSON fills m with the JSON encoded module metadata given by b
+func (m *ModuleMetadataV1) UnmarshalJSON(b []byte) error {
+	var tmp struct {
+		Name    Text            `json:"name"`
+		Prefix  Text            `json:"prefix"`
+		Storage json.RawMessage `json:"storage"`
+		Calls   json.RawMessage `json:"calls"`
+		Events  json.RawMessage `json:"events"`
+	}
+	err := json.Unmarshal(b, &tmp)
+	if err != nil {
+		return err
+	}
+
+	*m = ModuleMetadataV1{Name: tmp.Name, Prefix: tmp.Prefix}
+	m.HasStorage, err = unmarshalOptionJSON(tmp.Storage, &m.Storage)
+	if err != nil {
+		return err
+	}
+	m.HasCalls, err = unmarshalOptionJSON(tmp.Calls, &m.Calls)
+	if err != nil {
+		return err
+	}
+	m.HasEvents, err = unmarshalOptionJSON(tmp.Events, &m.Events)
+	return err
+}
+
+// MarshalJSON returns the JSON encoding of m, with null for missing options
+func (m ModuleMetadataV1) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name    Text        `json:"name"`
+		Prefix  Text        `json:"prefix"`
+		Storage interface{} `json:"storage"`
+		Calls   interface{} `json:"calls"`
+		Events  interface{} `json:"events"`
+	}{
+		Name:    m.Name,
+		Prefix:  m.Prefix,
+		Storage: optionJSON(m.HasStorage, m.Storage),
+		Calls:   optionJSON(m.HasCalls, m.Calls),
+		Events:  optionJSON(m.HasEvents, m.Events),
+	})
+}
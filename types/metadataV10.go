@@ -17,11 +17,13 @@
 package types
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"hash"
 	"strings"
 
+	blake2 "github.com/zenghq3/go-substrate-rpc-client/crypto/blake2b"
 	"github.com/zenghq3/go-substrate-rpc-client/scale"
 	"github.com/zenghq3/go-substrate-rpc-client/xxhash"
 	"golang.org/x/crypto/blake2b"
@@ -29,7 +31,7 @@ import (
 
 // Modelled after packages/types/src/Metadata/v10/Metadata.ts
 type MetadataV10 struct {
-	Modules []ModuleMetadataV10
+	Modules []ModuleMetadataV10 `json:"modules"`
 }
 
 func (m *MetadataV10) Decode(decoder scale.Decoder) error {
@@ -69,7 +71,31 @@ func (m *MetadataV10) FindCallIndex(call string) (CallIndex, error) {
 	return CallIndex{}, fmt.Errorf("module %v not found in metadata for call %v", s[0], call)
 }
 
+func (m *MetadataV10) FindCallMetadata(index CallIndex) (Text, FunctionMetadataV4, error) {
+	mi := uint8(0)
+	for _, mod := range m.Modules {
+		if !mod.HasCalls {
+			continue
+		}
+		if mi != index.SectionIndex {
+			mi++
+			continue
+		}
+		if int(index.MethodIndex) >= len(mod.Calls) {
+			return "", FunctionMetadataV4{}, fmt.Errorf("call index %v for module %v out of range", index.MethodIndex,
+				mod.Name)
+		}
+		return mod.Name, mod.Calls[index.MethodIndex], nil
+	}
+	return "", FunctionMetadataV4{}, fmt.Errorf("module index %v out of range", index.SectionIndex)
+}
+
 func (m *MetadataV10) FindEventNamesForEventID(eventID EventID) (Text, Text, error) {
+	mod, event, err := m.FindEventMetadata(eventID)
+	return mod, event.Name, err
+}
+
+func (m *MetadataV10) FindEventMetadata(eventID EventID) (Text, EventMetadataV4, error) {
 	mi := uint8(0)
 	for _, mod := range m.Modules {
 		if !mod.HasEvents {
@@ -80,11 +106,11 @@ func (m *MetadataV10) FindEventNamesForEventID(eventID EventID) (Text, Text, err
 			continue
 		}
 		if int(eventID[1]) >= len(mod.Events) {
-			return "", "", fmt.Errorf("event index %v for module %v out of range", eventID[1], mod.Name)
+			return "", EventMetadataV4{}, fmt.Errorf("event index %v for module %v out of range", eventID[1], mod.Name)
 		}
-		return mod.Name, mod.Events[eventID[1]].Name, nil
+		return mod.Name, mod.Events[eventID[1]], nil
 	}
-	return "", "", fmt.Errorf("module index %v out of range", eventID[0])
+	return "", EventMetadataV4{}, fmt.Errorf("module index %v out of range", eventID[0])
 }
 
 func (m *MetadataV10) FindStorageEntryMetadata(module string, fn string) (StorageEntryMetadata, error) {
@@ -122,6 +148,24 @@ func (m *MetadataV10) FindConstantMetadata(module string, constant string) (Modu
 	return ModuleConstantMetadataV6{}, fmt.Errorf("module %v not found in metadata", module)
 }
 
+// FindConstants returns all constants of the given module
+func (m *MetadataV10) FindConstants(module string) ([]ModuleConstantMetadataV6, error) {
+	for _, mod := range m.Modules {
+		if strings.EqualFold(string(mod.Name), module) {
+			return mod.Constants, nil
+		}
+	}
+	return nil, fmt.Errorf("module %v not found in metadata", module)
+}
+
+func (m MetadataV10) MarshalJSON() ([]byte, error) {
+	type plain MetadataV10
+	if m.Modules == nil {
+		m.Modules = []ModuleMetadataV10{}
+	}
+	return json.Marshal(plain(m))
+}
+
 type ModuleMetadataV10 struct {
 	Name       Text
 	HasStorage bool
@@ -234,17 +278,78 @@ func (m ModuleMetadataV10) Encode(encoder scale.Encoder) error {
 	return encoder.Encode(m.Errors)
 }
 
+// UnmarshalJSON fills m with the JSON encoded module metadata given by b
+func (m *ModuleMetadataV10) UnmarshalJSON(b []byte) error {
+	var tmp struct {
+		Name      Text                       `json:"name"`
+		Storage   json.RawMessage            `json:"storage"`
+		Calls     json.RawMessage            `json:"calls"`
+		Events    json.RawMessage            `json:"events"`
+		Constants []ModuleConstantMetadataV6 `json:"constants"`
+		Errors    []ErrorMetadataV8          `json:"errors"`
+	}
+	err := json.Unmarshal(b, &tmp)
+	if err != nil {
+		return err
+	}
+
+	*m = ModuleMetadataV10{Name: tmp.Name, Constants: tmp.Constants, Errors: tmp.Errors}
+	m.HasStorage, err = unmarshalOptionJSON(tmp.Storage, &m.Storage)
+	if err != nil {
+		return err
+	}
+	m.HasCalls, err = unmarshalOptionJSON(tmp.Calls, &m.Calls)
+	if err != nil {
+		return err
+	}
+	m.HasEvents, err = unmarshalOptionJSON(tmp.Events, &m.Events)
+	return err
+}
+
+// MarshalJSON returns the JSON encoding of m, with null for missing options and [] for empty lists
+func (m ModuleMetadataV10) MarshalJSON() ([]byte, error) {
+	if m.Constants == nil {
+		m.Constants = []ModuleConstantMetadataV6{}
+	}
+	if m.Errors == nil {
+		m.Errors = []ErrorMetadataV8{}
+	}
+	return json.Marshal(struct {
+		Name      Text                       `json:"name"`
+		Storage   interface{}                `json:"storage"`
+		Calls     interface{}                `json:"calls"`
+		Events    interface{}                `json:"events"`
+		Constants []ModuleConstantMetadataV6 `json:"constants"`
+		Errors    []ErrorMetadataV8          `json:"errors"`
+	}{
+		Name:      m.Name,
+		Storage:   optionJSON(m.HasStorage, m.Storage),
+		Calls:     optionJSON(m.HasCalls, m.Calls),
+		Events:    optionJSON(m.HasEvents, m.Events),
+		Constants: m.Constants,
+		Errors:    m.Errors,
+	})
+}
+
 type StorageMetadataV10 struct {
-	Prefix Text
-	Items  []StorageFunctionMetadataV10
+	Prefix Text                         `json:"prefix"`
+	Items  []StorageFunctionMetadataV10 `json:"items"`
+}
+
+func (s StorageMetadataV10) MarshalJSON() ([]byte, error) {
+	type plain StorageMetadataV10
+	if s.Items == nil {
+		s.Items = []StorageFunctionMetadataV10{}
+	}
+	return json.Marshal(plain(s))
 }
 
 type StorageFunctionMetadataV10 struct {
-	Name          Text
-	Modifier      StorageFunctionModifierV0
-	Type          StorageFunctionTypeV10
-	Fallback      Bytes
-	Documentation []Text
+	Name          Text                      `json:"name"`
+	Modifier      StorageFunctionModifierV0 `json:"modifier"`
+	Type          StorageFunctionTypeV10    `json:"type"`
+	Fallback      Bytes                     `json:"fallback"`
+	Documentation []Text                    `json:"documentation"`
 }
 
 func (s StorageFunctionMetadataV10) IsPlain() bool {
@@ -276,6 +381,22 @@ func (s StorageFunctionMetadataV10) Hasher2() (hash.Hash, error) {
 	return s.Type.AsDoubleMap.Key2Hasher.HashFunc()
 }
 
+func (s StorageFunctionMetadataV10) IsOptional() bool {
+	return s.Modifier.IsOptional
+}
+
+func (s StorageFunctionMetadataV10) DefaultValue() Bytes {
+	return s.Fallback
+}
+
+func (s StorageFunctionMetadataV10) MarshalJSON() ([]byte, error) {
+	type plain StorageFunctionMetadataV10
+	if s.Documentation == nil {
+		s.Documentation = []Text{}
+	}
+	return json.Marshal(plain(s))
+}
+
 type StorageFunctionTypeV10 struct {
 	IsType      bool
 	AsType      Type // 0
@@ -352,19 +473,56 @@ func (s StorageFunctionTypeV10) Encode(encoder scale.Encoder) error {
 	return nil
 }
 
+// UnmarshalJSON fills s with the JSON encoded storage function type given by b
+func (s *StorageFunctionTypeV10) UnmarshalJSON(b []byte) error {
+	variant, value, err := unmarshalEnumJSON(b)
+	if err != nil {
+		return err
+	}
+
+	*s = StorageFunctionTypeV10{}
+	switch variant {
+	case "plain":
+		s.IsType = true
+		return json.Unmarshal(value, &s.AsType)
+	case "map":
+		s.IsMap = true
+		return json.Unmarshal(value, &s.AsMap)
+	case "doubleMap":
+		s.IsDoubleMap = true
+		return json.Unmarshal(value, &s.AsDoubleMap)
+	default:
+		return fmt.Errorf("received unexpected storage function type %v", variant)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of s
+func (s StorageFunctionTypeV10) MarshalJSON() ([]byte, error) {
+	switch {
+	case s.IsType:
+		return marshalEnumJSON("plain", s.AsType)
+	case s.IsMap:
+		return marshalEnumJSON("map", s.AsMap)
+	case s.IsDoubleMap:
+		return marshalEnumJSON("doubleMap", s.AsDoubleMap)
+	default:
+		return nil, fmt.Errorf("expected storage function type, but none was set: %v", s)
+	}
+}
+
 type MapTypeV10 struct {
-	Hasher StorageHasherV10
-	Key    Type
-	Value  Type
-	Linked bool
+	Hasher StorageHasherV10 `json:"hasher"`
+	Key    Type             `json:"key"`
+	Value  Type             `json:"value"`
+	Linked bool             `json:"linked"`
 }
 
 type DoubleMapTypeV10 struct {
-	Hasher     StorageHasherV10
-	Key1       Type
-	Key2       Type
-	Value      Type
-	Key2Hasher StorageHasherV10
+	Hasher     StorageHasherV10 `json:"hasher"`
+	Key1       Type             `json:"key1"`
+	Key2       Type             `json:"key2"`
+	Value      Type             `json:"value"`
+	Key2Hasher StorageHasherV10 `json:"key2Hasher"`
 }
 
 type StorageHasherV10 struct {
@@ -426,7 +584,7 @@ func (s StorageHasherV10) Encode(encoder scale.Encoder) error {
 func (s StorageHasherV10) HashFunc() (hash.Hash, error) {
 	// Blake2_128
 	if s.IsBlake2_128 {
-		return blake2b.New(128, nil)
+		return blake2b.New(16, nil)
 	}
 
 	// Blake2_256
@@ -434,9 +592,9 @@ func (s StorageHasherV10) HashFunc() (hash.Hash, error) {
 		return blake2b.New256(nil)
 	}
 
-	// Blake2_256
-	if s.IsBlake2_128Concat { // TODO add support
-		return nil, errors.New("hash function type blake2_128concat not yet supported")
+	// Blake2_128Concat
+	if s.IsBlake2_128Concat {
+		return blake2.New128Concat(nil)
 	}
 
 	// Twox128
@@ -456,3 +614,70 @@ func (s StorageHasherV10) HashFunc() (hash.Hash, error) {
 
 	return nil, errors.New("hash function type not yet supported")
 }
+
+// KeyHasher returns the version independent description of s
+func (s StorageHasherV10) KeyHasher() (StorageKeyHasher, error) {
+	switch {
+	case s.IsBlake2_128:
+		return keyHasherBlake2_128, nil
+	case s.IsBlake2_256:
+		return keyHasherBlake2_256, nil
+	case s.IsBlake2_128Concat:
+		return keyHasherBlake2_128Concat, nil
+	case s.IsTwox128:
+		return keyHasherTwox128, nil
+	case s.IsTwox256:
+		return keyHasherTwox256, nil
+	case s.IsTwox64Concat:
+		return keyHasherTwox64Concat, nil
+	default:
+		return StorageKeyHasher{}, fmt.Errorf("expected storage hasher, but none was set: %v", s)
+	}
+}
+
+// UnmarshalJSON fills s with the JSON encoded name of the storage hasher given by b
+func (s *StorageHasherV10) UnmarshalJSON(b []byte) error {
+	name, err := unmarshalNameJSON(b)
+	if err != nil {
+		return err
+	}
+
+	*s = StorageHasherV10{}
+	switch name {
+	case "Blake2_128":
+		s.IsBlake2_128 = true
+	case "Blake2_256":
+		s.IsBlake2_256 = true
+	case "Blake2_128Concat":
+		s.IsBlake2_128Concat = true
+	case "Twox128":
+		s.IsTwox128 = true
+	case "Twox256":
+		s.IsTwox256 = true
+	case "Twox64Concat":
+		s.IsTwox64Concat = true
+	default:
+		return fmt.Errorf("received unexpected storage hasher %v", name)
+	}
+	return nil
+}
+
+// MarshalJSON returns the JSON encoded name of s
+func (s StorageHasherV10) MarshalJSON() ([]byte, error) {
+	switch {
+	case s.IsBlake2_128:
+		return json.Marshal("Blake2_128")
+	case s.IsBlake2_256:
+		return json.Marshal("Blake2_256")
+	case s.IsBlake2_128Concat:
+		return json.Marshal("Blake2_128Concat")
+	case s.IsTwox128:
+		return json.Marshal("Twox128")
+	case s.IsTwox256:
+		return json.Marshal("Twox256")
+	case s.IsTwox64Concat:
+		return json.Marshal("Twox64Concat")
+	default:
+		return nil, fmt.Errorf("expected storage hasher, but none was set: %v", s)
+	}
+}
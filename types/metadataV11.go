@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"hash"
@@ -14,8 +15,8 @@ import (
 
 // Modelled after packages/types/src/Metadata/v10/toV11.ts
 type MetadataV11 struct {
-	Modules   []ModuleMetadataV11
-	Extrinsic ExtrinsicV11
+	Modules   []ModuleMetadataV11 `json:"modules"`
+	Extrinsic ExtrinsicV11        `json:"extrinsic"`
 }
 
 func (m *MetadataV11) Decode(decoder scale.Decoder) error {
@@ -55,7 +56,31 @@ func (m *MetadataV11) FindCallIndex(call string) (CallIndex, error) {
 	return CallIndex{}, fmt.Errorf("module %v not found in metadata for call %v", s[0], call)
 }
 
+func (m *MetadataV11) FindCallMetadata(index CallIndex) (Text, FunctionMetadataV4, error) {
+	mi := uint8(0)
+	for _, mod := range m.Modules {
+		if !mod.HasCalls {
+			continue
+		}
+		if mi != index.SectionIndex {
+			mi++
+			continue
+		}
+		if int(index.MethodIndex) >= len(mod.Calls) {
+			return "", FunctionMetadataV4{}, fmt.Errorf("call index %v for module %v out of range", index.MethodIndex,
+				mod.Name)
+		}
+		return mod.Name, mod.Calls[index.MethodIndex], nil
+	}
+	return "", FunctionMetadataV4{}, fmt.Errorf("module index %v out of range", index.SectionIndex)
+}
+
 func (m *MetadataV11) FindEventNamesForEventID(eventID EventID) (Text, Text, error) {
+	mod, event, err := m.FindEventMetadata(eventID)
+	return mod, event.Name, err
+}
+
+func (m *MetadataV11) FindEventMetadata(eventID EventID) (Text, EventMetadataV4, error) {
 	mi := uint8(0)
 	for _, mod := range m.Modules {
 		if !mod.HasEvents {
@@ -66,11 +91,11 @@ func (m *MetadataV11) FindEventNamesForEventID(eventID EventID) (Text, Text, err
 			continue
 		}
 		if int(eventID[1]) >= len(mod.Events) {
-			return "", "", fmt.Errorf("event index %v for module %v out of range", eventID[1], mod.Name)
+			return "", EventMetadataV4{}, fmt.Errorf("event index %v for module %v out of range", eventID[1], mod.Name)
 		}
-		return mod.Name, mod.Events[eventID[1]].Name, nil
+		return mod.Name, mod.Events[eventID[1]], nil
 	}
-	return "", "", fmt.Errorf("module index %v out of range", eventID[0])
+	return "", EventMetadataV4{}, fmt.Errorf("module index %v out of range", eventID[0])
 }
 
 func (m *MetadataV11) FindStorageEntryMetadata(module string, fn string) (StorageEntryMetadata, error) {
@@ -108,6 +133,24 @@ func (m *MetadataV11) FindConstantMetadata(module string, constant string) (Modu
 	return ModuleConstantMetadataV6{}, fmt.Errorf("module %v not found in metadata", module)
 }
 
+// FindConstants returns all constants of the given module
+func (m *MetadataV11) FindConstants(module string) ([]ModuleConstantMetadataV6, error) {
+	for _, mod := range m.Modules {
+		if strings.EqualFold(string(mod.Name), module) {
+			return mod.Constants, nil
+		}
+	}
+	return nil, fmt.Errorf("module %v not found in metadata", module)
+}
+
+func (m MetadataV11) MarshalJSON() ([]byte, error) {
+	type plain MetadataV11
+	if m.Modules == nil {
+		m.Modules = []ModuleMetadataV11{}
+	}
+	return json.Marshal(plain(m))
+}
+
 type ModuleMetadataV11 struct {
 	Name       Text
 	HasStorage bool
@@ -220,17 +263,78 @@ func (m ModuleMetadataV11) Encode(encoder scale.Encoder) error {
 	return encoder.Encode(m.Errors)
 }
 
+// UnmarshalJSON fills m with the JSON encoded module metadata given by b
+func (m *ModuleMetadataV11) UnmarshalJSON(b []byte) error {
+	var tmp struct {
+		Name      Text                       `json:"name"`
+		Storage   json.RawMessage            `json:"storage"`
+		Calls     json.RawMessage            `json:"calls"`
+		Events    json.RawMessage            `json:"events"`
+		Constants []ModuleConstantMetadataV6 `json:"constants"`
+		Errors    []ErrorMetadataV8          `json:"errors"`
+	}
+	err := json.Unmarshal(b, &tmp)
+	if err != nil {
+		return err
+	}
+
+	*m = ModuleMetadataV11{Name: tmp.Name, Constants: tmp.Constants, Errors: tmp.Errors}
+	m.HasStorage, err = unmarshalOptionJSON(tmp.Storage, &m.Storage)
+	if err != nil {
+		return err
+	}
+	m.HasCalls, err = unmarshalOptionJSON(tmp.Calls, &m.Calls)
+	if err != nil {
+		return err
+	}
+	m.HasEvents, err = unmarshalOptionJSON(tmp.Events, &m.Events)
+	return err
+}
+
+// MarshalJSON returns the JSON encoding of m, with null for missing options and [] for empty lists
+func (m ModuleMetadataV11) MarshalJSON() ([]byte, error) {
+	if m.Constants == nil {
+		m.Constants = []ModuleConstantMetadataV6{}
+	}
+	if m.Errors == nil {
+		m.Errors = []ErrorMetadataV8{}
+	}
+	return json.Marshal(struct {
+		Name      Text                       `json:"name"`
+		Storage   interface{}                `json:"storage"`
+		Calls     interface{}                `json:"calls"`
+		Events    interface{}                `json:"events"`
+		Constants []ModuleConstantMetadataV6 `json:"constants"`
+		Errors    []ErrorMetadataV8          `json:"errors"`
+	}{
+		Name:      m.Name,
+		Storage:   optionJSON(m.HasStorage, m.Storage),
+		Calls:     optionJSON(m.HasCalls, m.Calls),
+		Events:    optionJSON(m.HasEvents, m.Events),
+		Constants: m.Constants,
+		Errors:    m.Errors,
+	})
+}
+
 type StorageMetadataV11 struct {
-	Prefix Text
-	Items  []StorageFunctionMetadataV11
+	Prefix Text                         `json:"prefix"`
+	Items  []StorageFunctionMetadataV11 `json:"items"`
+}
+
+func (s StorageMetadataV11) MarshalJSON() ([]byte, error) {
+	type plain StorageMetadataV11
+	if s.Items == nil {
+		s.Items = []StorageFunctionMetadataV11{}
+	}
+	return json.Marshal(plain(s))
 }
 
 type StorageFunctionMetadataV11 struct {
-	Name          Text
-	Modifier      StorageFunctionModifierV0
-	Type          StorageFunctionTypeV11
-	Fallback      Bytes
-	Documentation []Text
+	Name          Text                      `json:"name"`
+	Modifier      StorageFunctionModifierV0 `json:"modifier"`
+	Type          StorageFunctionTypeV11    `json:"type"`
+	Fallback      Bytes                     `json:"fallback"`
+	Documentation []Text                    `json:"documentation"`
 }
 
 func (s StorageFunctionMetadataV11) IsPlain() bool {
@@ -262,6 +366,22 @@ func (s StorageFunctionMetadataV11) Hasher2() (hash.Hash, error) {
 	return s.Type.AsDoubleMap.Key2Hasher.HashFunc()
 }
 
+func (s StorageFunctionMetadataV11) IsOptional() bool {
+	return s.Modifier.IsOptional
+}
+
+func (s StorageFunctionMetadataV11) DefaultValue() Bytes {
+	return s.Fallback
+}
+
+func (s StorageFunctionMetadataV11) MarshalJSON() ([]byte, error) {
+	type plain StorageFunctionMetadataV11
+	if s.Documentation == nil {
+		s.Documentation = []Text{}
+	}
+	return json.Marshal(plain(s))
+}
+
 type StorageFunctionTypeV11 struct {
 	IsType      bool
 	AsType      Type // 0
@@ -338,25 +458,62 @@ func (s StorageFunctionTypeV11) Encode(encoder scale.Encoder) error {
 	return nil
 }
 
+// UnmarshalJSON fills s with the JSON encoded storage function type given by b
+func (s *StorageFunctionTypeV11) UnmarshalJSON(b []byte) error {
+	variant, value, err := unmarshalEnumJSON(b)
+	if err != nil {
+		return err
+	}
+
+	*s = StorageFunctionTypeV11{}
+	switch variant {
+	case "plain":
+		s.IsType = true
+		return json.Unmarshal(value, &s.AsType)
+	case "map":
+		s.IsMap = true
+		return json.Unmarshal(value, &s.AsMap)
+	case "doubleMap":
+		s.IsDoubleMap = true
+		return json.Unmarshal(value, &s.AsDoubleMap)
+	default:
+		return fmt.Errorf("received unexpected storage function type %v", variant)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of s
+func (s StorageFunctionTypeV11) MarshalJSON() ([]byte, error) {
+	switch {
+	case s.IsType:
+		return marshalEnumJSON("plain", s.AsType)
+	case s.IsMap:
+		return marshalEnumJSON("map", s.AsMap)
+	case s.IsDoubleMap:
+		return marshalEnumJSON("doubleMap", s.AsDoubleMap)
+	default:
+		return nil, fmt.Errorf("expected storage function type, but none was set: %v", s)
+	}
+}
+
 type MapTypeV11 struct {
-	Hasher StorageHasherV11
-	Key    Type
-	Value  Type
-	Linked bool
+	Hasher StorageHasherV11 `json:"hasher"`
+	Key    Type             `json:"key"`
+	Value  Type             `json:"value"`
+	Linked bool             `json:"linked"`
 }
 
 type DoubleMapTypeV11 struct {
-	Hasher     StorageHasherV11
-	Key1       Type
-	Key2       Type
-	Value      Type
-	Key2Hasher StorageHasherV11
+	Hasher     StorageHasherV11 `json:"hasher"`
+	Key1       Type             `json:"key1"`
+	Key2       Type             `json:"key2"`
+	Value      Type             `json:"value"`
+	Key2Hasher StorageHasherV11 `json:"key2Hasher"`
 }
 
 // Modelled after packages/types/src/Metadata/v10/toV11.ts
 type ExtrinsicV11 struct {
-	Version          uint8
-	SignedExtensions []string
+	Version          uint8    `json:"version"`
+	SignedExtensions []string `json:"signedExtensions"`
 }
 
 func (e *ExtrinsicV11) Decode(decoder scale.Decoder) error {
@@ -377,6 +534,14 @@ func (e ExtrinsicV11) Encode(encoder scale.Encoder) error {
 	return encoder.Encode(e.SignedExtensions)
 }
 
+func (e ExtrinsicV11) MarshalJSON() ([]byte, error) {
+	type plain ExtrinsicV11
+	if e.SignedExtensions == nil {
+		e.SignedExtensions = []string{}
+	}
+	return json.Marshal(plain(e))
+}
+
 type StorageHasherV11 struct {
 	IsBlake2_128       bool // 0
 	IsBlake2_256       bool // 1
@@ -471,8 +636,81 @@ func (s StorageHasherV11) HashFunc() (hash.Hash, error) {
 
 	// Identity
 	if s.IsIdentity {
-		return blake2.New128Concat(nil)
+		return newIdentity(), nil
 	}
 
 	return nil, errors.New("hash function type not yet supported")
 }
+
+// KeyHasher returns the version independent description of s
+func (s StorageHasherV11) KeyHasher() (StorageKeyHasher, error) {
+	switch {
+	case s.IsBlake2_128:
+		return keyHasherBlake2_128, nil
+	case s.IsBlake2_256:
+		return keyHasherBlake2_256, nil
+	case s.IsBlake2_128Concat:
+		return keyHasherBlake2_128Concat, nil
+	case s.IsTwox128:
+		return keyHasherTwox128, nil
+	case s.IsTwox256:
+		return keyHasherTwox256, nil
+	case s.IsTwox64Concat:
+		return keyHasherTwox64Concat, nil
+	case s.IsIdentity:
+		return keyHasherIdentity, nil
+	default:
+		return StorageKeyHasher{}, fmt.Errorf("expected storage hasher, but none was set: %v", s)
+	}
+}
+
+// UnmarshalJSON fills s with the JSON encoded name of the storage hasher given by b
+func (s *StorageHasherV11) UnmarshalJSON(b []byte) error {
+	name, err := unmarshalNameJSON(b)
+	if err != nil {
+		return err
+	}
+
+	*s = StorageHasherV11{}
+	switch name {
+	case "Blake2_128":
+		s.IsBlake2_128 = true
+	case "Blake2_256":
+		s.IsBlake2_256 = true
+	case "Blake2_128Concat":
+		s.IsBlake2_128Concat = true
+	case "Twox128":
+		s.IsTwox128 = true
+	case "Twox256":
+		s.IsTwox256 = true
+	case "Twox64Concat":
+		s.IsTwox64Concat = true
+	case "Identity":
+		s.IsIdentity = true
+	default:
+		return fmt.Errorf("received unexpected storage hasher %v", name)
+	}
+	return nil
+}
+
+// MarshalJSON returns the JSON encoded name of s
+func (s StorageHasherV11) MarshalJSON() ([]byte, error) {
+	switch {
+	case s.IsBlake2_128:
+		return json.Marshal("Blake2_128")
+	case s.IsBlake2_256:
+		return json.Marshal("Blake2_256")
+	case s.IsBlake2_128Concat:
+		return json.Marshal("Blake2_128Concat")
+	case s.IsTwox128:
+		return json.Marshal("Twox128")
+	case s.IsTwox256:
+		return json.Marshal("Twox256")
+	case s.IsTwox64Concat:
+		return json.Marshal("Twox64Concat")
+	case s.IsIdentity:
+		return json.Marshal("Identity")
+	default:
+		return nil, fmt.Errorf("expected storage hasher, but none was set: %v", s)
+	}
+}
@@ -0,0 +1,564 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/zenghq3/go-substrate-rpc-client/scale"
+	"github.com/zenghq3/go-substrate-rpc-client/xxhash"
+)
+
+// Modelled after packages/types/src/interfaces/metadata/v14.ts
+type MetadataV14 struct {
+	Lookup    PortableRegistry    `json:"lookup"`
+	Pallets   []PalletMetadataV14 `json:"pallets"`
+	Extrinsic ExtrinsicV14        `json:"extrinsic"`
+	Type      Si1LookupTypeID     `json:"type"`
+}
+
+func (m *MetadataV14) FindCallIndex(call string) (CallIndex, error) {
+	s := strings.Split(call, ".")
+	for _, mod := range m.Pallets {
+		if !mod.HasCalls {
+			continue
+		}
+		if string(mod.Name) != s[0] {
+			continue
+		}
+		variants, err := m.findVariants(mod.Calls.Type)
+		if err != nil {
+			return CallIndex{}, err
+		}
+		v, err := variants.FindVariantByName(s[1])
+		if err != nil {
+			return CallIndex{}, fmt.Errorf("method %v not found within module %v for call %v", s[1], mod.Name, call)
+		}
+		return CallIndex{mod.Index, v.Index}, nil
+	}
+	return CallIndex{}, fmt.Errorf("module %v not found in metadata for call %v", s[0], call)
+}
+
+// FindCallVariant returns the pallet name together with the variant of the pallet call type describing the call, the
+// fields of the variant are the call arguments
+func (m *MetadataV14) FindCallVariant(index CallIndex) (Text, Si1Variant, error) {
+	for _, mod := range m.Pallets {
+		if !mod.HasCalls {
+			continue
+		}
+		if mod.Index != index.SectionIndex {
+			continue
+		}
+		variants, err := m.findVariants(mod.Calls.Type)
+		if err != nil {
+			return "", Si1Variant{}, err
+		}
+		v, err := variants.FindVariantByIndex(index.MethodIndex)
+		if err != nil {
+			return "", Si1Variant{}, fmt.Errorf("call index %v for module %v out of range", index.MethodIndex, mod.Name)
+		}
+		return mod.Name, v, nil
+	}
+	return "", Si1Variant{}, fmt.Errorf("module index %v out of range", index.SectionIndex)
+}
+
+func (m *MetadataV14) FindEventNamesForEventID(eventID EventID) (Text, Text, error) {
+	mod, variant, err := m.FindEventVariant(eventID)
+	return mod, variant.Name, err
+}
+
+// FindEventVariant returns the pallet name together with the variant of the pallet event type describing the event,
+// the fields of the variant are the event arguments
+func (m *MetadataV14) FindEventVariant(eventID EventID) (Text, Si1Variant, error) {
+	for _, mod := range m.Pallets {
+		if !mod.HasEvents {
+			continue
+		}
+		if mod.Index != eventID[0] {
+			continue
+		}
+		variants, err := m.findVariants(mod.Events.Type)
+		if err != nil {
+			return "", Si1Variant{}, err
+		}
+		v, err := variants.FindVariantByIndex(eventID[1])
+		if err != nil {
+			return "", Si1Variant{}, fmt.Errorf("event index %v for module %v out of range", eventID[1], mod.Name)
+		}
+		return mod.Name, v, nil
+	}
+	return "", Si1Variant{}, fmt.Errorf("module index %v out of range", eventID[0])
+}
+
+func (m *MetadataV14) FindStorageEntryMetadata(module string, fn string) (StorageEntryMetadata, error) {
+	for _, mod := range m.Pallets {
+		if !mod.HasStorage {
+			continue
+		}
+		if string(mod.Storage.Prefix) != module {
+			continue
+		}
+		for _, s := range mod.Storage.Items {
+			if string(s.Name) != fn {
+				continue
+			}
+			return s, nil
+		}
+		return nil, fmt.Errorf("storage %v not found within module %v", fn, module)
+	}
+	return nil, fmt.Errorf("module %v not found in metadata", module)
+}
+
+// FindConstantMetadata returns the constant converted to the format of earlier metadata versions, with the type
+// name derived from the portable registry
+func (m *MetadataV14) FindConstantMetadata(module string, constant string) (ModuleConstantMetadataV6, error) {
+	for _, mod := range m.Pallets {
+		if !strings.EqualFold(string(mod.Name), module) {
+			continue
+		}
+		for _, s := range mod.Constants {
+			if !strings.EqualFold(string(s.Name), constant) {
+				continue
+			}
+			return m.convertConstant(s)
+		}
+		return ModuleConstantMetadataV6{}, fmt.Errorf("constant %v not found within module %v", constant, module)
+	}
+	return ModuleConstantMetadataV6{}, fmt.Errorf("module %v not found in metadata", module)
+}
+
+// FindConstants returns all constants of the given module, converted to the format of earlier metadata versions
+func (m *MetadataV14) FindConstants(module string) ([]ModuleConstantMetadataV6, error) {
+	for _, mod := range m.Pallets {
+		if !strings.EqualFold(string(mod.Name), module) {
+			continue
+		}
+		constants := make([]ModuleConstantMetadataV6, len(mod.Constants))
+		for i, c := range mod.Constants {
+			var err error
+			constants[i], err = m.convertConstant(c)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return constants, nil
+	}
+	return nil, fmt.Errorf("module %v not found in metadata", module)
+}
+
+// convertConstant converts a constant to the format of earlier metadata versions, with the type name derived from
+// the portable registry
+func (m *MetadataV14) convertConstant(c ConstantMetadataV14) (ModuleConstantMetadataV6, error) {
+	typeName, err := m.Lookup.TypeName(c.Type)
+	if err != nil {
+		return ModuleConstantMetadataV6{}, err
+	}
+	return ModuleConstantMetadataV6{
+		Name:          c.Name,
+		Type:          Type(typeName),
+		Value:         c.Value,
+		Documentation: c.Documentation,
+	}, nil
+}
+
+// findVariants returns the variant definition of an enum type, such as the call, event or error type of a pallet
+func (m *MetadataV14) findVariants(id Si1LookupTypeID) (Si1TypeDefVariant, error) {
+	t, err := m.Lookup.FindType(id)
+	if err != nil {
+		return Si1TypeDefVariant{}, err
+	}
+	if !t.Def.IsVariant {
+		return Si1TypeDefVariant{}, fmt.Errorf("expected type %v to be a variant type", id.Int64())
+	}
+	return t.Def.AsVariant, nil
+}
+
+func (m MetadataV14) MarshalJSON() ([]byte, error) {
+	type plain MetadataV14
+	if m.Pallets == nil {
+		m.Pallets = []PalletMetadataV14{}
+	}
+	return json.Marshal(plain(m))
+}
+
+type PalletMetadataV14 struct {
+	Name       Text
+	HasStorage bool
+	Storage    StorageMetadataV14
+	HasCalls   bool
+	Calls      FunctionMetadataV14
+	HasEvents  bool
+	Events     EventMetadataV14
+	Constants  []ConstantMetadataV14
+	HasErrors  bool
+	Errors     ErrorMetadataV14
+	Index      uint8
+}
+
+func (m *PalletMetadataV14) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&m.Name)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.DecodeOption(&m.HasStorage, &m.Storage)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.DecodeOption(&m.HasCalls, &m.Calls)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.DecodeOption(&m.HasEvents, &m.Events)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Constants)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.DecodeOption(&m.HasErrors, &m.Errors)
+	if err != nil {
+		return err
+	}
+
+	return decoder.Decode(&m.Index)
+}
+
+func (m PalletMetadataV14) Encode(encoder scale.Encoder) error {
+	err := encoder.Encode(m.Name)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.EncodeOption(m.HasStorage, m.Storage)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.EncodeOption(m.HasCalls, m.Calls)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.EncodeOption(m.HasEvents, m.Events)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.Encode(m.Constants)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.EncodeOption(m.HasErrors, m.Errors)
+	if err != nil {
+		return err
+	}
+
+	return encoder.Encode(m.Index)
+}
+
+// UnmarshalJSON fills m with the JSON encoded pallet metadata given by b
+func (m *PalletMetadataV14) UnmarshalJSON(b []byte) error {
+	var tmp struct {
+		Name      Text                  `json:"name"`
+		Storage   json.RawMessage       `json:"storage"`
+		Calls     json.RawMessage       `json:"calls"`
+		Events    json.RawMessage       `json:"events"`
+		Constants []ConstantMetadataV14 `json:"constants"`
+		Errors    json.RawMessage       `json:"errors"`
+		Index     uint8                 `json:"index"`
+	}
+	err := json.Unmarshal(b, &tmp)
+	if err != nil {
+		return err
+	}
+
+	*m = PalletMetadataV14{Name: tmp.Name, Constants: tmp.Constants, Index: tmp.Index}
+	m.HasStorage, err = unmarshalOptionJSON(tmp.Storage, &m.Storage)
+	if err != nil {
+		return err
+	}
+	m.HasCalls, err = unmarshalOptionJSON(tmp.Calls, &m.Calls)
+	if err != nil {
+		return err
+	}
+	m.HasEvents, err = unmarshalOptionJSON(tmp.Events, &m.Events)
+	if err != nil {
+		return err
+	}
+	m.HasErrors, err = unmarshalOptionJSON(tmp.Errors, &m.Errors)
+	return err
+}
+
+// MarshalJSON returns the JSON encoding of m, with null for missing options and [] for empty lists
+func (m PalletMetadataV14) MarshalJSON() ([]byte, error) {
+	if m.Constants == nil {
+		m.Constants = []ConstantMetadataV14{}
+	}
+	return json.Marshal(struct {
+		Name      Text                  `json:"name"`
+		Storage   interface{}           `json:"storage"`
+		Calls     interface{}           `json:"calls"`
+		Events    interface{}           `json:"events"`
+		Constants []ConstantMetadataV14 `json:"constants"`
+		Errors    interface{}           `json:"errors"`
+		Index     uint8                 `json:"index"`
+	}{
+		Name:      m.Name,
+		Storage:   optionJSON(m.HasStorage, m.Storage),
+		Calls:     optionJSON(m.HasCalls, m.Calls),
+		Events:    optionJSON(m.HasEvents, m.Events),
+		Constants: m.Constants,
+		Errors:    optionJSON(m.HasErrors, m.Errors),
+		Index:     m.Index,
+	})
+}
+
+type StorageMetadataV14 struct {
+	Prefix Text                      `json:"prefix"`
+	Items  []StorageEntryMetadataV14 `json:"items"`
+}
+
+func (s StorageMetadataV14) MarshalJSON() ([]byte, error) {
+	type plain StorageMetadataV14
+	if s.Items == nil {
+		s.Items = []StorageEntryMetadataV14{}
+	}
+	return json.Marshal(plain(s))
+}
+
+type StorageEntryMetadataV14 struct {
+	Name          Text                      `json:"name"`
+	Modifier      StorageFunctionModifierV0 `json:"modifier"`
+	Type          StorageEntryTypeV14       `json:"type"`
+	Fallback      Bytes                     `json:"fallback"`
+	Documentation []Text                    `json:"docs"`
+}
+
+func (s StorageEntryMetadataV14) IsPlain() bool {
+	return s.Type.IsPlainType
+}
+
+// IsMap returns true for maps with a single key. Maps with more than one key are represented as a tuple of keys
+// together with one hasher per key.
+func (s StorageEntryMetadataV14) IsMap() bool {
+	return s.Type.IsMap && len(s.Type.AsMap.Hashers) == 1
+}
+
+func (s StorageEntryMetadataV14) IsDoubleMap() bool {
+	return s.Type.IsMap && len(s.Type.AsMap.Hashers) == 2
+}
+
+func (s StorageEntryMetadataV14) Hasher() (hash.Hash, error) {
+	if s.Type.IsMap {
+		if len(s.Type.AsMap.Hashers) == 0 {
+			return nil, fmt.Errorf("map %v has no hashers", s.Name)
+		}
+		return s.Type.AsMap.Hashers[0].HashFunc()
+	}
+	return xxhash.New128(nil), nil
+}
+
+func (s StorageEntryMetadataV14) Hasher2() (hash.Hash, error) {
+	if !s.IsDoubleMap() {
+		return nil, fmt.Errorf("only DoubleMaps have a Hasher2")
+	}
+	return s.Type.AsMap.Hashers[1].HashFunc()
+}
+
+func (s StorageEntryMetadataV14) IsOptional() bool {
+	return s.Modifier.IsOptional
+}
+
+func (s StorageEntryMetadataV14) DefaultValue() Bytes {
+	return s.Fallback
+}
+
+func (s StorageEntryMetadataV14) MarshalJSON() ([]byte, error) {
+	type plain StorageEntryMetadataV14
+	if s.Documentation == nil {
+		s.Documentation = []Text{}
+	}
+	return json.Marshal(plain(s))
+}
+
+type StorageEntryTypeV14 struct {
+	IsPlainType bool
+	AsPlainType Si1LookupTypeID // 0
+	IsMap       bool
+	AsMap       MapTypeV14 // 1
+}
+
+func (s *StorageEntryTypeV14) Decode(decoder scale.Decoder) error {
+	var t uint8
+	err := decoder.Decode(&t)
+	if err != nil {
+		return err
+	}
+
+	switch t {
+	case 0:
+		s.IsPlainType = true
+		err = decoder.Decode(&s.AsPlainType)
+		if err != nil {
+			return err
+		}
+	case 1:
+		s.IsMap = true
+		err = decoder.Decode(&s.AsMap)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("received unexpected type %v", t)
+	}
+	return nil
+}
+
+func (s StorageEntryTypeV14) Encode(encoder scale.Encoder) error {
+	switch {
+	case s.IsPlainType:
+		err := encoder.PushByte(0)
+		if err != nil {
+			return err
+		}
+		err = encoder.Encode(s.AsPlainType)
+		if err != nil {
+			return err
+		}
+	case s.IsMap:
+		err := encoder.PushByte(1)
+		if err != nil {
+			return err
+		}
+		err = encoder.Encode(s.AsMap)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("expected to be either plain type or map, but none was set: %v", s)
+	}
+	return nil
+}
+
+// UnmarshalJSON fills s with the JSON encoded storage entry type given by b
+func (s *StorageEntryTypeV14) UnmarshalJSON(b []byte) error {
+	variant, value, err := unmarshalEnumJSON(b)
+	if err != nil {
+		return err
+	}
+
+	*s = StorageEntryTypeV14{}
+	switch variant {
+	case "plain":
+		s.IsPlainType = true
+		return json.Unmarshal(value, &s.AsPlainType)
+	case "map":
+		s.IsMap = true
+		return json.Unmarshal(value, &s.AsMap)
+	default:
+		return fmt.Errorf("received unexpected storage entry type %v", variant)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of s
+func (s StorageEntryTypeV14) MarshalJSON() ([]byte, error) {
+	switch {
+	case s.IsPlainType:
+		return marshalEnumJSON("plain", s.AsPlainType)
+	case s.IsMap:
+		return marshalEnumJSON("map", s.AsMap)
+	default:
+		return nil, fmt.Errorf("expected storage entry type, but none was set: %v", s)
+	}
+}
+
+// MapTypeV14 is a storage map with one hasher per key. If there is more than one hasher, Key is a tuple type.
+type MapTypeV14 struct {
+	Hashers []StorageHasherV11 `json:"hashers"`
+	Key     Si1LookupTypeID    `json:"key"`
+	Value   Si1LookupTypeID    `json:"value"`
+}
+
+func (m MapTypeV14) MarshalJSON() ([]byte, error) {
+	type plain MapTypeV14
+	if m.Hashers == nil {
+		m.Hashers = []StorageHasherV11{}
+	}
+	return json.Marshal(plain(m))
+}
+
+// FunctionMetadataV14 refers to the enum type containing all calls of a pallet
+type FunctionMetadataV14 struct {
+	Type Si1LookupTypeID `json:"type"`
+}
+
+// EventMetadataV14 refers to the enum type containing all events of a pallet
+type EventMetadataV14 struct {
+	Type Si1LookupTypeID `json:"type"`
+}
+
+type ConstantMetadataV14 struct {
+	Name          Text            `json:"name"`
+	Type          Si1LookupTypeID `json:"type"`
+	Value         Bytes           `json:"value"`
+	Documentation []Text          `json:"docs"`
+}
+
+func (m ConstantMetadataV14) MarshalJSON() ([]byte, error) {
+	type plain ConstantMetadataV14
+	if m.Documentation == nil {
+		m.Documentation = []Text{}
+	}
+	return json.Marshal(plain(m))
+}
+
+// ErrorMetadataV14 refers to the enum type containing all errors of a pallet
+type ErrorMetadataV14 struct {
+	Type Si1LookupTypeID `json:"type"`
+}
+
+type ExtrinsicV14 struct {
+	Type             Si1LookupTypeID              `json:"type"`
+	Version          uint8                        `json:"version"`
+	SignedExtensions []SignedExtensionMetadataV14 `json:"signedExtensions"`
+}
+
+func (e ExtrinsicV14) MarshalJSON() ([]byte, error) {
+	type plain ExtrinsicV14
+	if e.SignedExtensions == nil {
+		e.SignedExtensions = []SignedExtensionMetadataV14{}
+	}
+	return json.Marshal(plain(e))
+}
+
+// SignedExtensionMetadataV14 describes a signed extension, Type is the type of the extra data included in the
+// extrinsic and AdditionalSigned the type of the data that is only part of the signed payload
+type SignedExtensionMetadataV14 struct {
+	Identifier       Text            `json:"identifier"`
+	Type             Si1LookupTypeID `json:"type"`
+	AdditionalSigned Si1LookupTypeID `json:"additionalSigned"`
+}
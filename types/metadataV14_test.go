@@ -0,0 +1,285 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+var exampleMetadataV14 = Metadata{
+	MagicNumber:   0x6174656d,
+	Version:       14,
+	IsMetadataV14: true,
+	AsMetadataV14: exampleRuntimeMetadataV14,
+}
+
+var exampleRuntimeMetadataV14 = MetadataV14{
+	Lookup: examplePortableRegistry,
+	Pallets: []PalletMetadataV14{examplePalletMetadataV14Empty, examplePalletMetadataV14System,
+		examplePalletMetadataV14Balances},
+	Extrinsic: ExtrinsicV14{
+		Type:    NewSi1LookupTypeID(6),
+		Version: 4,
+		SignedExtensions: []SignedExtensionMetadataV14{
+			{Identifier: "CheckNonce", Type: NewSi1LookupTypeID(3), AdditionalSigned: NewSi1LookupTypeID(9)},
+		},
+	},
+	Type: NewSi1LookupTypeID(9),
+}
+
+var examplePortableRegistry = PortableRegistry{
+	{ID: NewSi1LookupTypeID(0), Type: Si1Type{Def: Si1TypeDef{IsPrimitive: true, AsPrimitive: IsU8}}},
+	{ID: NewSi1LookupTypeID(1), Type: Si1Type{Def: Si1TypeDef{IsArray: true,
+		AsArray: Si1TypeDefArray{Len: 32, Type: NewSi1LookupTypeID(0)}}}},
+	{ID: NewSi1LookupTypeID(2), Type: Si1Type{
+		Path: Si1Path{"sp_core", "crypto", "AccountId32"},
+		Def: Si1TypeDef{IsComposite: true, AsComposite: Si1TypeDefComposite{Fields: []Si1Field{
+			{Type: NewSi1LookupTypeID(1), HasTypeName: true, TypeName: "[u8; 32]"},
+		}}},
+	}},
+	{ID: NewSi1LookupTypeID(3), Type: Si1Type{Def: Si1TypeDef{IsPrimitive: true, AsPrimitive: IsU32}}},
+	{ID: NewSi1LookupTypeID(4), Type: Si1Type{Def: Si1TypeDef{IsPrimitive: true, AsPrimitive: IsU128}}},
+	{ID: NewSi1LookupTypeID(5), Type: Si1Type{Def: Si1TypeDef{IsCompact: true,
+		AsCompact: Si1TypeDefCompact{Type: NewSi1LookupTypeID(4)}}}},
+	{ID: NewSi1LookupTypeID(6), Type: Si1Type{Def: Si1TypeDef{IsSequence: true,
+		AsSequence: Si1TypeDefSequence{Type: NewSi1LookupTypeID(0)}}}},
+	{ID: NewSi1LookupTypeID(7), Type: Si1Type{
+		Path:   Si1Path{"pallet_balances", "pallet", "Call"},
+		Params: []Si1TypeParameter{{Name: "T"}, {Name: "I"}},
+		Def: Si1TypeDef{IsVariant: true, AsVariant: Si1TypeDefVariant{Variants: []Si1Variant{
+			{Name: "transfer", Index: 0, Fields: []Si1Field{
+				{HasName: true, Name: "dest", Type: NewSi1LookupTypeID(2), HasTypeName: true, TypeName: "AccountId"},
+				{HasName: true, Name: "value", Type: NewSi1LookupTypeID(5), HasTypeName: true, TypeName: "Balance"},
+			}, Docs: []Text{"Transfer some liquid free balance to another account."}},
+			{Name: "transfer_keep_alive", Index: 3, Fields: []Si1Field{
+				{HasName: true, Name: "dest", Type: NewSi1LookupTypeID(2), HasTypeName: true, TypeName: "AccountId"},
+				{HasName: true, Name: "value", Type: NewSi1LookupTypeID(5), HasTypeName: true, TypeName: "Balance"},
+			}},
+		}}},
+		Docs: []Text{"Contains one variant per dispatchable that can be called by an extrinsic."},
+	}},
+	{ID: NewSi1LookupTypeID(8), Type: Si1Type{
+		Path:   Si1Path{"pallet_balances", "pallet", "Event"},
+		Params: []Si1TypeParameter{{Name: "T"}, {Name: "I"}},
+		Def: Si1TypeDef{IsVariant: true, AsVariant: Si1TypeDefVariant{Variants: []Si1Variant{
+			{Name: "Endowed", Index: 0, Fields: []Si1Field{
+				{HasName: true, Name: "account", Type: NewSi1LookupTypeID(2), HasTypeName: true, TypeName: "T::AccountId"},
+				{HasName: true, Name: "free_balance", Type: NewSi1LookupTypeID(4), HasTypeName: true, TypeName: "T::Balance"},
+			}},
+			{Name: "Transfer", Index: 2, Fields: []Si1Field{
+				{HasName: true, Name: "from", Type: NewSi1LookupTypeID(2), HasTypeName: true, TypeName: "T::AccountId"},
+				{HasName: true, Name: "to", Type: NewSi1LookupTypeID(2), HasTypeName: true, TypeName: "T::AccountId"},
+				{HasName: true, Name: "amount", Type: NewSi1LookupTypeID(4), HasTypeName: true, TypeName: "T::Balance"},
+			}},
+		}}},
+	}},
+	{ID: NewSi1LookupTypeID(9), Type: Si1Type{Def: Si1TypeDef{IsTuple: true}}},
+	{ID: NewSi1LookupTypeID(10), Type: Si1Type{
+		Path:   Si1Path{"frame_system", "AccountInfo"},
+		Params: []Si1TypeParameter{{Name: "Index", HasType: true, Type: NewSi1LookupTypeID(3)}},
+		Def: Si1TypeDef{IsComposite: true, AsComposite: Si1TypeDefComposite{Fields: []Si1Field{
+			{HasName: true, Name: "nonce", Type: NewSi1LookupTypeID(3), HasTypeName: true, TypeName: "Index"},
+			{HasName: true, Name: "free", Type: NewSi1LookupTypeID(4), HasTypeName: true, TypeName: "Balance"},
+		}}},
+	}},
+	{ID: NewSi1LookupTypeID(11), Type: Si1Type{Def: Si1TypeDef{IsTuple: true,
+		AsTuple: Si1TypeDefTuple{NewSi1LookupTypeID(2), NewSi1LookupTypeID(3)}}}},
+}
+
+var examplePalletMetadataV14Empty = PalletMetadataV14{
+	Name:  "EmptyPallet",
+	Index: 1,
+}
+
+var examplePalletMetadataV14System = PalletMetadataV14{
+	Name:       "System",
+	HasStorage: true,
+	Storage: StorageMetadataV14{
+		Prefix: "System",
+		Items: []StorageEntryMetadataV14{
+			{
+				Name:     "Account",
+				Modifier: StorageFunctionModifierV0{IsDefault: true},
+				Type: StorageEntryTypeV14{IsMap: true, AsMap: MapTypeV14{
+					Hashers: []StorageHasherV11{{IsBlake2_128Concat: true}},
+					Key:     NewSi1LookupTypeID(2),
+					Value:   NewSi1LookupTypeID(10),
+				}},
+				Fallback:      make(Bytes, 20),
+				Documentation: []Text{" The full account information for a particular account ID."},
+			},
+			{
+				Name:     "Number",
+				Modifier: StorageFunctionModifierV0{IsDefault: true},
+				Type:     StorageEntryTypeV14{IsPlainType: true, AsPlainType: NewSi1LookupTypeID(3)},
+				Fallback: Bytes{0, 0, 0, 0},
+			},
+			{
+				Name:     "Approvals",
+				Modifier: StorageFunctionModifierV0{IsOptional: true},
+				Type: StorageEntryTypeV14{IsMap: true, AsMap: MapTypeV14{
+					Hashers: []StorageHasherV11{{IsBlake2_128Concat: true}, {IsTwox64Concat: true}},
+					Key:     NewSi1LookupTypeID(11),
+					Value:   NewSi1LookupTypeID(4),
+				}},
+				Fallback: Bytes{0},
+			},
+		},
+	},
+	Constants: []ConstantMetadataV14{
+		{Name: "BlockHashCount", Type: NewSi1LookupTypeID(3), Value: Bytes{0x60, 0x09, 0, 0}},
+	},
+	Index: 0,
+}
+
+var examplePalletMetadataV14Balances = PalletMetadataV14{
+	Name:      "Balances",
+	HasCalls:  true,
+	Calls:     FunctionMetadataV14{Type: NewSi1LookupTypeID(7)},
+	HasEvents: true,
+	Events:    EventMetadataV14{Type: NewSi1LookupTypeID(8)},
+	Constants: []ConstantMetadataV14{
+		{Name: "ExistentialDeposit", Type: NewSi1LookupTypeID(4), Value: Bytes{0xf4, 0x01, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+			0, 0, 0}, Documentation: []Text{" The minimum amount required to keep an account open."}},
+	},
+	HasErrors: true,
+	Errors:    ErrorMetadataV14{Type: NewSi1LookupTypeID(9)},
+	Index:     5,
+}
+
+func TestMetadataV14_EncodeDecode(t *testing.T) {
+	assertRoundtrip(t, exampleMetadataV14)
+}
+
+func TestMetadataV14_Decode(t *testing.T) {
+	bz, err := EncodeToBytes(exampleMetadataV14)
+	assert.NoError(t, err)
+
+	metadata := NewMetadataV14()
+	err = DecodeFromBytes(bz, metadata)
+	assert.NoError(t, err)
+	assert.Equal(t, exampleMetadataV14, *metadata)
+}
+
+func TestPalletMetadataV14_Encode(t *testing.T) {
+	assertEncode(t, []encodingAssert{
+		{examplePalletMetadataV14Empty, MustHexDecodeString("0x2c456d70747950616c6c6574000000000001")},
+		{PalletMetadataV14{Name: "A", HasEvents: true, Events: EventMetadataV14{NewSi1LookupTypeID(8)}, Index: 2},
+			MustHexDecodeString("0x044100000120000002")},
+	})
+}
+
+func TestFindCallIndexV14(t *testing.T) {
+	callIndex, err := exampleMetadataV14.FindCallIndex("Balances.transfer_keep_alive")
+	assert.NoError(t, err)
+	assert.Equal(t, CallIndex{SectionIndex: 5, MethodIndex: 3}, callIndex)
+
+	_, err = exampleMetadataV14.FindCallIndex("Balances.burn")
+	assert.EqualError(t, err, "method burn not found within module Balances for call Balances.burn")
+
+	_, err = exampleMetadataV14.FindCallIndex("System.remark")
+	assert.EqualError(t, err, "module System not found in metadata for call System.remark")
+}
+
+func TestFindEventNamesForEventIDV14(t *testing.T) {
+	module, event, err := exampleMetadataV14.FindEventNamesForEventID(EventID([2]byte{5, 2}))
+	assert.NoError(t, err)
+	assert.Equal(t, Text("Balances"), module)
+	assert.Equal(t, Text("Transfer"), event)
+
+	_, _, err = exampleMetadataV14.FindEventNamesForEventID(EventID([2]byte{5, 1}))
+	assert.EqualError(t, err, "event index 1 for module Balances out of range")
+
+	_, _, err = exampleMetadataV14.FindEventNamesForEventID(EventID([2]byte{1, 0}))
+	assert.EqualError(t, err, "module index 1 out of range")
+}
+
+func TestFindStorageEntryMetadataV14(t *testing.T) {
+	entry, err := exampleMetadataV14.FindStorageEntryMetadata("System", "Account")
+	assert.NoError(t, err)
+	assert.True(t, entry.IsMap())
+	assert.False(t, entry.IsDoubleMap())
+
+	entry, err = exampleMetadataV14.FindStorageEntryMetadata("System", "Number")
+	assert.NoError(t, err)
+	assert.True(t, entry.IsPlain())
+
+	entry, err = exampleMetadataV14.FindStorageEntryMetadata("System", "Approvals")
+	assert.NoError(t, err)
+	assert.True(t, entry.IsDoubleMap())
+	_, err = entry.Hasher2()
+	assert.NoError(t, err)
+
+	_, err = exampleMetadataV14.FindStorageEntryMetadata("System", "Events")
+	assert.EqualError(t, err, "storage Events not found within module System")
+}
+
+func TestFindConstantMetadataV14(t *testing.T) {
+	constant, err := exampleMetadataV14.FindConstantMetadata("Balances", "ExistentialDeposit")
+	assert.NoError(t, err)
+	assert.Equal(t, Type("u128"), constant.Type)
+	assert.Equal(t, examplePalletMetadataV14Balances.Constants[0].Value, constant.Value)
+
+	var ed U128
+	err = DecodeFromBytes(constant.Value, &ed)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(500), ed.Int64())
+}
+
+func TestFindConstantsV14(t *testing.T) {
+	constants, err := exampleMetadataV14.FindConstants("System")
+	assert.NoError(t, err)
+	assert.Equal(t, []ModuleConstantMetadataV6{
+		{Name: "BlockHashCount", Type: "u32", Value: Bytes{0x60, 0x09, 0, 0}},
+	}, constants)
+
+	_, err = exampleMetadataV14.FindConstants("Unknown")
+	assert.EqualError(t, err, "module Unknown not found in metadata")
+}
+
+func TestMetadata_DecodeConstant(t *testing.T) {
+	var ed U128
+	err := exampleMetadataV14.DecodeConstant("Balances", "ExistentialDeposit", &ed)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(500), ed.Int64())
+
+	var blockHashCount U32
+	err = exampleMetadataV14.DecodeConstant("System", "BlockHashCount", &blockHashCount)
+	assert.NoError(t, err)
+	assert.Equal(t, U32(2400), blockHashCount)
+
+	err = exampleMetadataV14.DecodeConstant("System", "BlockHashCount", &ed)
+	assert.EqualError(t, err, "unable to decode constant System.BlockHashCount of type u32: "+
+		"Cannot read the required number of bytes 16, only 4 available")
+
+	err = exampleMetadataV14.DecodeConstant("Balances", "ExistentialDeposit", &blockHashCount)
+	assert.EqualError(t, err, "unable to decode constant Balances.ExistentialDeposit of type u128: "+
+		"12 bytes left after decoding")
+
+	err = exampleMetadataV14.DecodeConstant("System", "Unknown", &ed)
+	assert.EqualError(t, err, "constant Unknown not found within module System")
+}
+
+func TestCreateStorageKeyV14(t *testing.T) {
+	key, err := CreateStorageKey(&exampleMetadataV14, "System", "Number", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, MustHexDecodeString("0x26aa394eea5630e07c48ae0c9558cef702a5c1b19ab7a04f536c519aca4983ac"), []byte(key))
+}
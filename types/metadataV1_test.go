@@ -0,0 +1,90 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+var exampleMetadataV1 = Metadata{
+	MagicNumber:  0x6174656d,
+	Version:      1,
+	IsMetadataV1: true,
+	AsMetadataV1: MetadataV1{
+		Modules: []ModuleMetadataV1{{
+			Name:       "Balances",
+			Prefix:     "Balances",
+			HasStorage: true,
+			Storage:    []StorageFunctionMetadataV0{exampleStorageFunctionMetadataV0Map},
+			HasCalls:   true,
+			Calls:      []FunctionMetadataV4{exampleFunctionMetadataV4},
+		}},
+	},
+}
+
+var exampleMetadataV2 = Metadata{
+	MagicNumber:  0x6174656d,
+	Version:      2,
+	IsMetadataV2: true,
+	AsMetadataV2: MetadataV2{
+		Modules: []ModuleMetadataV2{{
+			Name:       "Balances",
+			Prefix:     "Balances",
+			HasStorage: true,
+			Storage: []StorageFunctionMetadataV2{{
+				Name:     "FreeBalance",
+				Modifier: StorageFunctionModifierV0{IsDefault: true},
+				Type: StorageFunctionTypeV2{IsMap: true, AsMap: MapTypeV2{
+					Key:    "AccountId",
+					Value:  "Balance",
+					Linked: true,
+				}},
+				Fallback: []byte{0},
+			}},
+			HasEvents: true,
+			Events:    []EventMetadataV4{exampleEventMetadataV4},
+		}},
+	},
+}
+
+func TestMetadataV1_EncodeDecode(t *testing.T) {
+	assertRoundtrip(t, exampleMetadataV1)
+}
+
+func TestMetadataV2_EncodeDecode(t *testing.T) {
+	assertRoundtrip(t, exampleMetadataV2)
+}
+
+func TestFindCallIndexV1(t *testing.T) {
+	callIndex, err := exampleMetadataV1.FindCallIndex("Balances.my function")
+	assert.NoError(t, err)
+	assert.Equal(t, CallIndex{SectionIndex: 0, MethodIndex: 0}, callIndex)
+}
+
+func TestCreateStorageKeyMapV2(t *testing.T) {
+	key, err := CreateStorageKey(&exampleMetadataV2, "Balances", "FreeBalance", []byte{0x01}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, key, 16)
+
+	keyV1, err := CreateStorageKey(&exampleMetadataV1, "Balances", "FreeBalance", []byte{0x01}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, keyV1, key)
+}
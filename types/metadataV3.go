@@ -0,0 +1,483 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/zenghq3/go-substrate-rpc-client/scale"
+	"github.com/zenghq3/go-substrate-rpc-client/xxhash"
+)
+
+// MetadataV3 adds double maps to MetadataV2
+//
+// Modelled after packages/types/src/Metadata/v3/Metadata.ts
+type MetadataV3 struct {
+	Modules []ModuleMetadataV3 `json:"modules"`
+}
+
+func (m *MetadataV3) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&m.Modules)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m MetadataV3) Encode(encoder scale.Encoder) error {
+	err := encoder.Encode(m.Modules)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *MetadataV3) FindCallIndex(call string) (CallIndex, error) {
+	s := strings.Split(call, ".")
+	mi := uint8(0)
+	for _, mod := range m.Modules {
+		if !mod.HasCalls {
+			continue
+		}
+		if string(mod.Name) != s[0] {
+			mi++
+			continue
+		}
+		for ci, f := range mod.Calls {
+			if string(f.Name) == s[1] {
+				return CallIndex{mi, uint8(ci)}, nil
+			}
+		}
+		return CallIndex{}, fmt.Errorf("method %v not found within module %v for call %v", s[1], mod.Name, call)
+	}
+	return CallIndex{}, fmt.Errorf("module %v not found in metadata for call %v", s[0], call)
+}
+
+func (m *MetadataV3) FindCallMetadata(index CallIndex) (Text, FunctionMetadataV4, error) {
+	mi := uint8(0)
+	for _, mod := range m.Modules {
+		if !mod.HasCalls {
+			continue
+		}
+		if mi != index.SectionIndex {
+			mi++
+			continue
+		}
+		if int(index.MethodIndex) >= len(mod.Calls) {
+			return "", FunctionMetadataV4{}, fmt.Errorf("call index %v for module %v out of range", index.MethodIndex,
+				mod.Name)
+		}
+		return mod.Name, mod.Calls[index.MethodIndex], nil
+	}
+	return "", FunctionMetadataV4{}, fmt.Errorf("module index %v out of range", index.SectionIndex)
+}
+
+func (m *MetadataV3) FindEventNamesForEventID(eventID EventID) (Text, Text, error) {
+	mod, event, err := m.FindEventMetadata(eventID)
+	return mod, event.Name, err
+}
+
+func (m *MetadataV3) FindEventMetadata(eventID EventID) (Text, EventMetadataV4, error) {
+	mi := uint8(0)
+	for _, mod := range m.Modules {
+		if !mod.HasEvents {
+			continue
+		}
+		if mi != eventID[0] {
+			mi++
+			continue
+		}
+		if int(eventID[1]) >= len(mod.Events) {
+			return "", EventMetadataV4{}, fmt.Errorf("event index %v for module %v out of range", eventID[1], mod.Name)
+		}
+		return mod.Prefix, mod.Events[eventID[1]], nil
+	}
+	return "", EventMetadataV4{}, fmt.Errorf("module index %v out of range", eventID[0])
+}
+
+func (m *MetadataV3) FindStorageEntryMetadata(module string, fn string) (StorageEntryMetadata, error) {
+	for _, mod := range m.Modules {
+		if !mod.HasStorage {
+			continue
+		}
+		if string(mod.Prefix) != module {
+			continue
+		}
+		for _, s := range mod.Storage {
+			if string(s.Name) != fn {
+				continue
+			}
+			return s, nil
+		}
+		return nil, fmt.Errorf("storage %v not found within module %v", fn, module)
+	}
+	return nil, fmt.Errorf("module %v not found in metadata", module)
+}
+
+func (m MetadataV3) MarshalJSON() ([]byte, error) {
+	type plain MetadataV3
+	if m.Modules == nil {
+		m.Modules = []ModuleMetadataV3{}
+	}
+	return json.Marshal(plain(m))
+}
+
+type ModuleMetadataV3 struct {
+	Name       Text
+	Prefix     Text
+	HasStorage bool
+	Storage    []StorageFunctionMetadataV3
+	HasCalls   bool
+	Calls      []FunctionMetadataV4
+	HasEvents  bool
+	Events     []EventMetadataV4
+}
+
+func (m *ModuleMetadataV3) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&m.Name)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Prefix)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.HasStorage)
+	if err != nil {
+		return err
+	}
+
+	if m.HasStorage {
+		err = decoder.Decode(&m.Storage)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = decoder.Decode(&m.HasCalls)
+	if err != nil {
+		return err
+	}
+
+	if m.HasCalls {
+		err = decoder.Decode(&m.Calls)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = decoder.Decode(&m.HasEvents)
+	if err != nil {
+		return err
+	}
+
+	if m.HasEvents {
+		err = decoder.Decode(&m.Events)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m ModuleMetadataV3) Encode(encoder scale.Encoder) error {
+	err := encoder.Encode(m.Name)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.Encode(m.Prefix)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.Encode(m.HasStorage)
+	if err != nil {
+		return err
+	}
+
+	if m.HasStorage {
+		err = encoder.Encode(m.Storage)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = encoder.Encode(m.HasCalls)
+	if err != nil {
+		return err
+	}
+
+	if m.HasCalls {
+		err = encoder.Encode(m.Calls)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = encoder.Encode(m.HasEvents)
+	if err != nil {
+		return err
+	}
+
+	if m.HasEvents {
+		err = encoder.Encode(m.Events)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalJSON fills m with the JSON encoded module metadata given by b
+func (m *ModuleMetadataV3) UnmarshalJSON(b []byte) error {
+	var tmp struct {
+		Name    Text            `json:"name"`
+		Prefix  Text            `json:"prefix"`
+		Storage json.RawMessage `json:"storage"`
+		Calls   json.RawMessage `json:"calls"`
+		Events  json.RawMessage `json:"events"`
+	}
+	err := json.Unmarshal(b, &tmp)
+	if err != nil {
+		return err
+	}
+
+	*m = ModuleMetadataV3{Name: tmp.Name, Prefix: tmp.Prefix}
+	m.HasStorage, err = unmarshalOptionJSON(tmp.Storage, &m.Storage)
+	if err != nil {
+		return err
+	}
+	m.HasCalls, err = unmarshalOptionJSON(tmp.Calls, &m.Calls)
+	if err != nil {
+		return err
+	}
+	m.HasEvents, err = unmarshalOptionJSON(tmp.Events, &m.Events)
+	return err
+}
+
+// MarshalJSON returns the JSON encoding of m, with null for missing options
+func (m ModuleMetadataV3) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name    Text        `json:"name"`
+		Prefix  Text        `json:"prefix"`
+		Storage interface{} `json:"storage"`
+		Calls   interface{} `json:"calls"`
+		Events  interface{} `json:"events"`
+	}{
+		Name:    m.Name,
+		Prefix:  m.Prefix,
+		Storage: optionJSON(m.HasStorage, m.Storage),
+		Calls:   optionJSON(m.HasCalls, m.Calls),
+		Events:  optionJSON(m.HasEvents, m.Events),
+	})
+}
+
+type StorageFunctionMetadataV3 struct {
+	Name          Text                      `json:"name"`
+	Modifier      StorageFunctionModifierV0 `json:"modifier"`
+	Type          StorageFunctionTypeV3     `json:"type"`
+	Fallback      Bytes                     `json:"fallback"`
+	Documentation []Text                    `json:"documentation"`
+}
+
+func (s StorageFunctionMetadataV3) IsPlain() bool {
+	return s.Type.IsType
+}
+
+func (s StorageFunctionMetadataV3) IsMap() bool {
+	return s.Type.IsMap
+}
+
+func (s StorageFunctionMetadataV3) IsDoubleMap() bool {
+	return s.Type.IsDoubleMap
+}
+
+// Hasher returns Twox128 for both plain values and maps, metadata before v4 had no configurable hashers
+func (s StorageFunctionMetadataV3) Hasher() (hash.Hash, error) {
+	return xxhash.New128(nil), nil
+}
+
+// Hasher2 returns the hasher for the second key of a DoubleMap, which is given by name in metadata before v5
+func (s StorageFunctionMetadataV3) Hasher2() (hash.Hash, error) {
+	if !s.Type.IsDoubleMap {
+		return nil, fmt.Errorf("only DoubleMaps have a Hasher2")
+	}
+	return hasherFromName(string(s.Type.AsDoubleMap.Key2Hasher))
+}
+
+func (s StorageFunctionMetadataV3) IsOptional() bool {
+	return s.Modifier.IsOptional
+}
+
+func (s StorageFunctionMetadataV3) DefaultValue() Bytes {
+	return s.Fallback
+}
+
+func (s StorageFunctionMetadataV3) MarshalJSON() ([]byte, error) {
+	type plain StorageFunctionMetadataV3
+	if s.Documentation == nil {
+		s.Documentation = []Text{}
+	}
+	return json.Marshal(plain(s))
+}
+
+type StorageFunctionTypeV3 struct {
+	IsType      bool
+	AsType      Type // 0
+	IsMap       bool
+	AsMap       MapTypeV2 // 1
+	IsDoubleMap bool
+	AsDoubleMap DoubleMapTypeV3 // 2
+}
+
+func (s *StorageFunctionTypeV3) Decode(decoder scale.Decoder) error {
+	var t uint8
+	err := decoder.Decode(&t)
+	if err != nil {
+		return err
+	}
+
+	switch t {
+	case 0:
+		s.IsType = true
+		err = decoder.Decode(&s.AsType)
+		if err != nil {
+			return err
+		}
+	case 1:
+		s.IsMap = true
+		err = decoder.Decode(&s.AsMap)
+		if err != nil {
+			return err
+		}
+	case 2:
+		s.IsDoubleMap = true
+		err = decoder.Decode(&s.AsDoubleMap)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("received unexpected type %v", t)
+	}
+	return nil
+}
+
+func (s StorageFunctionTypeV3) Encode(encoder scale.Encoder) error {
+	switch {
+	case s.IsType:
+		err := encoder.PushByte(0)
+		if err != nil {
+			return err
+		}
+		err = encoder.Encode(s.AsType)
+		if err != nil {
+			return err
+		}
+	case s.IsMap:
+		err := encoder.PushByte(1)
+		if err != nil {
+			return err
+		}
+		err = encoder.Encode(s.AsMap)
+		if err != nil {
+			return err
+		}
+	case s.IsDoubleMap:
+		err := encoder.PushByte(2)
+		if err != nil {
+			return err
+		}
+		err = encoder.Encode(s.AsDoubleMap)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("expected to be either type, map or double map, but none was set: %v", s)
+	}
+	return nil
+}
+
+// UnmarshalJSON fills s with the JSON encoded storage function type given by b
+func (s *StorageFunctionTypeV3) UnmarshalJSON(b []byte) error {
+	variant, value, err := unmarshalEnumJSON(b)
+	if err != nil {
+		return err
+	}
+
+	*s = StorageFunctionTypeV3{}
+	switch variant {
+	case "plain":
+		s.IsType = true
+		return json.Unmarshal(value, &s.AsType)
+	case "map":
+		s.IsMap = true
+		return json.Unmarshal(value, &s.AsMap)
+	case "doubleMap":
+		s.IsDoubleMap = true
+		return json.Unmarshal(value, &s.AsDoubleMap)
+	default:
+		return fmt.Errorf("received unexpected storage function type %v", variant)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of s
+func (s StorageFunctionTypeV3) MarshalJSON() ([]byte, error) {
+	switch {
+	case s.IsType:
+		return marshalEnumJSON("plain", s.AsType)
+	case s.IsMap:
+		return marshalEnumJSON("map", s.AsMap)
+	case s.IsDoubleMap:
+		return marshalEnumJSON("doubleMap", s.AsDoubleMap)
+	default:
+		return nil, fmt.Errorf("expected storage function type, but none was set: %v", s)
+	}
+}
+
+type DoubleMapTypeV3 struct {
+	Key1       Type `json:"key1"`
+	Key2       Type `json:"key2"`
+	Value      Type `json:"value"`
+	Key2Hasher Text `json:"key2Hasher"`
+}
+
+// hasherFromName returns the hasher for the names used by metadata v3 to describe the hasher of the second key of a
+// DoubleMap
+func hasherFromName(name string) (hash.Hash, error) {
+	switch name {
+	case "blake2_128":
+		return StorageHasher{IsBlake2_128: true}.HashFunc()
+	case "blake2_256":
+		return StorageHasher{IsBlake2_256: true}.HashFunc()
+	case "twox_128":
+		return StorageHasher{IsTwox128: true}.HashFunc()
+	case "twox_256":
+		return StorageHasher{IsTwox256: true}.HashFunc()
+	case "twox_64_concat":
+		return StorageHasher{IsTwox64Concat: true}.HashFunc()
+	default:
+		return nil, fmt.Errorf("unknown hasher %v", name)
+	}
+}
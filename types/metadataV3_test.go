@@ -0,0 +1,121 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/blake2b"
+
+	. "github.com/zenghq3/go-substrate-rpc-client/types"
+	"github.com/zenghq3/go-substrate-rpc-client/xxhash"
+)
+
+var exampleMetadataV3 = Metadata{
+	MagicNumber:  0x6174656d,
+	Version:      3,
+	IsMetadataV3: true,
+	AsMetadataV3: MetadataV3{
+		Modules: []ModuleMetadataV3{exampleModuleMetadataV3Empty, exampleModuleMetadataV31},
+	},
+}
+
+var exampleModuleMetadataV3Empty = ModuleMetadataV3{
+	Name:   "EmptyModule",
+	Prefix: "EmptyModule",
+}
+
+var exampleModuleMetadataV31 = ModuleMetadataV3{
+	Name:       "Module1",
+	Prefix:     "Module1",
+	HasStorage: true,
+	Storage: []StorageFunctionMetadataV3{
+		exampleStorageFunctionMetadataV3Map,
+		exampleStorageFunctionMetadataV3DoubleMap,
+	},
+	HasCalls:  true,
+	Calls:     []FunctionMetadataV4{exampleFunctionMetadataV4},
+	HasEvents: true,
+	Events:    []EventMetadataV4{exampleEventMetadataV4},
+}
+
+var exampleStorageFunctionMetadataV3Map = StorageFunctionMetadataV3{
+	Name:     "myStorageFunc2",
+	Modifier: StorageFunctionModifierV0{IsOptional: true},
+	Type: StorageFunctionTypeV3{IsMap: true,
+		AsMap: MapTypeV2{Key: "my key", Value: "and my value", Linked: true}},
+	Fallback:      []byte{23, 14},
+	Documentation: []Text{"My", "storage func", "doc"},
+}
+
+var exampleStorageFunctionMetadataV3DoubleMap = StorageFunctionMetadataV3{
+	Name:     "myStorageFunc3",
+	Modifier: StorageFunctionModifierV0{IsOptional: true},
+	Type: StorageFunctionTypeV3{IsDoubleMap: true, AsDoubleMap: DoubleMapTypeV3{
+		Key1:       "myKey",
+		Key2:       "otherKey",
+		Value:      "and a value",
+		Key2Hasher: "blake2_256",
+	}},
+	Fallback:      []byte{23, 14},
+	Documentation: []Text{"My", "storage func", "doc"},
+}
+
+func TestMetadataV3_EncodeDecode(t *testing.T) {
+	assertRoundtrip(t, exampleMetadataV3)
+}
+
+func TestStorageFunctionMetadataV3DoubleMap_EncodeDecode(t *testing.T) {
+	assertRoundtrip(t, exampleStorageFunctionMetadataV3DoubleMap)
+}
+
+func TestFindCallIndexV3(t *testing.T) {
+	callIndex, err := exampleMetadataV3.FindCallIndex("Module1.my function")
+	assert.NoError(t, err)
+	assert.Equal(t, CallIndex{SectionIndex: 0, MethodIndex: 0}, callIndex)
+}
+
+func TestFindEventNamesForEventIDV3(t *testing.T) {
+	module, event, err := exampleMetadataV3.FindEventNamesForEventID(EventID([2]byte{0, 0}))
+	assert.NoError(t, err)
+	assert.Equal(t, exampleModuleMetadataV31.Prefix, module)
+	assert.Equal(t, exampleEventMetadataV4.Name, event)
+}
+
+func TestStorageFunctionMetadataV3_Hasher2(t *testing.T) {
+	entry := exampleStorageFunctionMetadataV3DoubleMap
+	entry.Type.AsDoubleMap.Key2Hasher = "unknown"
+	_, err := entry.Hasher2()
+	assert.EqualError(t, err, "unknown hasher unknown")
+
+	_, err = exampleStorageFunctionMetadataV3Map.Hasher2()
+	assert.EqualError(t, err, "only DoubleMaps have a Hasher2")
+}
+
+func TestCreateStorageKeyDoubleMapV3(t *testing.T) {
+	arg1, arg2 := []byte{0x01, 0x02}, []byte{0x03, 0x04}
+
+	key, err := CreateStorageKey(&exampleMetadataV3, "Module1", "myStorageFunc3", arg1, arg2)
+	assert.NoError(t, err)
+
+	hasher := xxhash.New128(nil)
+	_, err = hasher.Write(append([]byte("Module1 myStorageFunc3"), arg1...))
+	assert.NoError(t, err)
+	hash2 := blake2b.Sum256(arg2)
+	assert.Equal(t, StorageKey(append(hasher.Sum(nil), hash2[:]...)), key)
+}
@@ -17,6 +17,7 @@
 package types
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"hash"
@@ -29,7 +30,7 @@ import (
 
 // Modelled after https://github.com/paritytech/substrate/blob/v1.0.0rc2/srml/metadata/src/lib.rs
 type MetadataV4 struct {
-	Modules []ModuleMetadataV4
+	Modules []ModuleMetadataV4 `json:"modules"`
 }
 
 func (m *MetadataV4) Decode(decoder scale.Decoder) error {
@@ -69,7 +70,31 @@ func (m *MetadataV4) FindCallIndex(call string) (CallIndex, error) {
 	return CallIndex{}, fmt.Errorf("module %v not found in metadata for call %v", s[0], call)
 }
 
+func (m *MetadataV4) FindCallMetadata(index CallIndex) (Text, FunctionMetadataV4, error) {
+	mi := uint8(0)
+	for _, mod := range m.Modules {
+		if !mod.HasCalls {
+			continue
+		}
+		if mi != index.SectionIndex {
+			mi++
+			continue
+		}
+		if int(index.MethodIndex) >= len(mod.Calls) {
+			return "", FunctionMetadataV4{}, fmt.Errorf("call index %v for module %v out of range", index.MethodIndex,
+				mod.Name)
+		}
+		return mod.Name, mod.Calls[index.MethodIndex], nil
+	}
+	return "", FunctionMetadataV4{}, fmt.Errorf("module index %v out of range", index.SectionIndex)
+}
+
 func (m *MetadataV4) FindEventNamesForEventID(eventID EventID) (Text, Text, error) {
+	mod, event, err := m.FindEventMetadata(eventID)
+	return mod, event.Name, err
+}
+
+func (m *MetadataV4) FindEventMetadata(eventID EventID) (Text, EventMetadataV4, error) {
 	mi := uint8(0)
 	for _, mod := range m.Modules {
 		if !mod.HasEvents {
@@ -80,11 +105,11 @@ func (m *MetadataV4) FindEventNamesForEventID(eventID EventID) (Text, Text, erro
 			continue
 		}
 		if int(eventID[1]) >= len(mod.Events) {
-			return "", "", fmt.Errorf("event index %v for module %v out of range", eventID[1], mod.Name)
+			return "", EventMetadataV4{}, fmt.Errorf("event index %v for module %v out of range", eventID[1], mod.Name)
 		}
-		return mod.Prefix, mod.Events[eventID[1]].Name, nil
+		return mod.Prefix, mod.Events[eventID[1]], nil
 	}
-	return "", "", fmt.Errorf("module index %v out of range", eventID[0])
+	return "", EventMetadataV4{}, fmt.Errorf("module index %v out of range", eventID[0])
 }
 
 func (m *MetadataV4) FindStorageEntryMetadata(module string, fn string) (StorageEntryMetadata, error) {
@@ -106,12 +131,24 @@ func (m *MetadataV4) FindStorageEntryMetadata(module string, fn string) (Storage
 	return nil, fmt.Errorf("module %v not found in metadata", module)
 }
 
+func (m MetadataV4) MarshalJSON() ([]byte, error) {
+	type plain MetadataV4
+	if m.Modules == nil {
+		m.Modules = []ModuleMetadataV4{}
+	}
+	return json.Marshal(plain(m))
+}
+
 type StorageEntryMetadata interface {
 	IsPlain() bool
 	IsMap() bool
 	IsDoubleMap() bool
 	Hasher() (hash.Hash, error)
 	Hasher2() (hash.Hash, error)
+	// IsOptional returns true if absent values are reported as such, otherwise DefaultValue is used for them
+	IsOptional() bool
+	// DefaultValue returns the encoded value of absent keys of entries that are not optional
+	DefaultValue() Bytes
 }
 
 type ModuleMetadataV4 struct {
@@ -223,12 +260,56 @@ func (m ModuleMetadataV4) Encode(encoder scale.Encoder) error {
 	return nil
 }
 
+// UnmarshalJSON fills m with the JSON encoded module metadata given by b
+func (m *ModuleMetadataV4) UnmarshalJSON(b []byte) error {
+	var tmp struct {
+		Name    Text            `json:"name"`
+		Prefix  Text            `json:"prefix"`
+		Storage json.RawMessage `json:"storage"`
+		Calls   json.RawMessage `json:"calls"`
+		Events  json.RawMessage `json:"events"`
+	}
+	err := json.Unmarshal(b, &tmp)
+	if err != nil {
+		return err
+	}
+
+	*m = ModuleMetadataV4{Name: tmp.Name, Prefix: tmp.Prefix}
+	m.HasStorage, err = unmarshalOptionJSON(tmp.Storage, &m.Storage)
+	if err != nil {
+		return err
+	}
+	m.HasCalls, err = unmarshalOptionJSON(tmp.Calls, &m.Calls)
+	if err != nil {
+		return err
+	}
+	m.HasEvents, err = unmarshalOptionJSON(tmp.Events, &m.Events)
+	return err
+}
+
+// MarshalJSON returns the JSON encoding of m, with null for missing options
+func (m ModuleMetadataV4) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name    Text        `json:"name"`
+		Prefix  Text        `json:"prefix"`
+		Storage interface{} `json:"storage"`
+		Calls   interface{} `json:"calls"`
+		Events  interface{} `json:"events"`
+	}{
+		Name:    m.Name,
+		Prefix:  m.Prefix,
+		Storage: optionJSON(m.HasStorage, m.Storage),
+		Calls:   optionJSON(m.HasCalls, m.Calls),
+		Events:  optionJSON(m.HasEvents, m.Events),
+	})
+}
+
 type StorageFunctionMetadataV4 struct {
-	Name          Text
-	Modifier      StorageFunctionModifierV0
-	Type          StorageFunctionTypeV4
-	Fallback      Bytes
-	Documentation []Text
+	Name          Text                      `json:"name"`
+	Modifier      StorageFunctionModifierV0 `json:"modifier"`
+	Type          StorageFunctionTypeV4     `json:"type"`
+	Fallback      Bytes                     `json:"fallback"`
+	Documentation []Text                    `json:"documentation"`
 }
 
 func (s StorageFunctionMetadataV4) IsPlain() bool {
@@ -257,6 +338,22 @@ func (s StorageFunctionMetadataV4) Hasher2() (hash.Hash, error) {
 	return nil, fmt.Errorf("Hasher2 is not supported for metadata v4, please upgrade to use metadata v8 or newer")
 }
 
+func (s StorageFunctionMetadataV4) IsOptional() bool {
+	return s.Modifier.IsOptional
+}
+
+func (s StorageFunctionMetadataV4) DefaultValue() Bytes {
+	return s.Fallback
+}
+
+func (s StorageFunctionMetadataV4) MarshalJSON() ([]byte, error) {
+	type plain StorageFunctionMetadataV4
+	if s.Documentation == nil {
+		s.Documentation = []Text{}
+	}
+	return json.Marshal(plain(s))
+}
+
 type StorageFunctionTypeV4 struct {
 	IsType      bool
 	AsType      Type // 0
@@ -333,19 +430,56 @@ func (s StorageFunctionTypeV4) Encode(encoder scale.Encoder) error {
 	return nil
 }
 
+// UnmarshalJSON fills s with the JSON encoded storage function type given by b
+func (s *StorageFunctionTypeV4) UnmarshalJSON(b []byte) error {
+	variant, value, err := unmarshalEnumJSON(b)
+	if err != nil {
+		return err
+	}
+
+	*s = StorageFunctionTypeV4{}
+	switch variant {
+	case "plain":
+		s.IsType = true
+		return json.Unmarshal(value, &s.AsType)
+	case "map":
+		s.IsMap = true
+		return json.Unmarshal(value, &s.AsMap)
+	case "doubleMap":
+		s.IsDoubleMap = true
+		return json.Unmarshal(value, &s.AsDoubleMap)
+	default:
+		return fmt.Errorf("received unexpected storage function type %v", variant)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of s
+func (s StorageFunctionTypeV4) MarshalJSON() ([]byte, error) {
+	switch {
+	case s.IsType:
+		return marshalEnumJSON("plain", s.AsType)
+	case s.IsMap:
+		return marshalEnumJSON("map", s.AsMap)
+	case s.IsDoubleMap:
+		return marshalEnumJSON("doubleMap", s.AsDoubleMap)
+	default:
+		return nil, fmt.Errorf("expected storage function type, but none was set: %v", s)
+	}
+}
+
 type DoubleMapTypeV4 struct {
-	Hasher     StorageHasher
-	Key1       Type
-	Key2       Type
-	Value      Type
-	Key2Hasher Text
+	Hasher     StorageHasher `json:"hasher"`
+	Key1       Type          `json:"key1"`
+	Key2       Type          `json:"key2"`
+	Value      Type          `json:"value"`
+	Key2Hasher Text          `json:"key2Hasher"`
 }
 
 type MapTypeV4 struct {
-	Hasher StorageHasher
-	Key    Type
-	Value  Type
-	Linked bool
+	Hasher StorageHasher `json:"hasher"`
+	Key    Type          `json:"key"`
+	Value  Type          `json:"value"`
+	Linked bool          `json:"linked"`
 }
 
 type StorageHasher struct {
@@ -400,21 +534,43 @@ func (s StorageHasher) Encode(encoder scale.Encoder) error {
 }
 
 type FunctionMetadataV4 struct {
-	Name          Text
-	Args          []FunctionArgumentMetadata
-	Documentation []Text
+	Name          Text                       `json:"name"`
+	Args          []FunctionArgumentMetadata `json:"args"`
+	Documentation []Text                     `json:"documentation"`
+}
+
+func (m FunctionMetadataV4) MarshalJSON() ([]byte, error) {
+	type plain FunctionMetadataV4
+	if m.Args == nil {
+		m.Args = []FunctionArgumentMetadata{}
+	}
+	if m.Documentation == nil {
+		m.Documentation = []Text{}
+	}
+	return json.Marshal(plain(m))
 }
 
 type EventMetadataV4 struct {
-	Name          Text
-	Args          []Type
-	Documentation []Text
+	Name          Text   `json:"name"`
+	Args          []Type `json:"args"`
+	Documentation []Text `json:"documentation"`
+}
+
+func (m EventMetadataV4) MarshalJSON() ([]byte, error) {
+	type plain EventMetadataV4
+	if m.Args == nil {
+		m.Args = []Type{}
+	}
+	if m.Documentation == nil {
+		m.Documentation = []Text{}
+	}
+	return json.Marshal(plain(m))
 }
 
 func (s StorageHasher) HashFunc() (hash.Hash, error) {
 	// Blake2_128
 	if s.IsBlake2_128 {
-		return blake2b.New(128, nil)
+		return blake2b.New(16, nil)
 	}
 
 	// Blake2_256
@@ -440,9 +596,70 @@ func (s StorageHasher) HashFunc() (hash.Hash, error) {
 	return nil, errors.New("hash function type not yet supported")
 }
 
+// KeyHasher returns the version independent description of s
+func (s StorageHasher) KeyHasher() (StorageKeyHasher, error) {
+	switch {
+	case s.IsBlake2_128:
+		return keyHasherBlake2_128, nil
+	case s.IsBlake2_256:
+		return keyHasherBlake2_256, nil
+	case s.IsTwox128:
+		return keyHasherTwox128, nil
+	case s.IsTwox256:
+		return keyHasherTwox256, nil
+	case s.IsTwox64Concat:
+		return keyHasherTwox64Concat, nil
+	default:
+		return StorageKeyHasher{}, fmt.Errorf("expected storage hasher, but none was set: %v", s)
+	}
+}
+
+// UnmarshalJSON fills s with the JSON encoded name of the storage hasher given by b
+func (s *StorageHasher) UnmarshalJSON(b []byte) error {
+	name, err := unmarshalNameJSON(b)
+	if err != nil {
+		return err
+	}
+
+	*s = StorageHasher{}
+	switch name {
+	case "Blake2_128":
+		s.IsBlake2_128 = true
+	case "Blake2_256":
+		s.IsBlake2_256 = true
+	case "Twox128":
+		s.IsTwox128 = true
+	case "Twox256":
+		s.IsTwox256 = true
+	case "Twox64Concat":
+		s.IsTwox64Concat = true
+	default:
+		return fmt.Errorf("received unexpected storage hasher %v", name)
+	}
+	return nil
+}
+
+// MarshalJSON returns the JSON encoded name of s
+func (s StorageHasher) MarshalJSON() ([]byte, error) {
+	switch {
+	case s.IsBlake2_128:
+		return json.Marshal("Blake2_128")
+	case s.IsBlake2_256:
+		return json.Marshal("Blake2_256")
+	case s.IsTwox128:
+		return json.Marshal("Twox128")
+	case s.IsTwox256:
+		return json.Marshal("Twox256")
+	case s.IsTwox64Concat:
+		return json.Marshal("Twox64Concat")
+	default:
+		return nil, fmt.Errorf("expected storage hasher, but none was set: %v", s)
+	}
+}
+
 type FunctionArgumentMetadata struct {
-	Name Text
-	Type Type
+	Name Text `json:"name"`
+	Type Type `json:"type"`
 }
 
 type StorageFunctionModifierV0 struct {
@@ -485,3 +702,38 @@ func (s StorageFunctionModifierV0) Encode(encoder scale.Encoder) error {
 	}
 	return encoder.PushByte(t)
 }
+
+// UnmarshalJSON fills s with the JSON encoded name of the storage function modifier given by b
+func (s *StorageFunctionModifierV0) UnmarshalJSON(b []byte) error {
+	name, err := unmarshalNameJSON(b)
+	if err != nil {
+		return err
+	}
+
+	*s = StorageFunctionModifierV0{}
+	switch name {
+	case "Optional":
+		s.IsOptional = true
+	case "Default":
+		s.IsDefault = true
+	case "Required":
+		s.IsRequired = true
+	default:
+		return fmt.Errorf("received unexpected storage function modifier %v", name)
+	}
+	return nil
+}
+
+// MarshalJSON returns the JSON encoded name of s
+func (s StorageFunctionModifierV0) MarshalJSON() ([]byte, error) {
+	switch {
+	case s.IsOptional:
+		return json.Marshal("Optional")
+	case s.IsDefault:
+		return json.Marshal("Default")
+	case s.IsRequired:
+		return json.Marshal("Required")
+	default:
+		return nil, fmt.Errorf("expected storage function modifier, but none was set: %v", s)
+	}
+}
@@ -0,0 +1,114 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+var exampleMetadataV6 = Metadata{
+	MagicNumber:  0x6174656d,
+	Version:      6,
+	IsMetadataV6: true,
+	AsMetadataV6: MetadataV6{
+		Modules: []ModuleMetadataV6{exampleModuleMetadataV6Empty, exampleModuleMetadataV61},
+	},
+}
+
+var exampleModuleMetadataV6Empty = ModuleMetadataV6{
+	Name:   "EmptyModule",
+	Prefix: "EmptyModule",
+}
+
+var exampleModuleMetadataV61 = ModuleMetadataV6{
+	Name:       "Balances",
+	Prefix:     "Balances",
+	HasStorage: true,
+	Storage: []StorageFunctionMetadataV5{{
+		Name:     "FreeBalance",
+		Modifier: StorageFunctionModifierV0{IsDefault: true},
+		Type: StorageFunctionTypeV5{IsMap: true, AsMap: MapTypeV4{
+			Hasher: StorageHasher{IsBlake2_256: true},
+			Key:    "AccountId",
+			Value:  "Balance",
+		}},
+		Fallback: []byte{0},
+	}},
+	HasCalls:  true,
+	Calls:     []FunctionMetadataV4{exampleFunctionMetadataV4},
+	HasEvents: true,
+	Events:    []EventMetadataV4{exampleEventMetadataV4},
+	Constants: []ModuleConstantMetadataV6{{
+		Name:          "ExistentialDeposit",
+		Type:          "Balance",
+		Value:         Bytes{0x01},
+		Documentation: []Text{"The minimum balance"},
+	}},
+}
+
+var exampleMetadataV5 = Metadata{
+	MagicNumber:  0x6174656d,
+	Version:      5,
+	IsMetadataV5: true,
+	AsMetadataV5: MetadataV5{
+		Modules: []ModuleMetadataV5{{
+			Name:       "Balances",
+			Prefix:     "Balances",
+			HasStorage: true,
+			Storage:    exampleModuleMetadataV61.Storage,
+		}},
+	},
+}
+
+func TestMetadataV5_EncodeDecode(t *testing.T) {
+	assertRoundtrip(t, exampleMetadataV5)
+}
+
+func TestMetadataV6_EncodeDecode(t *testing.T) {
+	assertRoundtrip(t, exampleMetadataV6)
+}
+
+func TestFindConstantMetadataV6(t *testing.T) {
+	constant, err := exampleMetadataV6.FindConstantMetadata("balances", "existentialDeposit")
+	assert.NoError(t, err)
+	assert.Equal(t, exampleModuleMetadataV61.Constants[0], constant)
+
+	_, err = exampleMetadataV5.FindConstantMetadata("Balances", "ExistentialDeposit")
+	assert.EqualError(t, err, "unsupported metadata version")
+}
+
+func TestFindConstantsV6(t *testing.T) {
+	constants, err := exampleMetadataV6.FindConstants("balances")
+	assert.NoError(t, err)
+	assert.Equal(t, exampleModuleMetadataV61.Constants, constants)
+
+	_, err = exampleMetadataV6.FindConstants("Unknown")
+	assert.EqualError(t, err, "module Unknown not found in metadata")
+
+	_, err = exampleMetadataV5.FindConstants("Balances")
+	assert.EqualError(t, err, "unsupported metadata version")
+}
+
+func TestFindStorageEntryMetadataV5(t *testing.T) {
+	entry, err := exampleMetadataV5.FindStorageEntryMetadata("Balances", "FreeBalance")
+	assert.NoError(t, err)
+	assert.True(t, entry.IsMap())
+}
@@ -17,6 +17,7 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 	"hash"
 	"strings"
@@ -27,7 +28,7 @@ import (
 
 // Modelled after packages/types/src/Metadata/v7/Metadata.ts
 type MetadataV7 struct {
-	Modules []ModuleMetadataV7
+	Modules []ModuleMetadataV7 `json:"modules"`
 }
 
 func (m *MetadataV7) Decode(decoder scale.Decoder) error {
@@ -67,7 +68,31 @@ func (m *MetadataV7) FindCallIndex(call string) (CallIndex, error) {
 	return CallIndex{}, fmt.Errorf("module %v not found in metadata for call %v", s[0], call)
 }
 
+func (m *MetadataV7) FindCallMetadata(index CallIndex) (Text, FunctionMetadataV4, error) {
+	mi := uint8(0)
+	for _, mod := range m.Modules {
+		if !mod.HasCalls {
+			continue
+		}
+		if mi != index.SectionIndex {
+			mi++
+			continue
+		}
+		if int(index.MethodIndex) >= len(mod.Calls) {
+			return "", FunctionMetadataV4{}, fmt.Errorf("call index %v for module %v out of range", index.MethodIndex,
+				mod.Name)
+		}
+		return mod.Name, mod.Calls[index.MethodIndex], nil
+	}
+	return "", FunctionMetadataV4{}, fmt.Errorf("module index %v out of range", index.SectionIndex)
+}
+
 func (m *MetadataV7) FindEventNamesForEventID(eventID EventID) (Text, Text, error) {
+	mod, event, err := m.FindEventMetadata(eventID)
+	return mod, event.Name, err
+}
+
+func (m *MetadataV7) FindEventMetadata(eventID EventID) (Text, EventMetadataV4, error) {
 	mi := uint8(0)
 	for _, mod := range m.Modules {
 		if !mod.HasEvents {
@@ -78,11 +103,11 @@ func (m *MetadataV7) FindEventNamesForEventID(eventID EventID) (Text, Text, erro
 			continue
 		}
 		if int(eventID[1]) >= len(mod.Events) {
-			return "", "", fmt.Errorf("event index %v for module %v out of range", eventID[1], mod.Name)
+			return "", EventMetadataV4{}, fmt.Errorf("event index %v for module %v out of range", eventID[1], mod.Name)
 		}
-		return mod.Name, mod.Events[eventID[1]].Name, nil
+		return mod.Name, mod.Events[eventID[1]], nil
 	}
-	return "", "", fmt.Errorf("module index %v out of range", eventID[0])
+	return "", EventMetadataV4{}, fmt.Errorf("module index %v out of range", eventID[0])
 }
 
 func (m *MetadataV7) FindStorageEntryMetadata(module string, fn string) (StorageEntryMetadata, error) {
@@ -120,6 +145,24 @@ func (m *MetadataV7) FindConstantMetadata(module string, constant string) (Modul
 	return ModuleConstantMetadataV6{}, fmt.Errorf("module %v not found in metadata", module)
 }
 
+// FindConstants returns all constants of the given module
+func (m *MetadataV7) FindConstants(module string) ([]ModuleConstantMetadataV6, error) {
+	for _, mod := range m.Modules {
+		if strings.EqualFold(string(mod.Name), module) {
+			return mod.Constants, nil
+		}
+	}
+	return nil, fmt.Errorf("module %v not found in metadata", module)
+}
+
+func (m MetadataV7) MarshalJSON() ([]byte, error) {
+	type plain MetadataV7
+	if m.Modules == nil {
+		m.Modules = []ModuleMetadataV7{}
+	}
+	return json.Marshal(plain(m))
+}
+
 type ModuleMetadataV7 struct {
 	Name       Text
 	HasStorage bool
@@ -221,17 +264,72 @@ func (m ModuleMetadataV7) Encode(encoder scale.Encoder) error {
 	return encoder.Encode(m.Constants)
 }
 
+// UnmarshalJSON fills m with the JSON encoded module metadata given by b
+func (m *ModuleMetadataV7) UnmarshalJSON(b []byte) error {
+	var tmp struct {
+		Name      Text                       `json:"name"`
+		Storage   json.RawMessage            `json:"storage"`
+		Calls     json.RawMessage            `json:"calls"`
+		Events    json.RawMessage            `json:"events"`
+		Constants []ModuleConstantMetadataV6 `json:"constants"`
+	}
+	err := json.Unmarshal(b, &tmp)
+	if err != nil {
+		return err
+	}
+
+	*m = ModuleMetadataV7{Name: tmp.Name, Constants: tmp.Constants}
+	m.HasStorage, err = unmarshalOptionJSON(tmp.Storage, &m.Storage)
+	if err != nil {
+		return err
+	}
+	m.HasCalls, err = unmarshalOptionJSON(tmp.Calls, &m.Calls)
+	if err != nil {
+		return err
+	}
+	m.HasEvents, err = unmarshalOptionJSON(tmp.Events, &m.Events)
+	return err
+}
+
+// MarshalJSON returns the JSON encoding of m, with null for missing options and [] for empty lists
+func (m ModuleMetadataV7) MarshalJSON() ([]byte, error) {
+	if m.Constants == nil {
+		m.Constants = []ModuleConstantMetadataV6{}
+	}
+	return json.Marshal(struct {
+		Name      Text                       `json:"name"`
+		Storage   interface{}                `json:"storage"`
+		Calls     interface{}                `json:"calls"`
+		Events    interface{}                `json:"events"`
+		Constants []ModuleConstantMetadataV6 `json:"constants"`
+	}{
+		Name:      m.Name,
+		Storage:   optionJSON(m.HasStorage, m.Storage),
+		Calls:     optionJSON(m.HasCalls, m.Calls),
+		Events:    optionJSON(m.HasEvents, m.Events),
+		Constants: m.Constants,
+	})
+}
+
 type StorageMetadata struct {
-	Prefix Text
-	Items  []StorageFunctionMetadataV5
+	Prefix Text                        `json:"prefix"`
+	Items  []StorageFunctionMetadataV5 `json:"items"`
+}
+
+func (s StorageMetadata) MarshalJSON() ([]byte, error) {
+	type plain StorageMetadata
+	if s.Items == nil {
+		s.Items = []StorageFunctionMetadataV5{}
+	}
+	return json.Marshal(plain(s))
 }
 
 type StorageFunctionMetadataV5 struct {
-	Name          Text
-	Modifier      StorageFunctionModifierV0
-	Type          StorageFunctionTypeV5
-	Fallback      Bytes
-	Documentation []Text
+	Name          Text                      `json:"name"`
+	Modifier      StorageFunctionModifierV0 `json:"modifier"`
+	Type          StorageFunctionTypeV5     `json:"type"`
+	Fallback      Bytes                     `json:"fallback"`
+	Documentation []Text                    `json:"documentation"`
 }
 
 func (s StorageFunctionMetadataV5) IsPlain() bool {
@@ -263,6 +361,22 @@ func (s StorageFunctionMetadataV5) Hasher2() (hash.Hash, error) {
 	return s.Type.AsDoubleMap.Key2Hasher.HashFunc()
 }
 
+func (s StorageFunctionMetadataV5) IsOptional() bool {
+	return s.Modifier.IsOptional
+}
+
+func (s StorageFunctionMetadataV5) DefaultValue() Bytes {
+	return s.Fallback
+}
+
+func (s StorageFunctionMetadataV5) MarshalJSON() ([]byte, error) {
+	type plain StorageFunctionMetadataV5
+	if s.Documentation == nil {
+		s.Documentation = []Text{}
+	}
+	return json.Marshal(plain(s))
+}
+
 type StorageFunctionTypeV5 struct {
 	IsType      bool
 	AsType      Type // 0
@@ -339,17 +453,62 @@ func (s StorageFunctionTypeV5) Encode(encoder scale.Encoder) error {
 	return nil
 }
 
+// UnmarshalJSON fills s with the JSON encoded storage function type given by b
+func (s *StorageFunctionTypeV5) UnmarshalJSON(b []byte) error {
+	variant, value, err := unmarshalEnumJSON(b)
+	if err != nil {
+		return err
+	}
+
+	*s = StorageFunctionTypeV5{}
+	switch variant {
+	case "plain":
+		s.IsType = true
+		return json.Unmarshal(value, &s.AsType)
+	case "map":
+		s.IsMap = true
+		return json.Unmarshal(value, &s.AsMap)
+	case "doubleMap":
+		s.IsDoubleMap = true
+		return json.Unmarshal(value, &s.AsDoubleMap)
+	default:
+		return fmt.Errorf("received unexpected storage function type %v", variant)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of s
+func (s StorageFunctionTypeV5) MarshalJSON() ([]byte, error) {
+	switch {
+	case s.IsType:
+		return marshalEnumJSON("plain", s.AsType)
+	case s.IsMap:
+		return marshalEnumJSON("map", s.AsMap)
+	case s.IsDoubleMap:
+		return marshalEnumJSON("doubleMap", s.AsDoubleMap)
+	default:
+		return nil, fmt.Errorf("expected storage function type, but none was set: %v", s)
+	}
+}
+
 type DoubleMapTypeV5 struct {
-	Hasher     StorageHasher
-	Key1       Type
-	Key2       Type
-	Value      Type
-	Key2Hasher StorageHasher
+	Hasher     StorageHasher `json:"hasher"`
+	Key1       Type          `json:"key1"`
+	Key2       Type          `json:"key2"`
+	Value      Type          `json:"value"`
+	Key2Hasher StorageHasher `json:"key2Hasher"`
 }
 
 type ModuleConstantMetadataV6 struct {
-	Name          Text
-	Type          Type
-	Value         Bytes
-	Documentation []Text
+	Name          Text   `json:"name"`
+	Type          Type   `json:"type"`
+	Value         Bytes  `json:"value"`
+	Documentation []Text `json:"documentation"`
+}
+
+func (m ModuleConstantMetadataV6) MarshalJSON() ([]byte, error) {
+	type plain ModuleConstantMetadataV6
+	if m.Documentation == nil {
+		m.Documentation = []Text{}
+	}
+	return json.Marshal(plain(m))
 }
@@ -17,6 +17,7 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -25,7 +26,7 @@ import (
 
 // Modelled after packages/types/src/Metadata/v8/Metadata.ts
 type MetadataV8 struct {
-	Modules []ModuleMetadataV8
+	Modules []ModuleMetadataV8 `json:"modules"`
 }
 
 func (m *MetadataV8) Decode(decoder scale.Decoder) error {
@@ -65,7 +66,31 @@ func (m *MetadataV8) FindCallIndex(call string) (CallIndex, error) {
 	return CallIndex{}, fmt.Errorf("module %v not found in metadata for call %v", s[0], call)
 }
 
+func (m *MetadataV8) FindCallMetadata(index CallIndex) (Text, FunctionMetadataV4, error) {
+	mi := uint8(0)
+	for _, mod := range m.Modules {
+		if !mod.HasCalls {
+			continue
+		}
+		if mi != index.SectionIndex {
+			mi++
+			continue
+		}
+		if int(index.MethodIndex) >= len(mod.Calls) {
+			return "", FunctionMetadataV4{}, fmt.Errorf("call index %v for module %v out of range", index.MethodIndex,
+				mod.Name)
+		}
+		return mod.Name, mod.Calls[index.MethodIndex], nil
+	}
+	return "", FunctionMetadataV4{}, fmt.Errorf("module index %v out of range", index.SectionIndex)
+}
+
 func (m *MetadataV8) FindEventNamesForEventID(eventID EventID) (Text, Text, error) {
+	mod, event, err := m.FindEventMetadata(eventID)
+	return mod, event.Name, err
+}
+
+func (m *MetadataV8) FindEventMetadata(eventID EventID) (Text, EventMetadataV4, error) {
 	mi := uint8(0)
 	for _, mod := range m.Modules {
 		if !mod.HasEvents {
@@ -76,11 +101,11 @@ func (m *MetadataV8) FindEventNamesForEventID(eventID EventID) (Text, Text, erro
 			continue
 		}
 		if int(eventID[1]) >= len(mod.Events) {
-			return "", "", fmt.Errorf("event index %v for module %v out of range", eventID[1], mod.Name)
+			return "", EventMetadataV4{}, fmt.Errorf("event index %v for module %v out of range", eventID[1], mod.Name)
 		}
-		return mod.Name, mod.Events[eventID[1]].Name, nil
+		return mod.Name, mod.Events[eventID[1]], nil
 	}
-	return "", "", fmt.Errorf("module index %v out of range", eventID[0])
+	return "", EventMetadataV4{}, fmt.Errorf("module index %v out of range", eventID[0])
 }
 
 func (m *MetadataV8) FindStorageEntryMetadata(module string, fn string) (StorageEntryMetadata, error) {
@@ -118,6 +143,24 @@ func (m *MetadataV8) FindConstantMetadata(module string, constant string) (Modul
 	return ModuleConstantMetadataV6{}, fmt.Errorf("module %v not found in metadata", module)
 }
 
+// FindConstants returns all constants of the given module
+func (m *MetadataV8) FindConstants(module string) ([]ModuleConstantMetadataV6, error) {
+	for _, mod := range m.Modules {
+		if strings.EqualFold(string(mod.Name), module) {
+			return mod.Constants, nil
+		}
+	}
+	return nil, fmt.Errorf("module %v not found in metadata", module)
+}
+
+func (m MetadataV8) MarshalJSON() ([]byte, error) {
+	type plain MetadataV8
+	if m.Modules == nil {
+		m.Modules = []ModuleMetadataV8{}
+	}
+	return json.Marshal(plain(m))
+}
+
 type ModuleMetadataV8 struct {
 	Name       Text
 	HasStorage bool
@@ -230,7 +273,68 @@ func (m ModuleMetadataV8) Encode(encoder scale.Encoder) error {
 	return encoder.Encode(m.Errors)
 }
 
+// UnmarshalJSON fills m with the JSON encoded module metadata given by b
+func (m *ModuleMetadataV8) UnmarshalJSON(b []byte) error {
+	var tmp struct {
+		Name      Text                       `json:"name"`
+		Storage   json.RawMessage            `json:"storage"`
+		Calls     json.RawMessage            `json:"calls"`
+		Events    json.RawMessage            `json:"events"`
+		Constants []ModuleConstantMetadataV6 `json:"constants"`
+		Errors    []ErrorMetadataV8          `json:"errors"`
+	}
+	err := json.Unmarshal(b, &tmp)
+	if err != nil {
+		return err
+	}
+
+	*m = ModuleMetadataV8{Name: tmp.Name, Constants: tmp.Constants, Errors: tmp.Errors}
+	m.HasStorage, err = unmarshalOptionJSON(tmp.Storage, &m.Storage)
+	if err != nil {
+		return err
+	}
+	m.HasCalls, err = unmarshalOptionJSON(tmp.Calls, &m.Calls)
+	if err != nil {
+		return err
+	}
+	m.HasEvents, err = unmarshalOptionJSON(tmp.Events, &m.Events)
+	return err
+}
+
+// MarshalJSON returns the JSON encoding of m, with null for missing options and [] for empty lists
+func (m ModuleMetadataV8) MarshalJSON() ([]byte, error) {
+	if m.Constants == nil {
+		m.Constants = []ModuleConstantMetadataV6{}
+	}
+	if m.Errors == nil {
+		m.Errors = []ErrorMetadataV8{}
+	}
+	return json.Marshal(struct {
+		Name      Text                       `json:"name"`
+		Storage   interface{}                `json:"storage"`
+		Calls     interface{}                `json:"calls"`
+		Events    interface{}                `json:"events"`
+		Constants []ModuleConstantMetadataV6 `json:"constants"`
+		Errors    []ErrorMetadataV8          `json:"errors"`
+	}{
+		Name:      m.Name,
+		Storage:   optionJSON(m.HasStorage, m.Storage),
+		Calls:     optionJSON(m.HasCalls, m.Calls),
+		Events:    optionJSON(m.HasEvents, m.Events),
+		Constants: m.Constants,
+		Errors:    m.Errors,
+	})
+}
+
 type ErrorMetadataV8 struct {
-	Name          Text
-	Documentation []Text
+	Name          Text   `json:"name"`
+	Documentation []Text `json:"documentation"`
+}
+
+func (m ErrorMetadataV8) MarshalJSON() ([]byte, error) {
+	type plain ErrorMetadataV8
+	if m.Documentation == nil {
+		m.Documentation = []Text{}
+	}
+	return json.Marshal(plain(m))
 }
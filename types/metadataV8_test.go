@@ -87,6 +87,31 @@ func TestFindEventNamesForEventIDV8(t *testing.T) {
 	assert.Equal(t, exampleEventMetadataV4.Name, event)
 }
 
+func TestFindEventMetadataV8(t *testing.T) {
+	module, event, err := exampleMetadataV8.FindEventMetadata(EventID([2]byte{1, 0}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, exampleModuleMetadataV82.Name, module)
+	assert.Equal(t, exampleEventMetadataV4, event)
+
+	_, _, err = exampleMetadataV8.FindEventMetadata(EventID([2]byte{1, 1}))
+	assert.EqualError(t, err, "event index 1 for module Module2 out of range")
+}
+
+func TestFindCallMetadataV8(t *testing.T) {
+	module, call, err := exampleMetadataV8.FindCallMetadata(CallIndex{SectionIndex: 1, MethodIndex: 0})
+
+	assert.NoError(t, err)
+	assert.Equal(t, exampleModuleMetadataV82.Name, module)
+	assert.Equal(t, exampleFunctionMetadataV4, call)
+
+	_, _, err = exampleMetadataV8.FindCallMetadata(CallIndex{SectionIndex: 1, MethodIndex: 1})
+	assert.EqualError(t, err, "call index 1 for module Module2 out of range")
+
+	_, _, err = exampleMetadataV8.FindCallMetadata(CallIndex{SectionIndex: 2, MethodIndex: 0})
+	assert.EqualError(t, err, "module index 2 out of range")
+}
+
 func TestFindStorageEntryMetadataV8(t *testing.T) {
 	_, err := exampleMetadataV8.FindStorageEntryMetadata("myStoragePrefix", "myStorageFunc2")
 	assert.NoError(t, err)
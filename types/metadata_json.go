@@ -0,0 +1,77 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// The JSON encoding of metadata follows the format of polkadot-js, as returned by api.runtimeMetadata.toJSON(). Enums
+// with values are encoded as an object with the camel cased name of the variant as single key, enums without values
+// as the name of the variant. Options are encoded as null if they are not set, lists as [] if they are empty. Since
+// encoding/json encodes nil slices as null, every type holding lists replaces them with empty slices in MarshalJSON.
+
+// marshalEnumJSON returns the JSON encoding of an enum with the given variant and value
+func marshalEnumJSON(variant string, value interface{}) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{variant: value})
+}
+
+// unmarshalEnumJSON returns the variant and the JSON encoded value of an enum encoded as object with a single key
+func unmarshalEnumJSON(b []byte) (string, json.RawMessage, error) {
+	var tmp map[string]json.RawMessage
+	err := json.Unmarshal(b, &tmp)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(tmp) != 1 {
+		return "", nil, fmt.Errorf("expected enum object with a single key, but got %v keys", len(tmp))
+	}
+	for variant, value := range tmp {
+		return variant, value, nil
+	}
+	return "", nil, nil
+}
+
+// unmarshalNameJSON returns the name of an enum without values
+func unmarshalNameJSON(b []byte) (string, error) {
+	var name string
+	err := json.Unmarshal(b, &name)
+	return name, err
+}
+
+// optionJSON returns value if the option is set and nil otherwise, to be encoded as null. Set options with a nil
+// slice are returned as empty slice, so that they are not encoded as null as well.
+func optionJSON(has bool, value interface{}) interface{} {
+	if !has {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		return reflect.MakeSlice(v.Type(), 0, 0).Interface()
+	}
+	return value
+}
+
+// unmarshalOptionJSON fills value with the JSON encoded option given by b and returns whether the option is set
+func unmarshalOptionJSON(b json.RawMessage, value interface{}) (bool, error) {
+	if len(b) == 0 || string(b) == "null" {
+		return false, nil
+	}
+	return true, json.Unmarshal(b, value)
+}
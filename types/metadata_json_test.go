@@ -0,0 +1,162 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+func TestMetadata_JSONRoundtrip(t *testing.T) {
+	for _, meta := range []*Metadata{&exampleMetadataV0, &exampleMetadataV1, &exampleMetadataV2, &exampleMetadataV3,
+		ExamplaryMetadataV4, ExamplaryMetadataV8, ExamplaryMetadataV9, ExamplaryMetadataV10,
+		ExamplaryMetadataV11Substrate, &exampleMetadataV14} {
+		bz, err := json.Marshal(meta)
+		assert.NoError(t, err)
+
+		var decoded Metadata
+		assert.NoError(t, json.Unmarshal(bz, &decoded))
+		assert.Equal(t, meta.Version, decoded.Version)
+
+		// compare the SCALE encodings, since empty byte slices are decoded as non-nil
+		assert.Equal(t, hexEncodeMetadata(t, meta), hexEncodeMetadata(t, &decoded))
+	}
+}
+
+func hexEncodeMetadata(t *testing.T, meta *Metadata) string {
+	s, err := EncodeToHexString(meta)
+	assert.NoError(t, err)
+	return s
+}
+
+func TestMetadata_MarshalJSON_V8(t *testing.T) {
+	bz, err := json.Marshal(ExamplaryMetadataV8)
+	assert.NoError(t, err)
+	s := string(bz)
+
+	assert.Contains(t, s, `{"magicNumber":1635018093,"metadata":{"v8":{"modules":[{"name":"System","storage":`+
+		`{"prefix":"System","items":[{"name":"AccountNonce","modifier":"Default","type":{"map":`+
+		`{"hasher":"Blake2_256","key":"T::AccountId","value":"T::Index","linked":false}},"fallback":"0x00000000",`+
+		`"documentation":[" Extrinsics nonce for accounts."]}`)
+	assert.Contains(t, s, `{"name":"ExtrinsicCount","modifier":"Optional","type":{"plain":"u32"},"fallback":"0x00"`)
+	// json.Marshal escapes < and >
+	assert.Contains(t, s, `{"name":"transfer","args":[{"name":"dest",`+
+		`"type":"\u003cT::Lookup as StaticLookup\u003e::Source"},{"name":"value","type":"Compact\u003cT::Balance\u003e"}]`)
+	assert.Contains(t, s, `{"name":"ExistentialDeposit","type":"T::Balance","value":"0x00407a10f35a00000000000000000000"`)
+}
+
+func TestMetadata_MarshalJSON_V14(t *testing.T) {
+	bz, err := json.Marshal(exampleMetadataV14)
+	assert.NoError(t, err)
+	s := string(bz)
+
+	assert.Contains(t, s, `{"magicNumber":1635018093,"metadata":{"v14":{"lookup":{"types":[`+
+		`{"id":0,"type":{"path":[],"params":[],"def":{"primitive":"U8"},"docs":[]}},`+
+		`{"id":1,"type":{"path":[],"params":[],"def":{"array":{"len":32,"type":0}},"docs":[]}},`+
+		`{"id":2,"type":{"path":["sp_core","crypto","AccountId32"],"params":[],"def":{"composite":{"fields":`+
+		`[{"name":null,"type":1,"typeName":"[u8; 32]","docs":[]}]}},"docs":[]}}`)
+	assert.Contains(t, s, `{"name":"EmptyPallet","storage":null,"calls":null,"events":null,"constants":[],`+
+		`"errors":null,"index":1}`)
+	assert.Contains(t, s, `{"name":"Account","modifier":"Default","type":{"map":{"hashers":["Blake2_128Concat"],`+
+		`"key":2,"value":10}},"fallback":"0x0000000000000000000000000000000000000000",`)
+	assert.Contains(t, s, `"hashers":["Blake2_128Concat","Twox64Concat"],"key":11`)
+	assert.Contains(t, s, `"extrinsic":{"type":6,"version":4,"signedExtensions":[{"identifier":"CheckNonce","type":3,`+
+		`"additionalSigned":9}]},"type":9}}}`)
+}
+
+func TestMetadata_MarshalJSON_EmptyLists(t *testing.T) {
+	id := NewSi1LookupTypeID
+	meta := NewMetadataV14()
+	meta.MagicNumber = MagicNumber
+	meta.AsMetadataV14.Lookup = PortableRegistry{
+		{ID: id(0), Type: Si1Type{Def: Si1TypeDef{IsTuple: true}}},
+		{ID: id(1), Type: Si1Type{Def: Si1TypeDef{IsComposite: true}}},
+		{ID: id(2), Type: Si1Type{Def: Si1TypeDef{IsVariant: true, AsVariant: Si1TypeDefVariant{
+			Variants: []Si1Variant{{Name: "None"}}}}}},
+	}
+	meta.AsMetadataV14.Pallets = []PalletMetadataV14{{
+		Name:       "Empty",
+		HasStorage: true,
+		Storage: StorageMetadataV14{Prefix: "Empty", Items: []StorageEntryMetadataV14{{
+			Name:     "Unit",
+			Modifier: StorageFunctionModifierV0{IsOptional: true},
+			Type:     StorageEntryTypeV14{IsPlainType: true, AsPlainType: id(0)},
+		}}},
+	}}
+
+	bz, err := json.Marshal(meta)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"magicNumber":1635018093,"metadata":{"v14":{"lookup":{"types":[`+
+		`{"id":0,"type":{"path":[],"params":[],"def":{"tuple":[]},"docs":[]}},`+
+		`{"id":1,"type":{"path":[],"params":[],"def":{"composite":{"fields":[]}},"docs":[]}},`+
+		`{"id":2,"type":{"path":[],"params":[],"def":{"variant":{"variants":[`+
+		`{"name":"None","fields":[],"index":0,"docs":[]}]}},"docs":[]}}]},`+
+		`"pallets":[{"name":"Empty","storage":{"prefix":"Empty","items":[{"name":"Unit","modifier":"Optional",`+
+		`"type":{"plain":0},"fallback":"0x","docs":[]}]},"calls":null,"events":null,"constants":[],"errors":null,`+
+		`"index":0}],"extrinsic":{"type":0,"version":0,"signedExtensions":[]},"type":0}}}`, string(bz))
+}
+
+// TestMetadata_MarshalJSON_NoNullLists checks that lists decoded from SCALE, which are nil if they are empty, are not
+// encoded as null
+func TestMetadata_MarshalJSON_NoNullLists(t *testing.T) {
+	lists := map[string]bool{"modules": true, "pallets": true, "types": true, "path": true, "params": true,
+		"fields": true, "variants": true, "tuple": true, "docs": true, "documentation": true, "args": true,
+		"arguments": true, "items": true, "functions": true, "constants": true, "hashers": true,
+		"signedExtensions": true}
+
+	for _, meta := range []*Metadata{&exampleMetadataV0, &exampleMetadataV1, &exampleMetadataV2, &exampleMetadataV3,
+		ExamplaryMetadataV4, ExamplaryMetadataV8, ExamplaryMetadataV9, ExamplaryMetadataV10,
+		ExamplaryMetadataV11Substrate, &exampleMetadataV14} {
+		var decoded Metadata
+		assert.NoError(t, DecodeFromHexString(hexEncodeMetadata(t, meta), &decoded))
+		bz, err := json.Marshal(decoded)
+		assert.NoError(t, err)
+
+		var v interface{}
+		assert.NoError(t, json.Unmarshal(bz, &v))
+		var walk func(v interface{})
+		walk = func(v interface{}) {
+			switch v := v.(type) {
+			case map[string]interface{}:
+				for key, value := range v {
+					assert.False(t, lists[key] && value == nil, "%v is null in metadata v%v", key, meta.Version)
+					walk(value)
+				}
+			case []interface{}:
+				for _, value := range v {
+					walk(value)
+				}
+			}
+		}
+		walk(v)
+	}
+}
+
+func TestMetadata_UnmarshalJSON_Invalid(t *testing.T) {
+	var meta Metadata
+	assert.EqualError(t, json.Unmarshal([]byte(`{"magicNumber":1635018093,"metadata":{"v12":{}}}`), &meta),
+		"unsupported metadata version 12")
+	assert.EqualError(t, json.Unmarshal([]byte(`{"magicNumber":1635018093,"metadata":{}}`), &meta),
+		"expected metadata of a single version, but got 0")
+	assert.EqualError(t, json.Unmarshal([]byte(`{"magicNumber":1635018093,"metadata":{"v8":{"modules":[`+
+		`{"name":"System","storage":{"prefix":"System","items":[{"name":"Number","modifier":"Default",`+
+		`"type":{"map":{"hasher":"Sha256"}}}]}}]}}}`), &meta), "received unexpected storage hasher Sha256")
+}
@@ -0,0 +1,669 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/zenghq3/go-substrate-rpc-client/scale"
+)
+
+// Modelled after packages/types/src/interfaces/scaleInfo/definitions.ts and the portable registry of
+// https://github.com/paritytech/scale-info
+
+// Si1LookupTypeID is the compact encoded numeric ID of a type within the PortableRegistry
+type Si1LookupTypeID struct {
+	UCompact
+}
+
+// NewSi1LookupTypeID creates a new Si1LookupTypeID type
+func NewSi1LookupTypeID(id uint64) Si1LookupTypeID {
+	return Si1LookupTypeID{UCompact(id)}
+}
+
+// Int64 returns the ID as an int64
+func (s Si1LookupTypeID) Int64() int64 {
+	return int64(s.UCompact)
+}
+
+// UnmarshalJSON fills s with the JSON encoded number given by b
+func (s *Si1LookupTypeID) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &s.UCompact)
+}
+
+// MarshalJSON returns the JSON encoding of s as number
+func (s Si1LookupTypeID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(uint64(s.UCompact))
+}
+
+// Si1Path is the fully qualified path of a type, e.g. ["sp_core", "crypto", "AccountId32"]
+type Si1Path []Text
+
+// String returns the path joined with "::"
+func (p Si1Path) String() string {
+	s := make([]string, len(p))
+	for i, t := range p {
+		s[i] = string(t)
+	}
+	return strings.Join(s, "::")
+}
+
+// MarshalJSON returns the JSON encoding of p, with [] for an empty path
+func (p Si1Path) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		p = Si1Path{}
+	}
+	return json.Marshal([]Text(p))
+}
+
+// Si1Type is a single type definition of the PortableRegistry
+type Si1Type struct {
+	Path   Si1Path            `json:"path"`
+	Params []Si1TypeParameter `json:"params"`
+	Def    Si1TypeDef         `json:"def"`
+	Docs   []Text             `json:"docs"`
+}
+
+func (s Si1Type) MarshalJSON() ([]byte, error) {
+	type plain Si1Type
+	if s.Params == nil {
+		s.Params = []Si1TypeParameter{}
+	}
+	if s.Docs == nil {
+		s.Docs = []Text{}
+	}
+	return json.Marshal(plain(s))
+}
+
+// Si1TypeParameter is a generic type parameter of a Si1Type, the type is missing for parameters that are not used
+// in the type definition
+type Si1TypeParameter struct {
+	Name    Text
+	HasType bool
+	Type    Si1LookupTypeID
+}
+
+func (s *Si1TypeParameter) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&s.Name)
+	if err != nil {
+		return err
+	}
+
+	return decoder.DecodeOption(&s.HasType, &s.Type)
+}
+
+func (s Si1TypeParameter) Encode(encoder scale.Encoder) error {
+	err := encoder.Encode(s.Name)
+	if err != nil {
+		return err
+	}
+
+	return encoder.EncodeOption(s.HasType, s.Type)
+}
+
+// UnmarshalJSON fills s with the JSON encoded type parameter given by b
+func (s *Si1TypeParameter) UnmarshalJSON(b []byte) error {
+	var tmp struct {
+		Name Text            `json:"name"`
+		Type json.RawMessage `json:"type"`
+	}
+	err := json.Unmarshal(b, &tmp)
+	if err != nil {
+		return err
+	}
+
+	*s = Si1TypeParameter{Name: tmp.Name}
+	s.HasType, err = unmarshalOptionJSON(tmp.Type, &s.Type)
+	return err
+}
+
+// MarshalJSON returns the JSON encoding of s, with null for missing options
+func (s Si1TypeParameter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name Text        `json:"name"`
+		Type interface{} `json:"type"`
+	}{
+		Name: s.Name,
+		Type: optionJSON(s.HasType, s.Type),
+	})
+}
+
+// Si1TypeDef is an enum of all type definitions supported by the PortableRegistry
+type Si1TypeDef struct {
+	IsComposite   bool
+	AsComposite   Si1TypeDefComposite // 0
+	IsVariant     bool
+	AsVariant     Si1TypeDefVariant // 1
+	IsSequence    bool
+	AsSequence    Si1TypeDefSequence // 2
+	IsArray       bool
+	AsArray       Si1TypeDefArray // 3
+	IsTuple       bool
+	AsTuple       Si1TypeDefTuple // 4
+	IsPrimitive   bool
+	AsPrimitive   Si0TypeDefPrimitive // 5
+	IsCompact     bool
+	AsCompact     Si1TypeDefCompact // 6
+	IsBitSequence bool
+	AsBitSequence Si1TypeDefBitSequence // 7
+}
+
+func (s *Si1TypeDef) Decode(decoder scale.Decoder) error {
+	var t uint8
+	err := decoder.Decode(&t)
+	if err != nil {
+		return err
+	}
+
+	switch t {
+	case 0:
+		s.IsComposite = true
+		err = decoder.Decode(&s.AsComposite)
+	case 1:
+		s.IsVariant = true
+		err = decoder.Decode(&s.AsVariant)
+	case 2:
+		s.IsSequence = true
+		err = decoder.Decode(&s.AsSequence)
+	case 3:
+		s.IsArray = true
+		err = decoder.Decode(&s.AsArray)
+	case 4:
+		s.IsTuple = true
+		err = decoder.Decode(&s.AsTuple)
+	case 5:
+		s.IsPrimitive = true
+		err = decoder.Decode(&s.AsPrimitive)
+	case 6:
+		s.IsCompact = true
+		err = decoder.Decode(&s.AsCompact)
+	case 7:
+		s.IsBitSequence = true
+		err = decoder.Decode(&s.AsBitSequence)
+	default:
+		return fmt.Errorf("received unexpected type definition %v", t)
+	}
+	return err
+}
+
+func (s Si1TypeDef) Encode(encoder scale.Encoder) error {
+	var t uint8
+	var v interface{}
+	switch {
+	case s.IsComposite:
+		t, v = 0, s.AsComposite
+	case s.IsVariant:
+		t, v = 1, s.AsVariant
+	case s.IsSequence:
+		t, v = 2, s.AsSequence
+	case s.IsArray:
+		t, v = 3, s.AsArray
+	case s.IsTuple:
+		t, v = 4, s.AsTuple
+	case s.IsPrimitive:
+		t, v = 5, s.AsPrimitive
+	case s.IsCompact:
+		t, v = 6, s.AsCompact
+	case s.IsBitSequence:
+		t, v = 7, s.AsBitSequence
+	default:
+		return fmt.Errorf("expected type definition, but none was set: %v", s)
+	}
+
+	err := encoder.PushByte(t)
+	if err != nil {
+		return err
+	}
+	return encoder.Encode(v)
+}
+
+// UnmarshalJSON fills s with the JSON encoded type definition given by b
+func (s *Si1TypeDef) UnmarshalJSON(b []byte) error {
+	variant, value, err := unmarshalEnumJSON(b)
+	if err != nil {
+		return err
+	}
+
+	*s = Si1TypeDef{}
+	switch variant {
+	case "composite":
+		s.IsComposite = true
+		return json.Unmarshal(value, &s.AsComposite)
+	case "variant":
+		s.IsVariant = true
+		return json.Unmarshal(value, &s.AsVariant)
+	case "sequence":
+		s.IsSequence = true
+		return json.Unmarshal(value, &s.AsSequence)
+	case "array":
+		s.IsArray = true
+		return json.Unmarshal(value, &s.AsArray)
+	case "tuple":
+		s.IsTuple = true
+		return json.Unmarshal(value, &s.AsTuple)
+	case "primitive":
+		s.IsPrimitive = true
+		return json.Unmarshal(value, &s.AsPrimitive)
+	case "compact":
+		s.IsCompact = true
+		return json.Unmarshal(value, &s.AsCompact)
+	case "bitSequence":
+		s.IsBitSequence = true
+		return json.Unmarshal(value, &s.AsBitSequence)
+	default:
+		return fmt.Errorf("received unexpected type definition %v", variant)
+	}
+}
+
+// MarshalJSON returns the JSON encoding of s
+func (s Si1TypeDef) MarshalJSON() ([]byte, error) {
+	switch {
+	case s.IsComposite:
+		return marshalEnumJSON("composite", s.AsComposite)
+	case s.IsVariant:
+		return marshalEnumJSON("variant", s.AsVariant)
+	case s.IsSequence:
+		return marshalEnumJSON("sequence", s.AsSequence)
+	case s.IsArray:
+		return marshalEnumJSON("array", s.AsArray)
+	case s.IsTuple:
+		return marshalEnumJSON("tuple", s.AsTuple)
+	case s.IsPrimitive:
+		return marshalEnumJSON("primitive", s.AsPrimitive)
+	case s.IsCompact:
+		return marshalEnumJSON("compact", s.AsCompact)
+	case s.IsBitSequence:
+		return marshalEnumJSON("bitSequence", s.AsBitSequence)
+	default:
+		return nil, fmt.Errorf("expected type definition, but none was set: %v", s)
+	}
+}
+
+// Si1TypeDefComposite is a struct or a tuple struct, fields of tuple structs do not have a name
+type Si1TypeDefComposite struct {
+	Fields []Si1Field `json:"fields"`
+}
+
+func (s Si1TypeDefComposite) MarshalJSON() ([]byte, error) {
+	type plain Si1TypeDefComposite
+	if s.Fields == nil {
+		s.Fields = []Si1Field{}
+	}
+	return json.Marshal(plain(s))
+}
+
+// Si1Field is a field of a composite type or of an enum variant
+type Si1Field struct {
+	HasName     bool
+	Name        Text
+	Type        Si1LookupTypeID
+	HasTypeName bool
+	TypeName    Text
+	Docs        []Text
+}
+
+func (s *Si1Field) Decode(decoder scale.Decoder) error {
+	err := decoder.DecodeOption(&s.HasName, &s.Name)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&s.Type)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.DecodeOption(&s.HasTypeName, &s.TypeName)
+	if err != nil {
+		return err
+	}
+
+	return decoder.Decode(&s.Docs)
+}
+
+func (s Si1Field) Encode(encoder scale.Encoder) error {
+	err := encoder.EncodeOption(s.HasName, s.Name)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.Encode(s.Type)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.EncodeOption(s.HasTypeName, s.TypeName)
+	if err != nil {
+		return err
+	}
+
+	return encoder.Encode(s.Docs)
+}
+
+// UnmarshalJSON fills s with the JSON encoded field given by b
+func (s *Si1Field) UnmarshalJSON(b []byte) error {
+	var tmp struct {
+		Name     json.RawMessage `json:"name"`
+		Type     Si1LookupTypeID `json:"type"`
+		TypeName json.RawMessage `json:"typeName"`
+		Docs     []Text          `json:"docs"`
+	}
+	err := json.Unmarshal(b, &tmp)
+	if err != nil {
+		return err
+	}
+
+	*s = Si1Field{Type: tmp.Type, Docs: tmp.Docs}
+	s.HasName, err = unmarshalOptionJSON(tmp.Name, &s.Name)
+	if err != nil {
+		return err
+	}
+	s.HasTypeName, err = unmarshalOptionJSON(tmp.TypeName, &s.TypeName)
+	return err
+}
+
+// MarshalJSON returns the JSON encoding of s, with null for missing options and [] for empty lists
+func (s Si1Field) MarshalJSON() ([]byte, error) {
+	if s.Docs == nil {
+		s.Docs = []Text{}
+	}
+	return json.Marshal(struct {
+		Name     interface{}     `json:"name"`
+		Type     Si1LookupTypeID `json:"type"`
+		TypeName interface{}     `json:"typeName"`
+		Docs     []Text          `json:"docs"`
+	}{
+		Name:     optionJSON(s.HasName, s.Name),
+		Type:     s.Type,
+		TypeName: optionJSON(s.HasTypeName, s.TypeName),
+		Docs:     s.Docs,
+	})
+}
+
+// Si1TypeDefVariant is an enum, the index of a variant is the byte that prefixes its encoded value
+type Si1TypeDefVariant struct {
+	Variants []Si1Variant `json:"variants"`
+}
+
+// FindVariantByIndex returns the variant with the given index
+func (s Si1TypeDefVariant) FindVariantByIndex(index uint8) (Si1Variant, error) {
+	for _, v := range s.Variants {
+		if v.Index == index {
+			return v, nil
+		}
+	}
+	return Si1Variant{}, fmt.Errorf("variant with index %v not found", index)
+}
+
+// FindVariantByName returns the variant with the given name
+func (s Si1TypeDefVariant) FindVariantByName(name string) (Si1Variant, error) {
+	for _, v := range s.Variants {
+		if string(v.Name) == name {
+			return v, nil
+		}
+	}
+	return Si1Variant{}, fmt.Errorf("variant %v not found", name)
+}
+
+func (s Si1TypeDefVariant) MarshalJSON() ([]byte, error) {
+	type plain Si1TypeDefVariant
+	if s.Variants == nil {
+		s.Variants = []Si1Variant{}
+	}
+	return json.Marshal(plain(s))
+}
+
+// Si1Variant is a single variant of an enum
+type Si1Variant struct {
+	Name   Text       `json:"name"`
+	Fields []Si1Field `json:"fields"`
+	Index  uint8      `json:"index"`
+	Docs   []Text     `json:"docs"`
+}
+
+func (s Si1Variant) MarshalJSON() ([]byte, error) {
+	type plain Si1Variant
+	if s.Fields == nil {
+		s.Fields = []Si1Field{}
+	}
+	if s.Docs == nil {
+		s.Docs = []Text{}
+	}
+	return json.Marshal(plain(s))
+}
+
+// Si1TypeDefSequence is a vector of elements of the same type
+type Si1TypeDefSequence struct {
+	Type Si1LookupTypeID `json:"type"`
+}
+
+// Si1TypeDefArray is a fixed length array of elements of the same type
+type Si1TypeDefArray struct {
+	Len  uint32          `json:"len"`
+	Type Si1LookupTypeID `json:"type"`
+}
+
+// Si1TypeDefTuple is a tuple of the given types, the unit type () is an empty tuple
+type Si1TypeDefTuple []Si1LookupTypeID
+
+// MarshalJSON returns the JSON encoding of s, with [] for the unit type
+func (s Si1TypeDefTuple) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		s = Si1TypeDefTuple{}
+	}
+	return json.Marshal([]Si1LookupTypeID(s))
+}
+
+// Si1TypeDefCompact is a compact encoded value of the given type
+type Si1TypeDefCompact struct {
+	Type Si1LookupTypeID `json:"type"`
+}
+
+// Si1TypeDefBitSequence is a vector of bits, stored in elements of BitStoreType ordered as per BitOrderType
+type Si1TypeDefBitSequence struct {
+	BitStoreType Si1LookupTypeID `json:"bitStoreType"`
+	BitOrderType Si1LookupTypeID `json:"bitOrderType"`
+}
+
+// Si0TypeDefPrimitive is an enum of the primitive types supported by the PortableRegistry
+type Si0TypeDefPrimitive byte
+
+const (
+	IsBool Si0TypeDefPrimitive = iota
+	IsChar
+	IsStr
+	IsU8
+	IsU16
+	IsU32
+	IsU64
+	IsU128
+	IsU256
+	IsI8
+	IsI16
+	IsI32
+	IsI64
+	IsI128
+	IsI256
+)
+
+var si0TypeDefPrimitiveNames = []string{"bool", "char", "str", "u8", "u16", "u32", "u64", "u128", "u256", "i8", "i16",
+	"i32", "i64", "i128", "i256"}
+
+func (s *Si0TypeDefPrimitive) Decode(decoder scale.Decoder) error {
+	b, err := decoder.ReadOneByte()
+	if err != nil {
+		return err
+	}
+	if int(b) >= len(si0TypeDefPrimitiveNames) {
+		return fmt.Errorf("received unexpected primitive type %v", b)
+	}
+	*s = Si0TypeDefPrimitive(b)
+	return nil
+}
+
+func (s Si0TypeDefPrimitive) Encode(encoder scale.Encoder) error {
+	if int(s) >= len(si0TypeDefPrimitiveNames) {
+		return fmt.Errorf("expected primitive type, but got %v", byte(s))
+	}
+	return encoder.PushByte(byte(s))
+}
+
+// String returns the Rust name of the primitive, e.g. u32
+func (s Si0TypeDefPrimitive) String() string {
+	if int(s) >= len(si0TypeDefPrimitiveNames) {
+		return fmt.Sprintf("Si0TypeDefPrimitive(%v)", byte(s))
+	}
+	return si0TypeDefPrimitiveNames[s]
+}
+
+// UnmarshalJSON fills s with the JSON encoded name of the primitive given by b, e.g. U32
+func (s *Si0TypeDefPrimitive) UnmarshalJSON(b []byte) error {
+	name, err := unmarshalNameJSON(b)
+	if err != nil {
+		return err
+	}
+	for i, n := range si0TypeDefPrimitiveNames {
+		if strings.EqualFold(n, name) {
+			*s = Si0TypeDefPrimitive(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("received unexpected primitive type %v", name)
+}
+
+// MarshalJSON returns the JSON encoded name of the primitive as used by polkadot-js, e.g. U32
+func (s Si0TypeDefPrimitive) MarshalJSON() ([]byte, error) {
+	if int(s) >= len(si0TypeDefPrimitiveNames) {
+		return nil, fmt.Errorf("expected primitive type, but got %v", byte(s))
+	}
+	name := si0TypeDefPrimitiveNames[s]
+	return json.Marshal(strings.ToUpper(name[:1]) + name[1:])
+}
+
+// PortableTypeV14 is a type of the PortableRegistry together with its ID
+type PortableTypeV14 struct {
+	ID   Si1LookupTypeID `json:"id"`
+	Type Si1Type         `json:"type"`
+}
+
+// PortableRegistry contains all types used in metadata V14 and later. Calls, events, storage entries and constants
+// refer to these types by their Si1LookupTypeID.
+type PortableRegistry []PortableTypeV14
+
+// FindType returns the type with the given ID
+func (p PortableRegistry) FindType(id Si1LookupTypeID) (Si1Type, error) {
+	// types are usually stored in order of their IDs
+	if i := id.Int64(); i < int64(len(p)) && p[i].ID == id {
+		return p[i].Type, nil
+	}
+	for _, t := range p {
+		if t.ID == id {
+			return t.Type, nil
+		}
+	}
+	return Si1Type{}, fmt.Errorf("type with ID %v not found in portable registry", id.Int64())
+}
+
+// TypeName returns a human readable name for the type with the given ID, such as `Vec<AccountId32>` or `u128`. It
+// is meant for display purposes and is not guaranteed to be unique.
+func (p PortableRegistry) TypeName(id Si1LookupTypeID) (string, error) {
+	t, err := p.FindType(id)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case t.Def.IsPrimitive:
+		return t.Def.AsPrimitive.String(), nil
+	case t.Def.IsSequence:
+		n, err := p.TypeName(t.Def.AsSequence.Type)
+		if err != nil {
+			return "", err
+		}
+		return "Vec<" + n + ">", nil
+	case t.Def.IsArray:
+		n, err := p.TypeName(t.Def.AsArray.Type)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("[%v; %v]", n, t.Def.AsArray.Len), nil
+	case t.Def.IsTuple:
+		names := make([]string, len(t.Def.AsTuple))
+		for i, e := range t.Def.AsTuple {
+			names[i], err = p.TypeName(e)
+			if err != nil {
+				return "", err
+			}
+		}
+		return "(" + strings.Join(names, ", ") + ")", nil
+	case t.Def.IsCompact:
+		n, err := p.TypeName(t.Def.AsCompact.Type)
+		if err != nil {
+			return "", err
+		}
+		return "Compact<" + n + ">", nil
+	case t.Def.IsBitSequence:
+		return "BitVec", nil
+	}
+
+	if len(t.Path) == 0 {
+		return "", fmt.Errorf("composite or variant type with ID %v has no path", id.Int64())
+	}
+	name := string(t.Path[len(t.Path)-1])
+
+	params := make([]string, 0, len(t.Params))
+	for _, param := range t.Params {
+		if !param.HasType {
+			continue
+		}
+		n, err := p.TypeName(param.Type)
+		if err != nil {
+			return "", err
+		}
+		params = append(params, n)
+	}
+	if len(params) == 0 {
+		return name, nil
+	}
+	return name + "<" + strings.Join(params, ", ") + ">", nil
+}
+
+// portableRegistryJSON is the JSON encoding of PortableRegistry
+type portableRegistryJSON struct {
+	Types []PortableTypeV14 `json:"types"`
+}
+
+// UnmarshalJSON fills p with the JSON encoded types given by b
+func (p *PortableRegistry) UnmarshalJSON(b []byte) error {
+	var tmp portableRegistryJSON
+	err := json.Unmarshal(b, &tmp)
+	if err != nil {
+		return err
+	}
+	*p = tmp.Types
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of p as object with the list of types
+func (p PortableRegistry) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		p = PortableRegistry{}
+	}
+	return json.Marshal(portableRegistryJSON{Types: p})
+}
@@ -0,0 +1,84 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/zenghq3/go-substrate-rpc-client/types"
+)
+
+func TestSi1Type_EncodeDecode(t *testing.T) {
+	for _, pt := range examplePortableRegistry {
+		assertRoundtrip(t, pt)
+	}
+}
+
+func TestSi1Type_Encode(t *testing.T) {
+	assertEncode(t, []encodingAssert{
+		{Si1Type{Def: Si1TypeDef{IsSequence: true, AsSequence: Si1TypeDefSequence{Type: NewSi1LookupTypeID(0)}}},
+			MustHexDecodeString("0x0000020000")},
+		{Si1Type{Def: Si1TypeDef{IsPrimitive: true, AsPrimitive: IsU128}}, MustHexDecodeString("0x0000050700")},
+		{Si1Field{HasName: true, Name: "a", Type: NewSi1LookupTypeID(300)}, MustHexDecodeString("0x010461b1040000")},
+		{Si1TypeParameter{Name: "T", HasType: true, Type: NewSi1LookupTypeID(1)}, MustHexDecodeString("0x04540104")},
+	})
+}
+
+func TestSi0TypeDefPrimitive_Decode(t *testing.T) {
+	var p Si0TypeDefPrimitive
+	err := DecodeFromBytes([]byte{0x0f}, &p)
+	assert.EqualError(t, err, "received unexpected primitive type 15")
+
+	err = DecodeFromBytes([]byte{0x0e}, &p)
+	assert.NoError(t, err)
+	assert.Equal(t, IsI256, p)
+	assert.Equal(t, "i256", p.String())
+}
+
+func TestPortableRegistry_FindType(t *testing.T) {
+	typ, err := examplePortableRegistry.FindType(NewSi1LookupTypeID(2))
+	assert.NoError(t, err)
+	assert.Equal(t, "sp_core::crypto::AccountId32", typ.Path.String())
+
+	shuffled := PortableRegistry{examplePortableRegistry[3], examplePortableRegistry[0]}
+	typ, err = shuffled.FindType(NewSi1LookupTypeID(0))
+	assert.NoError(t, err)
+	assert.Equal(t, IsU8, typ.Def.AsPrimitive)
+
+	_, err = examplePortableRegistry.FindType(NewSi1LookupTypeID(100))
+	assert.EqualError(t, err, "type with ID 100 not found in portable registry")
+}
+
+func TestPortableRegistry_TypeName(t *testing.T) {
+	for id, expected := range map[uint64]string{
+		0:  "u8",
+		1:  "[u8; 32]",
+		2:  "AccountId32",
+		5:  "Compact<u128>",
+		6:  "Vec<u8>",
+		7:  "Call",
+		9:  "()",
+		10: "AccountInfo<u32>",
+		11: "(AccountId32, u32)",
+	} {
+		name, err := examplePortableRegistry.TypeName(NewSi1LookupTypeID(id))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, name)
+	}
+}
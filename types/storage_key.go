@@ -17,6 +17,7 @@
 package types
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 
@@ -36,7 +37,8 @@ func NewStorageKey(b []byte) StorageKey {
 }
 
 // CreateStorageKey uses the given metadata and to derive the right hashing of method, prefix as well as arguments to
-// create a hashed StorageKey
+// create a hashed StorageKey. Maps of metadata v14 with more than two keys are not supported, use CreateStorageKeyArgs
+// for them.
 func CreateStorageKey(meta *Metadata, prefix, method string, arg []byte, arg2 []byte) (StorageKey, error) {
 	stringKey := []byte(prefix + " " + method)
 
@@ -45,6 +47,11 @@ func CreateStorageKey(meta *Metadata, prefix, method string, arg []byte, arg2 []
 		return nil, err
 	}
 
+	if s, ok := entryMeta.(StorageEntryMetadataV14); ok && s.Type.IsMap && len(s.Type.AsMap.Hashers) > 2 {
+		return nil, fmt.Errorf("%v is a map with %v keys, use CreateStorageKeyArgs to create its keys", method,
+			len(s.Type.AsMap.Hashers))
+	}
+
 	if entryMeta.IsDoubleMap() {
 		return createKeyDoubleMap(meta, method, prefix, stringKey, arg, arg2, entryMeta)
 	}
@@ -52,6 +59,50 @@ func CreateStorageKey(meta *Metadata, prefix, method string, arg []byte, arg2 []
 	return createKey(meta, method, prefix, stringKey, arg, entryMeta)
 }
 
+// CreateStorageKeyArgs creates a hashed StorageKey like CreateStorageKey, but takes one encoded argument per map key.
+// This also supports storage maps of metadata v14 with more than two keys. The number of arguments must match the
+// number of map keys of the storage entry.
+func CreateStorageKeyArgs(meta *Metadata, prefix, method string, args ...[]byte) (StorageKey, error) {
+	entryMeta, err := meta.FindStorageEntryMetadata(prefix, method)
+	if err != nil {
+		return nil, err
+	}
+
+	if s, ok := entryMeta.(StorageEntryMetadataV14); ok && s.Type.IsMap {
+		hashers := s.Type.AsMap.Hashers
+		if len(args) != len(hashers) {
+			return nil, fmt.Errorf("%v.%v requires %v keys, but got %v", prefix, method, len(hashers), len(args))
+		}
+		key := createPrefixedKey(method, prefix)
+		for i, h := range hashers {
+			hasher, err := h.HashFunc()
+			if err != nil {
+				return nil, err
+			}
+			_, err = hasher.Write(args[i])
+			if err != nil {
+				return nil, err
+			}
+			key = append(key, hasher.Sum(nil)...)
+		}
+		return key, nil
+	}
+
+	var n int
+	switch {
+	case entryMeta.IsMap():
+		n = 1
+	case entryMeta.IsDoubleMap():
+		n = 2
+	}
+	if len(args) != n {
+		return nil, fmt.Errorf("%v.%v requires %v keys, but got %v", prefix, method, n, len(args))
+	}
+
+	args = append(args, nil, nil)
+	return CreateStorageKey(meta, prefix, method, args[0], args[1])
+}
+
 // Encode implements encoding for StorageKey, which just unwraps the bytes of StorageKey
 func (s StorageKey) Encode(encoder scale.Encoder) error {
 	return encoder.Write(s)
@@ -153,3 +204,237 @@ func createKey(meta *Metadata, method, prefix string, stringKey, arg []byte, ent
 func createPrefixedKey(method, prefix string) []byte {
 	return append(xxhash.New128([]byte(prefix)).Sum(nil), xxhash.New128([]byte(method)).Sum(nil)...)
 }
+
+// StorageKeyParts holds the parts a storage key consists of, as returned by SplitStorageKey
+type StorageKeyParts struct {
+	// Module is the storage prefix of the module, which is usually the module name
+	Module Text
+	// Method is the name of the storage entry
+	Method       Text
+	ModulePrefix []byte
+	MethodPrefix []byte
+	// Hashers holds the hasher of every map key, it is empty for plain storage entries
+	Hashers []StorageKeyHasher
+	// KeyTypes holds the type of every map key. It is only set for metadata before v14, use the storage entry of the
+	// pallet to look up the key types of later versions.
+	KeyTypes []Type
+	// Hashed holds the hashed map keys following the prefixes
+	Hashed []byte
+}
+
+// SplitStorageKey splits the given storage key into module prefix, method prefix and hashed map keys, finding the
+// storage entry the key belongs to in the metadata. This requires metadata v9 or later, since storage keys of earlier
+// versions are hashed as a whole.
+func SplitStorageKey(meta *Metadata, key StorageKey) (*StorageKeyParts, error) {
+	if len(key) < 32 {
+		return nil, fmt.Errorf("storage key %#x is too short to contain a module and a method prefix", []byte(key))
+	}
+
+	p := StorageKeyParts{ModulePrefix: key[:16], MethodPrefix: key[16:32], Hashed: key[32:]}
+
+	var entries []storageEntryKeys
+	switch {
+	case meta.IsMetadataV9:
+		entries = storageEntryKeysV8(meta.AsMetadataV9.Modules)
+	case meta.IsMetadataV10:
+		entries = storageEntryKeysV10(meta.AsMetadataV10.Modules)
+	case meta.IsMetadataV11:
+		entries = storageEntryKeysV11(meta.AsMetadataV11.Modules)
+	case meta.IsMetadataV14:
+		entries = storageEntryKeysV14(meta.AsMetadataV14.Pallets)
+	default:
+		return nil, fmt.Errorf("splitting storage keys requires metadata v9 or later, but got v%v", meta.Version)
+	}
+
+	found, err := p.find(entries)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("no storage entry found for storage key %#x", []byte(key))
+	}
+
+	if len(p.Hashers) == 0 && len(p.Hashed) > 0 {
+		return nil, fmt.Errorf("storage key %#x is too long for plain storage entry %v.%v", []byte(key), p.Module,
+			p.Method)
+	}
+
+	return &p, nil
+}
+
+// DecodeKeysWith walks through the hashed map keys, calling decode with the hash of every key. For hashers that
+// append the plain map key to the hash, decode must read the encoded map key from decoder.
+func (p StorageKeyParts) DecodeKeysWith(decode func(i int, hash []byte, decoder scale.Decoder) error) error {
+	reader := bytes.NewReader(p.Hashed)
+	decoder := scale.NewDecoder(reader)
+
+	for i, h := range p.Hashers {
+		hash := make([]byte, h.HashLength)
+		if h.HashLength > 0 {
+			err := decoder.Read(hash)
+			if err != nil {
+				return fmt.Errorf("unable to read hash of key %v of %v.%v: %v", i, p.Module, p.Method, err)
+			}
+		}
+
+		err := decode(i, hash, *decoder)
+		if err != nil {
+			return err
+		}
+	}
+
+	if reader.Len() > 0 {
+		return fmt.Errorf("%v bytes left after decoding the keys of %v.%v", reader.Len(), p.Module, p.Method)
+	}
+	return nil
+}
+
+// DecodeKeys decodes the plain map keys into the given targets, one per map key. Keys hashed with a hasher that does
+// not retain the plain key, like Blake2_256, cannot be recovered and require a nil target.
+func (p StorageKeyParts) DecodeKeys(targets ...interface{}) error {
+	if len(targets) != len(p.Hashers) {
+		return fmt.Errorf("%v.%v has %v keys, but got %v targets", p.Module, p.Method, len(p.Hashers), len(targets))
+	}
+
+	return p.DecodeKeysWith(func(i int, hash []byte, decoder scale.Decoder) error {
+		if !p.Hashers[i].Concat {
+			if targets[i] != nil {
+				return fmt.Errorf("key %v of %v.%v is hashed with %v and cannot be decoded", i, p.Module, p.Method,
+					p.Hashers[i].Name)
+			}
+			return nil
+		}
+
+		if targets[i] == nil {
+			return fmt.Errorf("key %v of %v.%v is stored in plain text and requires a target", i, p.Module, p.Method)
+		}
+		err := decoder.Decode(targets[i])
+		if err != nil {
+			return fmt.Errorf("unable to decode key %v of %v.%v: %v", i, p.Module, p.Method, err)
+		}
+		return nil
+	})
+}
+
+type keyHasher interface {
+	KeyHasher() (StorageKeyHasher, error)
+}
+
+// find sets the names, hashers and key types of the storage entry the key belongs to, if any
+func (p *StorageKeyParts) find(entries []storageEntryKeys) (bool, error) {
+	for _, e := range entries {
+		if !isPrefixOf(p.ModulePrefix, e.prefix) || !isPrefixOf(p.MethodPrefix, e.name) {
+			continue
+		}
+
+		p.Module = e.prefix
+		p.Method = e.name
+		p.KeyTypes = e.keyTypes
+		p.Hashers = make([]StorageKeyHasher, len(e.hashers))
+		for i, h := range e.hashers {
+			var err error
+			p.Hashers[i], err = h.KeyHasher()
+			if err != nil {
+				return true, err
+			}
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// storageEntryKeys is a storage entry with the hashers and, before metadata v14, the types of its map keys, in a form
+// common to all metadata versions
+type storageEntryKeys struct {
+	prefix   Text
+	name     Text
+	keyTypes []Type
+	hashers  []keyHasher
+}
+
+func storageEntryKeysV8(modules []ModuleMetadataV8) []storageEntryKeys {
+	var entries []storageEntryKeys
+	for _, m := range modules {
+		if !m.HasStorage {
+			continue
+		}
+		for _, s := range m.Storage.Items {
+			e := storageEntryKeys{prefix: m.Storage.Prefix, name: s.Name}
+			switch t := s.Type; {
+			case t.IsMap:
+				e.keyTypes, e.hashers = []Type{t.AsMap.Key}, []keyHasher{t.AsMap.Hasher}
+			case t.IsDoubleMap:
+				e.keyTypes = []Type{t.AsDoubleMap.Key1, t.AsDoubleMap.Key2}
+				e.hashers = []keyHasher{t.AsDoubleMap.Hasher, t.AsDoubleMap.Key2Hasher}
+			}
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+func storageEntryKeysV10(modules []ModuleMetadataV10) []storageEntryKeys {
+	var entries []storageEntryKeys
+	for _, m := range modules {
+		if !m.HasStorage {
+			continue
+		}
+		for _, s := range m.Storage.Items {
+			e := storageEntryKeys{prefix: m.Storage.Prefix, name: s.Name}
+			switch t := s.Type; {
+			case t.IsMap:
+				e.keyTypes, e.hashers = []Type{t.AsMap.Key}, []keyHasher{t.AsMap.Hasher}
+			case t.IsDoubleMap:
+				e.keyTypes = []Type{t.AsDoubleMap.Key1, t.AsDoubleMap.Key2}
+				e.hashers = []keyHasher{t.AsDoubleMap.Hasher, t.AsDoubleMap.Key2Hasher}
+			}
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+func storageEntryKeysV11(modules []ModuleMetadataV11) []storageEntryKeys {
+	var entries []storageEntryKeys
+	for _, m := range modules {
+		if !m.HasStorage {
+			continue
+		}
+		for _, s := range m.Storage.Items {
+			e := storageEntryKeys{prefix: m.Storage.Prefix, name: s.Name}
+			switch t := s.Type; {
+			case t.IsMap:
+				e.keyTypes, e.hashers = []Type{t.AsMap.Key}, []keyHasher{t.AsMap.Hasher}
+			case t.IsDoubleMap:
+				e.keyTypes = []Type{t.AsDoubleMap.Key1, t.AsDoubleMap.Key2}
+				e.hashers = []keyHasher{t.AsDoubleMap.Hasher, t.AsDoubleMap.Key2Hasher}
+			}
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+func storageEntryKeysV14(pallets []PalletMetadataV14) []storageEntryKeys {
+	var entries []storageEntryKeys
+	for _, m := range pallets {
+		if !m.HasStorage {
+			continue
+		}
+		for _, s := range m.Storage.Items {
+			e := storageEntryKeys{prefix: m.Storage.Prefix, name: s.Name}
+			if s.Type.IsMap {
+				for _, h := range s.Type.AsMap.Hashers {
+					e.hashers = append(e.hashers, h)
+				}
+			}
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// isPrefixOf returns true if prefix is the Twox128 hash of name
+func isPrefixOf(prefix []byte, name Text) bool {
+	return bytes.Equal(prefix, xxhash.New128([]byte(name)).Sum(nil))
+}
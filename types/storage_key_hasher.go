@@ -0,0 +1,76 @@
+// Go Substrate RPC Client (GSRPC) provides APIs and types around Polkadot and any Substrate-based chain RPC calls
+//
+// Copyright 2019 Centrifuge GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"hash"
+)
+
+// StorageKeyHasher describes how a map key is hashed within a storage key, independent of the metadata version
+type StorageKeyHasher struct {
+	Name string
+	// HashLength is the number of bytes of the hash, 0 for the identity hasher
+	HashLength int
+	// Concat is set if the plain map key is appended to the hash, so that the key can be recovered
+	Concat bool
+}
+
+var (
+	keyHasherBlake2_128       = StorageKeyHasher{Name: "Blake2_128", HashLength: 16}
+	keyHasherBlake2_256       = StorageKeyHasher{Name: "Blake2_256", HashLength: 32}
+	keyHasherBlake2_128Concat = StorageKeyHasher{Name: "Blake2_128Concat", HashLength: 16, Concat: true}
+	keyHasherTwox128          = StorageKeyHasher{Name: "Twox128", HashLength: 16}
+	keyHasherTwox256          = StorageKeyHasher{Name: "Twox256", HashLength: 32}
+	keyHasherTwox64Concat     = StorageKeyHasher{Name: "Twox64Concat", HashLength: 8, Concat: true}
+	keyHasherIdentity         = StorageKeyHasher{Name: "Identity", Concat: true}
+)
+
+// identity is a hash.Hash that returns the written data unchanged
+type identity struct {
+	data []byte
+}
+
+// newIdentity returns a new hash.Hash computing the identity of the written data
+func newIdentity() hash.Hash {
+	return &identity{}
+}
+
+// Write adds more data to the running hash. It never returns an error.
+func (i *identity) Write(p []byte) (n int, err error) {
+	i.data = append(i.data, p...)
+	return len(p), nil
+}
+
+// Sum appends the written data to b and returns the resulting slice
+func (i *identity) Sum(b []byte) []byte {
+	return append(b, i.data...)
+}
+
+// Reset resets the Hash to its initial state
+func (i *identity) Reset() {
+	i.data = nil
+}
+
+// Size returns the number of bytes Sum will return
+func (i *identity) Size() int {
+	return len(i.data)
+}
+
+// BlockSize returns the hash's underlying block size
+func (i *identity) BlockSize() int {
+	return 1
+}
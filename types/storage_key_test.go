@@ -17,6 +17,8 @@
 package types_test
 
 import (
+	"hash"
+	"strings"
 	"testing"
 
 	. "github.com/zenghq3/go-substrate-rpc-client/types"
@@ -186,3 +188,160 @@ func TestStorageKey_Eq(t *testing.T) {
 		{NewStorageKey([]byte{0}), NewBool(false), false},
 	})
 }
+
+func TestSplitStorageKeyDoubleMapV10(t *testing.T) {
+	key, err := CreateStorageKey(ExamplaryMetadataV10, "Session", "NextKeys",
+		MustHexDecodeString("0x343a73657373696f6e3a6b657973"), MustHexDecodeString(AlicePubKey))
+	assert.NoError(t, err)
+
+	parts, err := SplitStorageKey(ExamplaryMetadataV10, key)
+	assert.NoError(t, err)
+	assert.Equal(t, Text("Session"), parts.Module)
+	assert.Equal(t, Text("NextKeys"), parts.Method)
+	assert.Equal(t, []byte(key[:16]), parts.ModulePrefix)
+	assert.Equal(t, []byte(key[16:32]), parts.MethodPrefix)
+	assert.Equal(t, []string{"Twox64Concat", "Blake2_256"},
+		[]string{parts.Hashers[0].Name, parts.Hashers[1].Name})
+	assert.Equal(t, []Type{"Vec<u8>", "T::ValidatorId"}, parts.KeyTypes)
+
+	var keyTypeID Bytes
+	err = parts.DecodeKeys(&keyTypeID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Bytes(":session:keys"), keyTypeID)
+
+	var validator AccountID
+	err = parts.DecodeKeys(&keyTypeID, &validator)
+	assert.EqualError(t, err, "key 1 of Session.NextKeys is hashed with Blake2_256 and cannot be decoded")
+
+	err = parts.DecodeKeys(&keyTypeID)
+	assert.EqualError(t, err, "Session.NextKeys has 2 keys, but got 1 targets")
+}
+
+func TestSplitStorageKeyV14(t *testing.T) {
+	alice := MustHexDecodeString(AlicePubKey)
+
+	key, err := CreateStorageKey(&exampleMetadataV14, "System", "Account", alice, nil)
+	assert.NoError(t, err)
+	parts, err := SplitStorageKey(&exampleMetadataV14, key)
+	assert.NoError(t, err)
+	assert.Equal(t, Text("Account"), parts.Method)
+	assert.Nil(t, parts.KeyTypes)
+	var account AccountID
+	err = parts.DecodeKeys(&account)
+	assert.NoError(t, err)
+	assert.Equal(t, NewAccountID(alice), account)
+
+	key, err = CreateStorageKey(&exampleMetadataV14, "System", "Approvals", alice, []byte{0x05, 0, 0, 0})
+	assert.NoError(t, err)
+	parts, err = SplitStorageKey(&exampleMetadataV14, key)
+	assert.NoError(t, err)
+	var index U32
+	err = parts.DecodeKeys(&account, &index)
+	assert.NoError(t, err)
+	assert.Equal(t, NewAccountID(alice), account)
+	assert.Equal(t, U32(5), index)
+
+	key, err = CreateStorageKey(&exampleMetadataV14, "System", "Number", nil, nil)
+	assert.NoError(t, err)
+	parts, err = SplitStorageKey(&exampleMetadataV14, key)
+	assert.NoError(t, err)
+	assert.Empty(t, parts.Hashers)
+	assert.NoError(t, parts.DecodeKeys())
+
+	_, err = SplitStorageKey(&exampleMetadataV14, append(key, 0x01))
+	assert.EqualError(t, err, "storage key "+key.Hex()+"01 is too long for plain storage entry System.Number")
+}
+
+func TestSplitStorageKey_Errors(t *testing.T) {
+	_, err := SplitStorageKey(ExamplaryMetadataV4, make(StorageKey, 32))
+	assert.EqualError(t, err, "splitting storage keys requires metadata v9 or later, but got v4")
+
+	_, err = SplitStorageKey(ExamplaryMetadataV10, StorageKey{0x01, 0x02})
+	assert.EqualError(t, err, "storage key 0x0102 is too short to contain a module and a method prefix")
+
+	_, err = SplitStorageKey(ExamplaryMetadataV10, make(StorageKey, 32))
+	assert.EqualError(t, err, "no storage entry found for storage key 0x"+strings.Repeat("00", 32))
+}
+
+func TestStorageHasher_HashFunc(t *testing.T) {
+	data := []byte{0x01, 0x02, 0x03}
+
+	for _, h := range []interface {
+		HashFunc() (hash.Hash, error)
+		KeyHasher() (StorageKeyHasher, error)
+	}{
+		StorageHasher{IsBlake2_128: true},
+		StorageHasherV10{IsBlake2_128: true},
+		StorageHasherV10{IsBlake2_128Concat: true},
+		StorageHasherV11{IsBlake2_128Concat: true},
+		StorageHasherV11{IsTwox64Concat: true},
+		StorageHasherV11{IsIdentity: true},
+	} {
+		fn, err := h.HashFunc()
+		assert.NoError(t, err)
+		kh, err := h.KeyHasher()
+		assert.NoError(t, err)
+
+		_, err = fn.Write(data)
+		assert.NoError(t, err)
+		sum := fn.Sum(nil)
+		if kh.Concat {
+			assert.Equal(t, kh.HashLength+len(data), len(sum), kh.Name)
+			assert.Equal(t, data, sum[kh.HashLength:], kh.Name)
+		} else {
+			assert.Equal(t, kh.HashLength, len(sum), kh.Name)
+		}
+	}
+}
+
+func TestCreateStorageKeyArgs(t *testing.T) {
+	alice := MustHexDecodeString(AlicePubKey)
+
+	key, err := CreateStorageKeyArgs(ExamplaryMetadataV10, "System", "AccountNonce", alice)
+	assert.NoError(t, err)
+	expected, err := CreateStorageKey(ExamplaryMetadataV10, "System", "AccountNonce", alice, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, key)
+
+	key, err = CreateStorageKeyArgs(&exampleMetadataV14, "System", "Approvals", alice, []byte{0x05, 0, 0, 0})
+	assert.NoError(t, err)
+	expected, err = CreateStorageKey(&exampleMetadataV14, "System", "Approvals", alice, []byte{0x05, 0, 0, 0})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, key)
+
+	key, err = CreateStorageKeyArgs(ExamplaryMetadataV10, "Timestamp", "Now")
+	assert.NoError(t, err)
+	assert.Equal(t, "0xf0c365c3cf59d671eb72da0e7a4113c49f1f0515f462cdcf84e0f1d6045dfcbb", key.Hex())
+
+	_, err = CreateStorageKeyArgs(ExamplaryMetadataV10, "System", "AccountNonce")
+	assert.EqualError(t, err, "System.AccountNonce requires 1 keys, but got 0")
+
+	_, err = CreateStorageKeyArgs(&exampleMetadataV14, "System", "Approvals", alice)
+	assert.EqualError(t, err, "System.Approvals requires 2 keys, but got 1")
+}
+
+func TestCreateStorageKey_NMap(t *testing.T) {
+	meta := exampleMetadataV14
+	meta.AsMetadataV14.Pallets = []PalletMetadataV14{{
+		Name:       "Assets",
+		HasStorage: true,
+		Storage: StorageMetadataV14{Prefix: "Assets", Items: []StorageEntryMetadataV14{{
+			Name:     "Approvals",
+			Modifier: StorageFunctionModifierV0{IsOptional: true},
+			Type: StorageEntryTypeV14{IsMap: true, AsMap: MapTypeV14{
+				Hashers: []StorageHasherV11{{IsBlake2_128Concat: true}, {IsBlake2_128Concat: true},
+					{IsTwox64Concat: true}},
+				Key:   NewSi1LookupTypeID(11),
+				Value: NewSi1LookupTypeID(4),
+			}},
+		}}},
+	}}
+	alice := MustHexDecodeString(AlicePubKey)
+
+	_, err := CreateStorageKey(&meta, "Assets", "Approvals", alice, alice)
+	assert.EqualError(t, err, "Approvals is a map with 3 keys, use CreateStorageKeyArgs to create its keys")
+
+	key, err := CreateStorageKeyArgs(&meta, "Assets", "Approvals", []byte{0x01}, alice, alice)
+	assert.NoError(t, err)
+	assert.Len(t, key, 32+(16+1)+(16+32)+(8+32))
+}